@@ -17,9 +17,10 @@ package main
 import (
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/introspection"
 )
 
 func main() {
-	gslbutils.InitAmkoAPIServer()
+	gslbutils.InitAmkoAPIServer(&introspection.IntrospectionModel{}, &ingestion.OperationsModel{})
 	ingestion.Initialize()
 }
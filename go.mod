@@ -21,6 +21,8 @@ require (
 	github.com/onsi/gomega v1.9.0
 	github.com/openshift/api v3.9.0+incompatible
 	github.com/openshift/client-go v0.0.0-20191022152013-2823239d2298
+	github.com/prometheus/client_golang v1.7.1
+	github.com/prometheus/client_model v0.2.0
 	github.com/stretchr/testify v1.5.1 // indirect
 	github.com/vmware/load-balancer-and-ingress-services-for-kubernetes v0.0.0-20200918095748-36994e7375fc
 	golang.org/x/crypto v0.0.0-20200728195943-123391ffb6de // indirect
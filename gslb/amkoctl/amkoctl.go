@@ -0,0 +1,723 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+// Package amkoctl implements amkoctl, the command line tool to inspect and operate AMKO through
+// the AMKO API server and the kubernetes API.
+package amkoctl
+
+import (
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"io/ioutil"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	filter "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gdp_filter"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/introspection"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+	gslbcs "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned"
+
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Output formats
+const (
+	OutputTable = "table"
+	OutputJSON  = "json"
+)
+
+// Options are the global options of amkoctl.
+type Options struct {
+	// Server is the URL of the AMKO API server, if set, the kubernetes API isn't used
+	Server     string
+	Kubeconfig string
+	Context    string
+	Namespace  string
+	// Pod is the AMKO pod to reach through the kubernetes API, the leader is picked if it's empty
+	Pod    string
+	Output string
+}
+
+type command struct {
+	name  string
+	usage string
+	// offline commands don't talk to AMKO
+	offline bool
+	// kubeAPI commands request an operation of AMKO with the annotations of the GSLBConfig object,
+	// through the kubernetes API
+	kubeAPI bool
+	run     func(cmd *cmdContext, args []string) error
+}
+
+// cmdContext is what a command runs with.
+type cmdContext struct {
+	opts       Options
+	client     Client
+	gslbClient gslbcs.Interface
+	out        io.Writer
+}
+
+func getCommands() []command {
+	return []command{
+		{name: "list", usage: "list [--fqdn FQDN] [--cluster CLUSTER] [--namespace NS]\n\tList the GSLB services with their members and weights", run: listGS},
+		{name: "show", usage: "show GS\n\tShow the GS graph of a GSLB service against its state in the Avi cache", run: showGS},
+		{name: "explain", usage: "explain [--type INGRESS|LBSVC|ROUTE] [--cluster CLUSTER] [--namespace NS] [--name NAME] [--fqdn FQDN]\n\tExplain why the member cluster objects are, or aren't, members of a GSLB service", run: explain},
+		{name: "plan", usage: "plan [--fqdn FQDN]\n\tShow the changes planned for the Avi controller in the dry run mode", run: plan},
+		{name: "resync", usage: "resync [--key TENANT/GS]\n\tResync a GS graph, or all of them, to the Avi controller", kubeAPI: true, run: resync},
+		{name: "pause", usage: "pause\n\tPause the reconciliation of the Avi controller", kubeAPI: true, run: pause},
+		{name: "resume", usage: "resume\n\tResume the reconciliation of the Avi controller", kubeAPI: true, run: resume},
+		{name: "status", usage: "status\n\tShow the state of the reconciliation of the Avi controller", run: status},
+		{name: "validate", usage: "validate -f FILE [-f FILE]... [--clusters CLUSTER,...]\n\tValidate GSLBConfig and GDP manifests offline", offline: true, run: validate},
+		{name: "simulate", usage: "simulate -f FILE [-f FILE]... --objects DIR\n\tCompute the GSLB services from the GSLBConfig, GDP and GSLBHostRule manifests, and the\n\tmember cluster objects in DIR/CLUSTER/*.yaml, offline", offline: true, run: simulate},
+	}
+}
+
+func usage(out io.Writer, fs *flag.FlagSet) {
+	fmt.Fprintln(out, "Usage: amkoctl [options] COMMAND [args]\n\nCommands:")
+	for _, cmd := range getCommands() {
+		fmt.Fprintln(out, "  "+cmd.usage)
+	}
+	fmt.Fprintln(out, "\nOptions:")
+	fs.SetOutput(out)
+	fs.PrintDefaults()
+}
+
+// Run runs amkoctl with the command line arguments, and writes the output to out.
+func Run(args []string, out io.Writer) error {
+	var opts Options
+	fs := flag.NewFlagSet("amkoctl", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.StringVar(&opts.Server, "server", "", "URL of the AMKO API server, e.g. http://127.0.0.1:8080 with kubectl port-forward")
+	fs.StringVar(&opts.Kubeconfig, "kubeconfig", "", "path to the kubeconfig of the cluster running AMKO")
+	fs.StringVar(&opts.Context, "context", "", "kubeconfig context of the cluster running AMKO")
+	fs.StringVar(&opts.Namespace, "n", gslbutils.AVISystem, "namespace of AMKO")
+	fs.StringVar(&opts.Pod, "pod", "", "AMKO pod, defaults to the AMKO leader")
+	fs.StringVar(&opts.Output, "o", OutputTable, "output format, "+OutputTable+" or "+OutputJSON)
+	if err := fs.Parse(args); err != nil {
+		usage(out, fs)
+		if err == flag.ErrHelp {
+			return nil
+		}
+		return err
+	}
+	if opts.Output != OutputTable && opts.Output != OutputJSON {
+		return errors.New("invalid output format " + opts.Output)
+	}
+	if fs.NArg() == 0 {
+		usage(out, fs)
+		return errors.New("no command given")
+	}
+
+	name := fs.Arg(0)
+	for _, cmd := range getCommands() {
+		if cmd.name != name {
+			continue
+		}
+		ctx := &cmdContext{opts: opts, out: out}
+		if cmd.kubeAPI {
+			gslbClient, err := newGSLBClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx.gslbClient = gslbClient
+		} else if !cmd.offline {
+			client, err := newClient(opts)
+			if err != nil {
+				return err
+			}
+			ctx.client = client
+		}
+		return cmd.run(ctx, fs.Args()[1:])
+	}
+	usage(out, fs)
+	return errors.New("unknown command " + name)
+}
+
+func loadKubeConfig(opts Options) (*restclient.Config, error) {
+	rules := clientcmd.NewDefaultClientConfigLoadingRules()
+	rules.ExplicitPath = opts.Kubeconfig
+	cfg, err := clientcmd.NewNonInteractiveDeferredLoadingClientConfig(rules,
+		&clientcmd.ConfigOverrides{CurrentContext: opts.Context}).ClientConfig()
+	if err != nil {
+		return nil, errors.New("error in loading the kubeconfig: " + err.Error())
+	}
+	return cfg, nil
+}
+
+// newGSLBClient returns the client for the AMKO objects of the cluster running AMKO.
+func newGSLBClient(opts Options) (gslbcs.Interface, error) {
+	if opts.Server != "" {
+		return nil, errors.New("the operations are requested through the kubernetes API, -server can't be used")
+	}
+	cfg, err := loadKubeConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	gslbClient, err := gslbcs.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.New("error in creating the GSLB client: " + err.Error())
+	}
+	return gslbClient, nil
+}
+
+// newClient returns the client for the AMKO API server at the server URL, or for the AMKO pod
+// through the kubernetes API.
+func newClient(opts Options) (Client, error) {
+	if opts.Server != "" {
+		return NewHTTPClient(opts.Server), nil
+	}
+	cfg, err := loadKubeConfig(opts)
+	if err != nil {
+		return nil, err
+	}
+	cs, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return nil, errors.New("error in creating the kubernetes client: " + err.Error())
+	}
+	pod := opts.Pod
+	if pod == "" {
+		pod = FindAMKOPod(cs, opts.Namespace)
+	}
+	return NewPodProxyClient(cs, opts.Namespace, pod, DefaultAPIPort), nil
+}
+
+// parseArgs parses the arguments of a command, and sets the non-empty string flags as the query
+// parameters.
+func parseArgs(name string, args []string, stringFlags []string) (*flag.FlagSet, url.Values, error) {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	values := make(map[string]*string, len(stringFlags))
+	for _, f := range stringFlags {
+		values[f] = fs.String(f, "", "")
+	}
+	if err := fs.Parse(args); err != nil {
+		return nil, nil, errors.New(name + ": " + err.Error())
+	}
+	params := url.Values{}
+	for f, value := range values {
+		if *value != "" {
+			params.Set(f, *value)
+		}
+	}
+	return fs, params, nil
+}
+
+func (c *cmdContext) printJSON(obj interface{}) error {
+	data, err := json.MarshalIndent(obj, "", "  ")
+	if err != nil {
+		return err
+	}
+	_, err = fmt.Fprintln(c.out, string(data))
+	return err
+}
+
+func (c *cmdContext) newTable(header ...string) *tabwriter.Writer {
+	tw := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintln(tw, strings.Join(header, "\t"))
+	return tw
+}
+
+func memberState(drained bool) string {
+	if drained {
+		return "drained"
+	}
+	return "enabled"
+}
+
+func listGS(c *cmdContext, args []string) error {
+	_, params, err := parseArgs("list", args, []string{introspection.FQDNParam, introspection.ClusterParam,
+		introspection.NamespaceParam})
+	if err != nil {
+		return err
+	}
+	var graphs []introspection.GraphView
+	if err := c.client.Get(introspection.GraphsRoute, params, &graphs); err != nil {
+		return err
+	}
+	if c.opts.Output == OutputJSON {
+		return c.printJSON(graphs)
+	}
+	tw := c.newTable("GS", "TENANT", "CLUSTER", "NAMESPACE", "TYPE", "NAME", "IP", "WEIGHT", "STATE")
+	for _, graph := range graphs {
+		if graph.Deleted || len(graph.Members) == 0 {
+			fmt.Fprintf(tw, "%s\t%s\t-\t-\t-\t-\t-\t-\t%s\n", graph.Name, graph.Tenant, "deleting")
+			continue
+		}
+		for _, member := range graph.Members {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%s\t%s\t%d\t%s\n", graph.Name, graph.Tenant, member.Cluster,
+				member.Namespace, member.ObjType, member.Name, member.IPAddr, member.Weight,
+				memberState(member.Drained))
+		}
+	}
+	return tw.Flush()
+}
+
+// Member sync states shown by show
+const (
+	MemberInSync    = "in sync"
+	MemberOutOfSync = "out of sync"
+	MemberMissing   = "missing in Avi"
+	MemberStale     = "stale in Avi"
+)
+
+// GSMemberState is a member of a GS graph against the member with the same IP in the Avi cache.
+type GSMemberState struct {
+	Cluster   string `json:"cluster,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	ObjType   string `json:"type,omitempty"`
+	Name      string `json:"name,omitempty"`
+	IPAddr    string `json:"ipAddr"`
+	Weight    int32  `json:"weight"`
+	Drained   bool   `json:"drained"`
+	// AviWeight and AviEnabled are the weight and state of the member in the Avi cache
+	AviWeight  int32  `json:"aviWeight"`
+	AviEnabled bool   `json:"aviEnabled"`
+	State      string `json:"state"`
+}
+
+// GSState is a GS graph against its GS in the Avi cache.
+type GSState struct {
+	Graph   introspection.GraphView `json:"graph"`
+	AviGS   *avicache.AviGSCache    `json:"aviGS"`
+	InSync  bool                    `json:"inSync"`
+	Members []GSMemberState         `json:"members"`
+}
+
+func compareGSWithCache(graph introspection.GraphView, aviGS *avicache.AviGSCache) GSState {
+	state := GSState{Graph: graph, AviGS: aviGS, Members: []GSMemberState{}}
+	aviMembers := make(map[string]avicache.GSMember)
+	if aviGS != nil {
+		state.InSync = aviGS.CloudConfigCksum == graph.Checksum
+		for _, member := range aviGS.Members {
+			aviMembers[member.IPAddr] = member
+		}
+	}
+	for _, member := range graph.Members {
+		ms := GSMemberState{
+			Cluster:   member.Cluster,
+			Namespace: member.Namespace,
+			ObjType:   member.ObjType,
+			Name:      member.Name,
+			IPAddr:    member.IPAddr,
+			Weight:    member.Weight,
+			Drained:   member.Drained,
+			State:     MemberMissing,
+		}
+		if aviMember, ok := aviMembers[member.IPAddr]; ok {
+			ms.AviWeight = aviMember.Weight
+			ms.AviEnabled = aviMember.Enabled
+			ms.State = MemberInSync
+			if aviMember.Weight != member.Weight || aviMember.Enabled == member.Drained {
+				ms.State = MemberOutOfSync
+			}
+			delete(aviMembers, member.IPAddr)
+		}
+		state.Members = append(state.Members, ms)
+	}
+	// the members in the Avi cache which aren't in the graph are yet to be removed
+	staleIPs := make([]string, 0, len(aviMembers))
+	for ip := range aviMembers {
+		staleIPs = append(staleIPs, ip)
+	}
+	sort.Strings(staleIPs)
+	for _, ip := range staleIPs {
+		state.Members = append(state.Members, GSMemberState{IPAddr: ip, AviWeight: aviMembers[ip].Weight,
+			AviEnabled: aviMembers[ip].Enabled, State: MemberStale})
+	}
+	return state
+}
+
+func showGS(c *cmdContext, args []string) error {
+	fs, _, err := parseArgs("show", args, nil)
+	if err != nil {
+		return err
+	}
+	if fs.NArg() != 1 {
+		return errors.New("show: the name of the GS is required")
+	}
+	name := fs.Arg(0)
+	params := url.Values{introspection.FQDNParam: []string{name}}
+	var graphs []introspection.GraphView
+	if err := c.client.Get(introspection.GraphsRoute, params, &graphs); err != nil {
+		return err
+	}
+	var graph *introspection.GraphView
+	for i := range graphs {
+		if graphs[i].Name == name {
+			graph = &graphs[i]
+			break
+		}
+	}
+	if graph == nil {
+		return errors.New("show: GS graph " + name + " not found")
+	}
+	var cache introspection.CacheView
+	if err := c.client.Get(introspection.CacheRoute, params, &cache); err != nil {
+		return err
+	}
+	var aviGS *avicache.AviGSCache
+	for i := range cache.GSLBServices {
+		if cache.GSLBServices[i].Name == name && cache.GSLBServices[i].Tenant == graph.Tenant {
+			aviGS = &cache.GSLBServices[i]
+			break
+		}
+	}
+
+	state := compareGSWithCache(*graph, aviGS)
+	if c.opts.Output == OutputJSON {
+		return c.printJSON(state)
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "GS:\t%s\n", graph.Name)
+	fmt.Fprintf(tw, "Tenant:\t%s\n", graph.Tenant)
+	fmt.Fprintf(tw, "Domain names:\t%s\n", strings.Join(graph.DomainNames, ", "))
+	if graph.HealthMonitor.Name != "" {
+		fmt.Fprintf(tw, "Health monitor:\t%s\n", graph.HealthMonitor.Name)
+	}
+	fmt.Fprintf(tw, "Retries left:\t%d\n", graph.RetryCount)
+	if graph.Deleted {
+		fmt.Fprintf(tw, "Deleting:\ttrue\n")
+	}
+	fmt.Fprintf(tw, "Graph checksum:\t%d\n", graph.Checksum)
+	switch {
+	case aviGS == nil:
+		fmt.Fprintf(tw, "Avi checksum:\tnot in the Avi cache\n")
+	case state.InSync:
+		fmt.Fprintf(tw, "Avi checksum:\t%d (in sync)\n", aviGS.CloudConfigCksum)
+	default:
+		fmt.Fprintf(tw, "Avi checksum:\t%d (out of sync)\n", aviGS.CloudConfigCksum)
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	fmt.Fprintln(c.out)
+
+	tw = c.newTable("CLUSTER", "NAMESPACE", "TYPE", "NAME", "IP", "WEIGHT", "AVI WEIGHT", "STATE")
+	for _, member := range state.Members {
+		aviWeight := "-"
+		if member.State != MemberMissing {
+			aviWeight = strconv.Itoa(int(member.AviWeight))
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%s\t%s\n", dashIfEmpty(member.Cluster),
+			dashIfEmpty(member.Namespace), dashIfEmpty(member.ObjType), dashIfEmpty(member.Name), member.IPAddr,
+			member.Weight, aviWeight, member.State)
+	}
+	return tw.Flush()
+}
+
+func dashIfEmpty(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
+
+func explain(c *cmdContext, args []string) error {
+	_, params, err := parseArgs("explain", args, []string{introspection.TypeParam, introspection.ClusterParam,
+		introspection.NamespaceParam, introspection.NameParam, introspection.FQDNParam})
+	if err != nil {
+		return err
+	}
+	var decisions []filter.Decision
+	if err := c.client.Get(introspection.ExplainRoute, params, &decisions); err != nil {
+		return err
+	}
+	if c.opts.Output == OutputJSON {
+		return c.printJSON(decisions)
+	}
+	if len(decisions) == 0 {
+		_, err := fmt.Fprintln(c.out, "no decisions found for the objects")
+		return err
+	}
+	for _, d := range decisions {
+		fmt.Fprintf(c.out, "%s %s/%s/%s: %s\n", d.ObjType, d.Cluster, d.Namespace, d.Name, d.Reason())
+		tw := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+		for _, step := range d.Steps {
+			result := "passed"
+			if !step.Passed {
+				result = "failed"
+			}
+			fmt.Fprintf(tw, "    %s\t%s\t%s\n", step.Check, result, step.Message)
+		}
+		if err := tw.Flush(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func plan(c *cmdContext, args []string) error {
+	_, params, err := parseArgs("plan", args, []string{introspection.FQDNParam})
+	if err != nil {
+		return err
+	}
+	var plans []rest.GSPlan
+	if err := c.client.Get(introspection.PlanRoute, params, &plans); err != nil {
+		return err
+	}
+	if c.opts.Output == OutputJSON {
+		return c.printJSON(plans)
+	}
+	if len(plans) == 0 {
+		_, err := fmt.Fprintln(c.out, "no changes planned, AMKO isn't running in the dry run mode, or the Avi controller is in sync")
+		return err
+	}
+	for _, p := range plans {
+		fmt.Fprintf(c.out, "%s (planned at %s)\n", p.Key, p.Time.Format(time.RFC3339))
+		for _, op := range p.Operations {
+			fmt.Fprintf(c.out, "  %s %s %s\n", op.Method, op.Model, op.Name)
+			tw := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+			for _, d := range op.Diff {
+				fmt.Fprintf(tw, "    %s:\t%s\t->\t%s\n", d.Field, dashIfEmpty(d.Current), dashIfEmpty(d.Planned))
+			}
+			if err := tw.Flush(); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func (c *cmdContext) printStatus(st ingestion.ReconciliationStatus) error {
+	if c.opts.Output == OutputJSON {
+		return c.printJSON(st)
+	}
+	reconciliation := "running"
+	if st.Paused {
+		reconciliation = "paused"
+	}
+	tw := tabwriter.NewWriter(c.out, 0, 8, 2, ' ', 0)
+	fmt.Fprintf(tw, "Reconciliation:\t%s\n", reconciliation)
+	fmt.Fprintf(tw, "Dry run:\t%t\n", st.DryRun)
+	fmt.Fprintf(tw, "AMKO leader:\t%t\n", st.AMKOLeader)
+	fmt.Fprintf(tw, "Avi controller leader:\t%t\n", st.ControllerLeader)
+	return tw.Flush()
+}
+
+// OperationRequest is an operation requested with the annotations of the GSLBConfig object.
+type OperationRequest struct {
+	GSLBConfig  string             `json:"gslbConfig"`
+	Annotations map[string]*string `json:"annotations"`
+}
+
+// requestOperation sets the annotations of the GSLBConfig object to request an operation, the
+// annotations which are nil are removed.
+func requestOperation(c *cmdContext, name string, annotations map[string]*string) error {
+	gcName, err := AnnotateGSLBConfig(c.gslbClient, c.opts.Namespace, annotations)
+	if err != nil {
+		return err
+	}
+	if c.opts.Output == OutputJSON {
+		return c.printJSON(OperationRequest{GSLBConfig: c.opts.Namespace + "/" + gcName, Annotations: annotations})
+	}
+	_, err = fmt.Fprintf(c.out, "%s requested on GSLBConfig %s/%s\n", name, c.opts.Namespace, gcName)
+	return err
+}
+
+func resync(c *cmdContext, args []string) error {
+	fs := flag.NewFlagSet("resync", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	key := fs.String("key", "", "")
+	if err := fs.Parse(args); err != nil {
+		return errors.New("resync: " + err.Error())
+	}
+	if *key == "" {
+		*key = gslbutils.ResyncAll
+	} else if len(strings.SplitN(*key, "/", 2)) != 2 {
+		return errors.New("resync: the key must be of the form tenant/name")
+	}
+	requestedAt := time.Now().UTC().Format(time.RFC3339Nano)
+	return requestOperation(c, "resync", map[string]*string{
+		gslbutils.ResyncAnnotation:            key,
+		gslbutils.ResyncRequestedAtAnnotation: &requestedAt,
+	})
+}
+
+func pause(c *cmdContext, args []string) error {
+	if _, _, err := parseArgs("pause", args, nil); err != nil {
+		return err
+	}
+	paused := "true"
+	return requestOperation(c, "pause", map[string]*string{gslbutils.ReconcilePausedAnnotation: &paused})
+}
+
+func resume(c *cmdContext, args []string) error {
+	if _, _, err := parseArgs("resume", args, nil); err != nil {
+		return err
+	}
+	return requestOperation(c, "resume", map[string]*string{gslbutils.ReconcilePausedAnnotation: nil})
+}
+
+func status(c *cmdContext, args []string) error {
+	if _, _, err := parseArgs("status", args, nil); err != nil {
+		return err
+	}
+	var st ingestion.ReconciliationStatus
+	if err := c.client.Get(ingestion.ReconciliationRoute, nil, &st); err != nil {
+		return err
+	}
+	return c.printStatus(st)
+}
+
+// stringList is a flag which can be set more than once.
+type stringList []string
+
+func (s *stringList) String() string {
+	return strings.Join(*s, ",")
+}
+
+func (s *stringList) Set(value string) error {
+	*s = append(*s, value)
+	return nil
+}
+
+func validate(c *cmdContext, args []string) error {
+	var files stringList
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(&files, "f", "")
+	clusters := fs.String("clusters", "", "")
+	if err := fs.Parse(args); err != nil {
+		return errors.New("validate: " + err.Error())
+	}
+	if len(files) == 0 {
+		return errors.New("validate: a manifest is required")
+	}
+	var manifests []io.Reader
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.New("validate: " + err.Error())
+		}
+		defer f.Close()
+		manifests = append(manifests, f)
+	}
+	var clusterList []string
+	if *clusters != "" {
+		clusterList = strings.Split(*clusters, ",")
+	}
+	results, err := ValidateManifests(manifests, c.opts.Namespace, clusterList)
+	if err != nil {
+		return errors.New("validate: " + err.Error())
+	}
+
+	invalid := 0
+	for _, result := range results {
+		msg := "valid"
+		if result.Err != nil {
+			msg = "invalid, " + result.Err.Error()
+			invalid++
+		}
+		fmt.Fprintf(c.out, "%s %s/%s: %s\n", result.Kind, result.Namespace, result.Name, msg)
+	}
+	if invalid > 0 {
+		return errors.New("validate: " + strconv.Itoa(invalid) + " invalid object(s)")
+	}
+	return nil
+}
+
+func simulate(c *cmdContext, args []string) error {
+	var files stringList
+	fs := flag.NewFlagSet("simulate", flag.ContinueOnError)
+	fs.SetOutput(ioutil.Discard)
+	fs.Var(&files, "f", "")
+	objectsDir := fs.String("objects", "", "")
+	if err := fs.Parse(args); err != nil {
+		return errors.New("simulate: " + err.Error())
+	}
+	if len(files) == 0 {
+		return errors.New("simulate: a manifest is required")
+	}
+	if *objectsDir == "" {
+		return errors.New("simulate: the objects directory is required")
+	}
+	var manifests []io.Reader
+	for _, file := range files {
+		f, err := os.Open(file)
+		if err != nil {
+			return errors.New("simulate: " + err.Error())
+		}
+		defer f.Close()
+		manifests = append(manifests, f)
+	}
+	result, err := Simulate(manifests, *objectsDir, c.opts.Namespace)
+	if err != nil {
+		return errors.New("simulate: " + err.Error())
+	}
+	if c.opts.Output == OutputJSON {
+		return c.printJSON(result)
+	}
+	tw := c.newTable("GS", "DOMAINS", "POOL", "ALGORITHM", "IP", "RATIO", "ENABLED", "HEALTH MONITORS")
+	for _, gs := range result {
+		var hmNames []string
+		for _, ref := range gs.GslbService.HealthMonitorRefs {
+			hmNames = append(hmNames, strings.TrimPrefix(ref, "/api/healthmonitor?name="))
+		}
+		domains := strings.Join(gs.GslbService.DomainNames, ",")
+		hms := strings.Join(hmNames, ",")
+		for _, pool := range gs.GslbService.Groups {
+			for _, member := range pool.Members {
+				fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%t\t%s\n", gs.Name, domains, derefString(pool.Name),
+					derefString(pool.Algorithm), derefString(member.IP.Addr), derefInt32(member.Ratio),
+					member.Enabled != nil && *member.Enabled, hms)
+			}
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return err
+	}
+	hmCount := 0
+	for _, gs := range result {
+		hmCount += len(gs.HealthMonitors)
+	}
+	if hmCount == 0 {
+		return nil
+	}
+	fmt.Fprintln(c.out)
+	tw = c.newTable("HEALTH MONITOR", "GS", "TYPE", "MONITOR PORT")
+	for _, gs := range result {
+		for _, hm := range gs.HealthMonitors {
+			fmt.Fprintf(tw, "%s\t%s\t%s\t%d\n", derefString(hm.Name), gs.Name, derefString(hm.Type),
+				derefInt32(hm.MonitorPort))
+		}
+	}
+	return tw.Flush()
+}
+
+func derefString(s *string) string {
+	if s == nil {
+		return ""
+	}
+	return *s
+}
+
+func derefInt32(i *int32) int32 {
+	if i == nil {
+		return 0
+	}
+	return *i
+}
@@ -0,0 +1,192 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package amkoctl
+
+import (
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	gslbcs "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+	"k8s.io/client-go/kubernetes"
+)
+
+const (
+	// DefaultAPIPort is the port of the AMKO API server in the AMKO pod
+	DefaultAPIPort = "8080"
+	// DefaultPod is the AMKO pod which is used if there's no leader election lease
+	DefaultPod = "amko-0"
+
+	requestTimeout = 30 * time.Second
+)
+
+// Client makes the requests to the AMKO API server, and decodes the JSON responses into out.
+type Client interface {
+	Get(path string, params url.Values, out interface{}) error
+	Post(path string, params url.Values, out interface{}) error
+}
+
+// httpClient makes the requests to an AMKO API server reachable on a URL, e.g. through
+// kubectl port-forward.
+type httpClient struct {
+	server string
+	client *http.Client
+}
+
+// NewHTTPClient returns a client for the AMKO API server at the server URL.
+func NewHTTPClient(server string) Client {
+	return &httpClient{
+		server: strings.TrimSuffix(server, "/"),
+		client: &http.Client{Timeout: requestTimeout},
+	}
+}
+
+func (c *httpClient) do(method, path string, params url.Values, out interface{}) error {
+	reqURL := c.server + path
+	if len(params) > 0 {
+		reqURL += "?" + params.Encode()
+	}
+	req, err := http.NewRequest(method, reqURL, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := c.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	body, err := ioutil.ReadAll(resp.Body)
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return errors.New(method + " " + path + ": " + resp.Status + ": " + strings.TrimSpace(string(body)))
+	}
+	return decodeResponse(path, body, out)
+}
+
+func (c *httpClient) Get(path string, params url.Values, out interface{}) error {
+	return c.do(http.MethodGet, path, params, out)
+}
+
+func (c *httpClient) Post(path string, params url.Values, out interface{}) error {
+	return c.do(http.MethodPost, path, params, out)
+}
+
+// podProxyClient makes the requests to the AMKO API server of a pod through the pod proxy of the
+// kubernetes API server.
+type podProxyClient struct {
+	cs        kubernetes.Interface
+	namespace string
+	pod       string
+	port      string
+}
+
+// NewPodProxyClient returns a client for the AMKO API server of a pod, reached through the
+// kubernetes API server.
+func NewPodProxyClient(cs kubernetes.Interface, namespace, pod, port string) Client {
+	return &podProxyClient{cs: cs, namespace: namespace, pod: pod, port: port}
+}
+
+func (c *podProxyClient) do(method, path string, params url.Values, out interface{}) error {
+	req := c.cs.CoreV1().RESTClient().Verb(method).Namespace(c.namespace).Resource("pods").
+		Name(c.pod + ":" + c.port).SubResource("proxy").Suffix(path).Timeout(requestTimeout)
+	for key, values := range params {
+		for _, value := range values {
+			req = req.Param(key, value)
+		}
+	}
+	body, err := req.DoRaw()
+	if err != nil {
+		return errors.New(method + " " + path + " on pod " + c.namespace + "/" + c.pod + ": " + err.Error())
+	}
+	return decodeResponse(path, body, out)
+}
+
+func (c *podProxyClient) Get(path string, params url.Values, out interface{}) error {
+	return c.do(http.MethodGet, path, params, out)
+}
+
+func (c *podProxyClient) Post(path string, params url.Values, out interface{}) error {
+	return c.do(http.MethodPost, path, params, out)
+}
+
+func decodeResponse(path string, body []byte, out interface{}) error {
+	if out == nil {
+		return nil
+	}
+	if err := json.Unmarshal(body, out); err != nil {
+		return errors.New("malformed response for " + path + ": " + err.Error())
+	}
+	return nil
+}
+
+// FindAMKOPod returns the AMKO pod holding the leader election lease, only the leader writes to the
+// Avi controller. If leader election isn't enabled, the default AMKO pod is returned.
+func FindAMKOPod(cs kubernetes.Interface, namespace string) string {
+	lease, err := cs.CoordinationV1().Leases(namespace).Get(ingestion.LeaderElectionLeaseName, metav1.GetOptions{})
+	if err != nil || lease.Spec.HolderIdentity == nil || *lease.Spec.HolderIdentity == "" {
+		return DefaultPod
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+// findGSLBConfig returns the name of the GSLBConfig object accepted by AMKO, or of the only
+// GSLBConfig object in the namespace.
+func findGSLBConfig(gslbClient gslbcs.Interface, namespace string) (string, error) {
+	gcList, err := gslbClient.AmkoV1alpha2().GSLBConfigs(namespace).List(metav1.ListOptions{})
+	if err != nil {
+		return "", errors.New("error in listing the GSLBConfig objects in " + namespace + ": " + err.Error())
+	}
+	for _, gc := range gcList.Items {
+		cond := gslbalphav2.FindCondition(gc.Status.Conditions, gslbalphav2.ConditionAccepted)
+		if cond != nil && cond.Status == metav1.ConditionTrue {
+			return gc.Name, nil
+		}
+	}
+	if len(gcList.Items) != 1 {
+		return "", errors.New("no accepted GSLBConfig object in " + namespace)
+	}
+	return gcList.Items[0].Name, nil
+}
+
+// AnnotateGSLBConfig sets the annotations of the GSLBConfig object in namespace, the annotations
+// which are nil are removed. Returns the name of the GSLBConfig object.
+func AnnotateGSLBConfig(gslbClient gslbcs.Interface, namespace string, annotations map[string]*string) (string, error) {
+	name, err := findGSLBConfig(gslbClient, namespace)
+	if err != nil {
+		return "", err
+	}
+	patch, err := json.Marshal(map[string]interface{}{
+		"metadata": map[string]interface{}{"annotations": annotations},
+	})
+	if err != nil {
+		return "", err
+	}
+	_, err = gslbClient.AmkoV1alpha2().GSLBConfigs(namespace).Patch(name, types.MergePatchType, patch)
+	if err != nil {
+		return "", errors.New("error in updating the GSLBConfig object " + namespace + "/" + name + ": " + err.Error())
+	}
+	return name, nil
+}
@@ -0,0 +1,212 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package amkoctl
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	avimodels "github.com/avinetworks/sdk/go/models"
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/networking/v1beta1"
+)
+
+// SimulatedGS is a GSLB service computed by the simulator, with its health monitors, as they would
+// be created on the Avi controller.
+type SimulatedGS struct {
+	Name           string                    `json:"name"`
+	GslbService    avimodels.GslbService     `json:"gslbService"`
+	HealthMonitors []avimodels.HealthMonitor `json:"healthMonitors"`
+}
+
+// manifestExts are the extensions of the files read from the objects directory.
+var manifestExts = []string{".yaml", ".yml", ".json"}
+
+// readClusterObjects reads the objects of the member clusters from dir, which has a directory with
+// the manifests for each member cluster, named after the cluster. Objects without a namespace are
+// taken to be in the default namespace.
+func readClusterObjects(dir string) ([]ingestion.MemberClusterObjects, error) {
+	clusterDirs, err := ioutil.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var clusters []ingestion.MemberClusterObjects
+	for _, clusterDir := range clusterDirs {
+		if !clusterDir.IsDir() {
+			continue
+		}
+		cluster := ingestion.MemberClusterObjects{Cluster: clusterDir.Name()}
+		if !gslbutils.IsClusterContextPresent(cluster.Cluster) {
+			return nil, errors.New("cluster " + cluster.Cluster + " is not a member cluster of the GSLBConfig object")
+		}
+		files, err := ioutil.ReadDir(filepath.Join(dir, clusterDir.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var manifests []io.Reader
+		for _, file := range files {
+			if file.IsDir() || !gslbutils.PresentInList(filepath.Ext(file.Name()), manifestExts) {
+				continue
+			}
+			f, err := os.Open(filepath.Join(dir, clusterDir.Name(), file.Name()))
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			manifests = append(manifests, f)
+		}
+		objs, err := decodeManifests(manifests)
+		if err != nil {
+			return nil, errors.New("cluster " + cluster.Cluster + ": " + err.Error())
+		}
+		for _, obj := range objs {
+			if err := addClusterObject(&cluster, obj); err != nil {
+				return nil, errors.New("cluster " + cluster.Cluster + ": " + err.Error())
+			}
+		}
+		clusters = append(clusters, cluster)
+	}
+	return clusters, nil
+}
+
+// addClusterObject decodes a namespace, ingress, service or route object of a member cluster.
+func addClusterObject(cluster *ingestion.MemberClusterObjects, obj manifestObj) error {
+	var err error
+	switch obj.Kind {
+	case "Namespace":
+		var ns corev1.Namespace
+		if err = json.Unmarshal(obj.raw, &ns); err == nil {
+			cluster.Namespaces = append(cluster.Namespaces, &ns)
+		}
+	case "Ingress":
+		// the extensions/v1beta1 and networking.k8s.io/v1beta1 ingresses have the same fields
+		var ing v1beta1.Ingress
+		if err = json.Unmarshal(obj.raw, &ing); err == nil {
+			setDefaultNamespace(&ing.ObjectMeta.Namespace)
+			cluster.Ingresses = append(cluster.Ingresses, &ing)
+		}
+	case "Service":
+		var svc corev1.Service
+		if err = json.Unmarshal(obj.raw, &svc); err == nil {
+			setDefaultNamespace(&svc.ObjectMeta.Namespace)
+			cluster.Services = append(cluster.Services, &svc)
+		}
+	case "Route":
+		var route routev1.Route
+		if err = json.Unmarshal(obj.raw, &route); err == nil {
+			setDefaultNamespace(&route.ObjectMeta.Namespace)
+			cluster.Routes = append(cluster.Routes, &route)
+		}
+	default:
+		return errors.New("unsupported kind " + obj.Kind + " for " + obj.Metadata.Name +
+			", only Namespace, Ingress, Service and Route objects are simulated")
+	}
+	if err != nil {
+		return errors.New("error in decoding " + obj.Kind + " " + obj.Metadata.Name + ": " + err.Error())
+	}
+	return nil
+}
+
+func setDefaultNamespace(ns *string) {
+	if *ns == "" {
+		*ns = corev1.NamespaceDefault
+	}
+}
+
+// Simulate computes the GSLB services and health monitors, which AMKO would create on the Avi
+// controller, offline. The GSLBConfig, GDP and GSLBHostRule objects of the manifests, and the
+// objects of the member clusters in objectsDir are run through the same filters and GS graph
+// construction as AMKO. Objects of the manifests without a namespace are taken to be in namespace.
+func Simulate(manifests []io.Reader, objectsDir, namespace string) ([]SimulatedGS, error) {
+	objs, err := decodeManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+	var gc *gslbalphav2.GSLBConfig
+	var gdps []*gslbalphav2.GlobalDeploymentPolicy
+	var hostRules []*gslbalphav2.GSLBHostRule
+	for _, obj := range objs {
+		if obj.Metadata.Namespace == "" {
+			obj.Metadata.Namespace = namespace
+		}
+		switch obj.Kind {
+		case ingestion.GSLBConfigKind:
+			if gc != nil {
+				return nil, errors.New("only one GSLBConfig object is allowed")
+			}
+			gc = &gslbalphav2.GSLBConfig{}
+			err = json.Unmarshal(obj.raw, gc)
+			gc.Namespace = obj.Metadata.Namespace
+		case ingestion.GDPKind:
+			gdp := &gslbalphav2.GlobalDeploymentPolicy{}
+			err = json.Unmarshal(obj.raw, gdp)
+			gdp.Namespace = obj.Metadata.Namespace
+			gdps = append(gdps, gdp)
+		case ingestion.GSLBHostRuleKind:
+			hr := &gslbalphav2.GSLBHostRule{}
+			err = json.Unmarshal(obj.raw, hr)
+			hr.Namespace = obj.Metadata.Namespace
+			hostRules = append(hostRules, hr)
+		default:
+			return nil, errors.New("unsupported kind " + obj.Kind + ", only " + ingestion.GSLBConfigKind + ", " +
+				ingestion.GDPKind + " and " + ingestion.GSLBHostRuleKind + " objects can be simulated")
+		}
+		if err != nil {
+			return nil, errors.New("error in decoding " + obj.Kind + " " + obj.Metadata.Name + ": " + err.Error())
+		}
+	}
+	if gc == nil {
+		return nil, errors.New("a GSLBConfig object is required")
+	}
+	if _, err := ingestion.IsGSLBConfigValid(gc); err != nil {
+		return nil, errors.New("GSLBConfig " + gc.Name + " is invalid: " + err.Error())
+	}
+	for _, cluster := range gc.Spec.MemberClusters {
+		gslbutils.AddClusterContext(cluster.ClusterContext)
+	}
+
+	clusters, err := readClusterObjects(objectsDir)
+	if err != nil {
+		return nil, err
+	}
+	graphs, err := ingestion.SimulateFullSync(gdps, hostRules, clusters)
+	if err != nil {
+		return nil, err
+	}
+	result := make([]SimulatedGS, 0, len(graphs))
+	for _, graph := range graphs {
+		gs := SimulatedGS{Name: graph.Name, HealthMonitors: []avimodels.HealthMonitor{}}
+		for _, op := range rest.BuildGSCreateOperations(graph) {
+			switch obj := op.Obj.(type) {
+			case avimodels.GslbService:
+				gs.GslbService = obj
+			case avimodels.HealthMonitor:
+				gs.HealthMonitors = append(gs.HealthMonitors, obj)
+			}
+		}
+		result = append(result, gs)
+	}
+	return result, nil
+}
@@ -0,0 +1,135 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package amkoctl
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/yaml"
+)
+
+// ValidationResult is the result of the validation of an object in a manifest, Err is nil if the
+// object is valid.
+type ValidationResult struct {
+	Kind      string
+	Namespace string
+	Name      string
+	Err       error
+}
+
+// manifestObj is an object of a manifest, decoded as JSON.
+type manifestObj struct {
+	metav1.TypeMeta `json:",inline"`
+	Metadata        metav1.ObjectMeta `json:"metadata"`
+	raw             json.RawMessage
+}
+
+func decodeManifests(manifests []io.Reader) ([]manifestObj, error) {
+	var objs []manifestObj
+	for _, manifest := range manifests {
+		decoder := yaml.NewYAMLOrJSONDecoder(manifest, 4096)
+		for {
+			var raw json.RawMessage
+			if err := decoder.Decode(&raw); err != nil {
+				if err == io.EOF {
+					break
+				}
+				return nil, errors.New("error in decoding the manifest: " + err.Error())
+			}
+			// empty documents are skipped
+			if len(raw) == 0 || string(raw) == "null" {
+				continue
+			}
+			obj := manifestObj{raw: raw}
+			if err := json.Unmarshal(raw, &obj); err != nil {
+				return nil, errors.New("error in decoding the manifest: " + err.Error())
+			}
+			objs = append(objs, obj)
+		}
+	}
+	return objs, nil
+}
+
+// ValidateManifests validates the GSLBConfig and GDP objects of the manifests offline, with the
+// same checks which are run by the AMKO controllers. The member clusters referred to by the GDP
+// objects are verified against the GSLBConfig object in the manifests, or against clusters if
+// the manifests don't have one. Objects without a namespace are taken to be in namespace.
+func ValidateManifests(manifests []io.Reader, namespace string, clusters []string) ([]ValidationResult, error) {
+	objs, err := decodeManifests(manifests)
+	if err != nil {
+		return nil, err
+	}
+	for i := range objs {
+		if objs[i].Metadata.Namespace == "" {
+			objs[i].Metadata.Namespace = namespace
+		}
+	}
+
+	results := make([]ValidationResult, len(objs))
+	// the GSLBConfig objects are validated first, since the GDP objects are validated against
+	// their member clusters
+	gcFound := false
+	for i, obj := range objs {
+		results[i] = ValidationResult{Kind: obj.Kind, Namespace: obj.Metadata.Namespace, Name: obj.Metadata.Name}
+		if obj.Kind != ingestion.GSLBConfigKind {
+			continue
+		}
+		var gc gslbalphav2.GSLBConfig
+		if results[i].Err = json.Unmarshal(obj.raw, &gc); results[i].Err != nil {
+			continue
+		}
+		gc.Namespace = obj.Metadata.Namespace
+		if gcFound {
+			results[i].Err = errors.New("only one GSLBConfig object is allowed")
+			continue
+		}
+		gcFound = true
+		if _, results[i].Err = ingestion.IsGSLBConfigValid(&gc); results[i].Err != nil {
+			continue
+		}
+		for _, cluster := range gc.Spec.MemberClusters {
+			gslbutils.AddClusterContext(cluster.ClusterContext)
+		}
+	}
+	if !gcFound {
+		for _, cluster := range clusters {
+			gslbutils.AddClusterContext(cluster)
+		}
+	}
+
+	for i, obj := range objs {
+		switch obj.Kind {
+		case ingestion.GSLBConfigKind:
+		case ingestion.GDPKind:
+			var gdp gslbalphav2.GlobalDeploymentPolicy
+			if results[i].Err = json.Unmarshal(obj.raw, &gdp); results[i].Err != nil {
+				continue
+			}
+			gdp.Namespace = obj.Metadata.Namespace
+			results[i].Err = ingestion.GDPSanityChecks(&gdp)
+		default:
+			results[i].Err = errors.New("unsupported kind " + obj.Kind + ", only " + ingestion.GSLBConfigKind +
+				" and " + ingestion.GDPKind + " objects can be validated")
+		}
+	}
+	return results, nil
+}
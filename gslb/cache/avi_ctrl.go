@@ -15,13 +15,16 @@
 package cache
 
 import (
+	"crypto/tls"
 	"errors"
+	"net/http"
 	"os"
 	"sync"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
 
 	"github.com/avinetworks/sdk/go/clients"
+	"github.com/avinetworks/sdk/go/session"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
 )
 
@@ -29,32 +32,153 @@ var aviClientInstance *utils.AviRestClientPool
 
 var clientOnce sync.Once
 
+// aviClientLock guards aviClientInstance, which is replaced when AMKO re-targets to a new GSLB
+// leader
+var aviClientLock sync.RWMutex
+
 // SharedAviClients initializes a pool of connections to the avi controller
 func SharedAviClients() *utils.AviRestClientPool {
 	clientOnce.Do(func() {
-		var err error
-
 		ctrlCfg := gslbutils.GetAviConfig()
-		if ctrlCfg.Username == "" || ctrlCfg.Password == "" || ctrlCfg.IPAddr == "" || ctrlCfg.Version == "" {
+		if !ctrlCfg.IsSet() || ctrlCfg.IPAddr == "" || ctrlCfg.Version == "" {
 			utils.AviLog.Fatal("AVI Controller information is missing, update them in kubernetes secret or via environment variable.")
 		}
 		os.Setenv("CTRL_VERSION", ctrlCfg.Version)
-		aviClientInstance, err = utils.NewAviRestClientPool(gslbutils.NumRestWorkers, ctrlCfg.IPAddr, ctrlCfg.Username, ctrlCfg.Password)
+		pool, err := newAviClientPool(gslbutils.NumRestWorkers, ctrlCfg.IPAddr, ctrlCfg.AviControllerCredentials)
 		if err != nil {
 			utils.AviLog.Errorf("AVI Controller Initialization failed, %s", err)
 		}
+		aviClientLock.Lock()
+		aviClientInstance = pool
+		aviClientLock.Unlock()
 	})
+	aviClientLock.RLock()
+	defer aviClientLock.RUnlock()
 	return aviClientInstance
 }
 
+// newAviClientPool builds a pool of num connections to the controller at ipAddr. The sessions log
+// in with the token if it's set, else with the password, and present the client certificate, if
+// any. The requests are authenticated by the client certificate alone if there's no password or
+// token. The certificate of the controller is verified as per the TLS config of the controller.
+func newAviClientPool(num uint32, ipAddr string, creds gslbutils.AviControllerCredentials) (*utils.AviRestClientPool, error) {
+	pool := &utils.AviRestClientPool{}
+	tlsConfig, err := buildControllerTLSConfig(gslbutils.GetAviConfig().TLS)
+	if err != nil {
+		return pool, err
+	}
+	options := []func(*session.AviSession) error{session.SetNoControllerStatusCheck}
+	if tlsConfig.InsecureSkipVerify && tlsConfig.VerifyPeerCertificate == nil {
+		options = append(options, session.SetInsecure)
+	}
+	if creds.Token != "" {
+		options = append(options, session.SetAuthToken(creds.Token))
+	} else if creds.Password != "" {
+		options = append(options, session.SetPassword(creds.Password))
+	}
+	if len(creds.ClientCert) > 0 {
+		cert, err := tls.X509KeyPair(creds.ClientCert, creds.ClientKey)
+		if err != nil {
+			return pool, errors.New("invalid client certificate, " + err.Error())
+		}
+		tlsConfig.Certificates = []tls.Certificate{cert}
+		if creds.Token == "" && creds.Password == "" {
+			options = append(options, session.SetLazyAuthentication(true))
+		}
+	}
+	options = append(options, session.SetTransport(&http.Transport{TLSClientConfig: tlsConfig}))
+	for i := uint32(0); i < num; i++ {
+		aviClient, err := clients.NewAviClient(ipAddr, creds.Username, options...)
+		if err != nil {
+			utils.AviLog.Warnf("NewAviClient returned err %v", err)
+			return pool, err
+		}
+		pool.AviClient = append(pool.AviClient, aviClient)
+	}
+	return pool, nil
+}
+
+// replaceAviClients builds a pool of connections to the controller at ipAddr with creds, and
+// replaces the shared pool with it. The rest operations which are in flight complete with the
+// clients of the old pool.
+func replaceAviClients(ipAddr string, creds gslbutils.AviControllerCredentials) error {
+	pool, err := newAviClientPool(gslbutils.NumRestWorkers, ipAddr, creds)
+	if err != nil {
+		return err
+	}
+	if len(pool.AviClient) < 1 {
+		return errors.New("no avi clients initialized")
+	}
+	version := gslbutils.GetAviConfig().Version
+	if version == "" {
+		if version, err = pool.AviClient[0].AviSession.GetControllerVersion(); err != nil {
+			return err
+		}
+	}
+	SetTenantAndVersion(pool.AviClient[0], version)
+	// the shared pool must not be initialized after it's replaced
+	clientOnce.Do(func() {})
+	aviClientLock.Lock()
+	aviClientInstance = pool
+	aviClientLock.Unlock()
+	return nil
+}
+
+// RetargetAviClients replaces the pool of connections with one to the controller at ipAddr.
+func RetargetAviClients(ipAddr string) error {
+	if err := replaceAviClients(ipAddr, gslbutils.GetAviConfig().AviControllerCredentials); err != nil {
+		return err
+	}
+	gslbutils.SetAviControllerIP(ipAddr)
+	return nil
+}
+
+// UpdateAviClientCredentials replaces the pool of connections with one which uses the new
+// credentials of the controller. The existing pool is kept if the controller can't be connected to
+// with the new credentials.
+func UpdateAviClientCredentials(creds gslbutils.AviControllerCredentials) error {
+	if err := replaceAviClients(gslbutils.GetAviConfig().IPAddr, creds); err != nil {
+		return err
+	}
+	gslbutils.SetAviControllerCredentials(creds)
+	return nil
+}
+
+// FindGslbLeaderEndpoint returns the first of the endpoints which is the controller of the GSLB
+// leader site.
+func FindGslbLeaderEndpoint(endpoints []string) (string, error) {
+	ctrlCfg := gslbutils.GetAviConfig()
+	for _, endpoint := range endpoints {
+		pool, err := newAviClientPool(1, endpoint, ctrlCfg.AviControllerCredentials)
+		if err != nil || len(pool.AviClient) < 1 {
+			gslbutils.Warnf("controllerIP: %s, msg: can't connect to the controller, %v", endpoint, err)
+			continue
+		}
+		leader, err := isGslbLeaderSite(pool.AviClient[0])
+		if err != nil {
+			gslbutils.Warnf("controllerIP: %s, msg: can't verify if the controller is the GSLB leader, %s",
+				endpoint, err.Error())
+			continue
+		}
+		if leader {
+			return endpoint, nil
+		}
+		gslbutils.Logf("controllerIP: %s, msg: controller is not the GSLB leader", endpoint)
+	}
+	return "", errors.New("none of the controller endpoints is the GSLB leader")
+}
+
 func IsAviSiteLeader() (bool, error) {
 	aviRestClientPool := SharedAviClients()
-	if len(aviRestClientPool.AviClient) < 1 {
+	if aviRestClientPool == nil || len(aviRestClientPool.AviClient) < 1 {
 		gslbutils.Errf("no avi clients initialized, returning")
 		return false, errors.New("no avi clients initialized")
 	}
+	return isGslbLeaderSite(aviRestClientPool.AviClient[0])
+}
 
-	aviClient := aviRestClientPool.AviClient[0]
+// isGslbLeaderSite returns true if the controller cluster of the client is the GSLB leader.
+func isGslbLeaderSite(aviClient *clients.AviClient) (bool, error) {
 	clusterUuid, err := GetClusterUuid(aviClient)
 	if err != nil {
 		gslbutils.Errf("error in finding controller cluster uuid: %s", err.Error())
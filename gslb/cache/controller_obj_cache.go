@@ -18,14 +18,11 @@ import (
 	"encoding/json"
 	"errors"
 	"fmt"
-	"strconv"
 	"strings"
 	"sync"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
 
-	gdpv1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
-
 	"github.com/avinetworks/sdk/go/clients"
 	"github.com/avinetworks/sdk/go/models"
 	"github.com/avinetworks/sdk/go/session"
@@ -47,9 +44,15 @@ type AviHmObj struct {
 	Port             int32
 	UUID             string
 	Type             string
+	Description      string
 	CloudConfigCksum uint32
 }
 
+// IsCreatedByAmko returns true if the health monitor was created by AMKO.
+func (hm *AviHmObj) IsCreatedByAmko() bool {
+	return strings.HasPrefix(hm.Name, "amko--") && hm.Description == gslbutils.AmkoHmDescription
+}
+
 type AviHmCache struct {
 	cacheLock sync.RWMutex
 	Cache     map[interface{}]interface{}
@@ -79,6 +82,12 @@ func (h *AviHmCache) AviHmCacheGet(k interface{}) (interface{}, bool) {
 	return val, ok
 }
 
+func (h *AviHmCache) AviHmCacheLen() int {
+	h.cacheLock.RLock()
+	defer h.cacheLock.RUnlock()
+	return len(h.Cache)
+}
+
 func (h *AviHmCache) AviHmGetAllKeys() []interface{} {
 	var hmKeys []interface{}
 
@@ -128,6 +137,23 @@ func (h *AviHmCache) AviHmCacheDelete(k interface{}) {
 	delete(h.Cache, k)
 }
 
+// AviHmCacheReplace replaces the health monitors in the cache with the ones in newCache.
+func (h *AviHmCache) AviHmCacheReplace(newCache *AviHmCache) {
+	newCache.cacheLock.RLock()
+	defer newCache.cacheLock.RUnlock()
+	h.cacheLock.Lock()
+	defer h.cacheLock.Unlock()
+
+	h.Cache = make(map[interface{}]interface{}, len(newCache.Cache))
+	for k, v := range newCache.Cache {
+		h.Cache[k] = v
+	}
+	h.UUIDCache = make(map[string]interface{}, len(newCache.UUIDCache))
+	for k, v := range newCache.UUIDCache {
+		h.UUIDCache[k] = v
+	}
+}
+
 func (h *AviHmCache) AviHmCachePopulate(client *clients.AviClient,
 	version string) {
 	SetTenantAndVersion(client, version)
@@ -187,6 +213,9 @@ func (h *AviHmCache) AviHmObjCachePopulate(client *clients.AviClient, hmname ...
 				Port:             *hm.MonitorPort,
 				CloudConfigCksum: cksum,
 			}
+			if hm.Description != nil {
+				hmCacheObj.Description = *hm.Description
+			}
 			h.AviHmCacheAdd(k, &hmCacheObj)
 			gslbutils.Debugf("processed health monitor %s", *hm.Name)
 			processedObjs++
@@ -210,8 +239,9 @@ func (h *AviHmCache) AviHmObjCachePopulate(client *clients.AviClient, hmname ...
 }
 
 type GSMember struct {
-	IPAddr string
-	Weight int32
+	IPAddr  string
+	Weight  int32
+	Enabled bool
 }
 
 type AviGSCache struct {
@@ -244,6 +274,12 @@ func (c *AviCache) AviCacheGet(k interface{}) (interface{}, bool) {
 	return val, ok
 }
 
+func (c *AviCache) AviCacheLen() int {
+	c.cacheLock.RLock()
+	defer c.cacheLock.RUnlock()
+	return len(c.Cache)
+}
+
 func (c *AviCache) AviCacheGetAllKeys() []TenantName {
 	var gses []TenantName
 
@@ -282,6 +318,19 @@ func (c *AviCache) AviCacheDelete(k interface{}) {
 	delete(c.Cache, k)
 }
 
+// AviCacheReplace replaces the GSLB services in the cache with the ones in newCache.
+func (c *AviCache) AviCacheReplace(newCache *AviCache) {
+	newCache.cacheLock.RLock()
+	defer newCache.cacheLock.RUnlock()
+	c.cacheLock.Lock()
+	defer c.cacheLock.Unlock()
+
+	c.Cache = make(map[interface{}]interface{}, len(newCache.Cache))
+	for k, v := range newCache.Cache {
+		c.Cache[k] = v
+	}
+}
+
 func (c *AviCache) AviObjGSCachePopulate(client *clients.AviClient, gsname ...string) {
 	var nextPageURI string
 	uri := "/api/gslbservice?page_size=100"
@@ -385,33 +434,17 @@ func parseGSObject(c *AviCache, gsObj models.GslbService, gsname []string) {
 
 }
 
-func parseDescription(description string) ([]string, error) {
-	// description field should be like:
-	// LBSvc/cluster-x/namespace-x/svc-x,Ingress/cluster-y/namespace-y/ingress-y/hostname,...
-	objList := strings.Split(description, ",")
-	if len(objList) == 0 {
-		return []string{}, errors.New("description field has no k8s/openshift objects")
-	}
-	for _, obj := range objList {
-		seg := strings.Split(obj, "/")
-		switch seg[0] {
-		case gdpv1alpha1.IngressObj:
-			if len(seg) != 5 {
-				return []string{}, errors.New("description field has malformed ingress: " + description)
-			}
-		case gdpv1alpha1.LBSvcObj:
-			if len(seg) != 4 {
-				return []string{}, errors.New("description field has malformed LB service: " + description)
-			}
-		case gdpv1alpha1.RouteObj:
-			if len(seg) != 4 {
-				return []string{}, errors.New("description field has malformed route: " + description)
-			}
-		default:
-			return []string{}, errors.New("description has unrecognised objects: " + description)
-		}
+// parseDescription returns the member objects from the description field of a GS, the checksum of
+// the member objects and the part of the GS checksum which comes from the version of the description.
+// The member objects are empty if the description has only their checksum. A GS with a malformed or a
+// legacy description will have a checksum different from its graph, and hence will be updated.
+func parseDescription(description string) ([]string, uint32, uint32, error) {
+	desc, err := gslbutils.DecodeGSDescription(description)
+	if err != nil {
+		return []string{}, gslbutils.GetGSMemberObjsChecksum([]string{}),
+			gslbutils.GetGSDescriptionChecksum(desc.Version), err
 	}
-	return objList, nil
+	return desc.MemberObjList(), desc.MembersChecksum(), gslbutils.GetGSDescriptionChecksum(desc.Version), nil
 }
 
 func GetDetailsFromAviGSLBFormatted(gsObj models.GslbService) (uint32, []GSMember, []string, []string, error) {
@@ -435,8 +468,7 @@ func GetDetailsFromAviGSLBFormatted(gsObj models.GslbService) (uint32, []GSMembe
 		return 0, nil, memberObjs, hms, errors.New("groups absent in gslb service")
 	}
 
-	description := *gsObj.Description
-	if description == "" {
+	if gsObj.Description == nil || *gsObj.Description == "" {
 		return 0, nil, memberObjs, hms, errors.New("description absent in gslb service")
 	}
 
@@ -481,20 +513,23 @@ func GetDetailsFromAviGSLBFormatted(gsObj models.GslbService) (uint32, []GSMembe
 				gslbutils.Warnf("invalid weight present, assigning 0: %v", member)
 				weight = 0
 			}
-			ipList = append(ipList, ipAddr+"-"+strconv.Itoa(int(weight)))
+			// a member is enabled by default
+			enabled := member.Enabled == nil || *member.Enabled
+			ipList = append(ipList, gslbutils.GetGSMemberChecksumStr(ipAddr, weight, enabled))
 			gsMember := GSMember{
-				IPAddr: ipAddr,
-				Weight: weight,
+				IPAddr:  ipAddr,
+				Weight:  weight,
+				Enabled: enabled,
 			}
 			gsMembers = append(gsMembers, gsMember)
 		}
 	}
-	memberObjs, err := parseDescription(description)
+	memberObjs, membersCksum, descCksum, err := parseDescription(*gsObj.Description)
 	if err != nil {
 		gslbutils.Errf("object: GSLBService, msg: error while parsing description field: %s", err)
 	}
 	// calculate the checksum
-	checksum := gslbutils.GetGSLBServiceChecksum(ipList, domainList, memberObjs, hms)
+	checksum := gslbutils.GetGSLBServiceChecksumWithMembers(ipList, domainList, membersCksum, hms) + descCksum
 	return checksum, gsMembers, memberObjs, hms, nil
 }
 
@@ -571,20 +606,26 @@ func GetDetailsFromAviGSLB(gslbSvcMap map[string]interface{}) (uint32, []GSMembe
 				weight = 0
 			}
 			weightI := int32(weight)
-			ipList = append(ipList, ipAddr+"-"+strconv.Itoa(int(weightI)))
+			// a member is enabled by default
+			enabled, ok := member["enabled"].(bool)
+			if !ok {
+				enabled = true
+			}
+			ipList = append(ipList, gslbutils.GetGSMemberChecksumStr(ipAddr, weightI, enabled))
 			gsMember := GSMember{
-				IPAddr: ipAddr,
-				Weight: weightI,
+				IPAddr:  ipAddr,
+				Weight:  weightI,
+				Enabled: enabled,
 			}
 			gsMembers = append(gsMembers, gsMember)
 		}
 	}
-	memberObjs, err := parseDescription(description)
+	memberObjs, membersCksum, descCksum, err := parseDescription(description)
 	if err != nil {
 		gslbutils.Errf("object: GSLBService, msg: error while parsing description field: %s", err)
 	}
 	// calculate the checksum
-	checksum := gslbutils.GetGSLBServiceChecksum(ipList, domainList, memberObjs, hms)
+	checksum := gslbutils.GetGSLBServiceChecksumWithMembers(ipList, domainList, membersCksum, hms) + descCksum
 	return checksum, gsMembers, memberObjs, hms, nil
 }
 
@@ -0,0 +1,96 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package cache
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"net"
+	"time"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+)
+
+const controllerTLSDialTimeout = 10 * time.Second
+
+// buildControllerTLSConfig builds the TLS config of the connections to the controller as per the
+// verification mode. The certificate isn't verified if the mode is None or isn't set.
+func buildControllerTLSConfig(tlsCfg gslbutils.AviControllerTLS) (*tls.Config, error) {
+	if tlsCfg.Verification == "" || tlsCfg.Verification == gslbutils.TLSVerificationNone {
+		return &tls.Config{InsecureSkipVerify: true}, nil
+	}
+
+	var roots *x509.CertPool
+	if len(tlsCfg.CAData) > 0 {
+		roots = x509.NewCertPool()
+		if !roots.AppendCertsFromPEM(tlsCfg.CAData) {
+			return nil, errors.New("no valid certificates in the CA bundle")
+		}
+	}
+	switch tlsCfg.Verification {
+	case gslbutils.TLSVerificationFull:
+		return &tls.Config{RootCAs: roots, ServerName: tlsCfg.ServerName}, nil
+	case gslbutils.TLSVerificationCAOnly:
+		// the default verification also checks the host name, so the chain is verified here instead
+		return &tls.Config{
+			InsecureSkipVerify: true,
+			VerifyPeerCertificate: func(rawCerts [][]byte, _ [][]*x509.Certificate) error {
+				return verifyCertificateChain(rawCerts, roots)
+			},
+		}, nil
+	}
+	return nil, errors.New("unrecognized TLS verification " + tlsCfg.Verification)
+}
+
+// verifyCertificateChain verifies the certificate chain presented by the controller against roots,
+// without verifying the host name.
+func verifyCertificateChain(rawCerts [][]byte, roots *x509.CertPool) error {
+	if len(rawCerts) == 0 {
+		return errors.New("controller didn't present a certificate")
+	}
+	certs := make([]*x509.Certificate, len(rawCerts))
+	for i, raw := range rawCerts {
+		cert, err := x509.ParseCertificate(raw)
+		if err != nil {
+			return err
+		}
+		certs[i] = cert
+	}
+	intermediates := x509.NewCertPool()
+	for _, cert := range certs[1:] {
+		intermediates.AddCert(cert)
+	}
+	_, err := certs[0].Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates})
+	return err
+}
+
+// VerifyControllerTLS does a TLS handshake with the controller at ipAddr, and returns an error if
+// its certificate can't be verified.
+func VerifyControllerTLS(ipAddr string) error {
+	tlsConfig, err := buildControllerTLSConfig(gslbutils.GetAviConfig().TLS)
+	if err != nil {
+		return err
+	}
+	addr := ipAddr
+	if _, _, err := net.SplitHostPort(ipAddr); err != nil {
+		addr = net.JoinHostPort(ipAddr, "443")
+	}
+	conn, err := tls.DialWithDialer(&net.Dialer{Timeout: controllerTLSDialTimeout}, "tcp", addr, tlsConfig)
+	if err != nil {
+		return err
+	}
+	return conn.Close()
+}
@@ -0,0 +1,102 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package cache
+
+import (
+	"encoding/json"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	"github.com/avinetworks/sdk/go/clients"
+	"github.com/avinetworks/sdk/go/models"
+)
+
+// Operational states of a GS member as reported in the GSLB service runtime
+const (
+	OperUp       = "OPER_UP"
+	OperDown     = "OPER_DOWN"
+	OperDisabled = "OPER_DISABLED"
+)
+
+func parseGSRuntime(resp []byte) ([]models.GslbServiceRuntime, error) {
+	var gsRuntimes []models.GslbServiceRuntime
+	if err := json.Unmarshal(resp, &gsRuntimes); err == nil {
+		return gsRuntimes, nil
+	}
+	// some controller versions return a single runtime object instead of a list
+	var gsRuntime models.GslbServiceRuntime
+	if err := json.Unmarshal(resp, &gsRuntime); err != nil {
+		return nil, err
+	}
+	return []models.GslbServiceRuntime{gsRuntime}, nil
+}
+
+// GSRuntimeState is the operational state of a GSLB service and its members, as reported in the
+// runtime of the GSLB service.
+type GSRuntimeState struct {
+	OperState string
+	// Members contains the operational state of each member, keyed by the member IP
+	Members map[string]string
+}
+
+// mergeOperState combines the states reported by different sites, a state down for any site is down.
+func mergeOperState(oldState, newState string) string {
+	if oldState == OperDown || newState == "" {
+		return oldState
+	}
+	return newState
+}
+
+func getOperState(operStatus *models.OperationalStatus) string {
+	if operStatus == nil || operStatus.State == nil {
+		return ""
+	}
+	return *operStatus.State
+}
+
+// GetGSRuntimeState fetches the runtime of the GSLB service with uuid gsUUID from the Avi controller
+// and returns the operational state of the GSLB service and its members.
+func GetGSRuntimeState(client *clients.AviClient, gsUUID string) (GSRuntimeState, error) {
+	state := GSRuntimeState{Members: make(map[string]string)}
+	uri := "/api/gslbservice/" + gsUUID + "/runtime"
+	resp, err := client.AviSession.GetRaw(uri)
+	if err != nil {
+		gslbutils.Warnf("object: GSRuntime, msg: GS runtime get URI %s returned error: %s", uri, err)
+		return state, err
+	}
+	gsRuntimes, err := parseGSRuntime(resp)
+	if err != nil {
+		gslbutils.Warnf("object: GSRuntime, msg: failed to unmarshal GS runtime, err: %s", err)
+		return state, err
+	}
+
+	// each site reports the state of the GS and its members
+	for _, gsRuntime := range gsRuntimes {
+		state.OperState = mergeOperState(state.OperState, getOperState(gsRuntime.OperStatus))
+		for _, group := range gsRuntime.Groups {
+			if group == nil {
+				continue
+			}
+			for _, member := range group.Members {
+				if member == nil || member.IP == nil || member.IP.Addr == nil {
+					continue
+				}
+				ipAddr := *member.IP.Addr
+				state.Members[ipAddr] = mergeOperState(state.Members[ipAddr], getOperState(member.OperStatus))
+			}
+		}
+	}
+	return state, nil
+}
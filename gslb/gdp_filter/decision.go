@@ -0,0 +1,237 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package filter
+
+import (
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+)
+
+// Checks recorded in the decision trace of an object, in the order in which they are evaluated
+const (
+	CheckGDP               = "GDP"
+	CheckStatus            = "Status"
+	CheckCluster           = "Cluster"
+	CheckNamespaceSelector = "NamespaceSelector"
+	CheckAppSelector       = "AppSelector"
+)
+
+// DecisionStep is a check of the decision trace.
+type DecisionStep struct {
+	Check   string `json:"check"`
+	Passed  bool   `json:"passed"`
+	Message string `json:"message"`
+}
+
+// Decision is the trace of the filter decision for an ingress host, route or LoadBalancer service
+// of a member cluster. The checks are evaluated in order until one of them fails.
+type Decision struct {
+	ObjType   string `json:"type"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	// Name is ingress name/hostname for the ingresses
+	Name     string `json:"name"`
+	Hostname string `json:"hostname"`
+	IPAddr   string `json:"ipAddr"`
+	// GDP is the namespace/name of the GDP object considered for the decision
+	GDP      string         `json:"gdp"`
+	Accepted bool           `json:"accepted"`
+	GSName   string         `json:"gsName,omitempty"`
+	Steps    []DecisionStep `json:"steps"`
+	Time     time.Time      `json:"time"`
+}
+
+// Reason returns the message of the check which rejected the object, or the GS name if the object
+// is accepted.
+func (d Decision) Reason() string {
+	if d.Accepted {
+		return "accepted as a member of GSLB service " + d.GSName + " by " + d.gdpDesc()
+	}
+	for _, step := range d.Steps {
+		if !step.Passed {
+			return "rejected by the " + step.Check + " check: " + step.Message
+		}
+	}
+	return "rejected"
+}
+
+func (d Decision) gdpDesc() string {
+	if d.GDP == "" {
+		return "the GDP object"
+	}
+	return "GDP " + d.GDP
+}
+
+func (d *Decision) addStep(check string, passed bool, msg string) bool {
+	d.Steps = append(d.Steps, DecisionStep{Check: check, Passed: passed, Message: msg})
+	return passed
+}
+
+func matchesLabel(labels map[string]string, lbl gslbutils.Label) bool {
+	v, ok := labels[lbl.Key]
+	return ok && v == lbl.Value
+}
+
+// Evaluate returns the decision trace of the GDP filter for an object.
+func Evaluate(obj k8sobjects.MetaObject) Decision {
+	d := Decision{
+		ObjType:   obj.GetType(),
+		Cluster:   obj.GetCluster(),
+		Namespace: obj.GetNamespace(),
+		Name:      obj.GetName(),
+		Hostname:  obj.GetHostname(),
+		IPAddr:    obj.GetIPAddr(),
+		Time:      time.Now(),
+	}
+	d.Accepted = evaluateSteps(&d, obj)
+	if d.Accepted {
+		// the hostname of an object is the name of its GS
+		d.GSName = d.Hostname
+	}
+	return d
+}
+
+func evaluateSteps(d *Decision, obj k8sobjects.MetaObject) bool {
+	gdpName, gdpNS := gslbutils.GetGDPObj()
+	if gdpName != "" {
+		d.GDP = gdpNS + "/" + gdpName
+	}
+	gf := gslbutils.GetGlobalFilter()
+	if gf == nil {
+		return d.addStep(CheckGDP, false, "no GDP object")
+	}
+	gf.GlobalLock.RLock()
+	defer gf.GlobalLock.RUnlock()
+
+	// the GDP object is recorded only after the objects are re-evaluated for it, so the filter
+	// decides whether a GDP object is applied
+	if gf.AppFilter == nil && gf.NSFilter == nil {
+		return d.addStep(CheckGDP, false, "no GDP object with a namespaceSelector or an appSelector")
+	}
+	d.addStep(CheckGDP, true, d.gdpDesc()+" is applied")
+
+	if d.IPAddr == "" || d.Hostname == "" {
+		return d.addStep(CheckStatus, false, "IP address or hostname not found in the status")
+	}
+	d.addStep(CheckStatus, true, "IP address "+d.IPAddr+", hostname "+d.Hostname)
+
+	if !gslbutils.PresentInList(d.Cluster, gf.ApplicableClusters) {
+		return d.addStep(CheckCluster, false, "cluster "+d.Cluster+" isn't in the matchClusters of the GDP")
+	}
+	d.addStep(CheckCluster, true, "cluster "+d.Cluster+" is selected")
+
+	if gf.NSFilter != nil {
+		gf.NSFilter.Lock.RLock()
+		nsList := gf.NSFilter.SelectedNS[d.Cluster]
+		lbl := gf.NSFilter.Label
+		gf.NSFilter.Lock.RUnlock()
+		if !gslbutils.PresentInList(d.Namespace, nsList) {
+			return d.addStep(CheckNamespaceSelector, false, "namespace "+d.Namespace+" doesn't have the label "+
+				lbl.Key+"="+lbl.Value)
+		}
+		d.addStep(CheckNamespaceSelector, true, "namespace "+d.Namespace+" has the label "+lbl.Key+"="+lbl.Value)
+		if gf.AppFilter == nil {
+			return true
+		}
+	} else {
+		d.addStep(CheckNamespaceSelector, true, "no namespaceSelector")
+	}
+
+	lbl := gf.AppFilter.Label
+	if !matchesLabel(obj.GetLabels(), lbl) {
+		return d.addStep(CheckAppSelector, false, "object doesn't have the label "+lbl.Key+"="+lbl.Value)
+	}
+	return d.addStep(CheckAppSelector, true, "object has the label "+lbl.Key+"="+lbl.Value)
+}
+
+// decisionStore keeps the latest decision for every object which went through the filter.
+type decisionStore struct {
+	lock      sync.RWMutex
+	decisions map[string]Decision
+	// hook is called with the decision of an object whenever it changes
+	hook func(Decision)
+}
+
+var decisions = decisionStore{decisions: make(map[string]Decision)}
+
+func decisionKey(objType, cluster, ns, name string) string {
+	return objType + "/" + cluster + "/" + ns + "/" + name
+}
+
+// SetDecisionHook sets the function which is called whenever the decision for an object changes.
+func SetDecisionHook(hook func(Decision)) {
+	decisions.lock.Lock()
+	defer decisions.lock.Unlock()
+	decisions.hook = hook
+}
+
+func recordDecision(d Decision) {
+	key := decisionKey(d.ObjType, d.Cluster, d.Namespace, d.Name)
+	decisions.lock.Lock()
+	prev, ok := decisions.decisions[key]
+	decisions.decisions[key] = d
+	hook := decisions.hook
+	decisions.lock.Unlock()
+
+	if hook != nil && (!ok || prev.Accepted != d.Accepted || prev.Reason() != d.Reason()) {
+		hook(d)
+	}
+}
+
+// RecordDecision evaluates and records the decision for an object which is rejected before it goes
+// through the filter, e.g. because it has no IP address in its status.
+func RecordDecision(obj k8sobjects.MetaObject) Decision {
+	d := Evaluate(obj)
+	recordDecision(d)
+	return d
+}
+
+// DeleteDecision removes the decision for a deleted object.
+func DeleteDecision(objType, cluster, ns, name string) {
+	decisions.lock.Lock()
+	defer decisions.lock.Unlock()
+	delete(decisions.decisions, decisionKey(objType, cluster, ns, name))
+}
+
+// GetDecisions returns the decisions, sorted by object, for the objects which match the type,
+// cluster, namespace, name and hostname. An empty argument matches all the objects, and the name
+// of an ingress matches all its hosts.
+func GetDecisions(objType, cluster, ns, name, hostname string) []Decision {
+	decisions.lock.RLock()
+	defer decisions.lock.RUnlock()
+
+	keys := make([]string, 0, len(decisions.decisions))
+	for key, d := range decisions.decisions {
+		if (objType != "" && d.ObjType != objType) || (cluster != "" && d.Cluster != cluster) ||
+			(ns != "" && d.Namespace != ns) || (hostname != "" && d.Hostname != hostname) {
+			continue
+		}
+		if name != "" && d.Name != name && !(d.ObjType == gslbutils.IngressType && strings.HasPrefix(d.Name, name+"/")) {
+			continue
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]Decision, 0, len(keys))
+	for _, key := range keys {
+		result = append(result, decisions.decisions[key])
+	}
+	return result
+}
@@ -21,13 +21,23 @@ import (
 
 // ApplyFilter applies the local namespace filter first to an object, if the namespace
 // filter is not present or if the object is rejected by the namespace filter, apply
-// the cluster filter if present. Default action is to reject the object.
+// the cluster filter if present. Default action is to reject the object. The decision
+// for an ingress, route or service is recorded, see Evaluate.
 func ApplyFilter(obj interface{}, cname string) bool {
 	gf := gslbutils.GetGlobalFilter()
 	if gf == nil {
 		gslbutils.Errf("cname: %s, msg: global filter doesn't exist, returning false", cname)
 		return false
 	}
+	// ingresses, routes and services are evaluated with a decision trace, which is recorded to
+	// explain why they are (not) part of a GS
+	if metaObj, ok := obj.(k8sobjects.MetaObject); ok {
+		d := RecordDecision(metaObj)
+		gslbutils.Logf("objType: %s, cluster: %s, namespace: %s, name: %s, msg: %s", d.ObjType, d.Cluster,
+			d.Namespace, d.Name, d.Reason())
+		return d.Accepted
+	}
+
 	metaobj, ok := obj.(k8sobjects.FilterableObject)
 	if !ok {
 		gslbutils.Warnf("cname: %s, msg: not a meta object, returning", cname)
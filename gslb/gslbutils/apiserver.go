@@ -15,14 +15,17 @@
 package gslbutils
 
 import (
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
+
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
 )
 
 var amkoAPI *api.ApiServer
 
-func InitAmkoAPIServer() {
-	amkoAPIServer := api.NewServer("8080", []models.ApiModel{})
+// InitAmkoAPIServer starts the AMKO API server with the metrics and the API models.
+func InitAmkoAPIServer(apiModels ...models.ApiModel) {
+	amkoAPIServer := api.NewServer("8080", append([]models.ApiModel{&metrics.MetricsModel{}}, apiModels...))
 	amkoAPIServer.InitApi()
 	amkoAPI = amkoAPIServer
 }
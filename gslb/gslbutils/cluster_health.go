@@ -0,0 +1,27 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+// ClusterHealthCheckInterval is the interval in seconds at which the API servers of the member
+// clusters are health checked.
+const ClusterHealthCheckInterval = 15
+
+// ClusterDisconnectThreshold is the number of consecutive failed health checks after which a
+// member cluster is disconnected and its informers are stopped. A cluster is degraded till then.
+const ClusterDisconnectThreshold = 3
+
+// ClusterHealthCheckTimeout is the time in seconds after which a health check of a member cluster
+// fails, so that an unreachable API server doesn't hold up the health checks.
+const ClusterHealthCheckTimeout = 10
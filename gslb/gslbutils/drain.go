@@ -0,0 +1,70 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+)
+
+// DrainAnnotation can be set on a route, service or an ingress to keep the members derived from
+// these objects in their GSLB services, but disabled.
+const DrainAnnotation = "amko.vmware.com/drain"
+
+// DrainStatusInterval is the interval in seconds at which the drain status of the GDP object is
+// updated, if the GSLB services changed since the last update.
+const DrainStatusInterval = 5
+
+// ParseDrain parses the drain annotation from a set of annotations, false is returned if the
+// annotation is absent.
+func ParseDrain(annotations map[string]string) (bool, error) {
+	val, ok := annotations[DrainAnnotation]
+	if !ok {
+		return false, nil
+	}
+	drain, err := strconv.ParseBool(strings.TrimSpace(val))
+	if err != nil {
+		return false, errors.New("invalid value " + val + " for annotation " + DrainAnnotation)
+	}
+	return drain, nil
+}
+
+// IsMemberDrained returns true if a member from cluster cname has to be disabled, either because
+// the object carries the drain annotation, the cluster is in drain mode, the traffic shift routes
+// no traffic to the cluster or the cluster is disconnected and the stale member policy disables
+// its members.
+func IsMemberDrained(objDrained bool, cname string) bool {
+	gf := GetGlobalFilter()
+	return objDrained || gf.IsClusterDrained(cname) || gf.IsClusterShiftedOut(cname) ||
+		AreClusterMembersDisabled(cname)
+}
+
+// DrainChangedClusters returns the list of clusters which were either added to or removed
+// from the drain list of a GDP object.
+func DrainChangedClusters(newList, oldList []string) []string {
+	changedClusters := []string{}
+	for _, cname := range newList {
+		if !PresentInList(cname, oldList) {
+			changedClusters = append(changedClusters, cname)
+		}
+	}
+	for _, cname := range oldList {
+		if !PresentInList(cname, newList) {
+			changedClusters = append(changedClusters, cname)
+		}
+	}
+	return changedClusters
+}
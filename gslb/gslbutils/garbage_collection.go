@@ -0,0 +1,85 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import (
+	"sync"
+	"time"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+)
+
+// GarbageCollectionInterval is the interval in seconds at which the orphaned GSLB services and
+// health monitors are garbage collected.
+const GarbageCollectionInterval = 300
+
+const (
+	// DefaultGCGracePeriod is the time in seconds for which an object has to be orphaned before
+	// it's deleted, if the garbage collection policy doesn't set it.
+	DefaultGCGracePeriod = 1800
+	// DefaultGCMaxDeletions is the maximum number of objects deleted in a garbage collection pass,
+	// if the garbage collection policy doesn't set it.
+	DefaultGCMaxDeletions = 10
+)
+
+// Types of the orphaned objects in the garbage collection report
+const (
+	GCTypeGS = "GSLBService"
+	GCTypeHM = "HealthMonitor"
+)
+
+// GCPolicy is the garbage collection policy of the GSLBConfig object.
+type GCPolicy struct {
+	Enabled      bool
+	GracePeriod  time.Duration
+	MaxDeletions int
+	DryRun       bool
+}
+
+var gcPolicy = struct {
+	lock   sync.RWMutex
+	policy GCPolicy
+}{}
+
+// SetGarbageCollectionPolicy sets the garbage collection policy from the GSLBConfig object, the
+// garbage collection is disabled if policy is nil.
+func SetGarbageCollectionPolicy(policy *gslbalphav2.GarbageCollection) {
+	gcPolicy.lock.Lock()
+	defer gcPolicy.lock.Unlock()
+
+	gcPolicy.policy = GCPolicy{}
+	if policy == nil {
+		return
+	}
+	gcPolicy.policy = GCPolicy{
+		Enabled:      true,
+		GracePeriod:  DefaultGCGracePeriod * time.Second,
+		MaxDeletions: DefaultGCMaxDeletions,
+		DryRun:       policy.DryRun,
+	}
+	if policy.GracePeriod > 0 {
+		gcPolicy.policy.GracePeriod = time.Duration(policy.GracePeriod) * time.Second
+	}
+	if policy.MaxDeletions > 0 {
+		gcPolicy.policy.MaxDeletions = policy.MaxDeletions
+	}
+}
+
+// GetGarbageCollectionPolicy returns the garbage collection policy.
+func GetGarbageCollectionPolicy() GCPolicy {
+	gcPolicy.lock.RLock()
+	defer gcPolicy.lock.RUnlock()
+	return gcPolicy.policy
+}
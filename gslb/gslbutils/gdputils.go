@@ -18,10 +18,13 @@ import (
 	"errors"
 	"strconv"
 	"sync"
+	"time"
 
-	gdpv1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gdpv1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/util/retry"
 )
 
 type GDPObj struct {
@@ -45,6 +48,30 @@ func GetGDPObj() (string, string) {
 	return gdpObj.Name, gdpObj.Namespace
 }
 
+// UpdateGDPStatus writes the status of the GDP object ns/name. update is applied to the latest
+// version of the object and returns false if the status is already up to date. The status is
+// written by the GDP controller as well as by the periodic workers, so a conflicting update is
+// retried on a fresh copy of the object.
+func UpdateGDPStatus(ns, name string, update func(gdp *gdpv1alpha2.GlobalDeploymentPolicy) bool) error {
+	// Always check this flag before writing the status on the GDP object, the fake client for unit tests
+	// can't do a runtime update of CRDs.
+	if !PublishGDPStatus.IsSet() {
+		return nil
+	}
+	gdpClient := GlobalGslbClient.AmkoV1alpha2().GlobalDeploymentPolicies(ns)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		gdp, err := gdpClient.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !update(gdp) {
+			return nil
+		}
+		_, err = gdpClient.Update(gdp)
+		return err
+	})
+}
+
 func IsEmpty() bool {
 	gdpObj.GDPLock.RLock()
 	defer gdpObj.GDPLock.RUnlock()
@@ -73,7 +100,15 @@ type GlobalFilter struct {
 	// ApplicableClusters contain the list of clusters on which the filters
 	// will be applicable
 	ApplicableClusters []string
-	Checksum           uint32
+	// DrainClusters contain the list of clusters whose members have to be disabled
+	DrainClusters []string
+	// TrafficSplitWindows contain the time bounded traffic splits which override TrafficSplit
+	TrafficSplitWindows []TrafficSplitWindow
+	// ActiveTrafficSplitWindow is the name of the window currently active, empty if none
+	ActiveTrafficSplitWindow string
+	// TrafficShift is the progressive traffic shift between two clusters, nil if none
+	TrafficShift *TrafficShift
+	Checksum     uint32
 	// Respective filters for the namespaces.
 	// NSFilterMap map[string]*NSFilter
 	// GlobalLock is locked before accessing any of the filters.
@@ -205,7 +240,7 @@ func createNewNSFilter(lbl map[string]string) *NamespaceFilter {
 // AddToFilter handles creation of new filters, cluster or otherwise.
 // Each namespace can have only one GDP object and one filter respectively, this is
 // taken care of in the admission controller.
-func (gf *GlobalFilter) AddToFilter(gdp *gdpv1alpha1.GlobalDeploymentPolicy) {
+func (gf *GlobalFilter) AddToFilter(gdp *gdpv1alpha2.GlobalDeploymentPolicy) {
 	gf.GlobalLock.Lock()
 	defer gf.GlobalLock.Unlock()
 	if len(gdp.Spec.MatchRules.AppSelector.Label) == 1 {
@@ -231,6 +266,13 @@ func (gf *GlobalFilter) AddToFilter(gdp *gdpv1alpha1.GlobalDeploymentPolicy) {
 		}
 		gf.TrafficSplit = append(gf.TrafficSplit, ct)
 	}
+	// Add the clusters to be drained
+	gf.DrainClusters = gdp.Spec.DrainClusters
+	// Add the scheduled traffic splits and find out the window active right now
+	gf.TrafficSplitWindows = BuildTrafficSplitWindows(gdp)
+	gf.ActiveTrafficSplitWindow = GetActiveTrafficSplitWindow(gf.TrafficSplitWindows, time.Now())
+	// Add the traffic shift, starting from its first step
+	gf.TrafficShift = BuildTrafficShift(gdp)
 	gf.ComputeChecksum()
 	Logf("ns: %s, object: NSFilter, msg: added/changed the global filter", gdp.ObjectMeta.Namespace)
 }
@@ -250,52 +292,59 @@ func (gf *GlobalFilter) ComputeChecksum() {
 	for _, ts := range gf.TrafficSplit {
 		cksum += utils.Hash(ts.ClusterName + strconv.Itoa(int(ts.Weight)))
 	}
+	for _, c := range gf.DrainClusters {
+		cksum += utils.Hash("drain/" + c)
+	}
+	for _, w := range gf.TrafficSplitWindows {
+		cksum += w.getChecksum()
+	}
+	if gf.TrafficShift != nil {
+		cksum += gf.TrafficShift.getChecksum()
+	}
 	gf.Checksum = cksum
 }
 
+// GetTrafficWeight returns the weight of traffic for cluster cname and namespace ns. A namespace
+// level override takes precedence over the cluster weights of the GDP object.
 func (gf *GlobalFilter) GetTrafficWeight(ns, cname string) (int32, error) {
+	if weight, ok := GetTrafficWeightOverrides().GetNamespaceWeight(ns, cname); ok {
+		return weight, nil
+	}
+	return gf.GetGDPTrafficWeight(cname)
+}
+
+// GetGDPTrafficWeight returns the weight of traffic for cluster cname as specified in the
+// accepted GDP object. The weight from the traffic shift takes precedence over the weight from the
+// active traffic split window, which takes precedence over the default traffic split.
+func (gf *GlobalFilter) GetGDPTrafficWeight(cname string) (int32, error) {
 	gf.GlobalLock.RLock()
 	defer gf.GlobalLock.RUnlock()
-	for _, ts := range gf.TrafficSplit {
-		if ts.ClusterName == cname {
-			return ts.Weight, nil
-		}
+	if weight, ok := gf.effectiveWeights()[cname]; ok {
+		return weight, nil
 	}
-	Logf("cname: %s, msg: no weight available for this cluster", cname)
+	Debugf("cname: %s, msg: no weight available for this cluster", cname)
 	return 0, errors.New("no weight available for cluster " + cname)
 }
 
-func PresentInList(key string, strList []string) bool {
-	for _, str := range strList {
-		if str == key {
-			return true
-		}
-	}
-	return false
+// IsClusterDrained returns true if cluster cname is in drain mode as per the accepted GDP object.
+func (gf *GlobalFilter) IsClusterDrained(cname string) bool {
+	gf.GlobalLock.RLock()
+	defer gf.GlobalLock.RUnlock()
+	return PresentInList(cname, gf.DrainClusters)
 }
 
-func isTrafficWeightChanged(new, old *gdpv1alpha1.GlobalDeploymentPolicy) bool {
-	// There are 3 conditions when a cluster traffic ratio is different between the old
-	// and new GDP objects:
-	// 1. Length of the Traffic Split elements is different between the two.
-	// 2. Length is same, but a member from the old list is not found in the new list.
-	// 3. Length is same, but a member has different ratios across both the objects.
+// GetDrainClusters returns a copy of the list of clusters in drain mode.
+func (gf *GlobalFilter) GetDrainClusters() []string {
+	gf.GlobalLock.RLock()
+	defer gf.GlobalLock.RUnlock()
+	drainClusters := make([]string, len(gf.DrainClusters))
+	copy(drainClusters, gf.DrainClusters)
+	return drainClusters
+}
 
-	if len(old.Spec.TrafficSplit) != len(new.Spec.TrafficSplit) {
-		return true
-	}
-	for _, oldMember := range old.Spec.TrafficSplit {
-		found := false
-		for _, newMember := range new.Spec.TrafficSplit {
-			if oldMember.Cluster == newMember.Cluster {
-				found = true
-				if oldMember.Weight != newMember.Weight {
-					return true
-				}
-			}
-		}
-		if found == false {
-			// this member was not found in the new GDP, so return true
+func PresentInList(key string, strList []string) bool {
+	for _, str := range strList {
+		if str == key {
 			return true
 		}
 	}
@@ -304,8 +353,10 @@ func isTrafficWeightChanged(new, old *gdpv1alpha1.GlobalDeploymentPolicy) bool {
 
 // UpdateGlobalFilter takes two arguments: the old and the new GDP objects, and verifies
 // whether a change is required to any of the filters. If yes, it changes either the cluster
-// filter or one of the namespace filters.
-func (gf *GlobalFilter) UpdateGlobalFilter(oldGDP, newGDP *gdpv1alpha1.GlobalDeploymentPolicy) (bool, bool) {
+// filter or one of the namespace filters. Along with whether the filter changed, it also returns the
+// list of clusters for which the effective traffic weights changed, this includes the weights of
+// the active traffic split window and the traffic shift.
+func (gf *GlobalFilter) UpdateGlobalFilter(oldGDP, newGDP *gdpv1alpha2.GlobalDeploymentPolicy) (bool, []string) {
 	// Need to check for the NSFilterMap
 	nf := GetNewGlobalFilter()
 	nf.AddToFilter(newGDP)
@@ -317,28 +368,40 @@ func (gf *GlobalFilter) UpdateGlobalFilter(oldGDP, newGDP *gdpv1alpha1.GlobalDep
 	Debugf("old checksum: %d, new checksum: %d", gf.Checksum, nf.Checksum)
 	if gf.Checksum == nf.Checksum {
 		// No updates needed, just return
-		return false, false
+		return false, nil
 	}
 	Logf("ns: %s, gdp: %s, object: filter, msg: %s", oldGDP.ObjectMeta.Namespace, oldGDP.ObjectMeta.Name,
 		"filter changed, will update filter and re-evaluate objects")
 	// update the filter if the checksums changed
+	oldWeights := gf.effectiveWeights()
 	gf.AppFilter = nf.AppFilter
 	gf.NSFilter = nf.NSFilter
 	gf.TrafficSplit = nf.TrafficSplit
 	gf.ApplicableClusters = nf.ApplicableClusters
+	gf.DrainClusters = nf.DrainClusters
+	gf.TrafficSplitWindows = nf.TrafficSplitWindows
+	gf.ActiveTrafficSplitWindow = nf.ActiveTrafficSplitWindow
+	// a traffic shift in progress continues from its current step, unless its spec was changed
+	if gf.TrafficShift == nil || nf.TrafficShift == nil ||
+		gf.TrafficShift.getChecksum() != nf.TrafficShift.getChecksum() {
+		gf.TrafficShift = nf.TrafficShift
+	}
 	gf.Checksum = nf.Checksum
 
-	trafficWeightChanged := isTrafficWeightChanged(newGDP, oldGDP)
-	return true, trafficWeightChanged
+	return true, weightChangedClusters(gf.effectiveWeights(), oldWeights)
 }
 
 // DeleteFromGlobalFilter deletes a filter pertaining to gdp.
-func (gf *GlobalFilter) DeleteFromGlobalFilter(gdp *gdpv1alpha1.GlobalDeploymentPolicy) {
+func (gf *GlobalFilter) DeleteFromGlobalFilter(gdp *gdpv1alpha2.GlobalDeploymentPolicy) {
 	gf.GlobalLock.Lock()
 	defer gf.GlobalLock.Unlock()
 	gf.AppFilter = nil
 	gf.NSFilter = nil
 	gf.ApplicableClusters = []string{}
+	gf.DrainClusters = []string{}
+	gf.TrafficSplitWindows = []TrafficSplitWindow{}
+	gf.ActiveTrafficSplitWindow = ""
+	gf.TrafficShift = nil
 	gf.Checksum = 0
 	gf.TrafficSplit = []ClusterTraffic{}
 }
@@ -348,10 +411,12 @@ func (gf *GlobalFilter) DeleteFromGlobalFilter(gdp *gdpv1alpha1.GlobalDeployment
 // or its some other namespace. Based on that this GlobalFilter is created.
 func GetNewGlobalFilter() *GlobalFilter {
 	gf := &GlobalFilter{
-		AppFilter:          nil,
-		NSFilter:           nil,
-		TrafficSplit:       []ClusterTraffic{},
-		ApplicableClusters: []string{},
+		AppFilter:           nil,
+		NSFilter:            nil,
+		TrafficSplit:        []ClusterTraffic{},
+		ApplicableClusters:  []string{},
+		DrainClusters:       []string{},
+		TrafficSplitWindows: []TrafficSplitWindow{},
 	}
 	return gf
 }
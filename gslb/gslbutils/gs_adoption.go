@@ -0,0 +1,47 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import "sync"
+
+// gsAdoptions is the set of fqdns, for which a GSLB service which exists on the Avi controller, and
+// wasn't created by AMKO, is adopted instead of creating a new one. It's set via GSLBHostRule objects.
+var gsAdoptions = struct {
+	fqdns map[string]bool
+	lock  sync.RWMutex
+}{fqdns: make(map[string]bool)}
+
+// SetGSAdoption enables or disables the adoption of an existing GSLB service for fqdn. Returns true
+// if there was a change.
+func SetGSAdoption(fqdn string, adopt bool) bool {
+	gsAdoptions.lock.Lock()
+	defer gsAdoptions.lock.Unlock()
+	if gsAdoptions.fqdns[fqdn] == adopt {
+		return false
+	}
+	if adopt {
+		gsAdoptions.fqdns[fqdn] = true
+	} else {
+		delete(gsAdoptions.fqdns, fqdn)
+	}
+	return true
+}
+
+// IsGSAdoptionEnabled returns true if an existing GSLB service can be adopted for fqdn.
+func IsGSAdoptionEnabled(fqdn string) bool {
+	gsAdoptions.lock.RLock()
+	defer gsAdoptions.lock.RUnlock()
+	return gsAdoptions.fqdns[fqdn]
+}
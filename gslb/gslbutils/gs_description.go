@@ -0,0 +1,279 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import (
+	"encoding/json"
+	"errors"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// GSDescriptionVersion is the version of the ownership metadata that AMKO writes in the description
+// field of a GSLB service. Version 0 is the legacy format, which is a comma separated list of
+// TYPE/cluster/namespace/name[/hostname] strings. Version 1 is a JSON object with a list of the member
+// objects. Version 2 is a compact JSON object with the member objects grouped by cluster, namespace
+// and type.
+const GSDescriptionVersion = 2
+
+// GSDescriptionMaxLen is the maximum length of the description that AMKO writes for a GSLB service. If
+// the member objects don't fit, only their count and checksum are written, so that the GSLB service
+// can still be created on the Avi controller.
+const GSDescriptionMaxLen = 1024
+
+// GSMemberObj identifies a k8s/openshift object which is a member of a GSLB service. For ingresses,
+// the name is ingress-name/hostname.
+type GSMemberObj struct {
+	ObjType   string `json:"type"`
+	Cluster   string `json:"cluster"`
+	Namespace string `json:"namespace"`
+	Name      string `json:"name"`
+}
+
+// String returns the member object as TYPE/cluster/namespace/name. This form is used in the checksum
+// of a GSLB service and in the cache, and hence, it must not change across the description versions.
+func (m GSMemberObj) String() string {
+	return m.ObjType + "/" + m.Cluster + "/" + m.Namespace + "/" + m.Name
+}
+
+// GSDescription is the ownership metadata of a GSLB service, which is stored in its description
+// field.
+type GSDescription struct {
+	Version int
+	Owner   string
+	Members []GSMemberObj
+	// Truncated is set if the member objects didn't fit in the description, Members is empty then and
+	// only the count and the checksum of the member objects are known.
+	Truncated    bool
+	MemberCount  int
+	membersCksum uint32
+}
+
+// gsDescriptionV1 is the JSON payload of a version 1 description.
+type gsDescriptionV1 struct {
+	Version int           `json:"version"`
+	Owner   string        `json:"owner"`
+	Members []GSMemberObj `json:"members"`
+}
+
+// gsDescriptionV2 is the JSON payload of a version 2 description. Members maps a cluster to a
+// namespace to an object type to the names of the member objects. Count and Checksum are written
+// instead of Members, if the description would be longer than GSDescriptionMaxLen.
+type gsDescriptionV2 struct {
+	Version  int                                       `json:"v"`
+	Owner    string                                    `json:"o"`
+	Members  map[string]map[string]map[string][]string `json:"m,omitempty"`
+	Count    int                                       `json:"n,omitempty"`
+	Checksum uint32                                    `json:"h,omitempty"`
+}
+
+// EncodeGSDescription returns the description field for a GSLB service with the member objects.
+func EncodeGSDescription(members []GSMemberObj) string {
+	payload := gsDescriptionV2{
+		Version: GSDescriptionVersion,
+		Owner:   AmkoUser,
+		Members: make(map[string]map[string]map[string][]string),
+	}
+	memberObjs := make([]string, 0, len(members))
+	for _, m := range members {
+		if payload.Members[m.Cluster] == nil {
+			payload.Members[m.Cluster] = make(map[string]map[string][]string)
+		}
+		if payload.Members[m.Cluster][m.Namespace] == nil {
+			payload.Members[m.Cluster][m.Namespace] = make(map[string][]string)
+		}
+		payload.Members[m.Cluster][m.Namespace][m.ObjType] = append(
+			payload.Members[m.Cluster][m.Namespace][m.ObjType], m.Name)
+		memberObjs = append(memberObjs, m.String())
+	}
+	desc, err := json.Marshal(payload)
+	if err == nil && len(desc) > GSDescriptionMaxLen {
+		Warnf("members: %d, length: %d, msg: GS description is longer than %d, writing only the checksum of the member objects",
+			len(members), len(desc), GSDescriptionMaxLen)
+		payload.Members = nil
+		payload.Count = len(members)
+		payload.Checksum = GetGSMemberObjsChecksum(memberObjs)
+		desc, err = json.Marshal(payload)
+	}
+	if err != nil {
+		// can't happen, all the fields are strings and integers
+		Errf("members: %v, msg: error in encoding the GS description: %s", members, err)
+		return ""
+	}
+	return string(desc)
+}
+
+// DecodeGSDescription parses the description field of a GSLB service. The JSON payloads and the legacy
+// comma separated format are accepted, the version of the returned object tells which one it was.
+func DecodeGSDescription(description string) (GSDescription, error) {
+	if !strings.HasPrefix(strings.TrimSpace(description), "{") {
+		return decodeLegacyGSDescription(description)
+	}
+	var probe struct {
+		Version int `json:"v"`
+	}
+	if err := json.Unmarshal([]byte(description), &probe); err != nil {
+		return GSDescription{}, errors.New("description field has malformed JSON: " + err.Error())
+	}
+	if probe.Version == 0 {
+		return decodeV1GSDescription(description)
+	}
+	return decodeV2GSDescription(description)
+}
+
+func decodeV1GSDescription(description string) (GSDescription, error) {
+	var payload gsDescriptionV1
+	if err := json.Unmarshal([]byte(description), &payload); err != nil {
+		return GSDescription{}, errors.New("description field has malformed JSON: " + err.Error())
+	}
+	if payload.Version != 1 {
+		return GSDescription{}, errors.New("description field has unsupported version " +
+			strconv.Itoa(payload.Version) + ": " + description)
+	}
+	desc := GSDescription{Version: payload.Version, Owner: payload.Owner, Members: payload.Members}
+	return desc, validateGSDescription(desc, description)
+}
+
+func decodeV2GSDescription(description string) (GSDescription, error) {
+	var payload gsDescriptionV2
+	if err := json.Unmarshal([]byte(description), &payload); err != nil {
+		return GSDescription{}, errors.New("description field has malformed JSON: " + err.Error())
+	}
+	if payload.Version < 2 || payload.Version > GSDescriptionVersion {
+		return GSDescription{}, errors.New("description field has unsupported version " +
+			strconv.Itoa(payload.Version) + ": " + description)
+	}
+	desc := GSDescription{Version: payload.Version, Owner: payload.Owner}
+	if payload.Count > 0 {
+		if len(payload.Members) != 0 {
+			return GSDescription{}, errors.New("description has both member objects and their count: " +
+				description)
+		}
+		desc.Truncated = true
+		desc.MemberCount = payload.Count
+		desc.membersCksum = payload.Checksum
+		return desc, validateGSDescription(desc, description)
+	}
+	// walk the maps in a sorted order, so that the member objects are always returned in the same order
+	for _, cluster := range sortedKeys(payload.Members) {
+		for _, ns := range sortedKeys(payload.Members[cluster]) {
+			for _, objType := range sortedKeys(payload.Members[cluster][ns]) {
+				for _, name := range payload.Members[cluster][ns][objType] {
+					desc.Members = append(desc.Members, GSMemberObj{ObjType: objType, Cluster: cluster,
+						Namespace: ns, Name: name})
+				}
+			}
+		}
+	}
+	desc.MemberCount = len(desc.Members)
+	return desc, validateGSDescription(desc, description)
+}
+
+func sortedKeys(m interface{}) []string {
+	var keys []string
+	switch v := m.(type) {
+	case map[string]map[string]map[string][]string:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string]map[string][]string:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	case map[string][]string:
+		for k := range v {
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func validateGSDescription(desc GSDescription, description string) error {
+	if desc.Owner != AmkoUser {
+		return errors.New("description field has a different owner: " + desc.Owner)
+	}
+	for _, m := range desc.Members {
+		if m.ObjType != IngressType && m.ObjType != SvcType && m.ObjType != RouteType {
+			return errors.New("description has unrecognised objects: " + description)
+		}
+		if m.Cluster == "" || m.Namespace == "" || m.Name == "" {
+			return errors.New("description has incomplete member object: " + m.String())
+		}
+	}
+	return nil
+}
+
+func decodeLegacyGSDescription(description string) (GSDescription, error) {
+	// description field should be like:
+	// LBSvc/cluster-x/namespace-x/svc-x,Ingress/cluster-y/namespace-y/ingress-y/hostname,...
+	desc := GSDescription{Owner: AmkoUser}
+	for _, obj := range strings.Split(description, ",") {
+		seg := strings.Split(obj, "/")
+		switch seg[0] {
+		case IngressType:
+			if len(seg) != 5 {
+				return GSDescription{}, errors.New("description field has malformed ingress: " + description)
+			}
+			seg[3] = seg[3] + "/" + seg[4]
+		case SvcType:
+			if len(seg) != 4 {
+				return GSDescription{}, errors.New("description field has malformed LB service: " + description)
+			}
+		case RouteType:
+			if len(seg) != 4 {
+				return GSDescription{}, errors.New("description field has malformed route: " + description)
+			}
+		default:
+			return GSDescription{}, errors.New("description has unrecognised objects: " + description)
+		}
+		desc.Members = append(desc.Members, GSMemberObj{ObjType: seg[0], Cluster: seg[1], Namespace: seg[2],
+			Name: seg[3]})
+	}
+	desc.MemberCount = len(desc.Members)
+	return desc, nil
+}
+
+// MemberObjList returns the member objects in their TYPE/cluster/namespace/name form.
+func (d GSDescription) MemberObjList() []string {
+	var objs []string
+	for _, m := range d.Members {
+		objs = append(objs, m.String())
+	}
+	return objs
+}
+
+// MembersChecksum returns the checksum of the member objects, as calculated by GetGSMemberObjsChecksum.
+// For a truncated description, it's the checksum which was written in the description.
+func (d GSDescription) MembersChecksum() uint32 {
+	if d.Truncated {
+		return d.membersCksum
+	}
+	return GetGSMemberObjsChecksum(d.MemberObjList())
+}
+
+// GetGSDescriptionChecksum returns the part of a GSLB service's checksum which comes from the version
+// of its description. It's 0 for the current version, so that the checksums of a GSLB service in the
+// cache and in the graph match. For older versions, the checksums differ and the GSLB service gets
+// updated with the current version on the next sync.
+func GetGSDescriptionChecksum(version int) uint32 {
+	if version == GSDescriptionVersion {
+		return 0
+	}
+	return utils.Hash("description/v" + strconv.Itoa(version))
+}
@@ -0,0 +1,80 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import (
+	"encoding/json"
+	"strings"
+	"sync/atomic"
+)
+
+// GSHealthPollInterval is the interval in seconds at which the runtime health of the GSLB services
+// is fetched from the Avi controller.
+const GSHealthPollInterval = 60
+
+// Health states of the GSLB services and their members
+const (
+	HealthUp       = "Up"
+	HealthDown     = "Down"
+	HealthDisabled = "Disabled"
+	HealthUnknown  = "Unknown"
+)
+
+// GSHealthAnnotation is set by AMKO on the routes, services and ingresses which are GS members. Its
+// value is a JSON map of the GS FQDN to the GS health of that FQDN.
+const GSHealthAnnotation = "amko.vmware.com/gslb-health"
+
+// gsHealthAnnotations is set if the GS health annotation is written on the member objects, which
+// needs the patch permission on them in the member clusters.
+var gsHealthAnnotations int32
+
+// SetGSHealthAnnotations enables or disables the GS health annotation on the member objects.
+func SetGSHealthAnnotations(enabled bool) {
+	var val int32
+	if enabled {
+		val = 1
+	}
+	atomic.StoreInt32(&gsHealthAnnotations, val)
+}
+
+// IsGSHealthAnnotationsEnabled returns true if the GS health annotation is written on the member
+// objects.
+func IsGSHealthAnnotationsEnabled() bool {
+	return atomic.LoadInt32(&gsHealthAnnotations) == 1
+}
+
+// GSHealthAnnotationValue is the health of a GS and of the member derived from the annotated object.
+type GSHealthAnnotationValue struct {
+	GSState     string `json:"gsState"`
+	MemberState string `json:"memberState"`
+}
+
+// BuildGSHealthAnnotation returns the value of the GS health annotation for an object, given the
+// health per FQDN of the GS members derived from that object.
+func BuildGSHealthAnnotation(health map[string]GSHealthAnnotationValue) string {
+	// json marshals the map keys in a sorted order, so the value is stable
+	val, err := json.Marshal(health)
+	if err != nil {
+		Errf("health: %v, msg: couldn't marshal the GS health annotation: %s", health, err)
+		return ""
+	}
+	return string(val)
+}
+
+// GetGSStatusObjName returns the name of the GSLBServiceStatus object for a GS, a wildcard in the GS
+// name is replaced, as it isn't allowed in object names.
+func GetGSStatusObjName(gsName string) string {
+	return strings.ToLower(strings.Replace(gsName, "*", "wildcard", -1))
+}
@@ -22,23 +22,24 @@ import (
 	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
-	gslbalphav1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	gslbcs "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned"
 
 	routev1 "github.com/openshift/api/route/v1"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
 	"k8s.io/api/networking/v1beta1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/util/retry"
 )
 
 const (
 	// MaxClusters is the supported number of clusters
 	MaxClusters int = 10
-	// GSLBKubePath is a temporary path to put the kubeconfig
-	GSLBKubePath = "/tmp/gslb-kubeconfig"
 	//AVISystem is the namespace where everything AVI related is created
 	AVISystem = "avi-system"
 	// Ingestion layer operations
@@ -46,9 +47,9 @@ const (
 	ObjectDelete = "DELETE"
 	ObjectUpdate = "UPDATE"
 	// Ingestion layer objects
-	RouteType        = gslbalphav1.RouteObj
-	IngressType      = gslbalphav1.IngressObj
-	SvcType          = gslbalphav1.LBSvcObj
+	RouteType        = gslbalphav2.RouteObj
+	IngressType      = gslbalphav2.IngressObj
+	SvcType          = gslbalphav2.LBSvcObj
 	PassthroughRoute = "passthrough"
 	// Refresh cycle for AVI cache in seconds
 	DefaultRefreshInterval = 600
@@ -67,6 +68,8 @@ const (
 	DefaultRetryCount = 5
 
 	AmkoUser = "amko-gslb"
+	// AmkoHmDescription is the description of the health monitors created by AMKO
+	AmkoHmDescription = "created by: amko"
 
 	NumRestWorkers = 8
 
@@ -267,17 +270,41 @@ var (
 	RejectedNSStore      *ObjectStore
 )
 
+// GetGSMemberChecksumStr returns the string used to calculate the checksum of a GS member. The
+// state is appended only for disabled members, so that the checksums of the enabled members stay
+// the same.
+func GetGSMemberChecksumStr(ipAddr string, weight int32, enabled bool) string {
+	memberStr := ipAddr + "-" + strconv.Itoa(int(weight))
+	if !enabled {
+		memberStr += "-disabled"
+	}
+	return memberStr
+}
+
 func GetGSLBServiceChecksum(ipList, domainList, memberObjs []string, hmNames []string) uint32 {
+	return GetGSLBServiceChecksumWithMembers(ipList, domainList, GetGSMemberObjsChecksum(memberObjs), hmNames)
+}
+
+// GetGSMemberObjsChecksum returns the part of a GSLB service's checksum which comes from its member
+// objects.
+func GetGSMemberObjsChecksum(memberObjs []string) uint32 {
+	sort.Strings(memberObjs)
+	return utils.Hash(utils.Stringify(memberObjs))
+}
+
+// GetGSLBServiceChecksumWithMembers returns the checksum of a GSLB service, with the checksum of its
+// member objects already calculated. It's used for the GSLB services whose description has only the
+// checksum of the member objects.
+func GetGSLBServiceChecksumWithMembers(ipList, domainList []string, membersCksum uint32, hmNames []string) uint32 {
 	sort.Strings(ipList)
 	sort.Strings(domainList)
-	sort.Strings(memberObjs)
 	sort.Strings(hmNames)
 
 	// checksum has to take into consideration the non-path HMs and the path based HMs
 
 	return utils.Hash(utils.Stringify(ipList)) +
 		utils.Hash(utils.Stringify(domainList)) +
-		utils.Hash(utils.Stringify(memberObjs)) +
+		membersCksum +
 		utils.Hash(utils.Stringify(hmNames))
 }
 
@@ -292,7 +319,7 @@ func GetAviAdminTenantRef() string {
 
 // GSLBConfigObj is global and is initialized only once
 type GSLBConfigObj struct {
-	configObj  *gslbalphav1.GSLBConfig
+	configObj  *gslbalphav2.GSLBConfig
 	configLock sync.RWMutex
 }
 
@@ -321,27 +348,171 @@ func GetGSLBConfigNameAndNS() (string, string) {
 	return gcObj.configObj.Name, gcObj.configObj.Namespace
 }
 
-func updateGSLBConfigStatusMsg(msg string) {
+// SetGSLBConfigCondition sets a condition in the status of the GSLBConfig object, the status is
+// published along with the next UpdateGSLBConfigStatus call.
+func SetGSLBConfigCondition(condType string, status metav1.ConditionStatus, reason, msg string) {
+	gcObj.configLock.Lock()
+	defer gcObj.configLock.Unlock()
+
+	if gcObj.configObj == nil {
+		return
+	}
+	gslbalphav2.SetCondition(&gcObj.configObj.Status.Conditions, gslbalphav2.Condition{
+		Type:               condType,
+		Status:             status,
+		ObservedGeneration: gcObj.configObj.Generation,
+		Reason:             reason,
+		Message:            msg,
+	})
+	gcObj.configObj.Status.ObservedGeneration = gcObj.configObj.Generation
+}
+
+// SetMemberClusterStatus sets the connectivity state of a member cluster and its Ready condition in
+// the status of the GSLBConfig object, the status is published along with the next
+// UpdateGSLBConfigStatus call. The Ready condition is unknown while the cluster is degraded.
+func SetMemberClusterStatus(cname, state, msg string) {
+	gcObj.configLock.Lock()
+	defer gcObj.configLock.Unlock()
+
+	member := getMemberClusterStatus(cname)
+	if member == nil {
+		return
+	}
+	status, reason := metav1.ConditionTrue, gslbalphav2.ReasonConnected
+	switch state {
+	case gslbalphav2.ClusterDegraded:
+		status, reason = metav1.ConditionUnknown, gslbalphav2.ReasonDegraded
+	case gslbalphav2.ClusterDisconnected:
+		status, reason = metav1.ConditionFalse, gslbalphav2.ReasonDisconnected
+	}
+	member.State = state
+	gslbalphav2.SetCondition(&member.Conditions, gslbalphav2.Condition{
+		Type:               gslbalphav2.ConditionReady,
+		Status:             status,
+		ObservedGeneration: gcObj.configObj.Generation,
+		Reason:             reason,
+		Message:            msg,
+	})
+}
+
+// SetMemberClusterStaleMembers sets the stale member policy action which was applied on the GS
+// members of a member cluster, empty if none.
+func SetMemberClusterStaleMembers(cname, staleMembers string) {
 	gcObj.configLock.Lock()
 	defer gcObj.configLock.Unlock()
 
-	gcObj.configObj.Status.State = msg
+	if member := getMemberClusterStatus(cname); member != nil {
+		member.StaleMembers = staleMembers
+	}
 }
 
-func SetGSLBConfigObj(gc *gslbalphav1.GSLBConfig) {
+// SetGarbageCollectionStatus sets the report of the last garbage collection pass in the status of
+// the GSLBConfig object, the status is published along with the next GSLBConfig status update.
+func SetGarbageCollectionStatus(gcStatus *gslbalphav2.GarbageCollectionStatus) {
+	gcObj.configLock.Lock()
+	defer gcObj.configLock.Unlock()
+
+	if gcObj.configObj == nil {
+		return
+	}
+	gcObj.configObj.Status.GarbageCollection = gcStatus
+}
+
+// DeleteMemberClusterStatus removes the status of a member cluster which is no longer part of the
+// GSLB configuration.
+func DeleteMemberClusterStatus(cname string) {
+	gcObj.configLock.Lock()
+	defer gcObj.configLock.Unlock()
+
+	if gcObj.configObj == nil {
+		return
+	}
+	members := gcObj.configObj.Status.MemberClusters
+	for i := range members {
+		if members[i].ClusterContext == cname {
+			gcObj.configObj.Status.MemberClusters = append(members[:i], members[i+1:]...)
+			return
+		}
+	}
+}
+
+// getMemberClusterStatus returns the status of a member cluster in the GSLBConfig object, it's
+// added if absent. Must be called with the config lock held.
+func getMemberClusterStatus(cname string) *gslbalphav2.MemberClusterStatus {
+	if gcObj.configObj == nil {
+		return nil
+	}
+	members := &gcObj.configObj.Status.MemberClusters
+	for i := range *members {
+		if (*members)[i].ClusterContext == cname {
+			return &(*members)[i]
+		}
+	}
+	*members = append(*members, gslbalphav2.MemberClusterStatus{ClusterContext: cname})
+	return &(*members)[len(*members)-1]
+}
+
+// NotReadyMemberClusters returns the member clusters whose Ready condition isn't true.
+func NotReadyMemberClusters() []string {
+	gcObj.configLock.RLock()
+	defer gcObj.configLock.RUnlock()
+
+	var notReady []string
+	if gcObj.configObj == nil {
+		return notReady
+	}
+	ready := make(map[string]bool)
+	for _, member := range gcObj.configObj.Status.MemberClusters {
+		ready[member.ClusterContext] = gslbalphav2.IsConditionTrue(member.Conditions, gslbalphav2.ConditionReady)
+	}
+	for _, member := range gcObj.configObj.Spec.MemberClusters {
+		if !ready[member.ClusterContext] {
+			notReady = append(notReady, member.ClusterContext)
+		}
+	}
+	return notReady
+}
+
+// GetGSLBConfigStatus returns a copy of the status of the GSLBConfig object.
+func GetGSLBConfigStatus() gslbalphav2.GSLBConfigStatus {
+	gcObj.configLock.RLock()
+	defer gcObj.configLock.RUnlock()
+
+	if gcObj.configObj == nil {
+		return gslbalphav2.GSLBConfigStatus{}
+	}
+	return *gcObj.configObj.Status.DeepCopy()
+}
+
+func SetGSLBConfigObj(gc *gslbalphav2.GSLBConfig) {
 	gcObj.configLock.Lock()
 	defer gcObj.configLock.Unlock()
 
 	gcObj.configObj = gc
 }
 
-func UpdateGSLBConfigStatus(msg string) error {
-	if !PublishGSLBStatus {
+// UpdateGSLBConfigStatus sets the condition condType of the GSLBConfig object and publishes its
+// status. The status of a condition is True or False once AMKO has evaluated it, and Unknown while
+// it's still being evaluated.
+func UpdateGSLBConfigStatus(condType string, status metav1.ConditionStatus, reason, msg string) error {
+	SetGSLBConfigCondition(condType, status, reason, msg)
+	return PublishGSLBConfigStatus()
+}
+
+// PublishGSLBConfigStatus publishes the status of the GSLBConfig object, along with the conditions
+// which were set after it was last published.
+func PublishGSLBConfigStatus() error {
+	if !PublishGSLBStatus.IsSet() {
 		return nil
 	}
 
-	updateGSLBConfigStatusMsg(msg)
-	updatedGC, updateErr := GlobalGslbClient.AmkoV1alpha1().GSLBConfigs(gcObj.configObj.ObjectMeta.Namespace).Update(gcObj.configObj)
+	gcObj.configLock.RLock()
+	gc := gcObj.configObj.DeepCopy()
+	gcObj.configLock.RUnlock()
+	if gc == nil {
+		return nil
+	}
+	updatedGC, updateErr := GlobalGslbClient.AmkoV1alpha2().GSLBConfigs(gc.ObjectMeta.Namespace).Update(gc)
 	if updateErr != nil {
 		Errf("error in updating the GSLBConfig object: %s", updateErr.Error())
 		return errors.New("error in GSLBConfig object update, " + updateErr.Error())
@@ -362,47 +533,206 @@ func SetGSLBConfig(value bool) {
 	gslbConfigSet = value
 }
 
-var GlobalKubeClient *kubernetes.Clientset
+var GlobalKubeClient kubernetes.Interface
 var GlobalGslbClient *gslbcs.Clientset
-var PublishGDPStatus bool
-var PublishGSLBStatus bool
 
-type AviControllerConfig struct {
+// StatusFlag enables the status updates of an AMKO object type. It's set by the leader election and
+// read by the controllers, so it's accessed atomically.
+type StatusFlag struct {
+	val int32
+}
+
+func (f *StatusFlag) Set(enabled bool) {
+	var val int32
+	if enabled {
+		val = 1
+	}
+	atomic.StoreInt32(&f.val, val)
+}
+
+func (f *StatusFlag) IsSet() bool {
+	return atomic.LoadInt32(&f.val) == 1
+}
+
+// The status of the AMKO objects is published only if these are set, only the leader publishes
+// the status.
+var (
+	PublishGDPStatus          StatusFlag
+	PublishGSLBStatus         StatusFlag
+	PublishGSLBHostRuleStatus StatusFlag
+	PublishGSLBServiceStatus  StatusFlag
+)
+
+// UpdateGSLBHostRuleStatus writes the status of the GSLBHostRule object ns/name. update is applied
+// to the latest version of the object and returns false if the status is already up to date. A
+// conflicting update is retried on a fresh copy of the object.
+func UpdateGSLBHostRuleStatus(ns, name string, update func(hr *gslbalphav2.GSLBHostRule) bool) error {
+	// The fake client used in unit tests doesn't support status updates on CRDs, so check this flag
+	// before writing the status.
+	if !PublishGSLBHostRuleStatus.IsSet() {
+		return nil
+	}
+	hrClient := GlobalGslbClient.AmkoV1alpha2().GSLBHostRules(ns)
+	return retry.RetryOnConflict(retry.DefaultRetry, func() error {
+		hr, err := hrClient.Get(name, metav1.GetOptions{})
+		if err != nil {
+			return err
+		}
+		if !update(hr) {
+			return nil
+		}
+		_, err = hrClient.Update(hr)
+		return err
+	})
+}
+
+// AviControllerCredentials are the credentials of the GSLB leader controller, the username along
+// with a password, an API token or a client certificate.
+type AviControllerCredentials struct {
 	Username string
 	Password string
-	IPAddr   string
-	Version  string
+	Token    string
+	// ClientCert and ClientKey are the PEM encoded certificate and key which AMKO presents to the
+	// controller
+	ClientCert []byte
+	ClientKey  []byte
+}
+
+// IsSet returns true if the username and one of the password, the token or the client certificate
+// are set.
+func (c AviControllerCredentials) IsSet() bool {
+	return c.Username != "" && (c.Password != "" || c.Token != "" || len(c.ClientCert) > 0)
+}
+
+// Verification modes of the certificate of the Avi controller
+const (
+	TLSVerificationFull   = "Full"
+	TLSVerificationCAOnly = "CAOnly"
+	TLSVerificationNone   = "None"
+)
+
+// IsTLSVerificationValid returns true if mode is a verification mode of the controller certificate.
+func IsTLSVerificationValid(mode string) bool {
+	return mode == TLSVerificationFull || mode == TLSVerificationCAOnly || mode == TLSVerificationNone
+}
+
+// AviControllerTLS is the verification of the TLS certificate of the Avi controller.
+type AviControllerTLS struct {
+	// Verification is one of Full, CAOnly or None
+	Verification string
+	// CAData is the PEM encoded CA bundle, the system CAs are used if it's empty
+	CAData []byte
+	// ServerName overrides the host name which the certificate is verified for
+	ServerName string
+}
+
+type AviControllerConfig struct {
+	AviControllerCredentials
+	TLS     AviControllerTLS
+	IPAddr  string
+	Version string
+	// Endpoints are the candidate controllers, AMKO re-targets to the one which is the GSLB leader
+	// if the controller at IPAddr isn't
+	Endpoints []string
 }
 
 var gslbLeaderConfig AviControllerConfig
 var leaderConfig sync.Once
 
+// leaderConfigLock guards gslbLeaderConfig, the controller IP changes when AMKO re-targets to a new
+// GSLB leader
+var leaderConfigLock sync.RWMutex
+
 func NewAviControllerConfig(username, password, ipAddr, version string) *AviControllerConfig {
 	leaderConfig.Do(func() {
+		leaderConfigLock.Lock()
+		defer leaderConfigLock.Unlock()
 		gslbLeaderConfig = AviControllerConfig{
-			Username: username,
-			Password: password,
-			IPAddr:   ipAddr,
-			Version:  version,
+			AviControllerCredentials: AviControllerCredentials{
+				Username: username,
+				Password: password,
+			},
+			TLS:     AviControllerTLS{Verification: TLSVerificationNone},
+			IPAddr:  ipAddr,
+			Version: version,
 		}
 	})
 	return &gslbLeaderConfig
 }
 
 func GetAviConfig() AviControllerConfig {
+	leaderConfigLock.RLock()
+	defer leaderConfigLock.RUnlock()
 	return gslbLeaderConfig
 }
 
+// SetAviControllerEndpoints sets the candidate controllers for the GSLB leader.
+func SetAviControllerEndpoints(endpoints []string) {
+	leaderConfigLock.Lock()
+	defer leaderConfigLock.Unlock()
+	gslbLeaderConfig.Endpoints = endpoints
+}
+
+// SetAviControllerCredentials changes the credentials with which AMKO connects to the controller.
+func SetAviControllerCredentials(creds AviControllerCredentials) {
+	leaderConfigLock.Lock()
+	defer leaderConfigLock.Unlock()
+	gslbLeaderConfig.AviControllerCredentials = creds
+}
+
+// SetAviControllerTLS sets the verification of the certificate of the controller.
+func SetAviControllerTLS(tlsConfig AviControllerTLS) {
+	leaderConfigLock.Lock()
+	defer leaderConfigLock.Unlock()
+	gslbLeaderConfig.TLS = tlsConfig
+}
+
+// SetAviControllerIP changes the controller which AMKO syncs the GSLB services to.
+func SetAviControllerIP(ipAddr string) {
+	leaderConfigLock.Lock()
+	defer leaderConfigLock.Unlock()
+	gslbLeaderConfig.IPAddr = ipAddr
+}
+
 var initializedClusterContexts []string
 
+// clusterContextsLock guards initializedClusterContexts, the discovered member clusters are added
+// and removed at runtime
+var clusterContextsLock sync.RWMutex
+
 func AddClusterContext(cc string) {
-	if IsClusterContextPresent(cc) {
-		return
+	clusterContextsLock.Lock()
+	defer clusterContextsLock.Unlock()
+	for _, context := range initializedClusterContexts {
+		if context == cc {
+			return
+		}
 	}
 	initializedClusterContexts = append(initializedClusterContexts, cc)
 }
 
+// RemoveClusterContext removes a member cluster which is no longer part of the GSLB configuration.
+func RemoveClusterContext(cc string) {
+	clusterContextsLock.Lock()
+	defer clusterContextsLock.Unlock()
+	for i, context := range initializedClusterContexts {
+		if context == cc {
+			initializedClusterContexts = append(initializedClusterContexts[:i], initializedClusterContexts[i+1:]...)
+			return
+		}
+	}
+}
+
+// ClusterContextsInitialized returns true once the member clusters of the GSLBConfig object are added.
+func ClusterContextsInitialized() bool {
+	clusterContextsLock.RLock()
+	defer clusterContextsLock.RUnlock()
+	return len(initializedClusterContexts) != 0
+}
+
 func IsClusterContextPresent(cc string) bool {
+	clusterContextsLock.RLock()
+	defer clusterContextsLock.RUnlock()
 	for _, context := range initializedClusterContexts {
 		if context == cc {
 			return true
@@ -425,6 +755,94 @@ func IsControllerLeader() bool {
 	return controllerIsLeader
 }
 
+// amkoLeader is set if this AMKO replica is the leader among the AMKO replicas, only the leader
+// writes to the Avi controller and publishes the status of the AMKO objects. It's set unless leader
+// election is enabled.
+var amkoLeader int32 = 1
+
+// SetAMKOLeader marks this AMKO replica as the leader or as a standby.
+func SetAMKOLeader(leader bool) {
+	var val int32
+	if leader {
+		val = 1
+	}
+	atomic.StoreInt32(&amkoLeader, val)
+}
+
+// IsAMKOLeader returns true if this AMKO replica is the leader.
+func IsAMKOLeader() bool {
+	return atomic.LoadInt32(&amkoLeader) == 1
+}
+
+// Annotations of the GSLBConfig object which request the operations of AMKO. They're set through the
+// kubernetes API, e.g. by amkoctl, so that the requests are authorized by RBAC, and are honored by
+// all the AMKO replicas and across restarts.
+const (
+	// ReconcilePausedAnnotation pauses the reconciliation of the Avi controller if it's set to "true"
+	ReconcilePausedAnnotation = "amko.vmware.com/reconcile-paused"
+	// ResyncAnnotation requests a resync of the GS graph with this tenant/name key, or of all the GS
+	// graphs if it's ResyncAll
+	ResyncAnnotation = "amko.vmware.com/resync"
+	// ResyncRequestedAtAnnotation is the time of the resync request, so that the resync of the same
+	// key can be requested again
+	ResyncRequestedAtAnnotation = "amko.vmware.com/resync-requested-at"
+	ResyncAll                   = "all"
+)
+
+// reconcilePaused is set if the reconciliation of the Avi controller is paused by the operator, the
+// GS graphs are still built, but aren't synced to the Avi controller till it's resumed.
+var reconcilePaused int32
+
+// SetReconcilePaused pauses or resumes the reconciliation of the Avi controller.
+func SetReconcilePaused(paused bool) {
+	var val int32
+	if paused {
+		val = 1
+	}
+	atomic.StoreInt32(&reconcilePaused, val)
+}
+
+// IsReconcilePaused returns true if the reconciliation of the Avi controller is paused.
+func IsReconcilePaused() bool {
+	return atomic.LoadInt32(&reconcilePaused) == 1
+}
+
+// dryRun is set if AMKO runs in the dry run mode, the rest operations for the Avi controller are
+// built and planned, but aren't executed.
+var dryRun int32
+
+// SetDryRun enables or disables the dry run mode.
+func SetDryRun(enabled bool) {
+	var val int32
+	if enabled {
+		val = 1
+	}
+	atomic.StoreInt32(&dryRun, val)
+}
+
+// IsDryRun returns true if AMKO runs in the dry run mode.
+func IsDryRun() bool {
+	return atomic.LoadInt32(&dryRun) == 1
+}
+
+// bootupSyncDone is set once the bootup sync has synced the objects of the member clusters and
+// generated the GS graphs.
+var bootupSyncDone int32
+
+// SetBootupSyncDone marks the bootup sync as done, or not.
+func SetBootupSyncDone(done bool) {
+	var val int32
+	if done {
+		val = 1
+	}
+	atomic.StoreInt32(&bootupSyncDone, val)
+}
+
+// IsBootupSyncDone returns true if the bootup sync is done.
+func IsBootupSyncDone() bool {
+	return atomic.LoadInt32(&bootupSyncDone) == 1
+}
+
 func GetKeyIdx(strList []string, key string) (int, bool) {
 	for i, str := range strList {
 		if str == key {
@@ -0,0 +1,101 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import (
+	"sync"
+	"time"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+)
+
+// Actions of the stale member policy, applied on the GS members of a disconnected member cluster
+const (
+	// StaleMemberKeep keeps the members as they were last known
+	StaleMemberKeep = "Keep"
+	// StaleMemberDisable keeps the members in their GSLB services, but disabled
+	StaleMemberDisable = "Disable"
+	// StaleMemberRemove removes the members from their GSLB services
+	StaleMemberRemove = "Remove"
+)
+
+// DefaultStaleMemberGracePeriod is the time in seconds for which a member cluster has to be
+// disconnected before the stale member policy is applied, if the policy doesn't set it.
+const DefaultStaleMemberGracePeriod = 300
+
+// IsStaleMemberActionValid returns true if action is a valid stale member policy action.
+func IsStaleMemberActionValid(action string) bool {
+	return action == StaleMemberKeep || action == StaleMemberDisable || action == StaleMemberRemove
+}
+
+type staleMembers struct {
+	lock        sync.RWMutex
+	action      string
+	gracePeriod time.Duration
+	// disabledClusters are the clusters whose members are disabled as per the policy
+	disabledClusters map[string]bool
+}
+
+var staleMemberPolicy = staleMembers{
+	action:           StaleMemberKeep,
+	gracePeriod:      DefaultStaleMemberGracePeriod * time.Second,
+	disabledClusters: make(map[string]bool),
+}
+
+// SetStaleMemberPolicy sets the stale member policy from the GSLBConfig object, the members are
+// kept if policy is nil.
+func SetStaleMemberPolicy(policy *gslbalphav2.StaleMemberPolicy) {
+	staleMemberPolicy.lock.Lock()
+	defer staleMemberPolicy.lock.Unlock()
+
+	staleMemberPolicy.action = StaleMemberKeep
+	staleMemberPolicy.gracePeriod = DefaultStaleMemberGracePeriod * time.Second
+	if policy == nil {
+		return
+	}
+	if policy.Action != "" {
+		staleMemberPolicy.action = policy.Action
+	}
+	if policy.GracePeriod > 0 {
+		staleMemberPolicy.gracePeriod = time.Duration(policy.GracePeriod) * time.Second
+	}
+}
+
+// GetStaleMemberPolicy returns the action and the grace period of the stale member policy.
+func GetStaleMemberPolicy() (string, time.Duration) {
+	staleMemberPolicy.lock.RLock()
+	defer staleMemberPolicy.lock.RUnlock()
+	return staleMemberPolicy.action, staleMemberPolicy.gracePeriod
+}
+
+// SetClusterMembersDisabled marks the members of cluster cname as disabled by the stale member
+// policy, or clears it.
+func SetClusterMembersDisabled(cname string, disabled bool) {
+	staleMemberPolicy.lock.Lock()
+	defer staleMemberPolicy.lock.Unlock()
+	if disabled {
+		staleMemberPolicy.disabledClusters[cname] = true
+		return
+	}
+	delete(staleMemberPolicy.disabledClusters, cname)
+}
+
+// AreClusterMembersDisabled returns true if the members of cluster cname are disabled by the stale
+// member policy.
+func AreClusterMembersDisabled(cname string) bool {
+	staleMemberPolicy.lock.RLock()
+	defer staleMemberPolicy.lock.RUnlock()
+	return staleMemberPolicy.disabledClusters[cname]
+}
@@ -196,6 +196,18 @@ func (clusterStore *ClusterStore) GetAllClusterNSObjects() []string {
 	return result
 }
 
+// GetClusterNSObjects returns the ns/objName list of all the objects of cluster cname.
+func (clusterStore *ClusterStore) GetClusterNSObjects(cname string) []string {
+	clusterStore.ClusterLock.RLock()
+	defer clusterStore.ClusterLock.RUnlock()
+
+	objStore, ok := clusterStore.ClusterObjectMap[cname]
+	if !ok || objStore == nil {
+		return []string{}
+	}
+	return objStore.GetAllNSObjects()
+}
+
 // AddOrUpdate fetches the right cluster store and then updates the object inside the
 // namespace store inside the cluster store.
 func (clusterStore *ClusterStore) AddOrUpdate(obj interface{}, cname, ns, objName string) {
@@ -0,0 +1,274 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import (
+	"strconv"
+	"time"
+
+	gdpv1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// TrafficShiftInterval is the interval in seconds at which the health of the target cluster of a
+// traffic shift is evaluated.
+const TrafficShiftInterval = 15
+
+// States of a traffic shift
+const (
+	TrafficShiftProgressing = "Progressing"
+	TrafficShiftCompleted   = "Completed"
+	TrafficShiftRolledBack  = "RolledBack"
+)
+
+// TrafficShift holds the spec of a traffic shift along with its progress.
+type TrafficShift struct {
+	SourceCluster string
+	TargetCluster string
+	Steps         []int
+	SoakTime      time.Duration
+
+	// Step is the index of the current step
+	Step  int
+	State string
+	// HealthySince is the time from which the target cluster's members are healthy in the current
+	// step, zero if they aren't healthy yet
+	HealthySince time.Time
+	Message      string
+}
+
+// TargetHealth is the health of the GS members of the target cluster of a traffic shift. Members
+// which are neither up nor down (e.g., still being initialized) are only counted in Members.
+type TargetHealth struct {
+	Members     int
+	UpMembers   int
+	DownMembers int
+}
+
+// Healthy returns true if there's at least one member and all the members are up.
+func (h TargetHealth) Healthy() bool {
+	return h.Members > 0 && h.UpMembers == h.Members
+}
+
+// Failed returns true if any of the members are down.
+func (h TargetHealth) Failed() bool {
+	return h.DownMembers > 0
+}
+
+// BuildTrafficShift builds the traffic shift from a GDP object, nil is returned if the GDP object
+// doesn't have one. The traffic shift starts at the first step.
+func BuildTrafficShift(gdp *gdpv1alpha2.GlobalDeploymentPolicy) *TrafficShift {
+	if gdp.Spec.TrafficShift == nil {
+		return nil
+	}
+	steps := make([]int, len(gdp.Spec.TrafficShift.Steps))
+	copy(steps, gdp.Spec.TrafficShift.Steps)
+	return &TrafficShift{
+		SourceCluster: gdp.Spec.TrafficShift.SourceCluster,
+		TargetCluster: gdp.Spec.TrafficShift.TargetCluster,
+		Steps:         steps,
+		SoakTime:      time.Duration(gdp.Spec.TrafficShift.SoakTime) * time.Second,
+		Step:          0,
+		State:         TrafficShiftProgressing,
+	}
+}
+
+func (ts *TrafficShift) getChecksum() uint32 {
+	cksum := utils.Hash("shift/" + ts.SourceCluster + "/" + ts.TargetCluster + "/" + ts.SoakTime.String())
+	for idx, step := range ts.Steps {
+		cksum += utils.Hash("shift/" + strconv.Itoa(idx) + "/" + strconv.Itoa(step))
+	}
+	return cksum
+}
+
+// TargetPercent returns the percentage of traffic routed to the target cluster right now.
+func (ts *TrafficShift) TargetPercent() int {
+	if ts.State == TrafficShiftRolledBack || len(ts.Steps) == 0 {
+		return 0
+	}
+	return ts.Steps[ts.Step]
+}
+
+// GetStatus returns the status of the traffic shift to be published on the GDP object.
+func (ts *TrafficShift) GetStatus() *gdpv1alpha2.TrafficShiftStatus {
+	return &gdpv1alpha2.TrafficShiftStatus{
+		Step:          ts.Step,
+		TargetPercent: ts.TargetPercent(),
+		State:         ts.State,
+		Message:       ts.Message,
+	}
+}
+
+// Resume continues the traffic shift from a status published earlier, e.g., before a restart of
+// AMKO. The status is ignored if it doesn't fit the steps of this traffic shift.
+func (ts *TrafficShift) Resume(status *gdpv1alpha2.TrafficShiftStatus) {
+	if status == nil || status.Step < 0 || status.Step >= len(ts.Steps) {
+		return
+	}
+	switch status.State {
+	case TrafficShiftProgressing, TrafficShiftCompleted, TrafficShiftRolledBack:
+		ts.Step = status.Step
+		ts.State = status.State
+		ts.Message = status.Message
+	}
+}
+
+// percentToWeights converts the percentage of traffic for the target cluster to the weights of
+// the target and the source clusters. The weights always add up to MaxTrafficWeight, a weight of 0
+// means that the members of that cluster have to be disabled.
+func percentToWeights(percent int) (int32, int32) {
+	if percent <= 0 {
+		return 0, MaxTrafficWeight
+	}
+	if percent >= 100 {
+		return MaxTrafficWeight, 0
+	}
+	target := int32((percent*MaxTrafficWeight + 50) / 100)
+	if target < 1 {
+		target = 1
+	} else if target > MaxTrafficWeight-1 {
+		target = MaxTrafficWeight - 1
+	}
+	return target, MaxTrafficWeight - target
+}
+
+// weights returns the weights of the source and the target clusters for the current step, the
+// clusters with a weight of 0 are excluded.
+func (ts *TrafficShift) weights() map[string]int32 {
+	weights := make(map[string]int32)
+	target, source := percentToWeights(ts.TargetPercent())
+	if target != 0 {
+		weights[ts.TargetCluster] = target
+	}
+	if source != 0 {
+		weights[ts.SourceCluster] = source
+	}
+	return weights
+}
+
+// shiftedOutClusters returns the clusters which don't get any traffic in the current step.
+func (ts *TrafficShift) shiftedOutClusters() []string {
+	target, source := percentToWeights(ts.TargetPercent())
+	if target == 0 {
+		return []string{ts.TargetCluster}
+	}
+	if source == 0 {
+		return []string{ts.SourceCluster}
+	}
+	return []string{}
+}
+
+// Advance moves the traffic shift ahead based on the health of the target cluster at time t. If any
+// of the members are down, the shift is rolled back and all the traffic goes to the source cluster.
+// If all the members are up for the soak time, the next step is applied, and after the last step, the
+// shift is completed. Returns true if the state of the traffic shift changed.
+func (ts *TrafficShift) Advance(t time.Time, health TargetHealth) bool {
+	if ts.State != TrafficShiftProgressing {
+		return false
+	}
+	if health.Failed() {
+		ts.State = TrafficShiftRolledBack
+		ts.HealthySince = time.Time{}
+		ts.Message = strconv.Itoa(health.DownMembers) + " of " + strconv.Itoa(health.Members) +
+			" members of cluster " + ts.TargetCluster + " down at step " + strconv.Itoa(ts.Step)
+		return true
+	}
+	if !health.Healthy() {
+		// no members yet or the members aren't up yet, wait for them
+		ts.HealthySince = time.Time{}
+		return false
+	}
+	if ts.HealthySince.IsZero() {
+		ts.HealthySince = t
+	}
+	if t.Sub(ts.HealthySince) < ts.SoakTime {
+		return false
+	}
+	if ts.Step == len(ts.Steps)-1 {
+		ts.State = TrafficShiftCompleted
+		ts.Message = ""
+		return true
+	}
+	ts.Step++
+	ts.HealthySince = time.Time{}
+	return true
+}
+
+// GetTrafficShift returns a copy of the traffic shift of the accepted GDP object, nil if there's
+// none.
+func (gf *GlobalFilter) GetTrafficShift() *TrafficShift {
+	gf.GlobalLock.RLock()
+	defer gf.GlobalLock.RUnlock()
+	if gf.TrafficShift == nil {
+		return nil
+	}
+	ts := *gf.TrafficShift
+	return &ts
+}
+
+// ResumeTrafficShift continues the traffic shift of the accepted GDP object from status.
+func (gf *GlobalFilter) ResumeTrafficShift(status *gdpv1alpha2.TrafficShiftStatus) {
+	gf.GlobalLock.Lock()
+	defer gf.GlobalLock.Unlock()
+	if gf.TrafficShift == nil {
+		return
+	}
+	gf.TrafficShift.Resume(status)
+}
+
+// IsClusterShiftedOut returns true if the members of cluster cname have to be disabled because
+// the traffic shift routes no traffic to it.
+func (gf *GlobalFilter) IsClusterShiftedOut(cname string) bool {
+	gf.GlobalLock.RLock()
+	defer gf.GlobalLock.RUnlock()
+	if gf.TrafficShift == nil {
+		return false
+	}
+	return PresentInList(cname, gf.TrafficShift.shiftedOutClusters())
+}
+
+// GetShiftedOutClusters returns the list of clusters which get no traffic due to the traffic shift.
+func (gf *GlobalFilter) GetShiftedOutClusters() []string {
+	gf.GlobalLock.RLock()
+	defer gf.GlobalLock.RUnlock()
+	if gf.TrafficShift == nil {
+		return []string{}
+	}
+	return gf.TrafficShift.shiftedOutClusters()
+}
+
+// AdvanceTrafficShift evaluates the traffic shift with the health of the target cluster at time t.
+// Returns whether the state of the shift changed, the clusters whose weights changed and the new
+// status of the shift.
+func (gf *GlobalFilter) AdvanceTrafficShift(t time.Time, health TargetHealth) (bool, []string,
+	*gdpv1alpha2.TrafficShiftStatus) {
+	gf.GlobalLock.Lock()
+	defer gf.GlobalLock.Unlock()
+	if gf.TrafficShift == nil {
+		return false, nil, nil
+	}
+	oldPercent := gf.TrafficShift.TargetPercent()
+	if !gf.TrafficShift.Advance(t, health) {
+		return false, nil, gf.TrafficShift.GetStatus()
+	}
+	Logf("source: %s, target: %s, step: %d, state: %s, msg: traffic shift progressed", gf.TrafficShift.SourceCluster,
+		gf.TrafficShift.TargetCluster, gf.TrafficShift.Step, gf.TrafficShift.State)
+	changedClusters := []string{}
+	if gf.TrafficShift.TargetPercent() != oldPercent {
+		changedClusters = []string{gf.TrafficShift.SourceCluster, gf.TrafficShift.TargetCluster}
+	}
+	return true, changedClusters, gf.TrafficShift.GetStatus()
+}
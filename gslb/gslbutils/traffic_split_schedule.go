@@ -0,0 +1,146 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import (
+	"strconv"
+	"time"
+
+	gdpv1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// TrafficSplitWindowInterval is the interval in seconds at which the scheduled traffic split windows
+// are evaluated.
+const TrafficSplitWindowInterval = 30
+
+// TrafficSplitWindow is a traffic split which is applicable only between Start and End.
+type TrafficSplitWindow struct {
+	Name         string
+	Start        time.Time
+	End          time.Time
+	TrafficSplit []ClusterTraffic
+}
+
+// IsActive returns true if the window is active at time t. Start is inclusive and End is exclusive.
+func (w TrafficSplitWindow) IsActive(t time.Time) bool {
+	return !t.Before(w.Start) && t.Before(w.End)
+}
+
+func (w TrafficSplitWindow) getChecksum() uint32 {
+	cksum := utils.Hash(w.Name + strconv.FormatInt(w.Start.Unix(), 10) + strconv.FormatInt(w.End.Unix(), 10))
+	for _, ts := range w.TrafficSplit {
+		cksum += utils.Hash(w.Name + ts.ClusterName + strconv.Itoa(int(ts.Weight)))
+	}
+	return cksum
+}
+
+// BuildTrafficSplitWindows builds the list of traffic split windows from a GDP object.
+func BuildTrafficSplitWindows(gdp *gdpv1alpha2.GlobalDeploymentPolicy) []TrafficSplitWindow {
+	windows := []TrafficSplitWindow{}
+	for _, sts := range gdp.Spec.ScheduledTrafficSplit {
+		window := TrafficSplitWindow{
+			Name:         sts.Name,
+			Start:        sts.Start.Time,
+			End:          sts.End.Time,
+			TrafficSplit: []ClusterTraffic{},
+		}
+		for _, ts := range sts.TrafficSplit {
+			window.TrafficSplit = append(window.TrafficSplit, ClusterTraffic{
+				ClusterName: ts.Cluster,
+				Weight:      int32(ts.Weight),
+			})
+		}
+		windows = append(windows, window)
+	}
+	return windows
+}
+
+// GetActiveTrafficSplitWindow returns the name of the window active at time t, an empty string if
+// no window is active.
+func GetActiveTrafficSplitWindow(windows []TrafficSplitWindow, t time.Time) string {
+	for _, w := range windows {
+		if w.IsActive(t) {
+			return w.Name
+		}
+	}
+	return ""
+}
+
+// effectiveWeights returns the weights of the clusters as per the default traffic split, overridden
+// by the active window's traffic split and then by the traffic shift. The caller must hold the
+// GlobalLock.
+func (gf *GlobalFilter) effectiveWeights() map[string]int32 {
+	weights := buildClusterWeights(gf.TrafficSplit)
+	for _, w := range gf.TrafficSplitWindows {
+		if w.Name != gf.ActiveTrafficSplitWindow {
+			continue
+		}
+		for _, ts := range w.TrafficSplit {
+			weights[ts.ClusterName] = ts.Weight
+		}
+	}
+	if gf.TrafficShift != nil {
+		for cname, weight := range gf.TrafficShift.weights() {
+			weights[cname] = weight
+		}
+	}
+	return weights
+}
+
+// weightChangedClusters returns the list of clusters for which the weight is different between
+// the old and the new weights. A cluster is part of this list if:
+// 1. it is present in only one of the old and new weights.
+// 2. it is present in both, but has different weights.
+func weightChangedClusters(newWeights, oldWeights map[string]int32) []string {
+	changedClusters := []string{}
+	for cname, weight := range newWeights {
+		if oldWeight, ok := oldWeights[cname]; !ok || oldWeight != weight {
+			changedClusters = append(changedClusters, cname)
+		}
+	}
+	for cname := range oldWeights {
+		if _, ok := newWeights[cname]; !ok {
+			changedClusters = append(changedClusters, cname)
+		}
+	}
+	return changedClusters
+}
+
+// GetActiveTrafficSplitWindow returns the name of the currently active traffic split window.
+func (gf *GlobalFilter) GetActiveTrafficSplitWindow() string {
+	gf.GlobalLock.RLock()
+	defer gf.GlobalLock.RUnlock()
+	return gf.ActiveTrafficSplitWindow
+}
+
+// UpdateActiveTrafficSplitWindow evaluates the traffic split windows at time t and switches to
+// the window active at t. Returns whether the active window changed, the clusters whose weights
+// changed due to this switch and the name of the new active window.
+func (gf *GlobalFilter) UpdateActiveTrafficSplitWindow(t time.Time) (bool, []string, string) {
+	gf.GlobalLock.Lock()
+	defer gf.GlobalLock.Unlock()
+
+	activeWindow := GetActiveTrafficSplitWindow(gf.TrafficSplitWindows, t)
+	if activeWindow == gf.ActiveTrafficSplitWindow {
+		return false, nil, activeWindow
+	}
+	Logf("oldWindow: %s, newWindow: %s, msg: active traffic split window changed", gf.ActiveTrafficSplitWindow,
+		activeWindow)
+	oldWeights := gf.effectiveWeights()
+	gf.ActiveTrafficSplitWindow = activeWindow
+	return true, weightChangedClusters(gf.effectiveWeights(), oldWeights), activeWindow
+}
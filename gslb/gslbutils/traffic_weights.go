@@ -0,0 +1,242 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package gslbutils
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+const (
+	// TrafficWeightAnnotation can be set on a route, service, ingress or a namespace to override
+	// the traffic weight of the members derived from these objects.
+	TrafficWeightAnnotation = "amko.vmware.com/traffic-weight"
+
+	MinTrafficWeight = 1
+	MaxTrafficWeight = 20
+)
+
+// TrafficWeightSource tells where the traffic weight of a GS member was picked up from. The
+// sources are ordered by priority, a lower value overrides all the higher values.
+type TrafficWeightSource int
+
+const (
+	WeightFromObject TrafficWeightSource = iota
+	WeightFromHostRule
+	WeightFromNamespace
+	WeightFromGDP
+	WeightFromDefault
+)
+
+func (src TrafficWeightSource) String() string {
+	switch src {
+	case WeightFromObject:
+		return "object"
+	case WeightFromHostRule:
+		return "gslbhostrule"
+	case WeightFromNamespace:
+		return "namespace"
+	case WeightFromGDP:
+		return "gdp"
+	}
+	return "default"
+}
+
+// ValidTrafficWeight returns an error if the weight is outside the range allowed by Avi.
+func ValidTrafficWeight(weight int32) error {
+	if weight < MinTrafficWeight || weight > MaxTrafficWeight {
+		return errors.New("traffic weight " + strconv.Itoa(int(weight)) + " must be between " +
+			strconv.Itoa(MinTrafficWeight) + " and " + strconv.Itoa(MaxTrafficWeight))
+	}
+	return nil
+}
+
+// ParseTrafficWeight parses the traffic weight annotation from a set of annotations. A weight of
+// 0 is returned if the annotation is absent.
+func ParseTrafficWeight(annotations map[string]string) (int32, error) {
+	val, ok := annotations[TrafficWeightAnnotation]
+	if !ok {
+		return 0, nil
+	}
+	weight, err := strconv.Atoi(strings.TrimSpace(val))
+	if err != nil {
+		return 0, errors.New("invalid value " + val + " for annotation " + TrafficWeightAnnotation)
+	}
+	if err := ValidTrafficWeight(int32(weight)); err != nil {
+		return 0, err
+	}
+	return int32(weight), nil
+}
+
+// TrafficWeightOverrides holds the traffic weights which override the cluster weights of the
+// accepted GDP object.
+type TrafficWeightOverrides struct {
+	// hostRuleWeights is a map of fqdn to the cluster weights from a GSLBHostRule
+	hostRuleWeights map[string]map[string]int32
+	// nsGDPWeights is a map of namespace to the cluster weights from a namespace scoped GDP
+	nsGDPWeights map[string]map[string]int32
+	// nsAnnotationWeights is a map of cluster to the namespace weights set via annotations
+	nsAnnotationWeights map[string]map[string]int32
+	lock                sync.RWMutex
+}
+
+var (
+	weightOverrides     *TrafficWeightOverrides
+	weightOverridesOnce sync.Once
+)
+
+func GetTrafficWeightOverrides() *TrafficWeightOverrides {
+	weightOverridesOnce.Do(func() {
+		weightOverrides = &TrafficWeightOverrides{
+			hostRuleWeights:     make(map[string]map[string]int32),
+			nsGDPWeights:        make(map[string]map[string]int32),
+			nsAnnotationWeights: make(map[string]map[string]int32),
+		}
+	})
+	return weightOverrides
+}
+
+func buildClusterWeights(ts []ClusterTraffic) map[string]int32 {
+	weights := make(map[string]int32)
+	for _, ct := range ts {
+		weights[ct.ClusterName] = ct.Weight
+	}
+	return weights
+}
+
+func clusterWeightsEqual(a, b map[string]int32) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for cname, weight := range a {
+		if w, ok := b[cname]; !ok || w != weight {
+			return false
+		}
+	}
+	return true
+}
+
+// setClusterWeights sets the cluster weights for key in the weights map, an empty list of cluster
+// weights removes the key. Returns true if there was a change.
+func setClusterWeights(weights map[string]map[string]int32, key string, ts []ClusterTraffic) bool {
+	existing, exists := weights[key]
+	if len(ts) == 0 {
+		delete(weights, key)
+		return exists
+	}
+	newWeights := buildClusterWeights(ts)
+	if exists && clusterWeightsEqual(existing, newWeights) {
+		return false
+	}
+	weights[key] = newWeights
+	return true
+}
+
+// SetHostRuleWeights sets the cluster weights for fqdn from a GSLBHostRule and returns
+// true if the weights changed.
+func (wo *TrafficWeightOverrides) SetHostRuleWeights(fqdn string, ts []ClusterTraffic) bool {
+	wo.lock.Lock()
+	defer wo.lock.Unlock()
+	return setClusterWeights(wo.hostRuleWeights, fqdn, ts)
+}
+
+func (wo *TrafficWeightOverrides) DeleteHostRuleWeights(fqdn string) bool {
+	return wo.SetHostRuleWeights(fqdn, nil)
+}
+
+// SetNSGDPWeights sets the cluster weights for a namespace from a namespace scoped GDP object
+// and returns true if the weights changed.
+func (wo *TrafficWeightOverrides) SetNSGDPWeights(ns string, ts []ClusterTraffic) bool {
+	wo.lock.Lock()
+	defer wo.lock.Unlock()
+	return setClusterWeights(wo.nsGDPWeights, ns, ts)
+}
+
+func (wo *TrafficWeightOverrides) DeleteNSGDPWeights(ns string) bool {
+	return wo.SetNSGDPWeights(ns, nil)
+}
+
+// SetNSAnnotationWeight sets the weight for namespace ns in cluster cname, a weight of 0 removes
+// the override. Returns true if the weight changed.
+func (wo *TrafficWeightOverrides) SetNSAnnotationWeight(cname, ns string, weight int32) bool {
+	wo.lock.Lock()
+	defer wo.lock.Unlock()
+
+	nsWeights, ok := wo.nsAnnotationWeights[cname]
+	if !ok {
+		if weight == 0 {
+			return false
+		}
+		nsWeights = make(map[string]int32)
+		wo.nsAnnotationWeights[cname] = nsWeights
+	}
+	existing, exists := nsWeights[ns]
+	if weight == 0 {
+		delete(nsWeights, ns)
+		if len(nsWeights) == 0 {
+			delete(wo.nsAnnotationWeights, cname)
+		}
+		return exists
+	}
+	if exists && existing == weight {
+		return false
+	}
+	nsWeights[ns] = weight
+	return true
+}
+
+func (wo *TrafficWeightOverrides) DeleteNSAnnotationWeight(cname, ns string) bool {
+	return wo.SetNSAnnotationWeight(cname, ns, 0)
+}
+
+func (wo *TrafficWeightOverrides) GetHostRuleWeight(fqdn, cname string) (int32, bool) {
+	wo.lock.RLock()
+	defer wo.lock.RUnlock()
+	weight, ok := wo.hostRuleWeights[fqdn][cname]
+	return weight, ok
+}
+
+// GetNamespaceWeight returns the namespace level weight for a cluster. A namespace annotation
+// takes precedence over a namespace scoped GDP object.
+func (wo *TrafficWeightOverrides) GetNamespaceWeight(ns, cname string) (int32, bool) {
+	wo.lock.RLock()
+	defer wo.lock.RUnlock()
+	if weight, ok := wo.nsAnnotationWeights[cname][ns]; ok {
+		return weight, true
+	}
+	weight, ok := wo.nsGDPWeights[ns][cname]
+	return weight, ok
+}
+
+// ResolveTrafficWeight determines the weight of a GS member in the order: object annotation,
+// GSLBHostRule for the fqdn, namespace and then the accepted GDP object. objWeight is 0 if the
+// object doesn't carry a weight. If nothing is defined, a default weight of 1 is returned.
+func ResolveTrafficWeight(objWeight int32, fqdn, ns, cname string) (int32, TrafficWeightSource) {
+	if objWeight != 0 {
+		return objWeight, WeightFromObject
+	}
+	if weight, ok := GetTrafficWeightOverrides().GetHostRuleWeight(fqdn, cname); ok {
+		return weight, WeightFromHostRule
+	}
+	if weight, ok := GetTrafficWeightOverrides().GetNamespaceWeight(ns, cname); ok {
+		return weight, WeightFromNamespace
+	}
+	if weight, err := GetGlobalFilter().GetGDPTrafficWeight(cname); err == nil {
+		return weight, WeightFromGDP
+	}
+	return 1, WeightFromDefault
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"reflect"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/clientcmd"
+)
+
+// Keys of a member cluster credentials Secret. A Secret has either a kubeconfig, or the API server
+// address along with a token or a client certificate and key. The CA certificate is optional.
+const (
+	KubeConfigKey = "kubeconfig"
+	ServerKey     = "server"
+	TokenKey      = "token"
+	ClientCertKey = "tls.crt"
+	ClientKeyKey  = "tls.key"
+	CACertKey     = "ca.crt"
+)
+
+// BuildContextConfig builds the kubernetes/openshift config for a context of a kubeconfig. The
+// current context of the kubeconfig is used if it doesn't have the context.
+func BuildContextConfig(kubeconfig []byte, context string) (*restclient.Config, error) {
+	cfg, err := clientcmd.Load(kubeconfig)
+	if err != nil {
+		return nil, err
+	}
+	if _, ok := cfg.Contexts[context]; !ok {
+		context = ""
+	}
+	return clientcmd.NewNonInteractiveClientConfig(*cfg, context, &clientcmd.ConfigOverrides{}, nil).ClientConfig()
+}
+
+// BuildSecretConfig builds the kubernetes/openshift config of a member cluster from the data of its
+// credentials Secret.
+func BuildSecretConfig(cname string, data map[string][]byte) (*restclient.Config, error) {
+	if kubeconfig, ok := data[KubeConfigKey]; ok {
+		return BuildContextConfig(kubeconfig, cname)
+	}
+	server := string(data[ServerKey])
+	if server == "" {
+		return nil, errors.New("credentials secret must have either the " + KubeConfigKey + " or the " +
+			ServerKey + " key")
+	}
+	cfg := &restclient.Config{
+		Host:            server,
+		TLSClientConfig: restclient.TLSClientConfig{CAData: data[CACertKey]},
+	}
+	if token, ok := data[TokenKey]; ok {
+		cfg.BearerToken = string(token)
+		return cfg, nil
+	}
+	cert, certOk := data[ClientCertKey]
+	key, keyOk := data[ClientKeyKey]
+	if !certOk || !keyOk {
+		return nil, errors.New("credentials secret must have either the " + TokenKey + " key or the " +
+			ClientCertKey + " and " + ClientKeyKey + " keys")
+	}
+	cfg.TLSClientConfig.CertData = cert
+	cfg.TLSClientConfig.KeyData = key
+	return cfg, nil
+}
+
+// clusterConfig builds the config of the cluster from its credentials Secret, or from the
+// kubeconfig in GSLB_CONFIG if the cluster doesn't have one. The credentials are only kept in
+// memory.
+func (m *memberClusterHealth) clusterConfig() (*restclient.Config, error) {
+	if m.secret == "" {
+		if membersKubeConfig == "" {
+			return nil, errors.New("cluster doesn't have a credentials secret and GSLB_CONFIG isn't set")
+		}
+		return BuildContextConfig([]byte(membersKubeConfig), m.name)
+	}
+	if gslbutils.GlobalKubeClient == nil {
+		return nil, errors.New("can't fetch the credentials secret " + m.secret)
+	}
+	secret, err := gslbutils.GlobalKubeClient.CoreV1().Secrets(m.secretNamespace).Get(m.secret, metav1.GetOptions{})
+	if err != nil {
+		return nil, errors.New("can't fetch the credentials secret " + m.secret + ", " + err.Error())
+	}
+	m.credentials = secret.Data
+	cfg, err := buildClusterConfig(m.secretFormat, m.name, secret.Data)
+	if err != nil {
+		return nil, errors.New("invalid credentials secret " + m.secret + ", " + err.Error())
+	}
+	return cfg, nil
+}
+
+// UpdateMemberClusterCredentials rebuilds the clients and the informers of the member clusters
+// which use secret for their credentials, if the credentials have changed. The objects of a
+// cluster are synced again once its informers are started with the new credentials.
+func UpdateMemberClusterCredentials(secret *corev1.Secret) {
+	memberClustersHealth.lock.Lock()
+	var rebuilt []*memberClusterHealth
+	for _, m := range memberClustersHealth.clusters {
+		if m.secret != secret.Name || m.secretNamespace != secret.Namespace || reflect.DeepEqual(m.credentials, secret.Data) {
+			continue
+		}
+		gslbutils.Logf("cluster: %s, secret: %s, msg: credentials changed, rebuilding the clients", m.name, m.secret)
+		m.stopInformers()
+		m.resetClients()
+		m.failures = 0
+		rebuilt = append(rebuilt, m)
+	}
+	memberClustersHealth.lock.Unlock()
+
+	if len(rebuilt) == 0 || !evaluateMemberClusters(rebuilt) {
+		return
+	}
+	setClustersReadyCondition()
+	gslbutils.PublishGSLBConfigStatus()
+}
+
+// startClusterSecretsInformer watches the Secrets in avi-system, so that the credentials of the
+// GSLB leader controller and the member clusters are updated when they are rotated.
+func startClusterSecretsInformer(kubeClient kubernetes.Interface, stopCh <-chan struct{}) {
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		informers.WithNamespace(gslbutils.AVISystem))
+	secretInformer := informerFactory.Core().V1().Secrets().Informer()
+	secretInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			UpdateControllerCredentials(obj.(*corev1.Secret))
+			UpdateMemberClusterCredentials(obj.(*corev1.Secret))
+		},
+		UpdateFunc: func(old, obj interface{}) {
+			UpdateControllerCredentials(obj.(*corev1.Secret))
+			UpdateMemberClusterCredentials(obj.(*corev1.Secret))
+		},
+		DeleteFunc: func(obj interface{}) {
+			secret, ok := obj.(*corev1.Secret)
+			if !ok {
+				return
+			}
+			if secret.Name == getControllerSecret() {
+				gslbutils.Warnf("secret: %s, msg: controller credentials secret deleted, the existing avi clients "+
+					"are kept till it's created again", secret.Name)
+			}
+			memberClustersHealth.lock.Lock()
+			defer memberClustersHealth.lock.Unlock()
+			for _, m := range memberClustersHealth.clusters {
+				if m.secret == secret.Name && m.secretNamespace == secret.Namespace {
+					gslbutils.Warnf("cluster: %s, secret: %s, msg: credentials secret deleted, the existing clients "+
+						"are kept till it's created again", m.name, m.secret)
+				}
+			}
+		},
+	})
+	go secretInformer.Run(stopCh)
+}
@@ -0,0 +1,298 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/labels"
+	"k8s.io/apimachinery/pkg/selection"
+	"k8s.io/client-go/informers"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+	"k8s.io/client-go/tools/cache"
+)
+
+// Formats of the secrets from which the member clusters are discovered
+const (
+	ClusterAPIFormat = "ClusterAPI"
+	ArgoCDFormat     = "ArgoCD"
+)
+
+const (
+	// The kubeconfig secret of a Cluster API cluster is named <cluster>-kubeconfig, has the cluster
+	// name label, and has the kubeconfig in the value key.
+	clusterAPIClusterLabel     = "cluster.x-k8s.io/cluster-name"
+	clusterAPIKubeConfigSuffix = "-kubeconfig"
+	clusterAPIKubeConfigKey    = "value"
+
+	// An Argo CD cluster secret has the secret type label, and has the name, the server and the
+	// connection config of the cluster.
+	argoCDSecretTypeLabel   = "argocd.argoproj.io/secret-type"
+	argoCDClusterSecretType = "cluster"
+	argoCDNameKey           = "name"
+	argoCDServerKey         = "server"
+	argoCDConfigKey         = "config"
+)
+
+func IsClusterDiscoveryFormatValid(format string) bool {
+	return format == ClusterAPIFormat || format == ArgoCDFormat
+}
+
+// argoCDClusterConfig is the connection config of an Argo CD cluster secret.
+type argoCDClusterConfig struct {
+	BearerToken     string `json:"bearerToken,omitempty"`
+	Username        string `json:"username,omitempty"`
+	Password        string `json:"password,omitempty"`
+	TLSClientConfig struct {
+		Insecure   bool   `json:"insecure,omitempty"`
+		ServerName string `json:"serverName,omitempty"`
+		CAData     []byte `json:"caData,omitempty"`
+		CertData   []byte `json:"certData,omitempty"`
+		KeyData    []byte `json:"keyData,omitempty"`
+	} `json:"tlsClientConfig,omitempty"`
+}
+
+func buildArgoCDConfig(data map[string][]byte) (*restclient.Config, error) {
+	server := string(data[argoCDServerKey])
+	if server == "" {
+		return nil, errors.New("argo cd cluster secret doesn't have the " + argoCDServerKey + " key")
+	}
+	var argoCfg argoCDClusterConfig
+	if err := json.Unmarshal(data[argoCDConfigKey], &argoCfg); err != nil {
+		return nil, errors.New("malformed argo cd cluster config, " + err.Error())
+	}
+	tlsCfg := argoCfg.TLSClientConfig
+	if argoCfg.BearerToken == "" && argoCfg.Username == "" && (len(tlsCfg.CertData) == 0 || len(tlsCfg.KeyData) == 0) {
+		return nil, errors.New("argo cd cluster config must have a bearer token, a username or a client certificate")
+	}
+	return &restclient.Config{
+		Host:        server,
+		BearerToken: argoCfg.BearerToken,
+		Username:    argoCfg.Username,
+		Password:    argoCfg.Password,
+		TLSClientConfig: restclient.TLSClientConfig{
+			Insecure:   tlsCfg.Insecure,
+			ServerName: tlsCfg.ServerName,
+			CAData:     tlsCfg.CAData,
+			CertData:   tlsCfg.CertData,
+			KeyData:    tlsCfg.KeyData,
+		},
+	}, nil
+}
+
+// buildClusterConfig builds the config of a member cluster from the data of its credentials secret
+// of format, the secrets of the member clusters in the GSLBConfig spec don't have a format.
+func buildClusterConfig(format, cname string, data map[string][]byte) (*restclient.Config, error) {
+	switch format {
+	case ClusterAPIFormat:
+		kubeconfig, ok := data[clusterAPIKubeConfigKey]
+		if !ok {
+			return nil, errors.New("cluster api secret doesn't have the " + clusterAPIKubeConfigKey + " key")
+		}
+		return BuildContextConfig(kubeconfig, cname)
+	case ArgoCDFormat:
+		return buildArgoCDConfig(data)
+	}
+	return BuildSecretConfig(cname, data)
+}
+
+// clusterDiscoverySelector returns the selector for the secrets of a cluster discovery, it has the
+// labels of the cluster discovery along with the label of its format.
+func clusterDiscoverySelector(discovery *gslbalphav2.ClusterDiscovery) (labels.Selector, error) {
+	var reqs []labels.Requirement
+	for key, value := range discovery.Label {
+		req, err := labels.NewRequirement(key, selection.Equals, []string{value})
+		if err != nil {
+			return nil, err
+		}
+		reqs = append(reqs, *req)
+	}
+	var formatReq *labels.Requirement
+	var err error
+	switch discovery.Format {
+	case ClusterAPIFormat:
+		formatReq, err = labels.NewRequirement(clusterAPIClusterLabel, selection.Exists, nil)
+	case ArgoCDFormat:
+		formatReq, err = labels.NewRequirement(argoCDSecretTypeLabel, selection.Equals, []string{argoCDClusterSecretType})
+	default:
+		return nil, errors.New("cluster discovery format " + discovery.Format + " unrecognized")
+	}
+	if err != nil {
+		return nil, err
+	}
+	return labels.NewSelector().Add(append(reqs, *formatReq)...), nil
+}
+
+// discoveredClusterAccess returns the access details of the member cluster in a discovery secret
+// of format, false if the secret isn't for a cluster.
+func discoveredClusterAccess(format string, secret *corev1.Secret) (kubeClusterDetails, bool) {
+	var cname string
+	switch format {
+	case ClusterAPIFormat:
+		// the other secrets of a Cluster API cluster have the same label
+		if !strings.HasSuffix(secret.Name, clusterAPIKubeConfigSuffix) {
+			return kubeClusterDetails{}, false
+		}
+		cname = secret.Labels[clusterAPIClusterLabel]
+		if cname == "" {
+			cname = strings.TrimSuffix(secret.Name, clusterAPIKubeConfigSuffix)
+		}
+	case ArgoCDFormat:
+		cname = string(secret.Data[argoCDNameKey])
+		if cname == "" {
+			cname = string(secret.Data[argoCDServerKey])
+		}
+	}
+	if cname == "" {
+		return kubeClusterDetails{}, false
+	}
+	return kubeClusterDetails{
+		clusterName:     cname,
+		secret:          secret.Name,
+		secretNamespace: secret.Namespace,
+		secretFormat:    format,
+		discovered:      true,
+	}, true
+}
+
+// discoverClusters returns the member clusters in the secrets selected by the cluster discovery.
+func discoverClusters(discovery *gslbalphav2.ClusterDiscovery) []kubeClusterDetails {
+	var clusterDetails []kubeClusterDetails
+	if discovery == nil || gslbutils.GlobalKubeClient == nil {
+		return clusterDetails
+	}
+	selector, err := clusterDiscoverySelector(discovery)
+	if err != nil {
+		gslbutils.Errf("format: %s, msg: invalid cluster discovery, %s", discovery.Format, err.Error())
+		return clusterDetails
+	}
+	secretList, err := gslbutils.GlobalKubeClient.CoreV1().Secrets(discovery.Namespace).List(
+		metav1.ListOptions{LabelSelector: selector.String()})
+	if err != nil {
+		gslbutils.Errf("ns: %s, selector: %s, msg: error in listing the cluster discovery secrets, %s",
+			discovery.Namespace, selector.String(), err.Error())
+		return clusterDetails
+	}
+	for i := range secretList.Items {
+		if cluster, ok := discoveredClusterAccess(discovery.Format, &secretList.Items[i]); ok {
+			gslbutils.Logf("cluster: %s, secret: %s/%s, msg: discovered member cluster", cluster.clusterName,
+				cluster.secretNamespace, cluster.secret)
+			clusterDetails = append(clusterDetails, cluster)
+		}
+	}
+	return clusterDetails
+}
+
+// AddDiscoveredCluster adds the member cluster in a discovery secret of format, which was created
+// after AMKO booted up, and starts its informers if it's reachable.
+func AddDiscoveredCluster(format string, secret *corev1.Secret) {
+	cluster, ok := discoveredClusterAccess(format, secret)
+	if !ok {
+		return
+	}
+	memberClustersHealth.lock.Lock()
+	if m := findMemberCluster(cluster.clusterName); m != nil && m.secret == secret.Name &&
+		m.secretNamespace == secret.Namespace {
+		memberClustersHealth.lock.Unlock()
+		return
+	}
+	m := initializeGSLBCluster(cluster)
+	memberClustersHealth.lock.Unlock()
+
+	if m == nil {
+		return
+	}
+	evaluateMemberClusters([]*memberClusterHealth{m})
+	memberClustersHealth.lock.Lock()
+	m.startInformers()
+	memberClustersHealth.lock.Unlock()
+	setClustersReadyCondition()
+	gslbutils.PublishGSLBConfigStatus()
+}
+
+// DeleteDiscoveredCluster removes the member cluster which was discovered from a deleted secret,
+// along with its GS members.
+func DeleteDiscoveredCluster(secret *corev1.Secret) {
+	memberClustersHealth.lock.Lock()
+	removed := false
+	for _, m := range memberClustersHealth.clusters {
+		if m.discovered && m.secret == secret.Name && m.secretNamespace == secret.Namespace {
+			removeMemberCluster(m)
+			removed = true
+			break
+		}
+	}
+	memberClustersHealth.lock.Unlock()
+
+	if !removed {
+		return
+	}
+	setClustersReadyCondition()
+	gslbutils.PublishGSLBConfigStatus()
+}
+
+// startClusterDiscoveryInformer watches the secrets selected by the cluster discovery, the member
+// clusters are added and removed along with their secrets.
+func startClusterDiscoveryInformer(kubeClient kubernetes.Interface, discovery *gslbalphav2.ClusterDiscovery,
+	stopCh <-chan struct{}) {
+	if discovery == nil || kubeClient == nil {
+		return
+	}
+	selector, err := clusterDiscoverySelector(discovery)
+	if err != nil {
+		gslbutils.Errf("format: %s, msg: invalid cluster discovery, %s", discovery.Format, err.Error())
+		return
+	}
+	format := discovery.Format
+	informerFactory := informers.NewSharedInformerFactoryWithOptions(kubeClient, 0,
+		informers.WithNamespace(discovery.Namespace),
+		informers.WithTweakListOptions(func(options *metav1.ListOptions) {
+			options.LabelSelector = selector.String()
+		}))
+	secretInformer := informerFactory.Core().V1().Secrets().Informer()
+	secretInformer.AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			AddDiscoveredCluster(format, obj.(*corev1.Secret))
+		},
+		UpdateFunc: func(old, obj interface{}) {
+			secret := obj.(*corev1.Secret)
+			AddDiscoveredCluster(format, secret)
+			UpdateMemberClusterCredentials(secret)
+		},
+		DeleteFunc: func(obj interface{}) {
+			secret, ok := obj.(*corev1.Secret)
+			if !ok {
+				tombstone, ok := obj.(cache.DeletedFinalStateUnknown)
+				if !ok {
+					return
+				}
+				if secret, ok = tombstone.Obj.(*corev1.Secret); !ok {
+					return
+				}
+			}
+			DeleteDiscoveredCluster(secret)
+		},
+	})
+	go secretInformer.Run(stopCh)
+}
@@ -0,0 +1,427 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	oshiftclient "github.com/openshift/client-go/route/clientset/versioned"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	restclient "k8s.io/client-go/rest"
+)
+
+// memberClusterHealth tracks the connectivity of a member cluster, along with the clients and the
+// informers of the cluster. The informers of a cluster run only while it's reachable.
+type memberClusterHealth struct {
+	name string
+	// secret is the name of the credentials Secret of the cluster, in secretNamespace, and of
+	// secretFormat
+	secret          string
+	secretNamespace string
+	secretFormat    string
+	// discovered is set for the clusters added by the cluster discovery, they are removed when their
+	// secret is deleted
+	discovered bool
+	// credentials is the data of the credentials Secret from which the clients were built
+	credentials map[string][]byte
+	state       string
+	failures    int
+	// disconnectedAt is the time at which the cluster was disconnected
+	disconnectedAt time.Time
+	// staleMembers is the stale member policy action applied on the members of the cluster, empty
+	// if none
+	staleMembers string
+	kubeClient   kubernetes.Interface
+	oshiftClient oshiftclient.Interface
+	// healthKubeClient and healthOshiftClient are used for the health checks, they time out unlike
+	// the clients of the informers, whose watches are long running. The informer clients are used
+	// if they aren't set.
+	healthKubeClient   kubernetes.Interface
+	healthOshiftClient oshiftclient.Interface
+	ctrl               *GSLBMemberController
+	stopCh             chan struct{}
+	// synced is set once the informer caches of the cluster are synced, and reset when the informers
+	// are stopped
+	synced bool
+}
+
+type memberClusterHealthList struct {
+	lock     sync.Mutex
+	clusters []*memberClusterHealth
+}
+
+var memberClustersHealth memberClusterHealthList
+
+// AddMemberCluster starts tracking the health of a member cluster. If the clients are nil, they
+// are built from the credentials secret on the next health check of the cluster.
+func AddMemberCluster(cname, secret string, kubeClient kubernetes.Interface, oshiftClient oshiftclient.Interface) {
+	memberClustersHealth.lock.Lock()
+	defer memberClustersHealth.lock.Unlock()
+
+	addMemberCluster(cname, secret, kubeClient, oshiftClient)
+}
+
+func addMemberCluster(cname, secret string, kubeClient kubernetes.Interface,
+	oshiftClient oshiftclient.Interface) *memberClusterHealth {
+	if m := findMemberCluster(cname); m != nil {
+		return m
+	}
+	m := &memberClusterHealth{
+		name:            cname,
+		secret:          secret,
+		secretNamespace: gslbutils.AVISystem,
+		kubeClient:      kubeClient,
+		oshiftClient:    oshiftClient,
+	}
+	memberClustersHealth.clusters = append(memberClustersHealth.clusters, m)
+	return m
+}
+
+// findMemberCluster returns the member cluster cname, must be called with the lock held.
+func findMemberCluster(cname string) *memberClusterHealth {
+	for _, m := range memberClustersHealth.clusters {
+		if m.name == cname {
+			return m
+		}
+	}
+	return nil
+}
+
+// GetMemberClusterState returns the connectivity state of a member cluster.
+func GetMemberClusterState(cname string) (string, bool) {
+	memberClustersHealth.lock.Lock()
+	defer memberClustersHealth.lock.Unlock()
+
+	if m := findMemberCluster(cname); m != nil {
+		return m.state, true
+	}
+	return "", false
+}
+
+// removeMemberCluster stops the informers of a member cluster, removes its GS members and removes
+// it from the GSLB configuration. Must be called with the lock held.
+func removeMemberCluster(m *memberClusterHealth) {
+	gslbutils.Logf("cluster: %s, msg: removing the member cluster", m.name)
+	m.stopInformers()
+	deleteClusterObjs(m.name, func(string, string, string) bool { return false })
+	gslbutils.SetClusterMembersDisabled(m.name, false)
+	gslbutils.DeleteMemberClusterStatus(m.name)
+	gslbutils.RemoveClusterContext(m.name)
+	for i, cluster := range memberClustersHealth.clusters {
+		if cluster == m {
+			memberClustersHealth.clusters = append(memberClustersHealth.clusters[:i],
+				memberClustersHealth.clusters[i+1:]...)
+			return
+		}
+	}
+}
+
+func (m *memberClusterHealth) buildClients() error {
+	cfg, err := m.clusterConfig()
+	if err != nil {
+		return errors.New("error in connecting to kubernetes API: " + err.Error())
+	}
+	kubeClient, err := kubernetes.NewForConfig(cfg)
+	if err != nil {
+		return errors.New("error in creating kubernetes clientset: " + err.Error())
+	}
+	oshiftClient, err := oshiftclient.NewForConfig(cfg)
+	if err != nil {
+		return errors.New("error in creating openshift clientset: " + err.Error())
+	}
+	healthCfg := restclient.CopyConfig(cfg)
+	healthCfg.Timeout = gslbutils.ClusterHealthCheckTimeout * time.Second
+	healthKubeClient, err := kubernetes.NewForConfig(healthCfg)
+	if err != nil {
+		return errors.New("error in creating kubernetes clientset: " + err.Error())
+	}
+	healthOshiftClient, err := oshiftclient.NewForConfig(healthCfg)
+	if err != nil {
+		return errors.New("error in creating openshift clientset: " + err.Error())
+	}
+	m.kubeClient = kubeClient
+	m.oshiftClient = oshiftClient
+	m.healthKubeClient = healthKubeClient
+	m.healthOshiftClient = healthOshiftClient
+	return nil
+}
+
+// resetClients drops the clients of the cluster, they are built again on the next health check.
+func (m *memberClusterHealth) resetClients() {
+	m.kubeClient = nil
+	m.oshiftClient = nil
+	m.healthKubeClient = nil
+	m.healthOshiftClient = nil
+}
+
+// checkClusterHealth verifies that the API server of a cluster is reachable and that the services
+// can be listed.
+func checkClusterHealth(kubeClient kubernetes.Interface) error {
+	_, err := kubeClient.CoreV1().Services("").List(metav1.ListOptions{Limit: 1, TimeoutSeconds: &informerTimeout})
+	if err != nil {
+		return errors.New("can't access the services api, " + err.Error())
+	}
+	return nil
+}
+
+// clusterProbe is a health check of a member cluster. It's run on a copy of the cluster without
+// holding the lock, so that an unreachable cluster doesn't block the others, and its result is
+// applied to the cluster with the lock held.
+type clusterProbe struct {
+	m *memberClusterHealth
+	// cluster is the copy of m on which the health check is run, its clients are built if required
+	cluster memberClusterHealth
+	// kubeClient is the client of m when the health check started
+	kubeClient kubernetes.Interface
+	// informers are the informers to set up for the cluster, if it doesn't have them
+	informers []string
+	err       error
+}
+
+// newClusterProbe returns a health check for the cluster, must be called with the lock held.
+func newClusterProbe(m *memberClusterHealth) *clusterProbe {
+	return &clusterProbe{m: m, cluster: *m, kubeClient: m.kubeClient}
+}
+
+// run builds the clients of the cluster if required, health checks it and finds the informers to
+// set up for it, if they aren't set up yet.
+func (p *clusterProbe) run() {
+	c := &p.cluster
+	if c.kubeClient == nil || c.oshiftClient == nil {
+		if p.err = c.buildClients(); p.err != nil {
+			return
+		}
+	}
+	kubeClient, oshiftClient := c.healthKubeClient, c.healthOshiftClient
+	if kubeClient == nil || oshiftClient == nil {
+		kubeClient, oshiftClient = c.kubeClient, c.oshiftClient
+	}
+	if p.err = checkClusterHealth(kubeClient); p.err != nil {
+		return
+	}
+	if c.ctrl != nil {
+		return
+	}
+	p.informers, p.err = InformersToRegister(oshiftClient, kubeClient, c.name)
+	if p.err == nil && len(p.informers) == 0 {
+		p.err = errors.New("no informers available for this cluster")
+	}
+}
+
+// apply sets the clients built by the health check in the cluster, and sets up its informers if the
+// cluster is reachable. Returns false if the cluster was removed, or its clients or informers were
+// changed, while it was health checked, the next health check then takes care of it. Must be called
+// with the lock held.
+func (p *clusterProbe) apply() bool {
+	m := p.m
+	if findMemberCluster(m.name) != m || m.kubeClient != p.kubeClient || m.ctrl != p.cluster.ctrl {
+		return false
+	}
+	c := &p.cluster
+	m.kubeClient, m.oshiftClient = c.kubeClient, c.oshiftClient
+	m.healthKubeClient, m.healthOshiftClient = c.healthKubeClient, c.healthOshiftClient
+	m.credentials = c.credentials
+	if p.err != nil || m.ctrl != nil {
+		return true
+	}
+	gslbutils.Logf("Informers for cluster %s: %v", m.name, p.informers)
+	informersArg := map[string]interface{}{
+		utils.INFORMERS_OPENSHIFT_CLIENT: m.oshiftClient,
+		utils.INFORMERS_INSTANTIATE_ONCE: false,
+	}
+	informerInstance := utils.NewInformers(utils.KubeClientIntf{ClientSet: m.kubeClient}, p.informers,
+		informersArg)
+	aviCtrl := GetGSLBMemberController(m.name, informerInstance)
+	aviCtrl.SetupEventHandlers(K8SInformers{Cs: m.kubeClient})
+	m.ctrl = &aviCtrl
+	return true
+}
+
+// startInformers starts the informers of the cluster, if they aren't running. It doesn't wait for
+// the informer caches to sync, the cluster is marked as synced once they are.
+func (m *memberClusterHealth) startInformers() {
+	if m.ctrl == nil || m.stopCh != nil {
+		return
+	}
+	gslbutils.Logf("cluster: %s, msg: starting the informers", m.name)
+	m.stopCh = make(chan struct{})
+	// the cluster may become unreachable before the caches are synced, in which case, the stop
+	// channel is closed by the next health check, so the health checks aren't blocked on this.
+	// Once synced, the objects which were deleted while the cluster was disconnected are removed.
+	ctrl, stopCh := m.ctrl, m.stopCh
+	go func() {
+		if !ctrl.Start(stopCh) {
+			return
+		}
+		memberClustersHealth.lock.Lock()
+		if m.stopCh == stopCh {
+			m.synced = true
+		}
+		memberClustersHealth.lock.Unlock()
+		deleteRemovedClusterObjs(ctrl)
+	}()
+}
+
+// stopInformers stops the informers of the cluster, they are set up again once the cluster is
+// reachable. The objects of the cluster which were already synced are retained.
+func (m *memberClusterHealth) stopInformers() {
+	if m.stopCh != nil {
+		gslbutils.Logf("cluster: %s, msg: stopping the informers", m.name)
+		close(m.stopCh)
+		m.stopCh = nil
+	}
+	m.synced = false
+	m.ctrl = nil
+	deregisterMemberClients(m.name)
+	deregisterDecisionRecorder(m.name)
+}
+
+// setState updates the connectivity state of the cluster, returns true if it has changed.
+func (m *memberClusterHealth) setState(state, msg string) bool {
+	gslbutils.SetMemberClusterStatus(m.name, state, msg)
+	if m.state == state {
+		return false
+	}
+	gslbutils.Logf("cluster: %s, oldState: %s, newState: %s, msg: cluster connectivity changed, %s", m.name,
+		m.state, state, msg)
+	if state == gslbalphav2.ClusterDisconnected {
+		m.disconnectedAt = time.Now()
+	}
+	m.state = state
+	return true
+}
+
+// evaluate updates the state of the cluster from its health check p. A reachable cluster is
+// connected and its informers are started. An unreachable cluster is degraded till it fails the
+// health checks ClusterDisconnectThreshold times in a row, and then it's disconnected and its
+// informers are stopped. A cluster whose informers were never set up is disconnected on the first
+// failure. Must be called with the lock held.
+func (m *memberClusterHealth) evaluate(p *clusterProbe) bool {
+	if !p.apply() {
+		return false
+	}
+	if err := p.err; err != nil {
+		m.failures++
+		gslbutils.Warnf("cluster: %s, failures: %d, msg: cluster health check failed, %s", m.name, m.failures,
+			err.Error())
+		if m.ctrl != nil && m.failures < gslbutils.ClusterDisconnectThreshold {
+			return m.setState(gslbalphav2.ClusterDegraded, "cluster health check failed: "+err.Error())
+		}
+		m.stopInformers()
+		return m.setState(gslbalphav2.ClusterDisconnected, "cluster health check failed: "+err.Error())
+	}
+	m.failures = 0
+	m.restoreStaleMembers()
+	// the informers of the clusters which are connected during bootup are started after the bootup
+	// sync
+	if m.state != "" {
+		m.startInformers()
+	}
+	return m.setState(gslbalphav2.ClusterConnected, "connected to the kubernetes API")
+}
+
+// evaluateMemberClusters health checks the clusters in parallel, without holding the lock, and
+// updates their states with the lock held. Returns true if the state of any cluster has changed.
+func evaluateMemberClusters(clusters []*memberClusterHealth) bool {
+	memberClustersHealth.lock.Lock()
+	probes := make([]*clusterProbe, 0, len(clusters))
+	for _, m := range clusters {
+		probes = append(probes, newClusterProbe(m))
+	}
+	memberClustersHealth.lock.Unlock()
+
+	var wg sync.WaitGroup
+	for _, p := range probes {
+		wg.Add(1)
+		go func(p *clusterProbe) {
+			defer wg.Done()
+			p.run()
+		}(p)
+	}
+	wg.Wait()
+
+	memberClustersHealth.lock.Lock()
+	defer memberClustersHealth.lock.Unlock()
+	changed := false
+	for _, p := range probes {
+		if p.m.evaluate(p) {
+			changed = true
+		}
+	}
+	return changed
+}
+
+// memberClustersSynced returns an error if any of the member clusters isn't connected, or if the
+// informer caches of a connected cluster aren't synced yet.
+func memberClustersSynced() error {
+	memberClustersHealth.lock.Lock()
+	defer memberClustersHealth.lock.Unlock()
+
+	for _, m := range memberClustersHealth.clusters {
+		if m.state != gslbalphav2.ClusterConnected {
+			return errors.New("cluster " + m.name + " isn't connected")
+		}
+		if !m.synced {
+			return errors.New("caches of cluster " + m.name + " aren't synced")
+		}
+	}
+	return nil
+}
+
+// EvaluateMemberClusterHealth health checks all the member clusters and brings the informers of a
+// cluster up or down as per its connectivity. The GSLBConfig status is published if the state of
+// any cluster has changed.
+func EvaluateMemberClusterHealth() {
+	memberClustersHealth.lock.Lock()
+	clusters := append([]*memberClusterHealth{}, memberClustersHealth.clusters...)
+	memberClustersHealth.lock.Unlock()
+
+	changed := evaluateMemberClusters(clusters)
+	if ApplyStaleMemberPolicy(time.Now()) {
+		changed = true
+	}
+	if !changed {
+		return
+	}
+	setClustersReadyCondition()
+	gslbutils.PublishGSLBConfigStatus()
+}
+
+// startMemberClusterInformers starts the informers of the connected member clusters without waiting
+// for their caches to sync, and stops them once stopCh is closed.
+func startMemberClusterInformers(stopCh <-chan struct{}) {
+	memberClustersHealth.lock.Lock()
+	for _, m := range memberClustersHealth.clusters {
+		m.startInformers()
+	}
+	memberClustersHealth.lock.Unlock()
+
+	go func() {
+		<-stopCh
+		memberClustersHealth.lock.Lock()
+		defer memberClustersHealth.lock.Unlock()
+		for _, m := range memberClustersHealth.clusters {
+			m.stopInformers()
+		}
+	}()
+}
@@ -0,0 +1,105 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Keys of the credentials Secret of the GSLB leader controller. The username is required, along with
+// the password, an API token, or a client certificate and key, which use the ClientCertKey and
+// ClientKeyKey keys.
+const (
+	UsernameKey = "username"
+	PasswordKey = "password"
+)
+
+// controllerSecret is the name of the credentials Secret of the GSLB leader controller in avi-system
+var controllerSecret string
+var controllerSecretLock sync.RWMutex
+
+func setControllerSecret(name string) {
+	controllerSecretLock.Lock()
+	defer controllerSecretLock.Unlock()
+	controllerSecret = name
+}
+
+func getControllerSecret() string {
+	controllerSecretLock.RLock()
+	defer controllerSecretLock.RUnlock()
+	return controllerSecret
+}
+
+// ParseControllerCredentials reads the credentials of the GSLB leader controller from the data of
+// its Secret.
+func ParseControllerCredentials(data map[string][]byte) (gslbutils.AviControllerCredentials, error) {
+	creds := gslbutils.AviControllerCredentials{
+		Username:   string(data[UsernameKey]),
+		Password:   string(data[PasswordKey]),
+		Token:      string(data[TokenKey]),
+		ClientCert: data[ClientCertKey],
+		ClientKey:  data[ClientKeyKey],
+	}
+	if creds.Username == "" {
+		return creds, errors.New("credentials secret must have the " + UsernameKey + " key")
+	}
+	if len(creds.ClientCert) > 0 && len(creds.ClientKey) == 0 {
+		return creds, errors.New("credentials secret must have the " + ClientKeyKey + " key along with the " +
+			ClientCertKey + " key")
+	}
+	if !creds.IsSet() {
+		return creds, errors.New("credentials secret must have one of the " + PasswordKey + ", " + TokenKey +
+			" or " + ClientCertKey + " keys")
+	}
+	return creds, nil
+}
+
+// UpdateControllerCredentials rebuilds the avi clients if secret is the credentials Secret of the
+// GSLB leader controller and its credentials have changed. The existing clients are kept if the new
+// credentials are invalid or the controller rejects them.
+func UpdateControllerCredentials(secret *corev1.Secret) {
+	if secret.Namespace != gslbutils.AVISystem || secret.Name != getControllerSecret() {
+		return
+	}
+	creds, err := ParseControllerCredentials(secret.Data)
+	if err != nil {
+		gslbutils.Errf("secret: %s, msg: invalid controller credentials, keeping the existing clients, %s",
+			secret.Name, err.Error())
+		return
+	}
+	if reflect.DeepEqual(creds, gslbutils.GetAviConfig().AviControllerCredentials) {
+		return
+	}
+	gslbutils.Logf("secret: %s, msg: controller credentials changed, rebuilding the avi clients", secret.Name)
+	if err := avicache.UpdateAviClientCredentials(creds); err != nil {
+		gslbutils.Errf("secret: %s, msg: can't connect to the controller with the new credentials, keeping the "+
+			"existing clients, %s", secret.Name, err.Error())
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionControllerReachable, metav1.ConditionFalse,
+			gslbalphav2.ReasonConnectionFailed, ControllerAPIErr+", new credentials rejected, "+err.Error())
+		return
+	}
+	gslbutils.Logf("secret: %s, msg: avi clients rebuilt with the new controller credentials", secret.Name)
+	gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionControllerReachable, metav1.ConditionTrue,
+		gslbalphav2.ReasonConnected, ControllerReachableMsg+" "+gslbutils.GetAviConfig().IPAddr)
+}
@@ -0,0 +1,87 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// ParseControllerTLS reads the verification of the controller certificate from the TLS config of
+// the GSLB leader, along with the CA bundle from its secret. The certificate isn't verified if
+// tlsSpec is nil.
+func ParseControllerTLS(tlsSpec *gslbalphav2.ControllerTLS) (gslbutils.AviControllerTLS, error) {
+	if tlsSpec == nil {
+		return gslbutils.AviControllerTLS{Verification: gslbutils.TLSVerificationNone}, nil
+	}
+	tlsCfg := gslbutils.AviControllerTLS{
+		Verification: tlsSpec.Verification,
+		ServerName:   tlsSpec.ServerName,
+	}
+	if tlsCfg.Verification == "" {
+		tlsCfg.Verification = gslbutils.TLSVerificationFull
+	}
+	if tlsSpec.CASecret == "" {
+		return tlsCfg, nil
+	}
+	if gslbutils.GlobalKubeClient == nil {
+		return tlsCfg, errors.New("can't fetch the CA secret " + tlsSpec.CASecret)
+	}
+	secret, err := gslbutils.GlobalKubeClient.CoreV1().Secrets(gslbutils.AVISystem).Get(tlsSpec.CASecret, metav1.GetOptions{})
+	if err != nil {
+		return tlsCfg, errors.New("can't fetch the CA secret " + tlsSpec.CASecret + ", " + err.Error())
+	}
+	tlsCfg.CAData = secret.Data[CACertKey]
+	if len(tlsCfg.CAData) == 0 {
+		return tlsCfg, errors.New("CA secret " + tlsSpec.CASecret + " doesn't have the " + CACertKey + " key")
+	}
+	return tlsCfg, nil
+}
+
+// setTLSVerifiedCondition sets the TLSVerified condition of the GSLBConfig object as per the result
+// of the TLS handshake with the controller at ipAddr.
+func setTLSVerifiedCondition(ipAddr string, handshakeErr error) {
+	switch {
+	case gslbutils.GetAviConfig().TLS.Verification == gslbutils.TLSVerificationNone:
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionTLSVerified, metav1.ConditionFalse,
+			gslbalphav2.ReasonTLSVerificationDisabled, "certificate of the controller "+ipAddr+" isn't verified")
+	case handshakeErr != nil:
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionTLSVerified, metav1.ConditionFalse,
+			gslbalphav2.ReasonTLSVerificationFailed, "TLS handshake with the controller "+ipAddr+" failed, "+
+				handshakeErr.Error())
+	default:
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionTLSVerified, metav1.ConditionTrue,
+			gslbalphav2.ReasonTLSVerified, "certificate of the controller "+ipAddr+" verified")
+	}
+}
+
+// checkControllerTLS verifies the certificate of the controller at ipAddr with a TLS handshake, if
+// the verification is enabled, and sets the TLSVerified condition.
+func checkControllerTLS(ipAddr string) error {
+	var err error
+	if gslbutils.GetAviConfig().TLS.Verification != gslbutils.TLSVerificationNone {
+		err = avicache.VerifyControllerTLS(ipAddr)
+	}
+	setTLSVerifiedCondition(ipAddr, err)
+	if err != nil {
+		gslbutils.Errf("controllerIP: %s, msg: TLS handshake with the controller failed, %s", ipAddr, err.Error())
+	}
+	return err
+}
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"strings"
+	"sync"
+
+	filter "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gdp_filter"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+
+	containerutils "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/apimachinery/pkg/api/meta"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/tools/record"
+)
+
+const (
+	// DecisionAcceptedReason and DecisionRejectedReason are the reasons of the events for the GDP
+	// filter decisions of the member cluster objects
+	DecisionAcceptedReason = "GSLBAccepted"
+	DecisionRejectedReason = "GSLBRejected"
+)
+
+// decisionRecorders keeps the event recorders of the member clusters, to write the GDP filter
+// decisions as events on the objects.
+var decisionRecorders = struct {
+	lock      sync.RWMutex
+	recorders map[string]record.EventRecorder
+}{recorders: make(map[string]record.EventRecorder)}
+
+func registerDecisionRecorder(cname string, recorder record.EventRecorder) {
+	decisionRecorders.lock.Lock()
+	defer decisionRecorders.lock.Unlock()
+	decisionRecorders.recorders[cname] = recorder
+}
+
+func deregisterDecisionRecorder(cname string) {
+	decisionRecorders.lock.Lock()
+	defer decisionRecorders.lock.Unlock()
+	delete(decisionRecorders.recorders, cname)
+}
+
+func getDecisionRecorder(cname string) (record.EventRecorder, bool) {
+	decisionRecorders.lock.RLock()
+	defer decisionRecorders.lock.RUnlock()
+	recorder, ok := decisionRecorders.recorders[cname]
+	return recorder, ok
+}
+
+// recordStatusDecision records the decision for an object which is rejected because it has no IP
+// address or hostname in its status, before it's evaluated by the GDP filter.
+func recordStatusDecision(obj k8sobjects.MetaObject) {
+	if obj.GetIPAddr() != "" && obj.GetHostname() != "" {
+		return
+	}
+	filter.RecordDecision(obj)
+}
+
+// getDecisionObjectRef returns the reference to the object of a decision, from the informer cache
+// of its cluster.
+func getDecisionObjectRef(d filter.Decision) (*corev1.ObjectReference, bool) {
+	informers, ok := getMemberClients(d.Cluster)
+	if !ok {
+		return nil, false
+	}
+	ref := &corev1.ObjectReference{Namespace: d.Namespace, Name: d.Name}
+	var informer cache.SharedIndexInformer
+	switch d.ObjType {
+	case gslbutils.IngressType:
+		if informers.IngressInformer == nil {
+			return nil, false
+		}
+		informer = informers.IngressInformer.Informer()
+		// the decisions for ingresses are per host, named as ingress name/hostname
+		ref.Name = strings.Split(d.Name, "/")[0]
+		ref.Kind, ref.APIVersion = "Ingress", "networking.k8s.io/v1beta1"
+		if informers.IngressVersion == containerutils.ExtV1IngressInformer {
+			ref.APIVersion = "extensions/v1beta1"
+		}
+	case gslbutils.SvcType:
+		if informers.ServiceInformer == nil {
+			return nil, false
+		}
+		informer = informers.ServiceInformer.Informer()
+		ref.Kind, ref.APIVersion = "Service", "v1"
+	case gslbutils.RouteType:
+		if informers.RouteInformer == nil {
+			return nil, false
+		}
+		informer = informers.RouteInformer.Informer()
+		ref.Kind, ref.APIVersion = "Route", "route.openshift.io/v1"
+	default:
+		return nil, false
+	}
+
+	obj, exists, err := informer.GetStore().GetByKey(ref.Namespace + "/" + ref.Name)
+	if err != nil || !exists {
+		return nil, false
+	}
+	objMeta, err := meta.Accessor(obj)
+	if err != nil {
+		return nil, false
+	}
+	ref.UID = objMeta.GetUID()
+	ref.ResourceVersion = objMeta.GetResourceVersion()
+	return ref, true
+}
+
+// publishDecisionEvent writes the changed decision for an object as an event on the object.
+func publishDecisionEvent(d filter.Decision) {
+	if !gslbutils.IsAMKOLeader() {
+		return
+	}
+	recorder, ok := getDecisionRecorder(d.Cluster)
+	if !ok {
+		return
+	}
+	ref, ok := getDecisionObjectRef(d)
+	if !ok {
+		gslbutils.Debugf("cluster: %s, objType: %s, ns: %s, name: %s, msg: object not found, won't write the decision event",
+			d.Cluster, d.ObjType, d.Namespace, d.Name)
+		return
+	}
+	msg := d.Reason()
+	if d.ObjType == gslbutils.IngressType {
+		msg = "host " + d.Hostname + " " + msg
+	}
+	if d.Accepted {
+		recorder.Event(ref, corev1.EventTypeNormal, DecisionAcceptedReason, msg)
+		return
+	}
+	recorder.Event(ref, corev1.EventTypeWarning, DecisionRejectedReason, msg)
+}
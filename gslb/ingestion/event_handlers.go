@@ -45,6 +45,7 @@ func AddLBSvcEventHandler(numWorkers uint32, c *GSLBMemberController) cache.Reso
 			}
 			svcMeta, ok := k8sobjects.GetSvcMeta(svc, c.name)
 			if !ok {
+				recordStatusDecision(svcMeta)
 				gslbutils.Logf("cluster: %s, msg: could not get meta object for service: %s, ns: %s",
 					c.name, svc.ObjectMeta.Name, svc.ObjectMeta.Namespace)
 				return
@@ -70,6 +71,7 @@ func AddLBSvcEventHandler(numWorkers uint32, c *GSLBMemberController) cache.Reso
 			}
 			DeleteFromLBSvcStore(acceptedLBSvcStore, svc, c.name)
 			DeleteFromLBSvcStore(rejectedLBSvcStore, svc, c.name)
+			filter.DeleteDecision(gslbutils.SvcType, c.name, svc.ObjectMeta.Namespace, svc.ObjectMeta.Name)
 
 			// For services, where the status field was deleted, won't contain the hostname in that case
 			hostName := ""
@@ -87,6 +89,9 @@ func AddLBSvcEventHandler(numWorkers uint32, c *GSLBMemberController) cache.Reso
 			svc := curr.(*corev1.Service)
 			if oldSvc.ResourceVersion != svc.ResourceVersion {
 				svcMeta, ok := k8sobjects.GetSvcMeta(svc, c.name)
+				if !ok && isSvcTypeLB(svc) {
+					recordStatusDecision(svcMeta)
+				}
 				if !ok || !isSvcTypeLB(svc) || !filter.ApplyFilter(svcMeta, c.name) {
 					// See if the svc was already accepted, if yes, need to delete the key
 					fetchedObj, ok := acceptedLBSvcStore.GetClusterNSObjectByName(c.name,
@@ -124,6 +129,7 @@ func filterAndAddIngressMeta(ingressHostMetaObjs []k8sobjects.IngressHostMeta, c
 	acceptedIngStore, rejectedIngStore *gslbutils.ClusterStore, numWorkers uint32, fullsync bool) {
 	for _, ihm := range ingressHostMetaObjs {
 		if ihm.IPAddr == "" || ihm.Hostname == "" {
+			recordStatusDecision(ihm)
 			gslbutils.Debugf("cluster: %s, ns: %s, ingress: %s, msg: %s\n",
 				c.name, ihm.Namespace, ihm.IngName,
 				"rejected ADD ingress because IP address/Hostname not found in status field")
@@ -148,6 +154,7 @@ func deleteIngressMeta(ingressHostMetaObjs []k8sobjects.IngressHostMeta, c *GSLB
 	for _, ihm := range ingressHostMetaObjs {
 		present := DeleteFromIngressStore(acceptedIngStore, ihm, c.name)
 		DeleteFromIngressStore(rejectedIngStore, ihm, c.name)
+		filter.DeleteDecision(gslbutils.IngressType, c.name, ihm.Namespace, ihm.ObjName)
 
 		// Only if the ihm object was part of the accepted list previously, we will send a delete key
 		// otherwise we will assume that the object was already deleted
@@ -171,6 +178,7 @@ func filterAndUpdateIngressMeta(oldIngMetaObjs, newIngMetaObjs []k8sobjects.Ingr
 				ihm.ObjName)
 			DeleteFromIngressStore(acceptedIngStore, ihm, c.name)
 			DeleteFromIngressStore(rejectedIngStore, ihm, c.name)
+			filter.DeleteDecision(gslbutils.IngressType, c.name, ihm.Namespace, ihm.ObjName)
 			// If part of accepted store, only then publish the delete key
 			if isAccepted {
 				publishKeyToGraphLayer(numWorkers, gslbutils.IngressType, c.name,
@@ -230,6 +238,7 @@ func filterAndUpdateIngressMeta(oldIngMetaObjs, newIngMetaObjs []k8sobjects.Ingr
 		// have been taken care of already
 		// Add this ingressHost object
 		if ihm.IPAddr == "" || ihm.Hostname == "" {
+			recordStatusDecision(ihm)
 			gslbutils.Logf("cluster: %s, ns: %s, ingress: %s, msg: %s",
 				c.name, ihm.Namespace, ihm.ObjName,
 				"rejected ADD ingress because IP address/Hostname not found in status field")
@@ -303,6 +312,7 @@ func AddRouteEventHandler(numWorkers uint32, c *GSLBMemberController) cache.Reso
 			// status field
 			// TODO: See if we can change rejectRoute to Graph layer.
 			if _, ok := gslbutils.RouteGetIPAddr(route); !ok {
+				recordStatusDecision(k8sobjects.GetRouteMeta(route, c.name))
 				gslbutils.Logf("cluster: %s, ns: %s, route: %s, msg: %s\n", c.name,
 					route.ObjectMeta.Namespace, route.ObjectMeta.Name, "rejected ADD route key because IP address not found")
 				return
@@ -327,6 +337,7 @@ func AddRouteEventHandler(numWorkers uint32, c *GSLBMemberController) cache.Reso
 			// Delete from all route stores
 			present := DeleteFromRouteStore(acceptedRouteStore, route, c.name)
 			DeleteFromRouteStore(rejectedRouteStore, route, c.name)
+			filter.DeleteDecision(gslbutils.RouteType, c.name, route.ObjectMeta.Namespace, route.ObjectMeta.Name)
 			routeMeta := k8sobjects.GetRouteMeta(route, c.name)
 			if present {
 				publishKeyToGraphLayer(numWorkers, gslbutils.RouteType, c.name, route.ObjectMeta.Namespace,
@@ -338,7 +349,11 @@ func AddRouteEventHandler(numWorkers uint32, c *GSLBMemberController) cache.Reso
 			route := curr.(*routev1.Route)
 			if oldRoute.ResourceVersion != route.ResourceVersion {
 				routeMeta := k8sobjects.GetRouteMeta(route, c.name)
-				if _, ok := gslbutils.RouteGetIPAddr(route); !ok || !filter.ApplyFilter(routeMeta, c.name) {
+				_, ok := gslbutils.RouteGetIPAddr(route)
+				if !ok {
+					recordStatusDecision(routeMeta)
+				}
+				if !ok || !filter.ApplyFilter(routeMeta, c.name) {
 					// See if the route was already accepted, if yes, need to delete the key
 					fetchedObj, ok := acceptedRouteStore.GetClusterNSObjectByName(c.name,
 						oldRoute.ObjectMeta.Namespace, oldRoute.ObjectMeta.Name)
@@ -397,13 +412,14 @@ func AddNamespaceEventHandler(numWorkers uint32, c *GSLBMemberController) cache.
 				return
 			}
 			nsMeta := k8sobjects.GetNSMeta(ns, c.name)
+			updateNSTrafficWeight(nsMeta, c.workqueue, numWorkers)
 			if !filter.ApplyFilter(nsMeta, c.name) {
 				AddOrUpdateNSStore(rejectedNSStore, ns, c.name)
 				gslbutils.Logf("cluster: %s, ns: %s, msg: %s\n", c.name, nsMeta.Name,
 					"ns didn't pass through the filter, adding to rejected list")
 				return
 			}
-			WriteChangedObjsToQueue(c.workqueue, numWorkers, false)
+			WriteChangedObjsToQueue(c.workqueue, numWorkers)
 			AddOrUpdateNSStore(acceptedNSStore, ns, c.name)
 		},
 		DeleteFunc: func(obj interface{}) {
@@ -413,6 +429,8 @@ func AddNamespaceEventHandler(numWorkers uint32, c *GSLBMemberController) cache.
 				return
 			}
 			nsMeta := k8sobjects.GetNSMeta(ns, c.name)
+			// all the objects of this namespace are deleted below, so no need to re-publish them
+			gslbutils.GetTrafficWeightOverrides().DeleteNSAnnotationWeight(c.name, nsMeta.Name)
 			if !nsMeta.DeleteFromFilter() {
 				gslbutils.Debugf("no namespace exists in the filter, nothing to change")
 			}
@@ -431,6 +449,7 @@ func AddNamespaceEventHandler(numWorkers uint32, c *GSLBMemberController) cache.
 			if oldNS.ResourceVersion != ns.ResourceVersion {
 				oldNSMeta := k8sobjects.GetNSMeta(oldNS, c.name)
 				newNSMeta := k8sobjects.GetNSMeta(ns, c.name)
+				updateNSTrafficWeight(newNSMeta, c.workqueue, numWorkers)
 				if !newNSMeta.UpdateFilter(oldNSMeta) {
 					// no changes, nothing to be dome
 					gslbutils.Debugf("ns didn't change, nothing to be done")
@@ -441,7 +460,7 @@ func AddNamespaceEventHandler(numWorkers uint32, c *GSLBMemberController) cache.
 				}
 				// filter changed, re-apply
 				gslbutils.Logf("namespace: %s, msg: namespace changed in filter, will re-apply", ns.Name)
-				WriteChangedObjsToQueue(c.workqueue, numWorkers, false)
+				WriteChangedObjsToQueue(c.workqueue, numWorkers)
 
 				// determine if the new namespace is accepted or rejected
 				if newNSMeta.ApplyFilter() {
@@ -16,15 +16,19 @@ package ingestion
 
 import (
 	"errors"
+	"time"
 
 	filter "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gdp_filter"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
-	gslbalphav1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	avirest "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
 
+	routev1 "github.com/openshift/api/route/v1"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/api/networking/v1beta1"
@@ -91,27 +95,35 @@ func fetchAndApplyAllServices(c *GSLBMemberController, nsList *corev1.NamespaceL
 				namespace.Name, err.Error())
 			continue
 		}
-		for _, svc := range svcList.Items {
-			if !isSvcTypeLB(&svc) {
-				continue
-			}
-			svcMeta, ok := k8sobjects.GetSvcMeta(&svc, c.GetName())
-			if !ok {
-				gslbutils.Logf("cluster: %s, namespace: %s, svc: %s, msg: couldn't get meta object for service",
-					c.GetName(), namespace.Name, svc.Name)
-				continue
-			}
-			if !filter.ApplyFilter(svcMeta, c.GetName()) {
-				AddOrUpdateLBSvcStore(rejectedLBSvcStore, &svc, c.GetName())
-				gslbutils.Logf("cluster: %s, ns: %s, svc: %s, msg: %s", c.GetName(), namespace.Name,
-					svc.Name, "rejected ADD svc key because it couldn't pass through the filter")
-				continue
-			}
-			AddOrUpdateLBSvcStore(acceptedLBSvcStore, &svc, c.GetName())
+		for idx := range svcList.Items {
+			filterAndAddLBSvc(c, &svcList.Items[idx], acceptedLBSvcStore, rejectedLBSvcStore)
 		}
 	}
 }
 
+// filterAndAddLBSvc adds a service of type LoadBalancer to the accepted or the rejected store,
+// as per the filter, during the full sync.
+func filterAndAddLBSvc(c *GSLBMemberController, svc *corev1.Service, acceptedLBSvcStore,
+	rejectedLBSvcStore *gslbutils.ClusterStore) {
+	if !isSvcTypeLB(svc) {
+		return
+	}
+	svcMeta, ok := k8sobjects.GetSvcMeta(svc, c.GetName())
+	if !ok {
+		recordStatusDecision(svcMeta)
+		gslbutils.Logf("cluster: %s, namespace: %s, svc: %s, msg: couldn't get meta object for service",
+			c.GetName(), svc.Namespace, svc.Name)
+		return
+	}
+	if !filter.ApplyFilter(svcMeta, c.GetName()) {
+		AddOrUpdateLBSvcStore(rejectedLBSvcStore, svc, c.GetName())
+		gslbutils.Logf("cluster: %s, ns: %s, svc: %s, msg: %s", c.GetName(), svc.Namespace,
+			svc.Name, "rejected ADD svc key because it couldn't pass through the filter")
+		return
+	}
+	AddOrUpdateLBSvcStore(acceptedLBSvcStore, svc, c.GetName())
+}
+
 func fetchAndApplyAllRoutes(c *GSLBMemberController, nsList *corev1.NamespaceList) {
 	acceptedRouteStore := gslbutils.GetAcceptedRouteStore()
 	rejectedRotueStore := gslbutils.GetRejectedRouteStore()
@@ -123,26 +135,34 @@ func fetchAndApplyAllRoutes(c *GSLBMemberController, nsList *corev1.NamespaceLis
 				namespace.Name, err.Error())
 			continue
 		}
-		for _, route := range routeList.Items {
-			routeMeta := k8sobjects.GetRouteMeta(&route, c.name)
-			if routeMeta.IPAddr == "" || routeMeta.Hostname == "" {
-				gslbutils.Debugf("cluster: %s, ns: %s, route: %s, msg: %s", c.name, routeMeta.Namespace,
-					routeMeta.Name, "rejected ADD route because IP address/hostname not found in status field")
-				continue
-			}
-			if !filter.ApplyFilter(routeMeta, c.name) {
-				AddOrUpdateRouteStore(rejectedRotueStore, &route, c.name)
-				gslbutils.Logf("cluster: %s, ns: %s, route: %s, msg: %s, routeObj: %v", c.name, routeMeta.Namespace,
-					routeMeta.Name, "rejected ADD route key because it couldn't pass through the filter", routeMeta)
-				continue
-			}
-			AddOrUpdateRouteStore(acceptedRouteStore, &route, c.name)
+		for idx := range routeList.Items {
+			filterAndAddRoute(c, &routeList.Items[idx], acceptedRouteStore, rejectedRotueStore)
 		}
 	}
 }
 
+// filterAndAddRoute adds a route to the accepted or the rejected store, as per the filter, during
+// the full sync.
+func filterAndAddRoute(c *GSLBMemberController, route *routev1.Route, acceptedRouteStore,
+	rejectedRouteStore *gslbutils.ClusterStore) {
+	routeMeta := k8sobjects.GetRouteMeta(route, c.name)
+	if routeMeta.IPAddr == "" || routeMeta.Hostname == "" {
+		recordStatusDecision(routeMeta)
+		gslbutils.Debugf("cluster: %s, ns: %s, route: %s, msg: %s", c.name, routeMeta.Namespace,
+			routeMeta.Name, "rejected ADD route because IP address/hostname not found in status field")
+		return
+	}
+	if !filter.ApplyFilter(routeMeta, c.name) {
+		AddOrUpdateRouteStore(rejectedRouteStore, route, c.name)
+		gslbutils.Logf("cluster: %s, ns: %s, route: %s, msg: %s, routeObj: %v", c.name, routeMeta.Namespace,
+			routeMeta.Name, "rejected ADD route key because it couldn't pass through the filter", routeMeta)
+		return
+	}
+	AddOrUpdateRouteStore(acceptedRouteStore, route, c.name)
+}
+
 func checkGDPsAndInitialize() error {
-	gdpList, err := gslbutils.GlobalGslbClient.AmkoV1alpha1().GlobalDeploymentPolicies(gslbutils.AVISystem).List(metav1.ListOptions{})
+	gdpList, err := gslbutils.GlobalGslbClient.AmkoV1alpha2().GlobalDeploymentPolicies(gslbutils.AVISystem).List(metav1.ListOptions{})
 	if err != nil {
 		return nil
 	}
@@ -152,11 +172,11 @@ func checkGDPsAndInitialize() error {
 		return nil
 	}
 
-	// check if any of these GDP objects have "success" in their fields
-	var successGDP *gslbalphav1.GlobalDeploymentPolicy
+	// check if any of these GDP objects were accepted
+	var successGDP *gslbalphav2.GlobalDeploymentPolicy
 
 	for _, gdp := range gdpList.Items {
-		if gdp.Status.ErrorStatus == GDPSuccess {
+		if gslbalphav2.IsConditionTrue(gdp.Status.Conditions, gslbalphav2.ConditionAccepted) {
 			if successGDP == nil {
 				successGDP = &gdp
 			} else {
@@ -181,8 +201,43 @@ func checkGDPsAndInitialize() error {
 	return nil
 }
 
+// checkNSGDPsAndInitialize applies the traffic weights of the GDP objects in all namespaces other
+// than AVISystem.
+func checkNSGDPsAndInitialize() {
+	gdpList, err := gslbutils.GlobalGslbClient.AmkoV1alpha2().GlobalDeploymentPolicies("").List(metav1.ListOptions{})
+	if err != nil {
+		gslbutils.Errf("msg: error in fetching the GDP objects for all namespaces, %s", err.Error())
+		return
+	}
+	for idx := range gdpList.Items {
+		if gdpList.Items[idx].ObjectMeta.Namespace == gslbutils.AVISystem {
+			continue
+		}
+		addOrUpdateNSGDPWeights(&gdpList.Items[idx], nil, 0)
+	}
+}
+
+// filterAndAddNS applies the traffic weight of a namespace, and adds it to the accepted or the
+// rejected store as per the namespace filter, during the full sync.
+func filterAndAddNS(c *GSLBMemberController, ns *corev1.Namespace, acceptedNSStore, rejectedNSStore *gslbutils.ObjectStore) {
+	nsMeta := k8sobjects.GetNSMeta(ns, c.GetName())
+	updateNSTrafficWeight(nsMeta, nil, 0)
+	if _, err := gslbutils.GetGlobalFilter().GetNSFilterLabel(); err != nil {
+		gslbutils.Debugf("no namespace filter present, will sync the applications now")
+		return
+	}
+	if !filter.ApplyFilter(nsMeta, c.GetName()) {
+		AddOrUpdateNSStore(rejectedNSStore, ns, c.GetName())
+		gslbutils.Logf("cluster: %s, ns: %s, msg: %s\n", c.GetName(), nsMeta.Name,
+			"ns didn't pass through the filter, adding to rejected list")
+		return
+	}
+	AddOrUpdateNSStore(acceptedNSStore, ns, c.GetName())
+}
+
 func bootupSync(ctrlList []*GSLBMemberController, gsCache *avicache.AviCache) {
 	gslbutils.Logf("Starting boot up sync, will sync all ingresses, routes and services from all member clusters")
+	defer metrics.ObserveSince(metrics.FullSyncDuration, time.Now(), metrics.FullSyncBootup)
 
 	// add a GDP object
 	err := checkGDPsAndInitialize()
@@ -190,6 +245,9 @@ func bootupSync(ctrlList []*GSLBMemberController, gsCache *avicache.AviCache) {
 		// Undefined state, panic
 		panic(err.Error())
 	}
+	// apply the traffic weight overrides
+	checkNSGDPsAndInitialize()
+	checkGSLBHostRulesAndInitialize()
 
 	gf := gslbutils.GetGlobalFilter()
 
@@ -214,20 +272,8 @@ func bootupSync(ctrlList []*GSLBMemberController, gsCache *avicache.AviCache) {
 			return
 		}
 
-		for _, ns := range selectedNamespaces.Items {
-			_, err := gf.GetNSFilterLabel()
-			if err == nil {
-				nsMeta := k8sobjects.GetNSMeta(&ns, c.GetName())
-				if !filter.ApplyFilter(nsMeta, c.GetName()) {
-					AddOrUpdateNSStore(rejectedNSStore, &ns, c.GetName())
-					gslbutils.Logf("cluster: %s, ns: %s, msg: %s\n", c.GetName(), nsMeta.Name,
-						"ns didn't pass through the filter, adding to rejected list")
-					continue
-				}
-				AddOrUpdateNSStore(acceptedNSStore, &ns, c.GetName())
-			} else {
-				gslbutils.Debugf("no namespace filter present, will sync the applications now")
-			}
+		for idx := range selectedNamespaces.Items {
+			filterAndAddNS(c, &selectedNamespaces.Items[idx], acceptedNSStore, rejectedNSStore)
 		}
 		if c.informers.IngressInformer != nil {
 			fetchAndApplyAllIngresses(c, selectedNamespaces)
@@ -243,6 +289,10 @@ func bootupSync(ctrlList []*GSLBMemberController, gsCache *avicache.AviCache) {
 
 	// Generate models
 	GenerateModels(gsCache)
+	// the GSs which are already in sync won't be published to the controller again, so the drain
+	// status has to be computed once after the models are generated
+	avirest.UpdateGDPDrainStatus("fullsync")
+	gslbutils.SetBootupSyncDone(true)
 	gslbutils.Logf("boot up sync completed")
 }
 
@@ -272,12 +322,32 @@ func GenerateModels(gsCache *avicache.AviCache) {
 
 	gslbutils.Logf("keys for GS graphs published to layer 3")
 
+	publishStaleAviObjKeys(gsCache)
+}
+
+// publishGSDelete saves a GS graph with no members in the delete cache for gsKey, and publishes the
+// key to the rest layer, which deletes the GS.
+func publishGSDelete(gsKey avicache.TenantName, sharedQ *utils.WorkerQueue) {
+	key := gsKey.Tenant + "/" + gsKey.Name
+	newGSGraph := nodes.NewAviGSObjectGraph()
+	newGSGraph.Name = gsKey.Name
+	newGSGraph.Tenant = gsKey.Tenant
+	newGSGraph.MemberObjs = []nodes.AviGSK8sObj{}
+	newGSGraph.SetRetryCounter()
+	nodes.SharedDeleteGSGraphLister().Save(key, newGSGraph)
+
+	bkt := utils.Bkt(key, sharedQ.NumWorkers)
+	sharedQ.Workqueue[bkt].AddRateLimited(key)
+}
+
+// publishStaleAviObjKeys publishes the keys of the GSs and the health monitors in the avi cache
+// which don't have a GS graph to the rest layer, so that they are deleted.
+func publishStaleAviObjKeys(gsCache *avicache.AviCache) {
 	sharedQ := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
 
 	gsKeys := gsCache.AviCacheGetAllKeys()
 	// find out the keys which are not already present in the list of created GS graphs
 	agl := nodes.SharedAviGSGraphLister()
-	dgl := nodes.SharedDeleteGSGraphLister()
 	for _, gsKey := range gsKeys {
 		key := gsKey.Tenant + "/" + gsKey.Name
 		found, _ := agl.Get(key)
@@ -285,16 +355,7 @@ func GenerateModels(gsCache *avicache.AviCache) {
 			continue
 		}
 		gslbutils.Logf("key: %v, msg: didn't get a GS in the model cache", key)
-		// create a new Graph with 0 members, push it to the delete queue
-		newGSGraph := nodes.NewAviGSObjectGraph()
-		newGSGraph.Name = gsKey.Name
-		newGSGraph.Tenant = gsKey.Tenant
-		newGSGraph.MemberObjs = []nodes.AviGSK8sObj{}
-		newGSGraph.SetRetryCounter()
-		dgl.Save(key, newGSGraph)
-
-		bkt := utils.Bkt(key, sharedQ.NumWorkers)
-		sharedQ.Workqueue[bkt].AddRateLimited(key)
+		publishGSDelete(gsKey, sharedQ)
 		gslbutils.Logf("process: fullSync, modelName: %s, msg: %s", gsKey, "published key to rest layer")
 	}
 
@@ -0,0 +1,246 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// orphans has the times at which the objects created by AMKO on the Avi controller were first found
+// orphaned, keyed by the type and the uuid of the objects.
+var orphans = struct {
+	lock  sync.Mutex
+	since map[string]time.Time
+}{since: make(map[string]time.Time)}
+
+// orphanedObj is a GSLB service or a health monitor found orphaned by a garbage collection pass.
+type orphanedObj struct {
+	gslbalphav2.OrphanedObject
+	gs *avicache.AviGSCache
+	hm *avicache.AviHmObj
+}
+
+// RunGarbageCollection runs a garbage collection pass, it's run periodically.
+func RunGarbageCollection() {
+	CollectGarbage(time.Now())
+}
+
+// CollectGarbage finds the GSLB services and health monitors created by AMKO on the Avi controller,
+// which don't belong to any GS graph, and deletes the ones which are orphaned for the grace period
+// at time t. Nothing is deleted in the dry run mode, or if more objects than the safety threshold
+// are due to be deleted. The pass is skipped, and the grace periods start afresh, till the bootup sync
+// is done and all the member clusters are connected and synced. The report of the pass is published
+// in the status of the GSLBConfig object and returned, nil if the pass didn't run.
+func CollectGarbage(t time.Time) *gslbalphav2.GarbageCollectionStatus {
+	policy := gslbutils.GetGarbageCollectionPolicy()
+	if !policy.Enabled || !gslbutils.IsAMKOLeader() {
+		// the grace period starts afresh once this replica runs the garbage collection again
+		resetOrphans()
+		return nil
+	}
+	if gslbutils.IsReconcilePaused() || !gslbutils.IsControllerLeader() {
+		gslbutils.Logf("msg: reconciliation is paused or the controller isn't the GSLB leader, skipping the garbage collection")
+		return nil
+	}
+	// the objects of a member cluster which isn't reachable, or isn't synced yet, are missing from the
+	// GS graphs, so the GSes of the cluster would be found orphaned
+	if err := gcReady(); err != nil {
+		gslbutils.Logf("msg: skipping the garbage collection, %s", err.Error())
+		resetOrphans()
+		status := &gslbalphav2.GarbageCollectionStatus{LastRun: metav1.NewTime(t), Message: "skipped, " + err.Error()}
+		gslbutils.SetGarbageCollectionStatus(status)
+		gslbutils.PublishGSLBConfigStatus()
+		return status
+	}
+
+	found := findOrphanedObjs(avicache.PopulateGSCache(false), avicache.PopulateHMCache(false))
+
+	orphans.lock.Lock()
+	defer orphans.lock.Unlock()
+	since := make(map[string]time.Time, len(found))
+	var due []*orphanedObj
+	for i := range found {
+		obj := &found[i]
+		id := obj.Type + "/" + obj.UUID
+		orphanedAt, ok := orphans.since[id]
+		if !ok {
+			orphanedAt = t
+		}
+		since[id] = orphanedAt
+		obj.OrphanedSince = metav1.NewTime(orphanedAt)
+		obj.Action = gslbalphav2.OrphanPending
+		if t.Sub(orphanedAt) >= policy.GracePeriod {
+			due = append(due, obj)
+		}
+	}
+	orphans.since = since
+
+	status := &gslbalphav2.GarbageCollectionStatus{LastRun: metav1.NewTime(t)}
+	switch {
+	case len(due) == 0:
+	case policy.DryRun || gslbutils.IsDryRun():
+		status.Message = fmt.Sprintf("dry run, %d orphaned objects are due to be deleted", len(due))
+		for _, obj := range due {
+			obj.Action = gslbalphav2.OrphanSkipped
+		}
+	case len(due) > policy.MaxDeletions:
+		status.Message = fmt.Sprintf("%d orphaned objects are due to be deleted, which is more than the maximum of %d, none were deleted",
+			len(due), policy.MaxDeletions)
+		gslbutils.Warnf("msg: %s", status.Message)
+		for _, obj := range due {
+			obj.Action = gslbalphav2.OrphanSkipped
+		}
+	default:
+		for _, obj := range due {
+			deleteOrphanedObj(obj)
+		}
+	}
+	for _, obj := range found {
+		status.Orphans = append(status.Orphans, obj.OrphanedObject)
+	}
+	gslbutils.Logf("orphans: %d, due: %d, msg: garbage collection done", len(found), len(due))
+
+	gslbutils.SetGarbageCollectionStatus(status)
+	gslbutils.PublishGSLBConfigStatus()
+	return status
+}
+
+// resetOrphans forgets the orphaned objects found so far.
+func resetOrphans() {
+	orphans.lock.Lock()
+	defer orphans.lock.Unlock()
+	orphans.since = make(map[string]time.Time)
+}
+
+// gcReady returns an error if the GS graphs may not have all the objects of the member clusters, i.e.
+// if the bootup sync isn't done, or if any member cluster isn't connected or its caches aren't synced.
+func gcReady() error {
+	if !gslbutils.IsBootupSyncDone() {
+		return errors.New("bootup sync isn't done")
+	}
+	if notReady := gslbutils.NotReadyMemberClusters(); len(notReady) > 0 {
+		return errors.New("member clusters " + strings.Join(notReady, ", ") + " aren't connected")
+	}
+	return memberClustersSynced()
+}
+
+// findOrphanedObjs returns the GSLB services in gsCache for which there's no GS graph, and the
+// health monitors in hmCache created by AMKO, which don't belong to any GS graph, or to any GSLB
+// service in gsCache. The health monitors of an orphaned GSLB service are orphaned once it's deleted.
+func findOrphanedObjs(gsCache *avicache.AviCache, hmCache *avicache.AviHmCache) []orphanedObj {
+	var found []orphanedObj
+	agl := nodes.SharedAviGSGraphLister()
+	usedHms := make(map[string]bool)
+	for _, key := range agl.GetAll() {
+		ok, graphIntf := agl.Get(key)
+		if !ok {
+			continue
+		}
+		graph, ok := graphIntf.(*nodes.AviGSObjectGraph)
+		if !ok || graph == nil {
+			continue
+		}
+		graph = graph.GetCopy()
+		usedHms[graph.Hm.Name] = true
+		for _, hmName := range graph.Hm.PathNames {
+			usedHms[hmName] = true
+		}
+	}
+
+	for _, gsKey := range gsCache.AviCacheGetAllKeys() {
+		gsIntf, ok := gsCache.AviCacheGet(gsKey)
+		if !ok {
+			continue
+		}
+		gs, ok := gsIntf.(*avicache.AviGSCache)
+		if !ok || gs == nil {
+			continue
+		}
+		for _, hmName := range gs.HealthMonitorNames {
+			usedHms[hmName] = true
+		}
+		if ok, _ := agl.Get(gsKey.Tenant + "/" + gsKey.Name); ok {
+			continue
+		}
+		found = append(found, orphanedObj{
+			OrphanedObject: gslbalphav2.OrphanedObject{Type: gslbutils.GCTypeGS, Name: gs.Name, UUID: gs.Uuid},
+			gs:             gs,
+		})
+	}
+
+	for _, hmKey := range hmCache.AviHmGetAllKeys() {
+		hmIntf, ok := hmCache.AviHmCacheGet(hmKey)
+		if !ok {
+			continue
+		}
+		hm, ok := hmIntf.(*avicache.AviHmObj)
+		if !ok || hm == nil || !hm.IsCreatedByAmko() || usedHms[hm.Name] ||
+			hm.Name == gslbutils.SystemGslbHealthMonitorPassthrough {
+			continue
+		}
+		found = append(found, orphanedObj{
+			OrphanedObject: gslbalphav2.OrphanedObject{Type: gslbutils.GCTypeHM, Name: hm.Name, UUID: hm.UUID},
+			hm:             hm,
+		})
+	}
+
+	sort.Slice(found, func(i, j int) bool {
+		if found[i].Type != found[j].Type {
+			return found[i].Type < found[j].Type
+		}
+		return found[i].Name < found[j].Name
+	})
+	return found
+}
+
+// deleteOrphanedObj deletes an orphaned GSLB service through the rest layer, along with its health
+// monitors, and an orphaned health monitor right away.
+func deleteOrphanedObj(obj *orphanedObj) {
+	gslbutils.Logf("type: %s, name: %s, uuid: %s, orphanedSince: %s, msg: deleting the orphaned object",
+		obj.Type, obj.Name, obj.UUID, obj.OrphanedSince.Format(time.RFC3339))
+	switch obj.Type {
+	case gslbutils.GCTypeGS:
+		gsKey := avicache.TenantName{Tenant: obj.gs.Tenant, Name: obj.gs.Name}
+		// the rest layer deletes the GS in its cache
+		aviCache := avicache.GetAviCache()
+		if _, ok := aviCache.AviCacheGet(gsKey); !ok {
+			aviCache.AviCacheAdd(gsKey, obj.gs)
+		}
+		publishGSDelete(gsKey, utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer))
+	case gslbutils.GCTypeHM:
+		if err := rest.DeleteOrphanedHM(obj.hm); err != nil {
+			obj.Action = gslbalphav2.OrphanFailed
+			return
+		}
+	}
+	obj.Action = gslbalphav2.OrphanDeleted
+	metrics.GCDeletions.WithLabelValues(obj.Type).Inc()
+}
@@ -16,23 +16,28 @@ package ingestion
 
 import (
 	"errors"
+	"reflect"
 	"strconv"
+	"strings"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
 
 	filter "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gdp_filter"
+	avirest "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
 
-	gdpalphav1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gdpalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 	gslbcs "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned"
 	gdpscheme "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned/scheme"
 	gslbinformers "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/informers/externalversions"
-	gdplisters "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/listers/amko/v1alpha1"
+	gdplisters "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/listers/amko/v1alpha2"
 
 	"github.com/openshift/client-go/route/clientset/versioned/scheme"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/apimachinery/pkg/util/validation"
 	"k8s.io/client-go/kubernetes"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
 	"k8s.io/client-go/tools/cache"
@@ -134,7 +139,7 @@ func MoveObjs(objList []string, fromStore *gslbutils.ClusterStore, toStore *gslb
 func splitName(objType, objName string) (string, string, string, error) {
 	var cname, ns, sname, hostname string
 	var err error
-	if objType == gdpalphav1.IngressObj {
+	if objType == gdpalphav2.IngressObj {
 		cname, ns, sname, hostname, err = gslbutils.SplitMultiClusterIngHostName(objName)
 		sname += "/" + hostname
 	} else {
@@ -148,15 +153,15 @@ func GetObjTypeStores(objType string) (string, *gslbutils.ClusterStore, *gslbuti
 	var acceptedObjStore *gslbutils.ClusterStore
 	var rejectedObjStore *gslbutils.ClusterStore
 
-	if objType == gdpalphav1.RouteObj {
+	if objType == gdpalphav2.RouteObj {
 		acceptedObjStore = gslbutils.GetAcceptedRouteStore()
 		rejectedObjStore = gslbutils.GetRejectedRouteStore()
 		objKey = gslbutils.RouteType
-	} else if objType == gdpalphav1.LBSvcObj {
+	} else if objType == gdpalphav2.LBSvcObj {
 		acceptedObjStore = gslbutils.GetAcceptedLBSvcStore()
 		rejectedObjStore = gslbutils.GetRejectedLBSvcStore()
 		objKey = gslbutils.SvcType
-	} else if objType == gdpalphav1.IngressObj {
+	} else if objType == gdpalphav2.IngressObj {
 		acceptedObjStore = gslbutils.GetAcceptedIngressStore()
 		rejectedObjStore = gslbutils.GetRejectedIngressStore()
 		objKey = gslbutils.IngressType
@@ -167,7 +172,7 @@ func GetObjTypeStores(objType string) (string, *gslbutils.ClusterStore, *gslbuti
 	return objKey, acceptedObjStore, rejectedObjStore, nil
 }
 
-func writeChangedObjToQueue(objType string, k8swq []workqueue.RateLimitingInterface, numWorkers uint32, trafficWeightChanged bool) {
+func writeChangedObjToQueue(objType string, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
 	var cname, ns, sname string
 	var err error
 
@@ -180,7 +185,7 @@ func writeChangedObjToQueue(objType string, k8swq []workqueue.RateLimitingInterf
 		// If we have objects in the accepted store, each one has to be passed through
 		// the filter again. If any object fails to pass through the filter, we need to
 		// add DELETE keys for them.
-		acceptedList, rejectedList := acceptedObjStore.GetAllFilteredClusterNSObjects(filter.ApplyFilter)
+		_, rejectedList := acceptedObjStore.GetAllFilteredClusterNSObjects(filter.ApplyFilter)
 		if len(rejectedList) != 0 {
 			gslbutils.Logf("ObjList: %v, msg: %s", rejectedList, "obj list will be deleted")
 			// Since, these objects are now rejected, they have to be moved to
@@ -201,21 +206,6 @@ func writeChangedObjToQueue(objType string, k8swq []workqueue.RateLimitingInterf
 					cname, ns, objType, sname, key)
 			}
 		}
-		// if the traffic weight changed, then the accepted list has to be sent to the nodes layer
-		if trafficWeightChanged {
-			for _, objName := range acceptedList {
-				cname, ns, sname, err = splitName(objType, objName)
-				if err != nil {
-					gslbutils.Errf("msg: couldn't split the key: %s, error, %s", objName, err)
-					continue
-				}
-				bkt := utils.Bkt(ns, numWorkers)
-				key := gslbutils.MultiClusterKey(gslbutils.ObjectUpdate, objKey, cname, ns, sname)
-				k8swq[bkt].AddRateLimited(key)
-				gslbutils.Logf("cluster: %s, ns: %s, objtype: %s, name: %s, key: %s, msg: added key",
-					cname, ns, objType, sname, key)
-			}
-		}
 	}
 
 	if rejectedObjStore != nil {
@@ -243,7 +233,7 @@ func writeChangedObjToQueue(objType string, k8swq []workqueue.RateLimitingInterf
 }
 
 func validObjectType(objType string) bool {
-	if objType == gdpalphav1.IngressObj || objType == gdpalphav1.LBSvcObj || objType == gdpalphav1.RouteObj {
+	if objType == gdpalphav2.IngressObj || objType == gdpalphav2.LBSvcObj || objType == gdpalphav2.RouteObj {
 		return true
 	}
 	return false
@@ -257,11 +247,17 @@ func validLabel(label map[string]string) error {
 		if v == "" {
 			return errors.New("label key is missing for value " + v)
 		}
+		if errs := validation.IsQualifiedName(k); len(errs) != 0 {
+			return errors.New("label key " + k + " is invalid: " + strings.Join(errs, ", "))
+		}
+		if errs := validation.IsValidLabelValue(v); len(errs) != 0 {
+			return errors.New("label value " + v + " is invalid: " + strings.Join(errs, ", "))
+		}
 	}
 	return nil
 }
 
-func GDPSanityChecks(gdp *gdpalphav1.GlobalDeploymentPolicy) error {
+func GDPSanityChecks(gdp *gdpalphav2.GlobalDeploymentPolicy) error {
 	// MatchRules checks
 	mr := gdp.Spec.MatchRules
 	// no app selector and no namespace selector means, no objects selected
@@ -292,20 +288,50 @@ func GDPSanityChecks(gdp *gdpalphav1.GlobalDeploymentPolicy) error {
 			return errors.New("traffic weight " + strconv.Itoa(int(tp.Weight)) + " must be between 1 and 20")
 		}
 	}
-	return nil
-}
 
-func updateGDPStatus(gdp *gdpalphav1.GlobalDeploymentPolicy, msg string) {
-	gdp.Status.ErrorStatus = msg
+	// DrainClusters checks
+	for _, cluster := range gdp.Spec.DrainClusters {
+		if !gslbutils.IsClusterContextPresent(cluster) {
+			return errors.New("drain cluster " + cluster + " not present in GSLBConfig")
+		}
+	}
 
-	// Always check this flag before writing the status on the GDP object. The reason is, for unit tests,
-	// the fake client doesn't have CRD capability and hence, can't do a runtime create/update of CRDs.
-	if !gslbutils.PublishGDPStatus {
-		return
+	// ScheduledTrafficSplit checks
+	if err := scheduledTrafficSplitSanityChecks(gdp); err != nil {
+		return err
 	}
-	obj, updateErr := gslbutils.GlobalGslbClient.AmkoV1alpha1().GlobalDeploymentPolicies(gdp.GetObjectMeta().GetNamespace()).Update(gdp)
-	if updateErr != nil {
-		gslbutils.Errf("Error in updating the GDP status object %v: %s", obj, updateErr)
+
+	// TrafficShift checks
+	return trafficShiftSanityChecks(gdp)
+}
+
+// updateGDPStatus sets the Accepted condition of the GDP object, the object is rejected with the
+// error message if err is not nil.
+func updateGDPStatus(gdp *gdpalphav2.GlobalDeploymentPolicy, err error) {
+	cond := gdpalphav2.Condition{
+		Type:               gdpalphav2.ConditionAccepted,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: gdp.Generation,
+		Reason:             gdpalphav2.ReasonAccepted,
+		Message:            GDPSuccess,
+	}
+	if err != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = gdpalphav2.ReasonRejected
+		cond.Message = err.Error()
+	}
+	generation := gdp.Generation
+	setStatus := func(gdp *gdpalphav2.GlobalDeploymentPolicy) bool {
+		oldStatus := gdp.Status.DeepCopy()
+		gdpalphav2.SetCondition(&gdp.Status.Conditions, cond)
+		gdp.Status.ObservedGeneration = generation
+		return !reflect.DeepEqual(oldStatus, &gdp.Status)
+	}
+	setStatus(gdp)
+
+	if updateErr := gslbutils.UpdateGDPStatus(gdp.Namespace, gdp.Name, setStatus); updateErr != nil {
+		gslbutils.Errf("ns: %s, gdp: %s, msg: error in updating the GDP status: %s", gdp.Namespace, gdp.Name,
+			updateErr)
 	}
 }
 
@@ -378,15 +404,15 @@ func deleteNamespacedObjsAndWriteToQueue(objType string, k8swq []workqueue.RateL
 }
 
 func DeleteNamespacedObjsFromAllStores(k8swq []workqueue.RateLimitingInterface, numWorkers uint32, nsMeta k8sobjects.NSMeta) {
-	deleteNamespacedObjsAndWriteToQueue(gdpalphav1.RouteObj, k8swq, numWorkers, nsMeta.Cluster, nsMeta.Name)
-	deleteNamespacedObjsAndWriteToQueue(gdpalphav1.LBSvcObj, k8swq, numWorkers, nsMeta.Cluster, nsMeta.Name)
-	deleteNamespacedObjsAndWriteToQueue(gdpalphav1.IngressObj, k8swq, numWorkers, nsMeta.Cluster, nsMeta.Name)
+	deleteNamespacedObjsAndWriteToQueue(gdpalphav2.RouteObj, k8swq, numWorkers, nsMeta.Cluster, nsMeta.Name)
+	deleteNamespacedObjsAndWriteToQueue(gdpalphav2.LBSvcObj, k8swq, numWorkers, nsMeta.Cluster, nsMeta.Name)
+	deleteNamespacedObjsAndWriteToQueue(gdpalphav2.IngressObj, k8swq, numWorkers, nsMeta.Cluster, nsMeta.Name)
 }
 
-func WriteChangedObjsToQueue(k8swq []workqueue.RateLimitingInterface, numWorkers uint32, trafficWeightChanged bool) {
-	writeChangedObjToQueue(gdpalphav1.RouteObj, k8swq, numWorkers, trafficWeightChanged)
-	writeChangedObjToQueue(gdpalphav1.LBSvcObj, k8swq, numWorkers, trafficWeightChanged)
-	writeChangedObjToQueue(gdpalphav1.IngressObj, k8swq, numWorkers, trafficWeightChanged)
+func WriteChangedObjsToQueue(k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
+	writeChangedObjToQueue(gdpalphav2.RouteObj, k8swq, numWorkers)
+	writeChangedObjToQueue(gdpalphav2.LBSvcObj, k8swq, numWorkers)
+	writeChangedObjToQueue(gdpalphav2.IngressObj, k8swq, numWorkers)
 }
 
 func applyAndUpdateNamespaces() {
@@ -425,7 +451,7 @@ func applyAndUpdateNamespaces() {
 	}
 }
 
-func applyAndRejectNamespaces(gf *gslbutils.GlobalFilter, gdp *gdpalphav1.GlobalDeploymentPolicy) {
+func applyAndRejectNamespaces(gf *gslbutils.GlobalFilter, gdp *gdpalphav2.GlobalDeploymentPolicy) {
 	acceptedNSStore := gslbutils.GetAcceptedNSStore()
 	rejectedNSStore := gslbutils.GetRejectedNSStore()
 
@@ -458,14 +484,15 @@ func applyAndAcceptNamespaces() {
 // AddGDPObj creates a new GlobalFilter if not present on the first GDP object. Subsequent
 // adds for GDP objects must fail as only one GDP object is allowed globally.
 func AddGDPObj(obj interface{}, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
-	gdp, ok := obj.(*gdpalphav1.GlobalDeploymentPolicy)
+	gdp, ok := obj.(*gdpalphav2.GlobalDeploymentPolicy)
 	if !ok {
 		gslbutils.Errf("object added is not of type GDP")
 		return
 	}
 
-	// GDPs for all other namespaces are rejected
+	// GDPs for all other namespaces can only override the traffic weights for their namespace
 	if gdp.ObjectMeta.Namespace != gslbutils.AVISystem {
+		addOrUpdateNSGDPWeights(gdp, k8swq, numWorkers)
 		return
 	}
 
@@ -477,32 +504,41 @@ func AddGDPObj(obj interface{}, k8swq []workqueue.RateLimitingInterface, numWork
 			// this object is already added, no need to update the status, just return
 			return
 		}
-		msg := "a GDP object already exists, can't add another"
-		gslbutils.Errf(msg)
-		updateGDPStatus(gdp, msg)
+		err := errors.New("a GDP object already exists, can't add another")
+		gslbutils.Errf(err.Error())
+		updateGDPStatus(gdp, err)
 		return
 	}
 	err := GDPSanityChecks(gdp)
 	if err != nil {
 		gslbutils.Errf("Error in accepting GDP object: %s", err.Error())
-		updateGDPStatus(gdp, err.Error())
+		updateGDPStatus(gdp, err)
 		return
 	}
-	updateGDPStatus(gdp, GDPSuccess)
+	updateGDPStatus(gdp, nil)
 
 	gslbutils.Logf("ns: %s, gdp: %s, msg: %s", gdp.ObjectMeta.Namespace, gdp.ObjectMeta.Name,
 		"GDP object added")
 
 	gslbutils.Logf("creating a new filter")
 	gf.AddToFilter(gdp)
+	// a traffic shift which was in progress before a restart resumes from the step in the GDP status
+	gf.ResumeTrafficShift(gdp.Status.TrafficShift)
 	// First apply the filter on the namespaces
 	applyAndAcceptNamespaces()
 	// for bootup sync, k8swq will be nil, in which case, the movement of objects will be taken
 	// care of by the bootupSync function
 	if k8swq != nil {
-		WriteChangedObjsToQueue(k8swq, numWorkers, false)
+		WriteChangedObjsToQueue(k8swq, numWorkers)
 	}
 	gslbutils.SetGDPObj(gdp.GetObjectMeta().GetName(), gdp.GetObjectMeta().GetNamespace())
+	publishActiveTrafficSplitWindow(gf.GetActiveTrafficSplitWindow())
+	publishTrafficShiftStatus(getTrafficShiftStatus())
+	if len(gdp.Spec.DrainClusters) != 0 {
+		gslbutils.Logf("ns: %s, gdp: %s, clusters: %v, msg: clusters in drain mode", gdp.ObjectMeta.Namespace,
+			gdp.ObjectMeta.Name, gdp.Spec.DrainClusters)
+		avirest.UpdateGDPDrainStatus(gdp.ObjectMeta.Namespace + "/" + gdp.ObjectMeta.Name)
+	}
 }
 
 // UpdateGDPObj updates the global and the namespace filters if a the GDP object
@@ -511,12 +547,17 @@ func AddGDPObj(obj interface{}, k8swq []workqueue.RateLimitingInterface, numWork
 // and added or deleted based on whether or not, they pass the new fitler objects.
 // TODO: Optimize the filter process a bit more based on how the filters are processed.
 func UpdateGDPObj(old, new interface{}, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
-	oldGdp := old.(*gdpalphav1.GlobalDeploymentPolicy)
-	newGdp := new.(*gdpalphav1.GlobalDeploymentPolicy)
+	oldGdp := old.(*gdpalphav2.GlobalDeploymentPolicy)
+	newGdp := new.(*gdpalphav2.GlobalDeploymentPolicy)
 	if oldGdp.ObjectMeta.ResourceVersion == newGdp.ObjectMeta.ResourceVersion {
 		return
 	}
 
+	if newGdp.ObjectMeta.Namespace != gslbutils.AVISystem {
+		addOrUpdateNSGDPWeights(newGdp, k8swq, numWorkers)
+		return
+	}
+
 	// update only the accepted GDP
 	if name, ns := gslbutils.GetGDPObj(); name != newGdp.GetObjectMeta().GetName() && ns != newGdp.GetObjectMeta().GetNamespace() {
 		gslbutils.Errf("A GDP object already exists, updates will be ignored for other GDP objects")
@@ -526,10 +567,10 @@ func UpdateGDPObj(old, new interface{}, k8swq []workqueue.RateLimitingInterface,
 	err := GDPSanityChecks(newGdp)
 	if err != nil {
 		gslbutils.Errf("Error in accepting the new GDP object: %s", err.Error())
-		updateGDPStatus(newGdp, err.Error())
+		updateGDPStatus(newGdp, err)
 		return
 	}
-	updateGDPStatus(newGdp, "success")
+	updateGDPStatus(newGdp, nil)
 
 	gf := gslbutils.GetGlobalFilter()
 	if gf == nil {
@@ -537,12 +578,35 @@ func UpdateGDPObj(old, new interface{}, k8swq []workqueue.RateLimitingInterface,
 		gslbutils.Errf("object: GlobalFilter, msg: global filter not initialized, can't update")
 		return
 	}
-	if gdpChanged, trafficWeightChanged := gf.UpdateGlobalFilter(oldGdp, newGdp); gdpChanged {
+	oldDrainClusters := gf.GetDrainClusters()
+	oldShiftedOutClusters := gf.GetShiftedOutClusters()
+	if gdpChanged, changedClusters := gf.UpdateGlobalFilter(oldGdp, newGdp); gdpChanged {
 		gslbutils.Logf("GDP object changed, will go through the objects again")
 		// first apply and update the namespaces in the filter
 		applyAndUpdateNamespaces()
-		WriteChangedObjsToQueue(k8swq, numWorkers, trafficWeightChanged)
+		WriteChangedObjsToQueue(k8swq, numWorkers)
+		// only the objects of the clusters whose weights changed need to be re-published
+		if len(changedClusters) != 0 {
+			gslbutils.Logf("clusters: %v, msg: traffic weights changed for clusters", changedClusters)
+			WriteTrafficWeightChangedObjsToQueue(k8swq, numWorkers, SelectObjsForGDPClusters(changedClusters))
+		}
+		// the members of the clusters which were added to or removed from the drain list have to be
+		// re-published with the new state
+		if drainChanged := gslbutils.DrainChangedClusters(gf.GetDrainClusters(), oldDrainClusters); len(drainChanged) != 0 {
+			gslbutils.Logf("clusters: %v, msg: drain mode changed for clusters", drainChanged)
+			WriteTrafficWeightChangedObjsToQueue(k8swq, numWorkers, SelectObjsForClusters(drainChanged))
+			avirest.UpdateGDPDrainStatus(newGdp.ObjectMeta.Namespace + "/" + newGdp.ObjectMeta.Name)
+		}
+		// same for the clusters which started or stopped getting traffic due to a change in the
+		// traffic shift
+		if shiftChanged := gslbutils.DrainChangedClusters(gf.GetShiftedOutClusters(),
+			oldShiftedOutClusters); len(shiftChanged) != 0 {
+			gslbutils.Logf("clusters: %v, msg: traffic shift changed for clusters", shiftChanged)
+			WriteTrafficWeightChangedObjsToQueue(k8swq, numWorkers, SelectObjsForClusters(shiftChanged))
+		}
+		publishTrafficShiftStatus(getTrafficShiftStatus())
 	}
+	publishActiveTrafficSplitWindow(gf.GetActiveTrafficSplitWindow())
 }
 
 // DeleteGDPObj requires to delete the filters that were previously created. If a GDP
@@ -550,10 +614,15 @@ func UpdateGDPObj(old, new interface{}, k8swq []workqueue.RateLimitingInterface,
 // this filter again to find out which filter is applicable, the global one or the
 // local one.
 func DeleteGDPObj(obj interface{}, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
-	gdp := obj.(*gdpalphav1.GlobalDeploymentPolicy)
+	gdp := obj.(*gdpalphav2.GlobalDeploymentPolicy)
 	gslbutils.Logf("ns: %s, gdp: %s, msg: %s", gdp.ObjectMeta.Namespace, gdp.ObjectMeta.Name,
 		"deleted GDP object")
 
+	if gdp.ObjectMeta.Namespace != gslbutils.AVISystem {
+		deleteNSGDPWeights(gdp, k8swq, numWorkers)
+		return
+	}
+
 	if name, ns := gslbutils.GetGDPObj(); name != gdp.GetObjectMeta().GetName() && ns != gdp.GetObjectMeta().GetNamespace() {
 		gslbutils.Errf("won't delete the filter as GDP object deleted wasn't accepted")
 		return
@@ -568,7 +637,7 @@ func DeleteGDPObj(obj interface{}, k8swq []workqueue.RateLimitingInterface, numW
 	gf.DeleteFromGlobalFilter(gdp)
 	// remove all namespaces from filter and re-apply
 	k8sobjects.RemoveAllSelectedNamespaces()
-	WriteChangedObjsToQueue(k8swq, numWorkers, false)
+	WriteChangedObjsToQueue(k8swq, numWorkers)
 
 	gslbutils.SetGDPObj("", "")
 }
@@ -581,7 +650,7 @@ func InitializeGDPController(kubeclientset kubernetes.Interface,
 	AddGDPFunc GDPAddDelfn, UpdateGDPFunc GDPUpdfn,
 	DeleteGDPFunc GDPAddDelfn) *GDPController {
 
-	gdpInformer := gslbInformerFactory.Amko().V1alpha1().GlobalDeploymentPolicies()
+	gdpInformer := gslbInformerFactory.Amko().V1alpha2().GlobalDeploymentPolicies()
 	gdpscheme.AddToScheme(scheme.Scheme)
 	gslbutils.Logf("object: GDPController, msg: %s", "creating event broadcaster")
 	eventBroadcaster := record.NewBroadcaster()
@@ -0,0 +1,257 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	avirest "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	containerutils "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	k8serrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+// memberClients keeps the clients of the member clusters, required to annotate the objects of these
+// clusters with their GS health.
+type memberClients struct {
+	lock    sync.RWMutex
+	clients map[string]*containerutils.Informers
+}
+
+var gsHealthMemberClients = memberClients{clients: make(map[string]*containerutils.Informers)}
+
+func registerMemberClients(cname string, informers *containerutils.Informers) {
+	gsHealthMemberClients.lock.Lock()
+	defer gsHealthMemberClients.lock.Unlock()
+	gsHealthMemberClients.clients[cname] = informers
+}
+
+func deregisterMemberClients(cname string) {
+	gsHealthMemberClients.lock.Lock()
+	defer gsHealthMemberClients.lock.Unlock()
+	delete(gsHealthMemberClients.clients, cname)
+}
+
+func getMemberClients(cname string) (*containerutils.Informers, bool) {
+	gsHealthMemberClients.lock.RLock()
+	defer gsHealthMemberClients.lock.RUnlock()
+	informers, ok := gsHealthMemberClients.clients[cname]
+	return informers, ok
+}
+
+// gsHealthObj identifies a route, service or ingress in a member cluster.
+type gsHealthObj struct {
+	cluster   string
+	objType   string
+	namespace string
+	name      string
+}
+
+// publishedGSHealth keeps the GS health annotation values last written to the objects, so that
+// objects are patched only on a change and the annotation can be removed from objects which are no
+// longer GS members.
+type publishedGSHealth struct {
+	lock        sync.Mutex
+	annotations map[gsHealthObj]string
+}
+
+var gsHealthAnnotations = publishedGSHealth{annotations: make(map[gsHealthObj]string)}
+
+func getGSHealthObj(member gslbalphav2.GSLBServiceMemberHealth) gsHealthObj {
+	name := member.Name
+	if member.ObjType == gslbutils.IngressType {
+		// ingress members are named as ingress name/hostname
+		name = strings.Split(member.Name, "/")[0]
+	}
+	return gsHealthObj{
+		cluster:   member.Cluster,
+		objType:   member.ObjType,
+		namespace: member.Namespace,
+		name:      name,
+	}
+}
+
+// patchGSHealthAnnotation sets the GS health annotation on an object to value, a nil value removes
+// the annotation.
+func patchGSHealthAnnotation(obj gsHealthObj, value *string) error {
+	informers, ok := getMemberClients(obj.cluster)
+	if !ok {
+		return errors.New("no clients for cluster " + obj.cluster)
+	}
+	patch := map[string]interface{}{
+		"metadata": map[string]interface{}{
+			"annotations": map[string]*string{
+				gslbutils.GSHealthAnnotation: value,
+			},
+		},
+	}
+	patchBytes, err := json.Marshal(patch)
+	if err != nil {
+		return err
+	}
+
+	switch obj.objType {
+	case gslbutils.SvcType:
+		_, err = informers.ClientSet.CoreV1().Services(obj.namespace).Patch(obj.name, types.MergePatchType,
+			patchBytes)
+	case gslbutils.IngressType:
+		if informers.IngressVersion == containerutils.ExtV1IngressInformer {
+			_, err = informers.ClientSet.ExtensionsV1beta1().Ingresses(obj.namespace).Patch(obj.name,
+				types.MergePatchType, patchBytes)
+		} else {
+			_, err = informers.ClientSet.NetworkingV1beta1().Ingresses(obj.namespace).Patch(obj.name,
+				types.MergePatchType, patchBytes)
+		}
+	case gslbutils.RouteType:
+		if informers.OshiftClient == nil {
+			return errors.New("no openshift client for cluster " + obj.cluster)
+		}
+		_, err = informers.OshiftClient.RouteV1().Routes(obj.namespace).Patch(obj.name, types.MergePatchType,
+			patchBytes)
+	default:
+		return errors.New("unknown object type " + obj.objType)
+	}
+	return err
+}
+
+// annotateGSHealth writes the GS health annotation on all the objects which are GS members, and
+// removes it from the objects which aren't members anymore.
+func annotateGSHealth(gsHealth map[string]gslbalphav2.GSLBServiceHealth) {
+	objHealth := make(map[gsHealthObj]map[string]gslbutils.GSHealthAnnotationValue)
+	for _, health := range gsHealth {
+		for _, member := range health.Members {
+			obj := getGSHealthObj(member)
+			if _, ok := objHealth[obj]; !ok {
+				objHealth[obj] = make(map[string]gslbutils.GSHealthAnnotationValue)
+			}
+			objHealth[obj][health.Fqdn] = gslbutils.GSHealthAnnotationValue{
+				GSState:     health.State,
+				MemberState: member.State,
+			}
+		}
+	}
+
+	gsHealthAnnotations.lock.Lock()
+	defer gsHealthAnnotations.lock.Unlock()
+	for obj, health := range objHealth {
+		value := gslbutils.BuildGSHealthAnnotation(health)
+		if value == "" || gsHealthAnnotations.annotations[obj] == value {
+			continue
+		}
+		if err := patchGSHealthAnnotation(obj, &value); err != nil {
+			gslbutils.Warnf("cluster: %s, objType: %s, ns: %s, name: %s, msg: couldn't set the GS health annotation: %s",
+				obj.cluster, obj.objType, obj.namespace, obj.name, err)
+			continue
+		}
+		gsHealthAnnotations.annotations[obj] = value
+	}
+	for obj := range gsHealthAnnotations.annotations {
+		if _, ok := objHealth[obj]; ok {
+			continue
+		}
+		err := patchGSHealthAnnotation(obj, nil)
+		if err != nil && !k8serrors.IsNotFound(err) {
+			gslbutils.Warnf("cluster: %s, objType: %s, ns: %s, name: %s, msg: couldn't remove the GS health annotation: %s",
+				obj.cluster, obj.objType, obj.namespace, obj.name, err)
+			continue
+		}
+		delete(gsHealthAnnotations.annotations, obj)
+	}
+}
+
+// publishGSLBServiceStatus creates, updates and deletes the GSLBServiceStatus objects in the
+// avi-system namespace, so that there's one object for each GS with its latest health.
+func publishGSLBServiceStatus(gsHealth map[string]gslbalphav2.GSLBServiceHealth) {
+	// The fake client used in unit tests doesn't support status updates on CRDs, so check this flag
+	// before writing the status objects.
+	if !gslbutils.PublishGSLBServiceStatus.IsSet() {
+		return
+	}
+	gssClient := gslbutils.GlobalGslbClient.AmkoV1alpha2().GSLBServiceStatuses(gslbutils.AVISystem)
+	gssList, err := gssClient.List(metav1.ListOptions{})
+	if err != nil {
+		gslbutils.Errf("ns: %s, msg: error in listing the GSLBServiceStatus objects: %s", gslbutils.AVISystem, err)
+		return
+	}
+	existing := make(map[string]gslbalphav2.GSLBServiceStatus)
+	for _, gss := range gssList.Items {
+		existing[gss.Name] = gss
+	}
+
+	for gsName, health := range gsHealth {
+		name := gslbutils.GetGSStatusObjName(gsName)
+		gss, ok := existing[name]
+		delete(existing, name)
+		if !ok {
+			newGss := &gslbalphav2.GSLBServiceStatus{
+				ObjectMeta: metav1.ObjectMeta{
+					Name:      name,
+					Namespace: gslbutils.AVISystem,
+				},
+			}
+			created, err := gssClient.Create(newGss)
+			if err != nil {
+				gslbutils.Errf("gsName: %s, msg: error in creating the GSLBServiceStatus object: %s", gsName, err)
+				continue
+			}
+			gss = *created
+		} else if reflect.DeepEqual(gss.Status, health) {
+			continue
+		}
+		gss.Status = health
+		if _, err := gssClient.UpdateStatus(&gss); err != nil {
+			gslbutils.Errf("gsName: %s, msg: error in updating the GSLBServiceStatus object: %s", gsName, err)
+		}
+	}
+
+	// the GSes of the remaining objects don't exist anymore
+	for name := range existing {
+		if err := gssClient.Delete(name, &metav1.DeleteOptions{}); err != nil && !k8serrors.IsNotFound(err) {
+			gslbutils.Errf("name: %s, msg: error in deleting the GSLBServiceStatus object: %s", name, err)
+		}
+	}
+}
+
+// PublishGSHealth writes the health of the GSLB services to the GSLBServiceStatus objects and, if
+// enabled, to the GS health annotation of their member objects.
+func PublishGSHealth(gsHealth map[string]gslbalphav2.GSLBServiceHealth) {
+	publishGSLBServiceStatus(gsHealth)
+	if gslbutils.IsGSHealthAnnotationsEnabled() {
+		annotateGSHealth(gsHealth)
+	}
+}
+
+// EvaluateGSHealth is run periodically to fetch the runtime health of the GSLB services from the Avi
+// controller and publish it.
+func EvaluateGSHealth() {
+	if !gslbutils.IsAMKOLeader() {
+		return
+	}
+	gsHealth, err := avirest.GetGSHealth()
+	if err != nil {
+		gslbutils.Warnf("msg: couldn't fetch the GS health, err: %s", err)
+		return
+	}
+	PublishGSHealth(gsHealth)
+}
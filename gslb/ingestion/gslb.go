@@ -18,12 +18,15 @@ import (
 	"errors"
 	"flag"
 	"os"
+	"reflect"
 	"sort"
 	"strconv"
+	"strings"
 	"sync"
 	"time"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
 
 	gslbcs "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned"
@@ -35,16 +38,15 @@ import (
 	"k8s.io/apimachinery/pkg/util/runtime"
 	"k8s.io/client-go/kubernetes"
 	"k8s.io/client-go/rest"
-	restclient "k8s.io/client-go/rest"
 	"k8s.io/client-go/tools/cache"
 	"k8s.io/client-go/tools/clientcmd"
 	"k8s.io/client-go/tools/record"
 	"k8s.io/client-go/util/workqueue"
 
-	gslbalphav1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 	gslbscheme "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned/scheme"
 	gslbinformers "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/informers/externalversions"
-	gslblisters "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/listers/amko/v1alpha1"
+	gslblisters "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/listers/amko/v1alpha2"
 
 	corev1 "k8s.io/api/core/v1"
 	typedcorev1 "k8s.io/client-go/kubernetes/typed/core/v1"
@@ -53,6 +55,7 @@ import (
 
 	avirest "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
 	aviretry "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/retry"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/webhook"
 
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 	_ "k8s.io/client-go/plugin/pkg/client/auth/oidc"
@@ -70,14 +73,19 @@ const (
 	NoSecretMsg            = "error: secret object doesn't exist"
 	KubeConfigErr          = "error: provided kubeconfig has an error"
 	ControllerAPIErr       = "error: issue in connecting to the controller API"
-	ClusterHealthCheckErr  = "error: cluster healthcheck failed, "
+	ControllerReachableMsg = "connected to the GSLB leader controller"
+	ClustersReadyMsg       = "connected to all the member clusters"
+	ClustersNotReadyMsg    = "couldn't connect to the member clusters "
 )
 
 type kubeClusterDetails struct {
-	clusterName string
-	kubeconfig  string
-	kubeapi     string
-	informers   *utils.Informers
+	clusterName     string
+	secret          string
+	secretNamespace string
+	secretFormat    string
+	discovered      bool
+	kubeapi         string
+	informers       *utils.Informers
 }
 
 type K8SInformers struct {
@@ -140,7 +148,7 @@ func initFlags() {
 	gslbutils.Logf("master: %s, kubeconfig: %s, msg: %s", masterURL, kubeConfig, "fetched from cmd")
 }
 
-func getGSLBConfigChecksum(gc *gslbalphav1.GSLBConfig) uint32 {
+func getGSLBConfigChecksum(gc *gslbalphav2.GSLBConfig) uint32 {
 	var cksum uint32
 
 	gcSpec := gc.Spec.DeepCopy()
@@ -152,12 +160,21 @@ func getGSLBConfigChecksum(gc *gslbalphav1.GSLBConfig) uint32 {
 
 	cksum += utils.Hash(gcSpec.GSLBLeader.ControllerIP) + utils.Hash(gcSpec.GSLBLeader.ControllerVersion) +
 		utils.Hash(gcSpec.GSLBLeader.Credentials)
+	if endpoints := gcSpec.GSLBLeader.ControllerEndpoints; len(endpoints) > 0 {
+		cksum += utils.Hash(utils.Stringify(endpoints))
+	}
+	if tlsSpec := gcSpec.GSLBLeader.TLS; tlsSpec != nil {
+		cksum += utils.Hash(tlsSpec.CASecret) + utils.Hash(tlsSpec.ServerName) + utils.Hash(tlsSpec.Verification)
+	}
 	memberClusters := []string{}
 	for _, c := range gcSpec.MemberClusters {
 		memberClusters = append(memberClusters, c.ClusterContext)
 	}
 	sort.Strings(memberClusters)
 	cksum += utils.Hash(utils.Stringify(memberClusters)) + utils.Hash(strconv.Itoa(gcSpec.RefreshInterval))
+	if discovery := gcSpec.ClusterDiscovery; discovery != nil {
+		cksum += utils.Hash(discovery.Format) + utils.Hash(discovery.Namespace) + utils.Hash(utils.Stringify(discovery.Label))
+	}
 	return cksum
 }
 
@@ -166,7 +183,7 @@ func GetNewController(kubeclientset kubernetes.Interface, gslbclientset gslbcs.I
 	gslbInformerFactory gslbinformers.SharedInformerFactory,
 	AddGSLBConfigFunc GSLBConfigAddfn) *GSLBConfigController {
 
-	gslbInformer := gslbInformerFactory.Amko().V1alpha1().GSLBConfigs()
+	gslbInformer := gslbInformerFactory.Amko().V1alpha2().GSLBConfigs()
 	// Create event broadcaster
 	gslbscheme.AddToScheme(scheme.Scheme)
 	gslbutils.Logf("object: GSLBConfigController, msg: %s", "creating event broadcaster")
@@ -189,7 +206,7 @@ func GetNewController(kubeclientset kubernetes.Interface, gslbclientset gslbcs.I
 		AddFunc: AddGSLBConfigFunc,
 		// Update not allowed for the GSLB Cluster Config object
 		DeleteFunc: func(obj interface{}) {
-			gcObj := obj.(*gslbalphav1.GSLBConfig)
+			gcObj := obj.(*gslbalphav2.GSLBConfig)
 			// Cleanup everything
 			gcName, gcNS := gslbutils.GetGSLBConfigNameAndNS()
 			if gcName != gcObj.GetObjectMeta().GetName() || gcNS != gcObj.GetObjectMeta().GetNamespace() {
@@ -199,8 +216,8 @@ func GetNewController(kubeclientset kubernetes.Interface, gslbclientset gslbcs.I
 			gslbController.Cleanup()
 		},
 		UpdateFunc: func(oldObj interface{}, newObj interface{}) {
-			oldGc := oldObj.(*gslbalphav1.GSLBConfig)
-			newGc := newObj.(*gslbalphav1.GSLBConfig)
+			oldGc := oldObj.(*gslbalphav2.GSLBConfig)
+			newGc := newObj.(*gslbalphav2.GSLBConfig)
 			if oldGc.ResourceVersion == newGc.ResourceVersion {
 				return
 			}
@@ -211,6 +228,8 @@ func GetNewController(kubeclientset kubernetes.Interface, gslbclientset gslbcs.I
 				return
 			}
 
+			ApplyReconcileAnnotations(oldGc, newGc)
+
 			if oldGc.Spec.LogLevel != newGc.Spec.LogLevel {
 				gslbutils.Logf("log level changed")
 				if gslbutils.IsLogLevelValid(newGc.Spec.LogLevel) {
@@ -221,11 +240,17 @@ func GetNewController(kubeclientset kubernetes.Interface, gslbclientset gslbcs.I
 				}
 			}
 
+			if !reflect.DeepEqual(oldGc.Spec.GarbageCollection, newGc.Spec.GarbageCollection) {
+				gslbutils.Logf("garbage collection policy changed, applying the new policy")
+				gslbutils.SetGarbageCollectionPolicy(newGc.Spec.GarbageCollection)
+			}
+
 			if getGSLBConfigChecksum(oldGc) == getGSLBConfigChecksum(newGc) {
 				return
 			}
 			gslbutils.Warnf("an update has been made to the GSLBConfig object, AMKO needs a reboot to register the changes")
-			gslbutils.UpdateGSLBConfigStatus(EditRestartMsg)
+			gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionSynced, metav1.ConditionFalse, gslbalphav2.ReasonRestartRequired,
+				EditRestartMsg)
 		},
 	})
 	return gslbController
@@ -233,12 +258,12 @@ func GetNewController(kubeclientset kubernetes.Interface, gslbclientset gslbcs.I
 
 // CheckAcceptedGSLBConfigAndInitalize checks whether there's already an accepted GSLBConfig object that
 // exists. If yes, we take that and set as our GSLB configuration.
-func CheckAcceptedGSLBConfigAndInitalize(gcList *gslbalphav1.GSLBConfigList) (bool, error) {
+func CheckAcceptedGSLBConfigAndInitalize(gcList *gslbalphav2.GSLBConfigList) (bool, error) {
 	gcObjs := gcList.Items
 
-	var acceptedGC *gslbalphav1.GSLBConfig
+	var acceptedGC *gslbalphav2.GSLBConfig
 	for _, gcObj := range gcObjs {
-		if gcObj.Status.State == AcceptedMsg {
+		if gslbalphav2.IsConditionTrue(gcObj.Status.Conditions, gslbalphav2.ConditionAccepted) {
 			if acceptedGC == nil {
 				acceptedGC = &gcObj
 			} else {
@@ -262,7 +287,7 @@ func CheckAcceptedGSLBConfigAndInitalize(gcList *gslbalphav1.GSLBConfigList) (bo
 // 2. add a GSLBConfig object if only one GSLBConfig object (in non-accepted state).
 // 3. returns if there was an error on either of the above two conditions.
 func CheckGSLBConfigsAndInitialize() {
-	gcList, err := gslbutils.GlobalGslbClient.AmkoV1alpha1().GSLBConfigs(gslbutils.AVISystem).List(metav1.ListOptions{TimeoutSeconds: &informerTimeout})
+	gcList, err := gslbutils.GlobalGslbClient.AmkoV1alpha2().GSLBConfigs(gslbutils.AVISystem).List(metav1.ListOptions{TimeoutSeconds: &informerTimeout})
 	if err != nil {
 		gslbutils.Errf("ns: %s, error in listing the GSLBConfig objects, %s, %s", gslbutils.AVISystem,
 			err.Error(), "can't do a full sync")
@@ -278,28 +303,84 @@ func CheckGSLBConfigsAndInitialize() {
 	if err != nil || added {
 		return
 	}
+	InitializeGSLBConfigs(gcList)
+}
 
-	if len(gcList.Items) > 1 {
-		// more than one GC objects exist and none of them were already accepted, we panic
-		panic("more than one GSLBConfig objects in " + gslbutils.AVISystem + " exist, please add only one")
+// InitializeGSLBConfigs adds the oldest of the GSLBConfig objects in gcList, none of which were accepted.
+// The other objects are rejected as a GSLBConfig object already exists.
+func InitializeGSLBConfigs(gcList *gslbalphav2.GSLBConfigList) {
+	gcObjs := make([]*gslbalphav2.GSLBConfig, 0, len(gcList.Items))
+	for i := range gcList.Items {
+		gcObjs = append(gcObjs, &gcList.Items[i])
+	}
+	sort.Slice(gcObjs, func(i, j int) bool {
+		if !gcObjs[i].CreationTimestamp.Equal(&gcObjs[j].CreationTimestamp) {
+			return gcObjs[i].CreationTimestamp.Before(&gcObjs[j].CreationTimestamp)
+		}
+		return gcObjs[i].Name < gcObjs[j].Name
+	})
+	if len(gcObjs) == 0 {
+		return
+	}
+	for _, gcObj := range gcObjs[1:] {
+		gslbutils.Errf("ns: %s, gslbConfig: %s, msg: more than one GSLBConfig objects exist, only %s will be added",
+			gslbutils.AVISystem, gcObj.Name, gcObjs[0].Name)
+		rejectGSLBConfigObject(gcObj)
 	}
 
 	gslbutils.Logf("ns: %s, msg: found a GSLBConfig object", gslbutils.AVISystem)
-	AddGSLBConfigObject(&gcList.Items[0])
+	AddGSLBConfigObject(gcObjs[0])
 }
 
 // IsGSLBConfigValid returns true if the the GSLB Config object was created
-// in "avi-system" namespace.
+// in "avi-system" namespace, with a valid log level and unique member clusters.
 // TODO: Validate the controllers inside the config object
-func IsGSLBConfigValid(obj interface{}) (*gslbalphav1.GSLBConfig, error) {
-	config := obj.(*gslbalphav1.GSLBConfig)
-	if config.ObjectMeta.Namespace == gslbutils.AVISystem {
-		return config, nil
+func IsGSLBConfigValid(obj interface{}) (*gslbalphav2.GSLBConfig, error) {
+	config := obj.(*gslbalphav2.GSLBConfig)
+	if config.ObjectMeta.Namespace != gslbutils.AVISystem {
+		return nil, errors.New("invalid gslb config, namespace can only be avi-system")
+	}
+	if config.Spec.LogLevel != "" && !gslbutils.IsLogLevelValid(config.Spec.LogLevel) {
+		return nil, errors.New("invalid gslb config, log level " + config.Spec.LogLevel + " unrecognized")
+	}
+	clusters := make(map[string]bool)
+	for _, cluster := range config.Spec.MemberClusters {
+		if cluster.ClusterContext == "" {
+			return nil, errors.New("invalid gslb config, cluster context of a member cluster is empty")
+		}
+		if clusters[cluster.ClusterContext] {
+			return nil, errors.New("invalid gslb config, member cluster " + cluster.ClusterContext +
+				" is added more than once")
+		}
+		clusters[cluster.ClusterContext] = true
+	}
+	if policy := config.Spec.StaleMemberPolicy; policy != nil {
+		if policy.Action != "" && !gslbutils.IsStaleMemberActionValid(policy.Action) {
+			return nil, errors.New("invalid gslb config, stale member action " + policy.Action + " unrecognized")
+		}
+		if policy.GracePeriod < 0 {
+			return nil, errors.New("invalid gslb config, stale member grace period can't be negative")
+		}
+	}
+	if gcPolicy := config.Spec.GarbageCollection; gcPolicy != nil {
+		if gcPolicy.GracePeriod < 0 || gcPolicy.MaxDeletions < 0 {
+			return nil, errors.New("invalid gslb config, garbage collection grace period and max deletions can't be negative")
+		}
+	}
+	if tlsSpec := config.Spec.GSLBLeader.TLS; tlsSpec != nil {
+		if tlsSpec.Verification != "" && !gslbutils.IsTLSVerificationValid(tlsSpec.Verification) {
+			return nil, errors.New("invalid gslb config, TLS verification " + tlsSpec.Verification + " unrecognized")
+		}
 	}
-	if gslbutils.IsLogLevelValid(config.Spec.LogLevel) {
-		return config, nil
+	if discovery := config.Spec.ClusterDiscovery; discovery != nil {
+		if !IsClusterDiscoveryFormatValid(discovery.Format) {
+			return nil, errors.New("invalid gslb config, cluster discovery format " + discovery.Format + " unrecognized")
+		}
+		if _, err := clusterDiscoverySelector(discovery); err != nil {
+			return nil, errors.New("invalid gslb config, cluster discovery label is invalid, " + err.Error())
+		}
 	}
-	return nil, errors.New("invalid gslb config, namespace can only be avi-system")
+	return config, nil
 }
 
 func PublishChangeToRestLayer(gsKey interface{}, sharedQ *utils.WorkerQueue) {
@@ -314,6 +395,10 @@ func PublishChangeToRestLayer(gsKey interface{}, sharedQ *utils.WorkerQueue) {
 func CheckAndSetGslbLeader() error {
 	var leader bool
 	leader, err := avicache.IsAviSiteLeader()
+	if (err != nil || !leader) && retargetGslbLeader() {
+		resyncAfterRetarget()
+		leader, err = true, nil
+	}
 	if err != nil {
 		gslbutils.SetResyncRequired(true)
 		return err
@@ -359,7 +444,9 @@ func ResyncNodesToRestLayer() {
 	// re-sync is required anyway
 	gslbutils.Logf("Gslb leader controller re-sync required, will perform re-sync now")
 
+	start := time.Now()
 	nodes.PublishAllGraphKeys()
+	metrics.ObserveSince(metrics.FullSyncDuration, start, metrics.FullSyncPeriodic)
 	// once syncing is done, no further resync required
 	gslbutils.SetResyncRequired(false)
 }
@@ -411,27 +498,33 @@ func CacheRefreshRoutine() {
 	gslbutils.Logf("AVI Cache refresh done")
 }
 
-// GenerateKubeConfig reads the kubeconfig given through the environment variable
-// decodes it and then writes to a temporary file.
-func GenerateKubeConfig() error {
+// loadMembersKubeConfig reads the kubeconfig given through the GSLB_CONFIG environment variable,
+// it's only required for the member clusters which don't have a credentials secret. GSLB_CONFIG is
+// deprecated in favour of the credentials secrets, and will be removed in the next minor release.
+func loadMembersKubeConfig(memberClusters []gslbalphav2.MemberCluster) error {
 	membersKubeConfig = os.Getenv("GSLB_CONFIG")
-	if membersKubeConfig == "" {
-		utils.AviLog.Fatal("GSLB_CONFIG environment variable not set, exiting...")
-		return errors.New("GSLB_CONFIG environment variable not set, exiting")
+	var clustersWithoutSecret []string
+	for _, cluster := range memberClusters {
+		if cluster.Secret == "" {
+			clustersWithoutSecret = append(clustersWithoutSecret, cluster.ClusterContext)
+		}
 	}
-	f, err := os.Create(gslbutils.GSLBKubePath)
-	if err != nil {
-		return errors.New("Error in creating file: " + err.Error())
+	if len(clustersWithoutSecret) == 0 {
+		if membersKubeConfig != "" {
+			gslbutils.Warnf("msg: GSLB_CONFIG environment variable is deprecated and isn't used, all the member clusters have a credentials secret")
+		}
+		return nil
 	}
-
-	_, err = f.WriteString(membersKubeConfig)
-	if err != nil {
-		return errors.New("Error in writing to config file: " + err.Error())
+	if membersKubeConfig == "" {
+		return errors.New("cluster " + clustersWithoutSecret[0] +
+			" doesn't have a credentials secret and GSLB_CONFIG environment variable isn't set")
 	}
+	gslbutils.Warnf("clusters: %v, msg: GSLB_CONFIG environment variable is deprecated, set a credentials secret for these member clusters",
+		clustersWithoutSecret)
 	return nil
 }
 
-func parseControllerDetails(gc *gslbalphav1.GSLBConfig) error {
+func parseControllerDetails(gc *gslbalphav2.GSLBConfig) error {
 	// Read the gslb leader's credentials
 	leaderIP := gc.Spec.GSLBLeader.ControllerIP
 	leaderVersion := gc.Spec.GSLBLeader.ControllerVersion
@@ -439,12 +532,14 @@ func parseControllerDetails(gc *gslbalphav1.GSLBConfig) error {
 
 	if leaderIP == "" {
 		gslbutils.Errf("controllerIP: %s, msg: Invalid controller IP for the leader", leaderIP)
-		gslbutils.UpdateGSLBConfigStatus(InvalidConfigMsg + " with controller IP " + leaderIP)
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse, gslbalphav2.ReasonInvalidConfig,
+			InvalidConfigMsg+" with controller IP "+leaderIP)
 		return errors.New("invalid leader IP")
 	}
 	if leaderSecret == "" {
 		gslbutils.Errf("credentials: %s, msg: Invalid controller secret for leader", leaderSecret)
-		gslbutils.UpdateGSLBConfigStatus(InvalidConfigMsg + " with leaderSecret " + leaderSecret)
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse, gslbalphav2.ReasonInvalidConfig,
+			InvalidConfigMsg+" with leaderSecret "+leaderSecret)
 		return errors.New("invalid leader secret")
 	}
 
@@ -452,20 +547,61 @@ func parseControllerDetails(gc *gslbalphav1.GSLBConfig) error {
 	if err != nil || secretObj == nil {
 		gslbutils.Errf("Error in fetching leader controller secret %s in namespace %s, can't initialize controller",
 			leaderSecret, gslbutils.AVISystem)
-		gslbutils.UpdateGSLBConfigStatus(NoSecretMsg + " " + leaderSecret)
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse, gslbalphav2.ReasonSecretNotFound,
+			NoSecretMsg+" "+leaderSecret)
 		return errors.New("error in fetching leader secret")
 	}
-	ctrlUsername := secretObj.Data["username"]
-	ctrlPassword := secretObj.Data["password"]
-	gslbutils.NewAviControllerConfig(string(ctrlUsername), string(ctrlPassword), leaderIP, leaderVersion)
+	creds, err := ParseControllerCredentials(secretObj.Data)
+	if err != nil {
+		gslbutils.Errf("secret: %s, msg: invalid leader controller credentials, %s", leaderSecret, err.Error())
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse, gslbalphav2.ReasonInvalidConfig,
+			InvalidConfigMsg+" with leaderSecret "+leaderSecret+", "+err.Error())
+		return err
+	}
+	tlsCfg, err := ParseControllerTLS(gc.Spec.GSLBLeader.TLS)
+	if err != nil {
+		gslbutils.Errf("msg: can't read the TLS config of the leader controller, %s", err.Error())
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse, gslbalphav2.ReasonSecretNotFound,
+			InvalidConfigMsg+" with the TLS config, "+err.Error())
+		return err
+	}
+	setControllerSecret(leaderSecret)
+	gslbutils.NewAviControllerConfig(creds.Username, creds.Password, leaderIP, leaderVersion)
+	gslbutils.SetAviControllerCredentials(creds)
+	gslbutils.SetAviControllerTLS(tlsCfg)
+	gslbutils.SetAviControllerEndpoints(gc.Spec.GSLBLeader.ControllerEndpoints)
 
 	return nil
 }
 
+// rejectGSLBConfigObject sets the Accepted condition of a GSLBConfig object, other than the one which
+// was added, to false and publishes its status.
+func rejectGSLBConfigObject(gslbObj *gslbalphav2.GSLBConfig) {
+	gslbalphav2.SetCondition(&gslbObj.Status.Conditions, gslbalphav2.Condition{
+		Type:               gslbalphav2.ConditionAccepted,
+		Status:             metav1.ConditionFalse,
+		ObservedGeneration: gslbObj.Generation,
+		Reason:             gslbalphav2.ReasonAlreadyExists,
+		Message:            AlreadySetMsg,
+	})
+	gslbObj.Status.ObservedGeneration = gslbObj.Generation
+	if !gslbutils.PublishGSLBStatus.IsSet() {
+		return
+	}
+	_, updateErr := gslbutils.GlobalGslbClient.AmkoV1alpha2().GSLBConfigs(gslbObj.Namespace).Update(gslbObj)
+	if updateErr != nil {
+		gslbutils.Errf("error in updating the status field of GSLB Config object %s in %s namespace",
+			gslbObj.GetObjectMeta().GetName(), gslbObj.GetObjectMeta().GetNamespace())
+	}
+}
+
 // AddGSLBConfigObject parses the gslb config object and starts informers
 // for the member clusters.
 func AddGSLBConfigObject(obj interface{}) {
-	gslbObj := obj.(*gslbalphav1.GSLBConfig)
+	bootupLock.Lock()
+	defer bootupLock.Unlock()
+
+	gslbObj := obj.(*gslbalphav2.GSLBConfig)
 	existingName, existingNS := gslbutils.GetGSLBConfigNameAndNS()
 	if existingName == "" && existingNS == "" {
 		gslbutils.SetGSLBConfigObj(gslbObj)
@@ -478,24 +614,24 @@ func AddGSLBConfigObject(obj interface{}) {
 			return
 		}
 		// else, populate the status field with an error message
-		gslbutils.Errf("GSLB configuration is set already, can't change it. Delete and re-create the GSLB config object.")
-		gslbObj.Status.State = AlreadySetMsg
-		_, updateErr := gslbutils.GlobalGslbClient.AmkoV1alpha1().GSLBConfigs(gslbObj.Namespace).Update(gslbObj)
-		if updateErr != nil {
-			gslbutils.Errf("error in updating the status field of GSLB Config object %s in %s namespace",
-				gslbObj.GetObjectMeta().GetName(), gslbObj.GetObjectMeta().GetNamespace())
-		}
+		gslbutils.Errf("ns: %s, gslbConfig: %s, msg: GSLB configuration is set already, can't change it. Delete and re-create the GSLB config object.",
+			gslbObj.Namespace, gslbObj.Name)
+		rejectGSLBConfigObject(gslbObj)
 		return
 	}
 
 	gc, err := IsGSLBConfigValid(obj)
 	if err != nil {
-		gslbutils.Warnf("ns: %s, gslbConfig: %s, msg: %s, %s", gc.ObjectMeta.Namespace, gc.ObjectMeta.Name,
+		gslbutils.Warnf("ns: %s, gslbConfig: %s, msg: %s, %s", gslbObj.ObjectMeta.Namespace, gslbObj.ObjectMeta.Name,
 			"invalid format", err)
-		gslbutils.UpdateGSLBConfigStatus(InvalidConfigMsg + err.Error())
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse, gslbalphav2.ReasonInvalidConfig,
+			InvalidConfigMsg+err.Error())
 		return
 	}
 	utils.AviLog.SetLevel(gc.Spec.LogLevel)
+	gslbutils.SetStaleMemberPolicy(gc.Spec.StaleMemberPolicy)
+	gslbutils.SetGarbageCollectionPolicy(gc.Spec.GarbageCollection)
+	ApplyReconcileAnnotations(nil, gc)
 
 	gslbutils.Debugf("ns: %s, gslbConfig: %s, msg: %s", gc.ObjectMeta.Namespace, gc.ObjectMeta.Name,
 		"got an add event")
@@ -506,25 +642,41 @@ func AddGSLBConfigObject(obj interface{}) {
 		gslbutils.Errf("error while parsing controller details: %s", err.Error())
 		return
 	}
-	err = avicache.VerifyVersion()
+	// verify the certificate of the controller before the avi clients log in to it
+	err = checkControllerTLS(gslbutils.GetAviConfig().IPAddr)
+	if err == nil {
+		err = avicache.VerifyVersion()
+	}
 	if err != nil {
-		gslbutils.UpdateGSLBConfigStatus(ControllerAPIErr + ", " + err.Error())
+		msg := ControllerAPIErr + ", " + err.Error()
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionControllerReachable, metav1.ConditionFalse,
+			gslbalphav2.ReasonConnectionFailed, msg)
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse,
+			gslbalphav2.ReasonConnectionFailed, msg)
 		return
 	}
 
 	// check if the controller details provided are for a leader site
 	isLeader, err := avicache.IsAviSiteLeader()
+	if (err != nil || !isLeader) && retargetGslbLeader() {
+		isLeader, err = true, nil
+	}
 	if err != nil {
 		gslbutils.Errf("error fetching Gslb leader site details, %s", err.Error())
 		return
 	}
 	if !isLeader {
 		gslbutils.Errf("Controller details provided are not for a leader, returning")
-		gslbutils.UpdateGSLBConfigStatus(ControllerNotLeaderMsg)
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionControllerReachable, metav1.ConditionFalse,
+			gslbalphav2.ReasonNotLeader, ControllerNotLeaderMsg)
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse,
+			gslbalphav2.ReasonNotLeader, ControllerNotLeaderMsg)
 		gslbutils.SetControllerAsFollower()
 		return
 	}
 	gslbutils.SetControllerAsLeader()
+	gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionControllerReachable, metav1.ConditionTrue,
+		gslbalphav2.ReasonConnected, ControllerReachableMsg+" "+gslbutils.GetAviConfig().IPAddr)
 
 	cacheRefreshInterval := gc.Spec.RefreshInterval
 	if cacheRefreshInterval <= 0 {
@@ -532,25 +684,30 @@ func AddGSLBConfigObject(obj interface{}) {
 		cacheRefreshInterval = gslbutils.DefaultRefreshInterval
 	}
 	gslbutils.Debugf("Cache refresh interval: %d seconds", cacheRefreshInterval)
-	// Secret created with name: "gslb-config-secret" and environment variable to set is
-	// GSLB_CONFIG.
-	err = GenerateKubeConfig()
+	// The credentials of each member cluster are read from its secret, the kubeconfig in
+	// GSLB_CONFIG is used for the clusters without one.
+	err = loadMembersKubeConfig(gc.Spec.MemberClusters)
 	if err != nil {
-		utils.AviLog.Fatalf("Error in generating the kubeconfig file: %s", err.Error())
-		gslbutils.UpdateGSLBConfigStatus(KubeConfigErr + " " + err.Error())
+		gslbutils.Errf("error in loading the member cluster credentials: %s", err.Error())
+		msg := KubeConfigErr + " " + err.Error()
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionClustersReady, metav1.ConditionFalse,
+			gslbalphav2.ReasonKubeConfigError, msg)
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse,
+			gslbalphav2.ReasonKubeConfigError, msg)
 		return
 	}
 
-	aviCtrlList, err := InitializeGSLBClusters(gslbutils.GSLBKubePath, gc.Spec.MemberClusters)
-	if err != nil {
-		gslbutils.Errf("couldn't initialize the kubernetes/openshift clusters: %s, returning", err.Error())
-		gslbutils.UpdateGSLBConfigStatus(ClusterHealthCheckErr + err.Error())
-		// shutdown the api server to let k8s/openshift restart the pod back up
-		gslbutils.GetAmkoAPIServer().ShutDown()
-		return
-	}
+	// AMKO starts with the member clusters which are reachable, the others are retried by the
+	// cluster health checks
+	aviCtrlList := InitializeGSLBClusters(gc.Spec.MemberClusters, gc.Spec.ClusterDiscovery)
+
+	// Rebuild the clients of the controller and the member clusters when their credentials are
+	// rotated
+	startClusterSecretsInformer(gslbutils.GlobalKubeClient, stopCh)
 
-	gslbutils.UpdateGSLBConfigStatus(BootupSyncMsg)
+	setClustersReadyCondition()
+	gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionSynced, metav1.ConditionUnknown, gslbalphav2.ReasonSyncing,
+		BootupSyncMsg)
 
 	// TODO: Change the GSLBConfig CRD to take full sync interval as an input and fetch that
 	// value before going into full sync
@@ -561,24 +718,62 @@ func AddGSLBConfigObject(obj interface{}) {
 
 	bootupSync(aviCtrlList, newCache)
 
-	gslbutils.UpdateGSLBConfigStatus(BootupSyncEndMsg)
+	gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionSynced, metav1.ConditionTrue, gslbalphav2.ReasonSyncComplete,
+		BootupSyncEndMsg)
 
 	// Initalize a periodic worker running full sync
 	resyncNodesWorker := gslbutils.NewFullSyncThread(time.Duration(cacheRefreshInterval))
 	resyncNodesWorker.SyncFunction = ResyncNodesToRestLayer
 	go resyncNodesWorker.Run()
 
+	// Initialize a periodic worker which applies the scheduled traffic splits of the GDP object
+	trafficSplitWorker := gslbutils.NewFullSyncThread(time.Duration(gslbutils.TrafficSplitWindowInterval))
+	trafficSplitWorker.SyncFunction = EvaluateTrafficSplitWindows
+	go trafficSplitWorker.Run()
+
+	// Initialize a periodic worker which progresses the traffic shift of the GDP object based on the
+	// health of the target cluster
+	trafficShiftWorker := gslbutils.NewFullSyncThread(time.Duration(gslbutils.TrafficShiftInterval))
+	trafficShiftWorker.SyncFunction = EvaluateTrafficShift
+	go trafficShiftWorker.Run()
+
+	// Initialize a periodic worker which updates the drain status of the GDP object, after the GSLB
+	// services are written
+	drainStatusWorker := gslbutils.NewFullSyncThread(time.Duration(gslbutils.DrainStatusInterval))
+	drainStatusWorker.SyncFunction = avirest.UpdatePendingGDPDrainStatus
+	go drainStatusWorker.Run()
+
+	// Initialize a periodic worker which publishes the runtime health of the GSLB services
+	gsHealthWorker := gslbutils.NewFullSyncThread(time.Duration(gslbutils.GSHealthPollInterval))
+	gsHealthWorker.SyncFunction = EvaluateGSHealth
+	go gsHealthWorker.Run()
+
+	// Initialize a periodic worker which deletes the orphaned GSLB services and health monitors created
+	// by AMKO
+	gcWorker := gslbutils.NewFullSyncThread(time.Duration(gslbutils.GarbageCollectionInterval))
+	gcWorker.SyncFunction = RunGarbageCollection
+	go gcWorker.Run()
+
 	gcChan := gslbutils.GetGSLBConfigObjectChan()
 	*gcChan <- true
 
-	// Start the informers for the member controllers
-	for _, aviCtrl := range aviCtrlList {
-		aviCtrl.Start(stopCh)
-	}
+	// Initialize a periodic worker which health checks the member clusters, and brings their
+	// informers up or down as per their connectivity
+	clusterHealthWorker := gslbutils.NewFullSyncThread(time.Duration(gslbutils.ClusterHealthCheckInterval))
+	clusterHealthWorker.SyncFunction = EvaluateMemberClusterHealth
+	go clusterHealthWorker.Run()
+
+	// Start the informers for the member controllers, the health worker stops the informers of a
+	// cluster which becomes unreachable before its caches are synced
+	startMemberClusterInformers(stopCh)
+
+	// Add and remove the discovered member clusters along with their secrets
+	startClusterDiscoveryInformer(gslbutils.GlobalKubeClient, gc.Spec.ClusterDiscovery, stopCh)
 
 	// GSLB Configuration successfully done
 	gslbutils.SetGSLBConfig(true)
-	gslbutils.UpdateGSLBConfigStatus(AcceptedMsg)
+	gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionTrue, gslbalphav2.ReasonAccepted,
+		AcceptedMsg)
 
 	// Set the workers for the node/graph layer
 	StartGraphLayerWorkers()
@@ -589,7 +784,7 @@ var graphOnce sync.Once
 func StartGraphLayerWorkers() {
 	graphOnce.Do(func() {
 		ingestionSharedQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
-		ingestionSharedQueue.SyncFunc = nodes.SyncFromIngestionLayer
+		ingestionSharedQueue.SyncFunc = metrics.InstrumentSyncFunc(utils.ObjectIngestionLayer, nodes.SyncFromIngestionLayer)
 		ingestionSharedQueue.Run(stopCh, gslbutils.GetWaitGroupFromMap(gslbutils.WGIngestion))
 	})
 }
@@ -636,29 +831,51 @@ func Initialize() {
 	// required to publish the GDP status, the reason we need this is because, during unit tests, we don't
 	// traverse this path and hence we don't initialize GlobalGslbClient, and hence, we can't update the
 	// status of the GDP object. Always check this flag before updating the status.
-	gslbutils.PublishGDPStatus = true
-	gslbutils.PublishGSLBStatus = true
+	setStatusPublishing(true)
+
+	// Only the leader among the AMKO replicas writes to the controller and publishes the status
+	if err := startLeaderElection(kubeClient, stopCh); err != nil {
+		gslbutils.Errf("msg: %s, this replica stays a standby", err)
+	}
+
+	// In the dry run mode, the rest operations for the Avi controller are only planned
+	if os.Getenv("DRY_RUN") == "true" {
+		gslbutils.Logf("msg: running in the dry run mode, the Avi controller won't be updated")
+		gslbutils.SetDryRun(true)
+	}
+
+	// The member objects are annotated with their GS health only if enabled, as it needs the patch
+	// permission on them in the member clusters
+	if os.Getenv("GS_HEALTH_ANNOTATIONS") == "true" {
+		gslbutils.Logf("msg: the GS health annotation is enabled on the member objects")
+		gslbutils.SetGSHealthAnnotations(true)
+	}
 
 	SetInformerListTimeout(120)
 
+	// Validate the AMKO objects on their creation and update, if the webhook certificates are mounted
+	RegisterWebhookValidators()
+	webhook.StartServer()
+
 	ingestionQueueParams := utils.WorkerQueue{NumWorkers: utils.NumWorkersIngestion, WorkqueueName: utils.ObjectIngestionLayer}
 	graphQueueParams := utils.WorkerQueue{NumWorkers: gslbutils.NumRestWorkers, WorkqueueName: utils.GraphLayer}
 	slowRetryQParams := utils.WorkerQueue{NumWorkers: 1, WorkqueueName: gslbutils.SlowRetryQueue, SlowSyncTime: gslbutils.SlowSyncTime}
 	fastRetryQParams := utils.WorkerQueue{NumWorkers: 1, WorkqueueName: gslbutils.FastRetryQueue}
 
 	utils.SharedWorkQueue(ingestionQueueParams, graphQueueParams, slowRetryQParams, fastRetryQParams)
+	RegisterMetricsCollectors()
 
 	// Set workers for layer 3 (REST layer)
 	graphSharedQueue := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
-	graphSharedQueue.SyncFunc = avirest.SyncFromNodesLayer
+	graphSharedQueue.SyncFunc = metrics.InstrumentSyncFunc(utils.GraphLayer, avirest.SyncFromNodesLayer)
 	graphSharedQueue.Run(stopCh, gslbutils.GetWaitGroupFromMap(gslbutils.WGGraph))
 
 	// Set up retry Queue
 	slowRetryQueue := utils.SharedWorkQueue().GetQueueByName(gslbutils.SlowRetryQueue)
-	slowRetryQueue.SyncFunc = aviretry.SyncFromRetryLayer
+	slowRetryQueue.SyncFunc = metrics.InstrumentSyncFunc(gslbutils.SlowRetryQueue, aviretry.SyncFromRetryLayer)
 	slowRetryQueue.Run(stopCh, gslbutils.GetWaitGroupFromMap(gslbutils.WGSlowRetry))
 	fastRetryQueue := utils.SharedWorkQueue().GetQueueByName(gslbutils.FastRetryQueue)
-	fastRetryQueue.SyncFunc = aviretry.SyncFromRetryLayer
+	fastRetryQueue.SyncFunc = metrics.InstrumentSyncFunc(gslbutils.FastRetryQueue, aviretry.SyncFromRetryLayer)
 	fastRetryQueue.Run(stopCh, gslbutils.GetWaitGroupFromMap(gslbutils.WGFastRetry))
 
 	// kubeInformerFactory := kubeinformers.NewSharedInformerFactory(kubeClient, time.Second*30)
@@ -673,7 +890,7 @@ func Initialize() {
 	CheckGSLBConfigsAndInitialize()
 
 	// Start the informer for the GDP controller
-	gslbInformer := gslbInformerFactory.Amko().V1alpha1().GSLBConfigs()
+	gslbInformer := gslbInformerFactory.Amko().V1alpha2().GSLBConfigs()
 
 	go gslbInformer.Informer().Run(stopCh)
 
@@ -687,9 +904,15 @@ func Initialize() {
 		UpdateGDPObj, DeleteGDPObj)
 
 	// Start the informer for the GDP controller
-	gdpInformer := gslbInformerFactory.Amko().V1alpha1().GlobalDeploymentPolicies()
+	gdpInformer := gslbInformerFactory.Amko().V1alpha2().GlobalDeploymentPolicies()
 	go gdpInformer.Informer().Run(stopCh)
 
+	gslbhrCtrl := InitializeGSLBHostRuleController(kubeClient, gslbClient, gslbInformerFactory, AddGSLBHostRuleObj,
+		UpdateGSLBHostRuleObj, DeleteGSLBHostRuleObj)
+	gslbhrInformer := gslbInformerFactory.Amko().V1alpha2().GSLBHostRules()
+	go gslbhrInformer.Informer().Run(stopCh)
+	go gslbhrCtrl.Run(stopCh)
+
 	go RunGDPAndGSLBControllers(gslbController, gdpCtrl, stopCh)
 	<-stopCh
 	gslbutils.WaitForWorkersToExit()
@@ -705,16 +928,7 @@ func RunGDPAndGSLBControllers(gslbController *GSLBConfigController, gdpControlle
 	}
 }
 
-// BuildContextConfig builds the kubernetes/openshift context config
-func BuildContextConfig(kubeconfigPath, context string) (*restclient.Config, error) {
-	return clientcmd.NewNonInteractiveDeferredLoadingClientConfig(
-		&clientcmd.ClientConfigLoadingRules{ExplicitPath: kubeconfigPath},
-		&clientcmd.ConfigOverrides{
-			CurrentContext: context,
-		}).ClientConfig()
-}
-
-func InformersToRegister(oclient *oshiftclient.Clientset, kclient *kubernetes.Clientset, cname string) ([]string, error) {
+func InformersToRegister(oclient oshiftclient.Interface, kclient kubernetes.Interface, cname string) ([]string, error) {
 
 	allInformers := []string{}
 	_, err := kclient.CoreV1().Services("").List(metav1.ListOptions{TimeoutSeconds: &informerTimeout})
@@ -737,65 +951,79 @@ func InformersToRegister(oclient *oshiftclient.Clientset, kclient *kubernetes.Cl
 	return allInformers, nil
 }
 
-// InitializeGSLBClusters initializes the GSLB member clusters
-func InitializeGSLBClusters(membersKubeConfig string, memberClusters []gslbalphav1.MemberCluster) ([]*GSLBMemberController, error) {
-	clusterDetails := loadClusterAccess(membersKubeConfig, memberClusters)
-	clients := make(map[string]*kubernetes.Clientset)
-
-	informersArg := make(map[string]interface{})
+// InitializeGSLBClusters initializes the GSLB member clusters, along with the clusters found by the
+// cluster discovery, and returns the controllers of the clusters which are reachable. The
+// unreachable clusters are retried by the cluster health checks, and their informers are started
+// once they are reachable.
+func InitializeGSLBClusters(memberClusters []gslbalphav2.MemberCluster,
+	discovery *gslbalphav2.ClusterDiscovery) []*GSLBMemberController {
+	clusterDetails := append(loadClusterAccess(memberClusters), discoverClusters(discovery)...)
 
-	aviCtrlList := make([]*GSLBMemberController, 0)
+	memberClustersHealth.lock.Lock()
+	var clusters []*memberClusterHealth
 	for _, cluster := range clusterDetails {
-		gslbutils.Logf("cluster: %s, msg: %s", cluster.clusterName, "initializing")
-		cfg, err := BuildContextConfig(cluster.kubeconfig, cluster.clusterName)
-		if err != nil {
-			gslbutils.Warnf("cluster: %s, msg: %s, %s", cluster.clusterName, "error in connecting to kubernetes API",
-				err)
-			continue
-		} else {
-			gslbutils.Logf("cluster: %s, msg: %s", cluster.clusterName, "successfully connected to kubernetes API")
-		}
-		kubeClient, err := kubernetes.NewForConfig(cfg)
-		if err != nil {
-			gslbutils.Warnf("cluster: %s, msg: %s, %s", cluster.clusterName, "error in creating kubernetes clientset",
-				err)
-			continue
-		}
-		oshiftClient, err := oshiftclient.NewForConfig(cfg)
-		if err != nil {
-			gslbutils.Warnf("cluster: %s, msg: %s, %s", cluster.clusterName, "error in creating openshift clientset")
-			continue
-		}
-		informersArg[utils.INFORMERS_OPENSHIFT_CLIENT] = oshiftClient
-		informersArg[utils.INFORMERS_INSTANTIATE_ONCE] = false
-		registeredInformers, err := InformersToRegister(oshiftClient, kubeClient, cluster.clusterName)
-		if err != nil {
-			gslbutils.Errf("error in initializing informers")
-			return aviCtrlList, err
+		if m := initializeGSLBCluster(cluster); m != nil {
+			clusters = append(clusters, m)
 		}
-		if len(registeredInformers) == 0 {
-			gslbutils.Errf("No informers available for this cluster %s, returning", cluster.clusterName)
+	}
+	memberClustersHealth.lock.Unlock()
+
+	evaluateMemberClusters(clusters)
+
+	memberClustersHealth.lock.Lock()
+	defer memberClustersHealth.lock.Unlock()
+	aviCtrlList := make([]*GSLBMemberController, 0)
+	for _, m := range clusters {
+		if m.ctrl == nil {
 			continue
 		}
-		gslbutils.Logf("Informers for cluster %s: %v", cluster.clusterName, registeredInformers)
-		informerInstance := utils.NewInformers(utils.KubeClientIntf{
-			ClientSet: kubeClient},
-			registeredInformers,
-			informersArg)
-		clients[cluster.clusterName] = kubeClient
-		aviCtrl := GetGSLBMemberController(cluster.clusterName, informerInstance)
-		gslbutils.AddClusterContext(cluster.clusterName)
-		aviCtrl.SetupEventHandlers(K8SInformers{Cs: clients[cluster.clusterName]})
-		aviCtrlList = append(aviCtrlList, &aviCtrl)
-	}
-	return aviCtrlList, nil
+		gslbutils.Logf("cluster: %s, msg: %s", m.name, "successfully connected to kubernetes API")
+		aviCtrlList = append(aviCtrlList, m.ctrl)
+	}
+	return aviCtrlList
+}
+
+// initializeGSLBCluster adds a member cluster, which is to be health checked by the caller, returns nil
+// if a member cluster with the same name already exists. Must be called with the member clusters lock
+// held.
+func initializeGSLBCluster(cluster kubeClusterDetails) *memberClusterHealth {
+	if m := findMemberCluster(cluster.clusterName); m != nil {
+		gslbutils.Warnf("cluster: %s, secret: %s/%s, msg: a member cluster with the same name exists, ignoring",
+			cluster.clusterName, cluster.secretNamespace, cluster.secret)
+		return nil
+	}
+	gslbutils.Logf("cluster: %s, msg: %s", cluster.clusterName, "initializing")
+	// the cluster context is added even if the cluster is unreachable, so that the objects
+	// referring to it are still accepted
+	gslbutils.AddClusterContext(cluster.clusterName)
+	m := addMemberCluster(cluster.clusterName, cluster.secret, nil, nil)
+	m.secretNamespace = cluster.secretNamespace
+	m.secretFormat = cluster.secretFormat
+	m.discovered = cluster.discovered
+	return m
+}
+
+// setClustersReadyCondition sets the ClustersReady condition of the GSLBConfig object from the
+// Ready condition of each member cluster.
+func setClustersReadyCondition() {
+	notReady := gslbutils.NotReadyMemberClusters()
+	if len(notReady) != 0 {
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionClustersReady, metav1.ConditionFalse,
+			gslbalphav2.ReasonConnectionFailed, ClustersNotReadyMsg+strings.Join(notReady, ", "))
+		return
+	}
+	gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionClustersReady, metav1.ConditionTrue,
+		gslbalphav2.ReasonConnected, ClustersReadyMsg)
 }
 
-func loadClusterAccess(membersKubeConfig string, memberClusters []gslbalphav1.MemberCluster) []kubeClusterDetails {
+func loadClusterAccess(memberClusters []gslbalphav2.MemberCluster) []kubeClusterDetails {
 	var clusterDetails []kubeClusterDetails
 	for _, memberCluster := range memberClusters {
-		clusterDetails = append(clusterDetails, kubeClusterDetails{memberCluster.ClusterContext,
-			membersKubeConfig, "", nil})
+		clusterDetails = append(clusterDetails, kubeClusterDetails{
+			clusterName:     memberCluster.ClusterContext,
+			secret:          memberCluster.Secret,
+			secretNamespace: gslbutils.AVISystem,
+		})
 		gslbutils.Logf("cluster: %s, msg: %s", memberCluster.ClusterContext, "loaded cluster access")
 	}
 	return clusterDetails
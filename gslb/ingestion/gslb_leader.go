@@ -0,0 +1,66 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// retargetGslbLeader finds the GSLB leader among the controller endpoints of the GSLBConfig object,
+// and re-targets the avi clients to it. Returns true if AMKO is connected to the new leader.
+func retargetGslbLeader() bool {
+	ctrlCfg := gslbutils.GetAviConfig()
+	candidates := []string{}
+	for _, endpoint := range ctrlCfg.Endpoints {
+		if endpoint != ctrlCfg.IPAddr {
+			candidates = append(candidates, endpoint)
+		}
+	}
+	if len(candidates) == 0 {
+		return false
+	}
+	gslbutils.Logf("controllerIP: %s, msg: controller is not the GSLB leader, looking for the leader in %v",
+		ctrlCfg.IPAddr, candidates)
+	leaderIP, err := avicache.FindGslbLeaderEndpoint(candidates)
+	if err != nil {
+		gslbutils.Errf("msg: can't find the GSLB leader, %s", err.Error())
+		return false
+	}
+	if err := avicache.RetargetAviClients(leaderIP); err != nil {
+		gslbutils.Errf("controllerIP: %s, msg: can't re-target to the GSLB leader, %s", leaderIP, err.Error())
+		return false
+	}
+	gslbutils.Logf("controllerIP: %s, msg: re-targeted to the new GSLB leader", leaderIP)
+	setTLSVerifiedCondition(leaderIP, nil)
+	gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionControllerReachable, metav1.ConditionTrue,
+		gslbalphav2.ReasonConnected, ControllerReachableMsg+" "+leaderIP)
+	gslbutils.PublishGSLBConfigStatus()
+	return true
+}
+
+// resyncAfterRetarget refreshes the avi caches from the new GSLB leader, and publishes the keys of
+// the GSs which are in the new leader but don't have a graph. The keys of all the GS graphs are
+// published by the next full sync.
+func resyncAfterRetarget() {
+	avicache.GetAviHmCache().AviHmCacheReplace(avicache.PopulateHMCache(false))
+	gsCache := avicache.PopulateGSCache(false)
+	avicache.GetAviCache().AviCacheReplace(gsCache)
+	publishStaleAviObjKeys(gsCache)
+	gslbutils.SetResyncRequired(true)
+}
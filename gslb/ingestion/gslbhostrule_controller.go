@@ -0,0 +1,293 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	gslbcs "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned"
+	gslbinformers "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/informers/externalversions"
+	gslblisters "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/listers/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/util/runtime"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/cache"
+	"k8s.io/client-go/util/workqueue"
+)
+
+const (
+	GSLBHostRuleAccepted = gslbalphav2.ReasonAccepted
+	GSLBHostRuleRejected = gslbalphav2.ReasonRejected
+)
+
+// GSLBHostRuleAddDelfn is a type of function which handles an add or a delete of a
+// GSLBHostRule object.
+type GSLBHostRuleAddDelfn func(obj interface{}, k8swq []workqueue.RateLimitingInterface, numWorkers uint32)
+
+// GSLBHostRuleUpdfn is a type of function which handles an update of a GSLBHostRule object.
+type GSLBHostRuleUpdfn func(old, new interface{}, k8swq []workqueue.RateLimitingInterface, numWorkers uint32)
+
+// GSLBHostRuleController defines the members required to hold an instance of a controller
+// handling GSLBHostRule events.
+type GSLBHostRuleController struct {
+	kubeclientset kubernetes.Interface
+	gslbclientset gslbcs.Interface
+	gslbhrLister  gslblisters.GSLBHostRuleLister
+	gslbhrSynced  cache.InformerSynced
+}
+
+func (gslbhrController *GSLBHostRuleController) Run(stopCh <-chan struct{}) error {
+	defer runtime.HandleCrash()
+	gslbutils.Logf("object: GSLBHostRuleController, msg: %s", "starting the workers")
+	<-stopCh
+	gslbutils.Logf("object: GSLBHostRuleController, msg: %s", "shutting down the workers")
+	return nil
+}
+
+// hostRuleFqdnMap keeps track of the accepted GSLBHostRule for each fqdn, only one GSLBHostRule
+// can be accepted for a fqdn.
+type hostRuleFqdnMap struct {
+	fqdnToHostRule map[string]string
+	lock           sync.RWMutex
+}
+
+var hrFqdnMap = hostRuleFqdnMap{fqdnToHostRule: make(map[string]string)}
+
+func (hrMap *hostRuleFqdnMap) getOwner(fqdn string) (string, bool) {
+	hrMap.lock.RLock()
+	defer hrMap.lock.RUnlock()
+	owner, ok := hrMap.fqdnToHostRule[fqdn]
+	return owner, ok
+}
+
+func (hrMap *hostRuleFqdnMap) setOwner(fqdn, owner string) {
+	hrMap.lock.Lock()
+	defer hrMap.lock.Unlock()
+	hrMap.fqdnToHostRule[fqdn] = owner
+}
+
+func (hrMap *hostRuleFqdnMap) deleteOwner(fqdn string) {
+	hrMap.lock.Lock()
+	defer hrMap.lock.Unlock()
+	delete(hrMap.fqdnToHostRule, fqdn)
+}
+
+func getHostRuleKey(hr *gslbalphav2.GSLBHostRule) string {
+	return hr.ObjectMeta.Namespace + "/" + hr.ObjectMeta.Name
+}
+
+func isHostRuleOwner(hr *gslbalphav2.GSLBHostRule) bool {
+	owner, ok := hrFqdnMap.getOwner(hr.Spec.Fqdn)
+	return ok && owner == getHostRuleKey(hr)
+}
+
+// GSLBHostRuleSanityChecks verifies the fields of a GSLBHostRule object.
+func GSLBHostRuleSanityChecks(hr *gslbalphav2.GSLBHostRule) error {
+	if hr.ObjectMeta.Namespace != gslbutils.AVISystem {
+		return errors.New("GSLBHostRule objects are only accepted in the " + gslbutils.AVISystem + " namespace")
+	}
+	if hr.Spec.Fqdn == "" {
+		return errors.New("fqdn can't be empty")
+	}
+	if owner, ok := hrFqdnMap.getOwner(hr.Spec.Fqdn); ok && owner != getHostRuleKey(hr) {
+		return errors.New("GSLBHostRule " + owner + " already exists for fqdn " + hr.Spec.Fqdn)
+	}
+	for _, tp := range hr.Spec.TrafficSplit {
+		if !gslbutils.IsClusterContextPresent(tp.Cluster) {
+			return errors.New("cluster " + tp.Cluster + " in traffic policy not present in GSLBConfig")
+		}
+		if err := gslbutils.ValidTrafficWeight(int32(tp.Weight)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updateGSLBHostRuleStatus sets the Accepted condition of the GSLBHostRule object, the object is
+// rejected with the error message if err is not nil. The status is written on the latest version of
+// the object, hr is from the informer cache and isn't modified.
+func updateGSLBHostRuleStatus(hr *gslbalphav2.GSLBHostRule, err error) {
+	cond := gslbalphav2.Condition{
+		Type:               gslbalphav2.ConditionAccepted,
+		Status:             metav1.ConditionTrue,
+		ObservedGeneration: hr.Generation,
+		Reason:             GSLBHostRuleAccepted,
+	}
+	if err != nil {
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = GSLBHostRuleRejected
+		cond.Message = err.Error()
+	}
+	generation := hr.Generation
+	updateErr := gslbutils.UpdateGSLBHostRuleStatus(hr.Namespace, hr.Name, func(hr *gslbalphav2.GSLBHostRule) bool {
+		oldStatus := hr.Status.DeepCopy()
+		gslbalphav2.SetCondition(&hr.Status.Conditions, cond)
+		hr.Status.ObservedGeneration = generation
+		return !reflect.DeepEqual(oldStatus, &hr.Status)
+	})
+	if updateErr != nil {
+		gslbutils.Errf("ns: %s, gslbhostrule: %s, msg: error in updating the GSLBHostRule status: %s", hr.Namespace,
+			hr.Name, updateErr)
+	}
+}
+
+func getHostRuleClusterTraffic(hr *gslbalphav2.GSLBHostRule) []gslbutils.ClusterTraffic {
+	ts := []gslbutils.ClusterTraffic{}
+	for _, elem := range hr.Spec.TrafficSplit {
+		ts = append(ts, gslbutils.ClusterTraffic{
+			ClusterName: elem.Cluster,
+			Weight:      int32(elem.Weight),
+		})
+	}
+	return ts
+}
+
+// deleteHostRuleWeights removes the traffic weights and the GS adoption of an accepted GSLBHostRule
+// object and re-publishes the members of its GS.
+func deleteHostRuleWeights(hr *gslbalphav2.GSLBHostRule, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
+	if !isHostRuleOwner(hr) {
+		return
+	}
+	fqdn := hr.Spec.Fqdn
+	hrFqdnMap.deleteOwner(fqdn)
+	gslbutils.SetGSAdoption(fqdn, false)
+	if gslbutils.GetTrafficWeightOverrides().DeleteHostRuleWeights(fqdn) {
+		gslbutils.Logf("ns: %s, gslbhostrule: %s, fqdn: %s, msg: traffic weights removed", hr.ObjectMeta.Namespace,
+			hr.ObjectMeta.Name, fqdn)
+		WriteTrafficWeightChangedObjsToQueue(k8swq, numWorkers, SelectObjsForHostname(fqdn))
+	}
+}
+
+// AddGSLBHostRuleObj accepts a GSLBHostRule object and applies its traffic weights to the members of
+// the GS for its fqdn, and the adoption of an existing GS for the fqdn.
+func AddGSLBHostRuleObj(obj interface{}, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
+	hr, ok := obj.(*gslbalphav2.GSLBHostRule)
+	if !ok {
+		gslbutils.Errf("object added is not of type GSLBHostRule")
+		return
+	}
+	if err := GSLBHostRuleSanityChecks(hr); err != nil {
+		gslbutils.Errf("ns: %s, gslbhostrule: %s, msg: error in accepting GSLBHostRule object: %s",
+			hr.ObjectMeta.Namespace, hr.ObjectMeta.Name, err.Error())
+		updateGSLBHostRuleStatus(hr, err)
+		return
+	}
+	fqdn := hr.Spec.Fqdn
+	hrFqdnMap.setOwner(fqdn, getHostRuleKey(hr))
+	updateGSLBHostRuleStatus(hr, nil)
+	gslbutils.Logf("ns: %s, gslbhostrule: %s, fqdn: %s, msg: GSLBHostRule object accepted", hr.ObjectMeta.Namespace,
+		hr.ObjectMeta.Name, fqdn)
+
+	if gslbutils.GetTrafficWeightOverrides().SetHostRuleWeights(fqdn, getHostRuleClusterTraffic(hr)) {
+		gslbutils.Logf("ns: %s, gslbhostrule: %s, fqdn: %s, msg: traffic weights changed", hr.ObjectMeta.Namespace,
+			hr.ObjectMeta.Name, fqdn)
+		WriteTrafficWeightChangedObjsToQueue(k8swq, numWorkers, SelectObjsForHostname(fqdn))
+	}
+	if gslbutils.SetGSAdoption(fqdn, hr.Spec.AdoptExistingGS) {
+		gslbutils.Logf("ns: %s, gslbhostrule: %s, fqdn: %s, adopt: %v, msg: adoption of an existing GSLB service changed",
+			hr.ObjectMeta.Namespace, hr.ObjectMeta.Name, fqdn, hr.Spec.AdoptExistingGS)
+		// the GS graph for the fqdn may have failed to sync, as a GS already exists for it
+		if hr.Spec.AdoptExistingGS && k8swq != nil {
+			resyncKey(utils.ADMIN_NS + "/" + fqdn)
+		}
+	}
+}
+
+// UpdateGSLBHostRuleObj re-evaluates a GSLBHostRule object. If the fqdn changed, the weights for
+// the old fqdn are removed first.
+func UpdateGSLBHostRuleObj(old, new interface{}, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
+	oldHr := old.(*gslbalphav2.GSLBHostRule)
+	newHr := new.(*gslbalphav2.GSLBHostRule)
+	if oldHr.ObjectMeta.ResourceVersion == newHr.ObjectMeta.ResourceVersion {
+		return
+	}
+	if oldHr.Spec.Fqdn != newHr.Spec.Fqdn {
+		deleteHostRuleWeights(oldHr, k8swq, numWorkers)
+	}
+	if err := GSLBHostRuleSanityChecks(newHr); err != nil {
+		gslbutils.Errf("ns: %s, gslbhostrule: %s, msg: error in accepting GSLBHostRule object: %s",
+			newHr.ObjectMeta.Namespace, newHr.ObjectMeta.Name, err.Error())
+		deleteHostRuleWeights(newHr, k8swq, numWorkers)
+		updateGSLBHostRuleStatus(newHr, err)
+		return
+	}
+	AddGSLBHostRuleObj(newHr, k8swq, numWorkers)
+}
+
+// DeleteGSLBHostRuleObj removes the traffic weights of a GSLBHostRule object, if it was accepted.
+func DeleteGSLBHostRuleObj(obj interface{}, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
+	hr, ok := obj.(*gslbalphav2.GSLBHostRule)
+	if !ok {
+		gslbutils.Errf("object deleted is not of type GSLBHostRule")
+		return
+	}
+	gslbutils.Logf("ns: %s, gslbhostrule: %s, msg: deleted GSLBHostRule object", hr.ObjectMeta.Namespace,
+		hr.ObjectMeta.Name)
+	deleteHostRuleWeights(hr, k8swq, numWorkers)
+}
+
+// checkGSLBHostRulesAndInitialize applies the GSLBHostRule objects during the bootup sync.
+func checkGSLBHostRulesAndInitialize() {
+	hrList, err := gslbutils.GlobalGslbClient.AmkoV1alpha2().GSLBHostRules(gslbutils.AVISystem).List(metav1.ListOptions{})
+	if err != nil {
+		gslbutils.Errf("ns: %s, msg: error in fetching the GSLBHostRule objects, %s", gslbutils.AVISystem, err.Error())
+		return
+	}
+	for idx := range hrList.Items {
+		AddGSLBHostRuleObj(&hrList.Items[idx], nil, 0)
+	}
+}
+
+// InitializeGSLBHostRuleController handles initialization of a controller which handles
+// GSLBHostRule object events.
+func InitializeGSLBHostRuleController(kubeclientset kubernetes.Interface,
+	gslbclientset gslbcs.Interface,
+	gslbInformerFactory gslbinformers.SharedInformerFactory,
+	AddGSLBHostRuleFunc GSLBHostRuleAddDelfn, UpdateGSLBHostRuleFunc GSLBHostRuleUpdfn,
+	DeleteGSLBHostRuleFunc GSLBHostRuleAddDelfn) *GSLBHostRuleController {
+
+	gslbhrInformer := gslbInformerFactory.Amko().V1alpha2().GSLBHostRules()
+	k8sQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+	k8sWorkqueue := k8sQueue.Workqueue
+	numWorkers := k8sQueue.NumWorkers
+
+	gslbhrController := &GSLBHostRuleController{
+		kubeclientset: kubeclientset,
+		gslbclientset: gslbclientset,
+		gslbhrLister:  gslbhrInformer.Lister(),
+		gslbhrSynced:  gslbhrInformer.Informer().HasSynced,
+	}
+	gslbutils.Logf("object: GSLBHostRuleController, msg: %s", "setting up event handlers")
+	gslbhrInformer.Informer().AddEventHandler(cache.ResourceEventHandlerFuncs{
+		AddFunc: func(obj interface{}) {
+			AddGSLBHostRuleFunc(obj, k8sWorkqueue, numWorkers)
+		},
+		UpdateFunc: func(old, new interface{}) {
+			UpdateGSLBHostRuleFunc(old, new, k8sWorkqueue, numWorkers)
+		},
+		DeleteFunc: func(obj interface{}) {
+			DeleteGSLBHostRuleFunc(obj, k8sWorkqueue, numWorkers)
+		},
+	})
+
+	return gslbhrController
+}
@@ -0,0 +1,171 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"context"
+	"errors"
+	"os"
+	"sync"
+	"time"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/tools/leaderelection"
+	"k8s.io/client-go/tools/leaderelection/resourcelock"
+)
+
+const (
+	// LeaderElectionLeaseName is the name of the lease in avi-system held by the AMKO leader
+	LeaderElectionLeaseName = "amko-leader"
+	leaseDuration           = 15 * time.Second
+	renewDeadline           = 10 * time.Second
+	retryPeriod             = 2 * time.Second
+)
+
+// leaderElectionStopped is set once the leader election is stopped as AMKO is shutting down, the
+// lease is then released on purpose.
+var leaderElectionStopped gslbutils.StatusFlag
+
+// bootupLock is held while a GSLBConfig object is processed, so that a replica which becomes the
+// leader during the bootup sync reconciles only after the sync is done.
+var bootupLock sync.Mutex
+
+// setStatusPublishing enables or disables the status updates of the AMKO objects, only the leader
+// publishes them.
+func setStatusPublishing(enabled bool) {
+	gslbutils.PublishGDPStatus.Set(enabled)
+	gslbutils.PublishGSLBStatus.Set(enabled)
+	gslbutils.PublishGSLBHostRuleStatus.Set(enabled)
+	gslbutils.PublishGSLBServiceStatus.Set(enabled)
+}
+
+// LeaderElectionConfig returns the config to elect a leader among the AMKO replicas with the lease
+// LeaderElectionLeaseName in avi-system. The replica becomes the leader or a standby via the
+// callbacks.
+func LeaderElectionConfig(kubeClient kubernetes.Interface, identity string) leaderelection.LeaderElectionConfig {
+	return leaderelection.LeaderElectionConfig{
+		Lock: &resourcelock.LeaseLock{
+			LeaseMeta:  metav1.ObjectMeta{Namespace: gslbutils.AVISystem, Name: LeaderElectionLeaseName},
+			Client:     kubeClient.CoordinationV1(),
+			LockConfig: resourcelock.ResourceLockConfig{Identity: identity},
+		},
+		LeaseDuration:   leaseDuration,
+		RenewDeadline:   renewDeadline,
+		RetryPeriod:     retryPeriod,
+		ReleaseOnCancel: true,
+		Name:            LeaderElectionLeaseName,
+		Callbacks: leaderelection.LeaderCallbacks{
+			OnStartedLeading: func(ctx context.Context) {
+				gslbutils.Logf("identity: %s, msg: acquired the lease, started leading", identity)
+				BecomeLeader()
+			},
+			OnStoppedLeading: func() {
+				if leaderElectionStopped.IsSet() {
+					gslbutils.Logf("identity: %s, msg: released the lease, stopped leading", identity)
+					BecomeStandby()
+					return
+				}
+				// the rest operations of this replica may still be in flight, while another replica
+				// takes over, so the process exits and restarts as a standby
+				gslbutils.Errf("identity: %s, msg: lost the lease, stopped leading, exiting", identity)
+				BecomeStandby()
+				os.Exit(1)
+			},
+		},
+	}
+}
+
+// RunLeaderElection runs the leader election till stopCh is closed. The elector returns once this
+// replica stops leading, the callbacks of AMKO exit the process then, unless stopCh is closed, else
+// it's run again to acquire the lease as a standby. The lease is released once stopCh is closed.
+func RunLeaderElection(le *leaderelection.LeaderElector, stopCh <-chan struct{}) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-stopCh
+		leaderElectionStopped.Set(true)
+		cancel()
+	}()
+	for ctx.Err() == nil {
+		le.Run(ctx)
+	}
+}
+
+// startLeaderElection elects a leader among the AMKO replicas if LEADER_ELECTION is set to true,
+// else, this replica is the leader. The standby replicas run the informers and build the GS graphs
+// like the leader, but don't write to the Avi controller and don't publish the status of the AMKO
+// objects. If the leader election can't be set up, the replica stays a standby and an error is
+// returned.
+func startLeaderElection(kubeClient kubernetes.Interface, stopCh <-chan struct{}) error {
+	if os.Getenv("LEADER_ELECTION") != "true" {
+		gslbutils.SetAMKOLeader(true)
+		return nil
+	}
+	identity := os.Getenv("POD_NAME")
+	if identity == "" {
+		identity, _ = os.Hostname()
+	}
+	BecomeStandby()
+	le, err := leaderelection.NewLeaderElector(LeaderElectionConfig(kubeClient, identity))
+	if err != nil {
+		return errors.New("error in setting up the leader election: " + err.Error())
+	}
+	gslbutils.Logf("identity: %s, msg: leader election enabled, starting as a standby", identity)
+	go RunLeaderElection(le, stopCh)
+	return nil
+}
+
+// BecomeLeader makes this replica the AMKO leader. If the GSLBConfig object was already processed
+// as a standby, the Avi controller is reconciled with the warm state of this replica.
+func BecomeLeader() {
+	gslbutils.SetAMKOLeader(true)
+	setStatusPublishing(true)
+	go func() {
+		bootupLock.Lock()
+		defer bootupLock.Unlock()
+		// the bootup sync publishes all the keys, if it's yet to be done
+		if !gslbutils.IsGSLBConfigSet() {
+			return
+		}
+		ReconcileAsLeader()
+		gslbutils.PublishGSLBConfigStatus()
+	}()
+}
+
+// BecomeStandby stops this replica from writing to the Avi controller and from publishing the
+// status of the AMKO objects.
+func BecomeStandby() {
+	gslbutils.SetAMKOLeader(false)
+	setStatusPublishing(false)
+}
+
+// ReconcileAsLeader syncs the Avi controller with the GS graphs which were built while this replica
+// was a standby. The avi caches are refreshed, since the previous leader may have changed the
+// objects, the traffic shift continues from where the previous leader left it, and the keys of all
+// the GS graphs, along with the keys of the GSs without a graph, are published to the rest layer.
+func ReconcileAsLeader() {
+	gslbutils.Logf("msg: reconciling the avi controller with the GS graphs as the AMKO leader")
+	avicache.GetAviHmCache().AviHmCacheReplace(avicache.PopulateHMCache(false))
+	gsCache := avicache.PopulateGSCache(false)
+	avicache.GetAviCache().AviCacheReplace(gsCache)
+	resumeTrafficShiftFromStatus()
+	nodes.PublishAllGraphKeys()
+	publishStaleAviObjKeys(gsCache)
+}
@@ -18,11 +18,13 @@ import (
 	"fmt"
 	"sync"
 
+	filter "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gdp_filter"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
 
 	routev1 "github.com/openshift/api/route/v1"
+	"github.com/openshift/client-go/route/clientset/versioned/scheme"
 	containerutils "github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
 	corev1 "k8s.io/api/core/v1"
 	"k8s.io/apimachinery/pkg/util/runtime"
@@ -109,10 +111,15 @@ func (c *GSLBMemberController) SetupEventHandlers(k8sinfo K8SInformers) {
 	eventBroadcaster := record.NewBroadcaster()
 	eventBroadcaster.StartLogging(containerutils.AviLog.Infof)
 	eventBroadcaster.StartRecordingToSink(&typedcorev1.EventSinkImpl{Interface: cs.CoreV1().Events("")})
+	// the GDP filter decisions for the objects of the cluster are written as events on the objects
+	registerDecisionRecorder(c.name, eventBroadcaster.NewRecorder(scheme.Scheme,
+		corev1.EventSource{Component: "amko"}))
+	filter.SetDecisionHook(publishDecisionEvent)
 
 	k8sQueue := containerutils.SharedWorkQueue().GetQueueByName(containerutils.ObjectIngestionLayer)
 	c.workqueue = k8sQueue.Workqueue
 	numWorkers := k8sQueue.NumWorkers
+	registerMemberClients(c.name, c.informers)
 
 	if c.informers.IngressInformer != nil {
 		ingressEventHandler := AddIngressEventHandler(numWorkers, c)
@@ -163,7 +170,9 @@ func DeleteFromLBSvcStore(clusterSvcStore *gslbutils.ClusterStore,
 	clusterSvcStore.DeleteClusterNSObj(cname, svc.ObjectMeta.Namespace, svc.ObjectMeta.Name)
 }
 
-func (c *GSLBMemberController) Start(stopCh <-chan struct{}) {
+// Start runs the informers of the member cluster and waits for their caches to sync, returns false
+// if the caches couldn't be synced.
+func (c *GSLBMemberController) Start(stopCh <-chan struct{}) bool {
 	var cacheSyncParam []cache.InformerSynced
 
 	if c.informers.IngressInformer != nil {
@@ -192,9 +201,10 @@ func (c *GSLBMemberController) Start(stopCh <-chan struct{}) {
 
 	if !cache.WaitForCacheSync(stopCh, cacheSyncParam...) {
 		runtime.HandleError(fmt.Errorf("Timed out waiting for caches to sync"))
-	} else {
-		gslbutils.Logf("cluster: %s, msg: %s", c.name, "caches synced")
+		return false
 	}
+	gslbutils.Logf("cluster: %s, msg: %s", c.name, "caches synced")
+	return true
 }
 
 func (c *GSLBMemberController) Run(stopCh <-chan struct{}) error {
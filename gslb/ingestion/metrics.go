@@ -0,0 +1,108 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"sync"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+var metricsCollectorsOnce sync.Once
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
+
+func queueDepthSamples() []metrics.Sample {
+	var samples []metrics.Sample
+	for _, name := range []string{utils.ObjectIngestionLayer, utils.GraphLayer, gslbutils.SlowRetryQueue,
+		gslbutils.FastRetryQueue} {
+		queue := utils.SharedWorkQueue().GetQueueByName(name)
+		if queue == nil {
+			continue
+		}
+		depth := 0
+		for _, wq := range queue.Workqueue {
+			depth += wq.Len()
+		}
+		samples = append(samples, metrics.Sample{LabelValues: []string{name}, Value: float64(depth)})
+	}
+	return samples
+}
+
+func aviCacheSamples() []metrics.Sample {
+	return []metrics.Sample{
+		{LabelValues: []string{"GSLBService"}, Value: float64(avicache.GetAviCache().AviCacheLen())},
+		{LabelValues: []string{"HealthMonitor"}, Value: float64(avicache.GetAviHmCache().AviHmCacheLen())},
+	}
+}
+
+func memberObjectSamples() []metrics.Sample {
+	stores := []struct {
+		objType string
+		state   string
+		store   *gslbutils.ClusterStore
+	}{
+		{gslbutils.IngressType, metrics.ObjectAccepted, gslbutils.GetAcceptedIngressStore()},
+		{gslbutils.IngressType, metrics.ObjectRejected, gslbutils.GetRejectedIngressStore()},
+		{gslbutils.SvcType, metrics.ObjectAccepted, gslbutils.GetAcceptedLBSvcStore()},
+		{gslbutils.SvcType, metrics.ObjectRejected, gslbutils.GetRejectedLBSvcStore()},
+		{gslbutils.RouteType, metrics.ObjectAccepted, gslbutils.GetAcceptedRouteStore()},
+		{gslbutils.RouteType, metrics.ObjectRejected, gslbutils.GetRejectedRouteStore()},
+	}
+	var samples []metrics.Sample
+	for _, s := range stores {
+		for _, cname := range s.store.GetAllClusters() {
+			samples = append(samples, metrics.Sample{
+				LabelValues: []string{cname, s.objType, s.state},
+				Value:       float64(len(s.store.GetClusterNSObjects(cname))),
+			})
+		}
+	}
+	return samples
+}
+
+// RegisterMetricsCollectors registers the metrics which are collected from the queues, caches and
+// stores of AMKO when they are scraped. The workqueues must be set up before this is called.
+func RegisterMetricsCollectors() {
+	metricsCollectorsOnce.Do(func() {
+		metrics.Registry.MustRegister(
+			metrics.NewGaugeFunc("amko_workqueue_depth", "Number of keys waiting in the workqueue.",
+				queueDepthSamples, "queue"),
+			metrics.NewGaugeFunc("amko_avi_cache_objects",
+				"Number of Avi objects in the cache of AMKO.", aviCacheSamples, "object"),
+			metrics.NewGaugeFunc("amko_member_objects",
+				"Number of objects of the member clusters accepted or rejected by the GDP filters.",
+				memberObjectSamples, "cluster", "type", "state"),
+			metrics.NewGaugeFunc("amko_leader", "Whether this AMKO replica is the leader.",
+				func() []metrics.Sample {
+					return []metrics.Sample{{Value: boolToFloat(gslbutils.IsAMKOLeader())}}
+				}),
+			metrics.NewGaugeFunc("amko_avi_controller_leader",
+				"Whether the Avi controller configured for AMKO is the GSLB leader.",
+				func() []metrics.Sample {
+					return []metrics.Sample{{Value: boolToFloat(gslbutils.IsControllerLeader())}}
+				}),
+		)
+	})
+}
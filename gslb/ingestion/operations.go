@@ -0,0 +1,141 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"net/http"
+	"strings"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+const (
+	ReconciliationRoute = "/api/gslb/reconciliation"
+)
+
+// OperationsModel implements ApiModel and serves the state of the reconciliation of the Avi
+// controller. The operations which change it are requested with the annotations of the GSLBConfig
+// object, so the AMKO API server doesn't serve any route which changes the state of AMKO.
+type OperationsModel struct{}
+
+func (m *OperationsModel) InitModel() {}
+
+func (m *OperationsModel) ApiOperationMap() []models.OperationMap {
+	return []models.OperationMap{
+		{Route: ReconciliationRoute, Method: "GET", Handler: GetReconciliation},
+	}
+}
+
+// ReconciliationStatus is the state of the reconciliation of the Avi controller by this replica.
+type ReconciliationStatus struct {
+	Paused           bool `json:"paused"`
+	DryRun           bool `json:"dryRun"`
+	AMKOLeader       bool `json:"amkoLeader"`
+	ControllerLeader bool `json:"controllerLeader"`
+}
+
+func getReconciliationStatus() ReconciliationStatus {
+	return ReconciliationStatus{
+		Paused:           gslbutils.IsReconcilePaused(),
+		DryRun:           gslbutils.IsDryRun(),
+		AMKOLeader:       gslbutils.IsAMKOLeader(),
+		ControllerLeader: gslbutils.IsControllerLeader(),
+	}
+}
+
+// GetReconciliation responds with the state of the reconciliation.
+func GetReconciliation(w http.ResponseWriter, r *http.Request) {
+	utils.Respond(w, getReconciliationStatus())
+}
+
+// resyncKey publishes the key of a GS graph, of the form tenant/name, to the rest layer. Returns
+// false if there's no such graph.
+func resyncKey(key string) bool {
+	segments := strings.SplitN(key, "/", 2)
+	if len(segments) != 2 {
+		return false
+	}
+	found, _ := nodes.SharedAviGSGraphLister().Get(key)
+	if !found {
+		found, _ = nodes.SharedDeleteGSGraphLister().Get(key)
+	}
+	if !found {
+		return false
+	}
+	sharedQ := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
+	nodes.PublishKeyToRestLayer(segments[0], segments[1], key, sharedQ)
+	return true
+}
+
+// resync publishes the GS graph of key to the rest layer. All the GS graphs are resynced for
+// ResyncAll, as on the periodic resync.
+func resync(key string) {
+	if key == gslbutils.ResyncAll {
+		gslbutils.Logf("msg: resync of all the GS graphs requested")
+		gslbutils.SetResyncRequired(true)
+		// the leadership of the Avi controller is checked first, which may take a while
+		go ResyncNodesToRestLayer()
+		return
+	}
+	if !resyncKey(key) {
+		gslbutils.Warnf("key: %s, msg: GS graph not found for the resync, the key must be of the form tenant/name", key)
+		return
+	}
+	gslbutils.Logf("key: %s, msg: resync of the GS graph requested", key)
+}
+
+// resumeReconciliation resumes the reconciliation of the Avi controller, the keys of all the GS
+// graphs and of the GSs without a graph are published to the rest layer, since they might have
+// been skipped while it was paused.
+func resumeReconciliation() {
+	gslbutils.SetReconcilePaused(false)
+	gslbutils.Logf("msg: reconciliation of the avi controller resumed")
+	nodes.PublishAllGraphKeys()
+	publishStaleAviObjKeys(avicache.GetAviCache())
+}
+
+func resyncRequested(oldGc, newGc *gslbalphav2.GSLBConfig) bool {
+	key := newGc.Annotations[gslbutils.ResyncAnnotation]
+	if key == "" {
+		return false
+	}
+	return key != oldGc.Annotations[gslbutils.ResyncAnnotation] ||
+		newGc.Annotations[gslbutils.ResyncRequestedAtAnnotation] != oldGc.Annotations[gslbutils.ResyncRequestedAtAnnotation]
+}
+
+// ApplyReconcileAnnotations pauses or resumes the reconciliation of the Avi controller, and resyncs
+// the GS graphs, as requested by the annotations of the GSLBConfig object. The GS graphs are still
+// built while the reconciliation is paused, but the rest layer skips them. oldGc is nil when the
+// GSLBConfig object is added, only the paused state is applied then, as the bootup sync syncs all
+// the GS graphs.
+func ApplyReconcileAnnotations(oldGc, newGc *gslbalphav2.GSLBConfig) {
+	paused := newGc.Annotations[gslbutils.ReconcilePausedAnnotation] == "true"
+	if paused && !gslbutils.IsReconcilePaused() {
+		gslbutils.SetReconcilePaused(true)
+		gslbutils.Logf("ns: %s, gslbConfig: %s, msg: reconciliation of the avi controller paused",
+			newGc.Namespace, newGc.Name)
+	} else if !paused && gslbutils.IsReconcilePaused() {
+		resumeReconciliation()
+	}
+	if oldGc != nil && resyncRequested(oldGc, newGc) {
+		resync(newGc.Annotations[gslbutils.ResyncAnnotation])
+	}
+}
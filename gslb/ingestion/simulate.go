@@ -0,0 +1,134 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"sort"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	routev1 "github.com/openshift/api/route/v1"
+	corev1 "k8s.io/api/core/v1"
+	"k8s.io/api/networking/v1beta1"
+)
+
+// MemberClusterObjects are the objects of a member cluster, which are run through the filter by
+// SimulateFullSync.
+type MemberClusterObjects struct {
+	Cluster    string
+	Namespaces []*corev1.Namespace
+	Ingresses  []*v1beta1.Ingress
+	Services   []*corev1.Service
+	Routes     []*routev1.Route
+}
+
+// SimulateFullSync runs the GDP and GSLBHostRule objects, and the objects of the member clusters
+// through the same filters and GS graph construction as the bootup sync, without the member
+// clusters and the Avi controller. The member clusters of the GSLBConfig object are expected to be
+// added already, and no GDP object must have been accepted in the global filter. Returns the GS
+// graphs sorted by name, or an error if a GDP or a GSLBHostRule object was rejected. The GS graphs
+// aren't published to the rest layer.
+func SimulateFullSync(gdps []*gslbalphav2.GlobalDeploymentPolicy, hostRules []*gslbalphav2.GSLBHostRule,
+	clusters []MemberClusterObjects) ([]*nodes.AviGSObjectGraph, error) {
+	if filterExists(gslbutils.GetGlobalFilter()) {
+		return nil, errors.New("a GDP object is already accepted in the global filter")
+	}
+	for _, gdp := range gdps {
+		AddGDPObj(gdp, nil, 0)
+		if !gslbalphav2.IsConditionTrue(gdp.Status.Conditions, gslbalphav2.ConditionAccepted) {
+			return nil, errors.New("GDP " + gdp.Namespace + "/" + gdp.Name + " rejected: " +
+				getRejectionMessage(gdp.Status.Conditions))
+		}
+	}
+	// the status of a GSLBHostRule object is only written on the API server, so it's checked before
+	// it's added
+	for _, hr := range hostRules {
+		if err := GSLBHostRuleSanityChecks(hr); err != nil {
+			return nil, errors.New("GSLBHostRule " + hr.Namespace + "/" + hr.Name + " rejected: " + err.Error())
+		}
+		AddGSLBHostRuleObj(hr, nil, 0)
+	}
+
+	gf := gslbutils.GetGlobalFilter()
+	acceptedNSStore := gslbutils.GetAcceptedNSStore()
+	rejectedNSStore := gslbutils.GetRejectedNSStore()
+	acceptedIngStore := gslbutils.GetAcceptedIngressStore()
+	rejectedIngStore := gslbutils.GetRejectedIngressStore()
+	acceptedLBSvcStore := gslbutils.GetAcceptedLBSvcStore()
+	rejectedLBSvcStore := gslbutils.GetRejectedLBSvcStore()
+	acceptedRouteStore := gslbutils.GetAcceptedRouteStore()
+	rejectedRouteStore := gslbutils.GetRejectedRouteStore()
+
+	// the members are added to the GS graphs in the order of the clusters and their objects, so that
+	// the GS graphs are the same across the runs
+	var acceptedObjs []k8sobjects.MetaObject
+	getAccepted := func(store *gslbutils.ClusterStore, cname, ns, name string) {
+		if obj, ok := store.GetClusterNSObjectByName(cname, ns, name); ok {
+			acceptedObjs = append(acceptedObjs, obj.(k8sobjects.MetaObject))
+		}
+	}
+	for _, cluster := range clusters {
+		if !gf.IsClusterAllowed(cluster.Cluster) {
+			gslbutils.Logf("cluster %s is not allowed via GDP", cluster.Cluster)
+			continue
+		}
+		c := &GSLBMemberController{name: cluster.Cluster}
+		for _, ns := range cluster.Namespaces {
+			filterAndAddNS(c, ns, acceptedNSStore, rejectedNSStore)
+		}
+		for _, ing := range cluster.Ingresses {
+			ihms := k8sobjects.GetIngressHostMeta(ing, c.name)
+			filterAndAddIngressMeta(ihms, c, acceptedIngStore, rejectedIngStore, 0, true)
+			for _, ihm := range ihms {
+				getAccepted(acceptedIngStore, c.name, ihm.Namespace, ihm.ObjName)
+			}
+		}
+		for _, svc := range cluster.Services {
+			filterAndAddLBSvc(c, svc, acceptedLBSvcStore, rejectedLBSvcStore)
+			getAccepted(acceptedLBSvcStore, c.name, svc.Namespace, svc.Name)
+		}
+		for _, route := range cluster.Routes {
+			filterAndAddRoute(c, route, acceptedRouteStore, rejectedRouteStore)
+			getAccepted(acceptedRouteStore, c.name, route.Namespace, route.Name)
+		}
+	}
+
+	agl := nodes.NewAviGSGraphLister()
+	for _, metaObj := range acceptedObjs {
+		key := gslbutils.MultiClusterKey(gslbutils.ObjectAdd, metaObj.GetType(), metaObj.GetCluster(),
+			metaObj.GetNamespace(), metaObj.GetName())
+		nodes.AddOrUpdateGSMember(key, metaObj, agl)
+	}
+	modelNames := agl.GetAll()
+	sort.Strings(modelNames)
+	graphs := make([]*nodes.AviGSObjectGraph, 0, len(modelNames))
+	for _, modelName := range modelNames {
+		if _, graph := agl.Get(modelName); graph != nil {
+			graphs = append(graphs, graph.(*nodes.AviGSObjectGraph))
+		}
+	}
+	return graphs, nil
+}
+
+func getRejectionMessage(conditions []gslbalphav2.Condition) string {
+	if cond := gslbalphav2.FindCondition(conditions, gslbalphav2.ConditionAccepted); cond != nil {
+		return cond.Message
+	}
+	return "not accepted"
+}
@@ -0,0 +1,152 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"strings"
+	"time"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	"k8s.io/client-go/tools/cache"
+)
+
+// clusterObjKeepFn returns true if the object name in namespace ns of type objType has to be kept
+// in the object stores.
+type clusterObjKeepFn func(objType, ns, name string) bool
+
+// deleteClusterObjs deletes the objects of cluster cname which aren't kept by keepFn from the
+// object stores. DELETE keys are added for the accepted objects, so that their GS members are
+// removed.
+func deleteClusterObjs(cname string, keepFn clusterObjKeepFn) {
+	k8sQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+	for _, objType := range []string{gslbalphav2.RouteObj, gslbalphav2.LBSvcObj, gslbalphav2.IngressObj} {
+		objKey, acceptedObjStore, rejectedObjStore, err := GetObjTypeStores(objType)
+		if err != nil {
+			gslbutils.Errf("objtype error: %s", err.Error())
+			continue
+		}
+		for _, store := range []*gslbutils.ClusterStore{acceptedObjStore, rejectedObjStore} {
+			for _, nsObj := range store.GetClusterNSObjects(cname) {
+				nsObjName := strings.SplitN(nsObj, "/", 2)
+				if len(nsObjName) != 2 {
+					continue
+				}
+				ns, objName := nsObjName[0], nsObjName[1]
+				// the objects in the ingress stores are per ingress host, named as ingress/host
+				name := objName
+				if objType == gslbalphav2.IngressObj {
+					name = strings.SplitN(objName, "/", 2)[0]
+				}
+				if keepFn(objType, ns, name) {
+					continue
+				}
+				obj, ok := store.DeleteClusterNSObj(cname, ns, objName)
+				if !ok || store != acceptedObjStore {
+					continue
+				}
+				metaObj, ok := obj.(k8sobjects.MetaObject)
+				if !ok {
+					continue
+				}
+				publishKeyToGraphLayer(k8sQueue.NumWorkers, objKey, cname, ns, objName, gslbutils.ObjectDelete,
+					metaObj.GetHostname(), k8sQueue.Workqueue)
+			}
+		}
+	}
+}
+
+func informerKeys(informer cache.SharedIndexInformer) map[string]bool {
+	keys := make(map[string]bool)
+	for _, key := range informer.GetStore().ListKeys() {
+		keys[key] = true
+	}
+	return keys
+}
+
+// deleteRemovedClusterObjs deletes the objects of a member cluster which aren't present in the
+// caches of its informers, these objects were deleted while the cluster was disconnected. Must be
+// called once the caches are synced.
+func deleteRemovedClusterObjs(c *GSLBMemberController) {
+	present := make(map[string]map[string]bool)
+	if c.informers.RouteInformer != nil {
+		present[gslbalphav2.RouteObj] = informerKeys(c.informers.RouteInformer.Informer())
+	}
+	if c.informers.ServiceInformer != nil {
+		present[gslbalphav2.LBSvcObj] = informerKeys(c.informers.ServiceInformer.Informer())
+	}
+	if c.informers.IngressInformer != nil {
+		present[gslbalphav2.IngressObj] = informerKeys(c.informers.IngressInformer.Informer())
+	}
+	deleteClusterObjs(c.name, func(objType, ns, name string) bool {
+		return present[objType][ns+"/"+name]
+	})
+}
+
+// ApplyStaleMemberPolicy applies the stale member policy on the members of the clusters which are
+// disconnected for the grace period at time t. Returns true if the policy was applied on any
+// cluster.
+func ApplyStaleMemberPolicy(t time.Time) bool {
+	action, gracePeriod := gslbutils.GetStaleMemberPolicy()
+	if action == gslbutils.StaleMemberKeep {
+		return false
+	}
+	k8sQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+
+	memberClustersHealth.lock.Lock()
+	defer memberClustersHealth.lock.Unlock()
+
+	applied := false
+	for _, m := range memberClustersHealth.clusters {
+		if m.state != gslbalphav2.ClusterDisconnected || m.staleMembers != "" || t.Sub(m.disconnectedAt) < gracePeriod {
+			continue
+		}
+		gslbutils.Logf("cluster: %s, action: %s, disconnectedAt: %s, msg: applying the stale member policy",
+			m.name, action, m.disconnectedAt.Format(time.RFC3339))
+		switch action {
+		case gslbutils.StaleMemberDisable:
+			gslbutils.SetClusterMembersDisabled(m.name, true)
+			WriteTrafficWeightChangedObjsToQueue(k8sQueue.Workqueue, k8sQueue.NumWorkers,
+				SelectObjsForClusters([]string{m.name}))
+			m.staleMembers = gslbalphav2.StaleMembersDisabled
+		case gslbutils.StaleMemberRemove:
+			deleteClusterObjs(m.name, func(string, string, string) bool { return false })
+			m.staleMembers = gslbalphav2.StaleMembersRemoved
+		default:
+			continue
+		}
+		gslbutils.SetMemberClusterStaleMembers(m.name, m.staleMembers)
+		applied = true
+	}
+	return applied
+}
+
+// restoreStaleMembers undoes the stale member policy once the cluster is reachable again. The
+// informers of the cluster are restarted, which re-publish all the objects of the cluster, so the
+// disabled members are enabled and the removed members are added back.
+func (m *memberClusterHealth) restoreStaleMembers() {
+	if m.staleMembers == "" {
+		return
+	}
+	gslbutils.Logf("cluster: %s, staleMembers: %s, msg: cluster is reachable, restoring the stale members",
+		m.name, m.staleMembers)
+	gslbutils.SetClusterMembersDisabled(m.name, false)
+	m.staleMembers = ""
+	gslbutils.SetMemberClusterStaleMembers(m.name, "")
+}
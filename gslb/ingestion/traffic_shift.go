@@ -0,0 +1,160 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	avirest "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+
+	gdpalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// trafficShiftSanityChecks verifies that the clusters of the traffic shift are present and the steps
+// are valid percentages in increasing order.
+func trafficShiftSanityChecks(gdp *gdpalphav2.GlobalDeploymentPolicy) error {
+	shift := gdp.Spec.TrafficShift
+	if shift == nil {
+		return nil
+	}
+	if !gslbutils.IsClusterContextPresent(shift.SourceCluster) {
+		return errors.New("traffic shift source cluster " + shift.SourceCluster + " not present in GSLBConfig")
+	}
+	if !gslbutils.IsClusterContextPresent(shift.TargetCluster) {
+		return errors.New("traffic shift target cluster " + shift.TargetCluster + " not present in GSLBConfig")
+	}
+	if shift.SourceCluster == shift.TargetCluster {
+		return errors.New("traffic shift source and target clusters must be different")
+	}
+	if len(shift.Steps) == 0 {
+		return errors.New("traffic shift must have at least one step")
+	}
+	prev := 0
+	for _, step := range shift.Steps {
+		if step < 1 || step > 100 {
+			return errors.New("traffic shift step " + strconv.Itoa(step) + " should be between 1 and 100")
+		}
+		if step <= prev {
+			return errors.New("traffic shift steps must be in increasing order")
+		}
+		prev = step
+	}
+	if shift.SoakTime < 0 {
+		return errors.New("traffic shift soak time can't be negative")
+	}
+	return nil
+}
+
+// getTrafficShiftStatus returns the status of the traffic shift of the accepted GDP object, nil if
+// there's none.
+func getTrafficShiftStatus() *gdpalphav2.TrafficShiftStatus {
+	ts := gslbutils.GetGlobalFilter().GetTrafficShift()
+	if ts == nil {
+		return nil
+	}
+	return ts.GetStatus()
+}
+
+// publishTrafficShiftStatus writes the status of the traffic shift to the accepted GDP object.
+func publishTrafficShiftStatus(status *gdpalphav2.TrafficShiftStatus) {
+	name, ns := gslbutils.GetGDPObj()
+	if name == "" {
+		return
+	}
+	err := gslbutils.UpdateGDPStatus(ns, name, func(gdp *gdpalphav2.GlobalDeploymentPolicy) bool {
+		if reflect.DeepEqual(gdp.Status.TrafficShift, status) {
+			return false
+		}
+		gdp.Status.TrafficShift = status
+		return true
+	})
+	if err != nil {
+		gslbutils.Errf("ns: %s, gdp: %s, msg: error in updating the traffic shift status: %s", ns, name, err)
+	}
+}
+
+// ApplyTrafficShift moves the traffic shift ahead with the health of the target cluster at time t.
+// If the weights of the source and target clusters change, all the objects of these clusters are
+// re-published, as the members of a cluster with no traffic have to be disabled.
+func ApplyTrafficShift(t time.Time, health gslbutils.TargetHealth) {
+	changed, changedClusters, status := gslbutils.GetGlobalFilter().AdvanceTrafficShift(t, health)
+	if !changed {
+		return
+	}
+	gslbutils.Logf("step: %d, targetPercent: %d, state: %s, msg: traffic shift changed", status.Step,
+		status.TargetPercent, status.State)
+	if len(changedClusters) != 0 {
+		k8sQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+		WriteTrafficWeightChangedObjsToQueue(k8sQueue.Workqueue, k8sQueue.NumWorkers,
+			SelectObjsForClusters(changedClusters))
+	}
+	publishTrafficShiftStatus(status)
+}
+
+// EvaluateTrafficShift is run periodically to fetch the health of the target cluster's members
+// from the Avi controller and progress the traffic shift. Only the leader progresses the shift, the
+// standby replicas pick it up from the GDP status once they become the leader.
+func EvaluateTrafficShift() {
+	if !gslbutils.IsAMKOLeader() {
+		return
+	}
+	ts := gslbutils.GetGlobalFilter().GetTrafficShift()
+	if ts == nil || ts.State != gslbutils.TrafficShiftProgressing {
+		return
+	}
+	health, err := avirest.GetClusterMembersHealth(ts.TargetCluster)
+	if err != nil {
+		gslbutils.Warnf("cluster: %s, msg: couldn't fetch the health of the traffic shift target, err: %s",
+			ts.TargetCluster, err)
+		return
+	}
+	ApplyTrafficShift(time.Now(), health)
+}
+
+// resumeTrafficShiftFromStatus continues the traffic shift from the status of the accepted GDP
+// object, which the previous leader may have moved ahead while this replica was a standby. If the
+// shift changed, the objects of its clusters are re-published.
+func resumeTrafficShiftFromStatus() {
+	name, ns := gslbutils.GetGDPObj()
+	oldStatus := getTrafficShiftStatus()
+	if name == "" || oldStatus == nil {
+		return
+	}
+	gdp, err := gslbutils.GlobalGslbClient.AmkoV1alpha2().GlobalDeploymentPolicies(ns).Get(name, metav1.GetOptions{})
+	if err != nil {
+		gslbutils.Errf("ns: %s, gdp: %s, msg: couldn't fetch the GDP object to resume the traffic shift: %s", ns,
+			name, err)
+		return
+	}
+	gf := gslbutils.GetGlobalFilter()
+	gf.ResumeTrafficShift(gdp.Status.TrafficShift)
+	status := getTrafficShiftStatus()
+	if reflect.DeepEqual(oldStatus, status) {
+		return
+	}
+	ts := gf.GetTrafficShift()
+	gslbutils.Logf("step: %d, targetPercent: %d, state: %s, msg: traffic shift resumed from the GDP status",
+		status.Step, status.TargetPercent, status.State)
+	k8sQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+	WriteTrafficWeightChangedObjsToQueue(k8sQueue.Workqueue, k8sQueue.NumWorkers,
+		SelectObjsForClusters([]string{ts.SourceCluster, ts.TargetCluster}))
+}
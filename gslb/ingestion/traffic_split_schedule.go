@@ -0,0 +1,102 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"time"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	gdpalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+func trafficSplitWindowsOverlap(a, b gdpalphav2.ScheduledTrafficSplit) bool {
+	return a.Start.Time.Before(b.End.Time) && b.Start.Time.Before(a.End.Time)
+}
+
+// scheduledTrafficSplitSanityChecks verifies that the windows are named uniquely, don't overlap
+// and have valid traffic splits.
+func scheduledTrafficSplitSanityChecks(gdp *gdpalphav2.GlobalDeploymentPolicy) error {
+	windows := gdp.Spec.ScheduledTrafficSplit
+	for idx, sts := range windows {
+		if sts.Name == "" {
+			return errors.New("name is missing for a scheduled traffic split")
+		}
+		if !sts.End.Time.After(sts.Start.Time) {
+			return errors.New("end must be after start for scheduled traffic split " + sts.Name)
+		}
+		for _, tp := range sts.TrafficSplit {
+			if !gslbutils.IsClusterContextPresent(tp.Cluster) {
+				return errors.New("cluster " + tp.Cluster + " in scheduled traffic split " + sts.Name +
+					" not present in GSLBConfig")
+			}
+			if err := gslbutils.ValidTrafficWeight(int32(tp.Weight)); err != nil {
+				return errors.New(err.Error() + " for scheduled traffic split " + sts.Name)
+			}
+		}
+		for _, other := range windows[idx+1:] {
+			if other.Name == sts.Name {
+				return errors.New("scheduled traffic split " + sts.Name + " is defined more than once")
+			}
+			if trafficSplitWindowsOverlap(sts, other) {
+				return errors.New("scheduled traffic splits " + sts.Name + " and " + other.Name + " overlap")
+			}
+		}
+	}
+	return nil
+}
+
+// publishActiveTrafficSplitWindow writes the name of the active traffic split window to the status
+// of the accepted GDP object.
+func publishActiveTrafficSplitWindow(window string) {
+	name, ns := gslbutils.GetGDPObj()
+	if name == "" {
+		return
+	}
+	err := gslbutils.UpdateGDPStatus(ns, name, func(gdp *gdpalphav2.GlobalDeploymentPolicy) bool {
+		if gdp.Status.ActiveTrafficSplitWindow == window {
+			return false
+		}
+		gdp.Status.ActiveTrafficSplitWindow = window
+		return true
+	})
+	if err != nil {
+		gslbutils.Errf("ns: %s, gdp: %s, msg: error in updating the active traffic split window: %s", ns, name, err)
+	}
+}
+
+// ApplyTrafficSplitWindows switches the global filter to the traffic split window active at time
+// t. If the weights of any of the clusters change, the objects of those clusters are re-published.
+func ApplyTrafficSplitWindows(t time.Time) {
+	changed, changedClusters, window := gslbutils.GetGlobalFilter().UpdateActiveTrafficSplitWindow(t)
+	if !changed {
+		return
+	}
+	gslbutils.Logf("window: %s, clusters: %v, msg: active traffic split window changed", window, changedClusters)
+	if len(changedClusters) != 0 {
+		k8sQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+		WriteTrafficWeightChangedObjsToQueue(k8sQueue.Workqueue, k8sQueue.NumWorkers,
+			SelectObjsForGDPClusters(changedClusters))
+	}
+	publishActiveTrafficSplitWindow(window)
+}
+
+// EvaluateTrafficSplitWindows is run periodically to apply the scheduled traffic splits.
+func EvaluateTrafficSplitWindows() {
+	ApplyTrafficSplitWindows(time.Now())
+}
@@ -0,0 +1,239 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"sync"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+
+	gdpalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	"k8s.io/client-go/util/workqueue"
+)
+
+// TrafficWeightSelectorFn decides whether an accepted object is affected by a change in traffic
+// weights.
+type TrafficWeightSelectorFn func(metaObj k8sobjects.MetaObject) bool
+
+// weightResolvedFrom returns true if the weight of the object is now resolved from a source with a
+// priority equal to or lower than src. If a higher priority source defines the weight for this
+// object, a change in src doesn't affect it.
+func weightResolvedFrom(metaObj k8sobjects.MetaObject, src gslbutils.TrafficWeightSource) bool {
+	_, resolvedSrc := gslbutils.ResolveTrafficWeight(metaObj.GetTrafficWeight(), metaObj.GetHostname(),
+		metaObj.GetNamespace(), metaObj.GetCluster())
+	return resolvedSrc >= src
+}
+
+// SelectObjsForGDPClusters selects the objects belonging to clusters whose weights changed in the
+// GDP object.
+func SelectObjsForGDPClusters(clusters []string) TrafficWeightSelectorFn {
+	return func(metaObj k8sobjects.MetaObject) bool {
+		return gslbutils.PresentInList(metaObj.GetCluster(), clusters) &&
+			weightResolvedFrom(metaObj, gslbutils.WeightFromGDP)
+	}
+}
+
+// SelectObjsForClusters selects all the objects belonging to the clusters.
+func SelectObjsForClusters(clusters []string) TrafficWeightSelectorFn {
+	return func(metaObj k8sobjects.MetaObject) bool {
+		return gslbutils.PresentInList(metaObj.GetCluster(), clusters)
+	}
+}
+
+// SelectObjsForNamespace selects the objects of namespace ns across all the clusters.
+func SelectObjsForNamespace(ns string) TrafficWeightSelectorFn {
+	return func(metaObj k8sobjects.MetaObject) bool {
+		return metaObj.GetNamespace() == ns && weightResolvedFrom(metaObj, gslbutils.WeightFromNamespace)
+	}
+}
+
+// SelectObjsForClusterNamespace selects the objects of namespace ns in cluster cname.
+func SelectObjsForClusterNamespace(cname, ns string) TrafficWeightSelectorFn {
+	return func(metaObj k8sobjects.MetaObject) bool {
+		return metaObj.GetCluster() == cname && metaObj.GetNamespace() == ns &&
+			weightResolvedFrom(metaObj, gslbutils.WeightFromNamespace)
+	}
+}
+
+// SelectObjsForHostname selects the objects which are members of the GS for hostname.
+func SelectObjsForHostname(hostname string) TrafficWeightSelectorFn {
+	return func(metaObj k8sobjects.MetaObject) bool {
+		return metaObj.GetHostname() == hostname && weightResolvedFrom(metaObj, gslbutils.WeightFromHostRule)
+	}
+}
+
+func writeTrafficWeightChangedObjToQueue(objType string, k8swq []workqueue.RateLimitingInterface, numWorkers uint32,
+	selectFn TrafficWeightSelectorFn) {
+
+	objKey, acceptedObjStore, _, err := GetObjTypeStores(objType)
+	if err != nil {
+		gslbutils.Errf("objtype error: %s", err.Error())
+		return
+	}
+	if acceptedObjStore == nil {
+		return
+	}
+	for _, objName := range acceptedObjStore.GetAllClusterNSObjects() {
+		cname, ns, sname, err := splitName(objType, objName)
+		if err != nil {
+			gslbutils.Errf("msg: couldn't split the key: %s, error, %s", objName, err)
+			continue
+		}
+		obj, ok := acceptedObjStore.GetClusterNSObjectByName(cname, ns, sname)
+		if !ok {
+			continue
+		}
+		metaObj, ok := obj.(k8sobjects.MetaObject)
+		if !ok || !selectFn(metaObj) {
+			continue
+		}
+		bkt := utils.Bkt(ns, numWorkers)
+		key := gslbutils.MultiClusterKey(gslbutils.ObjectUpdate, objKey, cname, ns, sname)
+		k8swq[bkt].AddRateLimited(key)
+		gslbutils.Logf("cluster: %s, ns: %s, objtype: %s, name: %s, key: %s, msg: traffic weight changed, added key",
+			cname, ns, objType, sname, key)
+	}
+}
+
+// WriteTrafficWeightChangedObjsToQueue adds UPDATE keys only for the accepted objects selected by
+// selectFn, so that only the affected GS members are re-evaluated in the nodes layer.
+func WriteTrafficWeightChangedObjsToQueue(k8swq []workqueue.RateLimitingInterface, numWorkers uint32,
+	selectFn TrafficWeightSelectorFn) {
+	// for bootup sync, k8swq will be nil, the weights will be applied when the models are generated
+	if k8swq == nil {
+		return
+	}
+	writeTrafficWeightChangedObjToQueue(gdpalphav2.RouteObj, k8swq, numWorkers, selectFn)
+	writeTrafficWeightChangedObjToQueue(gdpalphav2.LBSvcObj, k8swq, numWorkers, selectFn)
+	writeTrafficWeightChangedObjToQueue(gdpalphav2.IngressObj, k8swq, numWorkers, selectFn)
+}
+
+// updateNSTrafficWeight records the traffic weight annotation of a namespace and re-publishes
+// the objects of this namespace if the weight changed.
+func updateNSTrafficWeight(nsMeta k8sobjects.NSMeta, k8swq []workqueue.RateLimitingInterface, numWorkers uint32) {
+	if !gslbutils.GetTrafficWeightOverrides().SetNSAnnotationWeight(nsMeta.Cluster, nsMeta.Name, nsMeta.TrafficWeight) {
+		return
+	}
+	gslbutils.Logf("cluster: %s, ns: %s, weight: %d, msg: namespace traffic weight changed", nsMeta.Cluster,
+		nsMeta.Name, nsMeta.TrafficWeight)
+	WriteTrafficWeightChangedObjsToQueue(k8swq, numWorkers, SelectObjsForClusterNamespace(nsMeta.Cluster, nsMeta.Name))
+}
+
+func getGDPClusterTraffic(gdp *gdpalphav2.GlobalDeploymentPolicy) []gslbutils.ClusterTraffic {
+	ts := []gslbutils.ClusterTraffic{}
+	for _, elem := range gdp.Spec.TrafficSplit {
+		ts = append(ts, gslbutils.ClusterTraffic{
+			ClusterName: elem.Cluster,
+			Weight:      int32(elem.Weight),
+		})
+	}
+	return ts
+}
+
+// nsGDPMap keeps track of the accepted GDP object for each namespace other than AVISystem, only one
+// GDP object can override the traffic weights of a namespace.
+type nsGDPMap struct {
+	nsToGDP map[string]string
+	lock    sync.RWMutex
+}
+
+var nsGDPOwners = nsGDPMap{nsToGDP: make(map[string]string)}
+
+func (gdpMap *nsGDPMap) getOwner(ns string) (string, bool) {
+	gdpMap.lock.RLock()
+	defer gdpMap.lock.RUnlock()
+	owner, ok := gdpMap.nsToGDP[ns]
+	return owner, ok
+}
+
+func (gdpMap *nsGDPMap) setOwner(ns, owner string) {
+	gdpMap.lock.Lock()
+	defer gdpMap.lock.Unlock()
+	gdpMap.nsToGDP[ns] = owner
+}
+
+func (gdpMap *nsGDPMap) deleteOwner(ns string) {
+	gdpMap.lock.Lock()
+	defer gdpMap.lock.Unlock()
+	delete(gdpMap.nsToGDP, ns)
+}
+
+func getGDPKey(gdp *gdpalphav2.GlobalDeploymentPolicy) string {
+	return gdp.ObjectMeta.Namespace + "/" + gdp.ObjectMeta.Name
+}
+
+func isNSGDPOwner(gdp *gdpalphav2.GlobalDeploymentPolicy) bool {
+	owner, ok := nsGDPOwners.getOwner(gdp.ObjectMeta.Namespace)
+	return ok && owner == getGDPKey(gdp)
+}
+
+// nsGDPExists returns an error if a GDP object other than gdp is already accepted for the namespace
+// of gdp.
+func nsGDPExists(gdp *gdpalphav2.GlobalDeploymentPolicy) error {
+	if owner, ok := nsGDPOwners.getOwner(gdp.ObjectMeta.Namespace); ok && owner != getGDPKey(gdp) {
+		return errors.New("GDP object " + owner + " already exists for namespace " + gdp.ObjectMeta.Namespace)
+	}
+	return nil
+}
+
+// addOrUpdateNSGDPWeights handles a GDP object in a namespace other than AVISystem. Such a GDP
+// object only overrides the traffic weights for the objects in its namespace, all the other
+// fields are ignored. Only the first GDP object accepted for a namespace is applied, the others
+// are rejected till the accepted one is deleted.
+func addOrUpdateNSGDPWeights(gdp *gdpalphav2.GlobalDeploymentPolicy, k8swq []workqueue.RateLimitingInterface,
+	numWorkers uint32) {
+	ns := gdp.ObjectMeta.Namespace
+	if err := nsGDPExists(gdp); err != nil {
+		gslbutils.Errf("ns: %s, gdp: %s, msg: error in accepting namespace GDP object: %s", ns,
+			gdp.ObjectMeta.Name, err.Error())
+		updateGDPStatus(gdp, err)
+		return
+	}
+	if err := GDPSanityChecks(gdp); err != nil {
+		gslbutils.Errf("ns: %s, gdp: %s, msg: error in accepting namespace GDP object: %s", ns,
+			gdp.ObjectMeta.Name, err.Error())
+		updateGDPStatus(gdp, err)
+		// an accepted GDP object which became invalid no longer overrides the weights
+		deleteNSGDPWeights(gdp, k8swq, numWorkers)
+		return
+	}
+	nsGDPOwners.setOwner(ns, getGDPKey(gdp))
+	updateGDPStatus(gdp, nil)
+	if !gslbutils.GetTrafficWeightOverrides().SetNSGDPWeights(ns, getGDPClusterTraffic(gdp)) {
+		return
+	}
+	gslbutils.Logf("ns: %s, gdp: %s, msg: namespace traffic weights changed", ns, gdp.ObjectMeta.Name)
+	WriteTrafficWeightChangedObjsToQueue(k8swq, numWorkers, SelectObjsForNamespace(ns))
+}
+
+// deleteNSGDPWeights removes the traffic weights of a namespace if gdp is the GDP object accepted
+// for the namespace.
+func deleteNSGDPWeights(gdp *gdpalphav2.GlobalDeploymentPolicy, k8swq []workqueue.RateLimitingInterface,
+	numWorkers uint32) {
+	if !isNSGDPOwner(gdp) {
+		return
+	}
+	ns := gdp.ObjectMeta.Namespace
+	nsGDPOwners.deleteOwner(ns)
+	if !gslbutils.GetTrafficWeightOverrides().DeleteNSGDPWeights(ns) {
+		return
+	}
+	gslbutils.Logf("ns: %s, gdp: %s, msg: namespace traffic weights removed", ns, gdp.ObjectMeta.Name)
+	WriteTrafficWeightChangedObjsToQueue(k8swq, numWorkers, SelectObjsForNamespace(ns))
+}
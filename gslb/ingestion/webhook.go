@@ -0,0 +1,128 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"encoding/json"
+	"errors"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/webhook"
+
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// Kinds of the objects validated by the webhook
+const (
+	GSLBConfigKind   = "GSLBConfig"
+	GDPKind          = "GlobalDeploymentPolicy"
+	GSLBHostRuleKind = "GSLBHostRule"
+)
+
+// decodeAdmissionObj unmarshals the object of an admission request into obj. The namespace may be
+// missing in the object of a create request, so it's taken from the request.
+func decodeAdmissionObj(req *webhook.AdmissionRequest, obj metav1.Object) error {
+	if err := json.Unmarshal(req.Object.Raw, obj); err != nil {
+		return errors.New("malformed " + req.Kind.Kind + " object: " + err.Error())
+	}
+	if obj.GetNamespace() == "" {
+		obj.SetNamespace(req.Namespace)
+	}
+	return nil
+}
+
+// gslbConfigExists returns an error if a GSLBConfig object other than name already exists, only
+// one GSLBConfig object is allowed.
+func gslbConfigExists(name string) error {
+	if existingName, _ := gslbutils.GetGSLBConfigNameAndNS(); existingName != "" && existingName != name {
+		return errors.New("GSLBConfig " + existingName + " already exists in " + gslbutils.AVISystem +
+			", only one GSLBConfig object is allowed")
+	}
+	if gslbutils.GlobalGslbClient == nil {
+		return nil
+	}
+	gcList, err := gslbutils.GlobalGslbClient.AmkoV1alpha2().GSLBConfigs(gslbutils.AVISystem).List(metav1.ListOptions{})
+	if err != nil {
+		gslbutils.Warnf("ns: %s, msg: error in listing the GSLBConfig objects, %s", gslbutils.AVISystem, err)
+		return nil
+	}
+	for _, gc := range gcList.Items {
+		if gc.Name != name {
+			return errors.New("GSLBConfig " + gc.Name + " already exists in " + gslbutils.AVISystem +
+				", only one GSLBConfig object is allowed")
+		}
+	}
+	return nil
+}
+
+func validateGSLBConfig(req *webhook.AdmissionRequest) error {
+	var gc gslbalphav2.GSLBConfig
+	if err := decodeAdmissionObj(req, &gc); err != nil {
+		return err
+	}
+	if _, err := IsGSLBConfigValid(&gc); err != nil {
+		return err
+	}
+	if req.Operation != webhook.Create {
+		return nil
+	}
+	return gslbConfigExists(gc.Name)
+}
+
+// validateGDP runs the same checks which are run when a GDP object is added, a second GDP object
+// for a namespace other than AVISystem is rejected. The clusters can only be verified once the
+// GSLBConfig object is accepted, till then, the GDP objects are validated after they are created.
+func validateGDP(req *webhook.AdmissionRequest) error {
+	var gdp gslbalphav2.GlobalDeploymentPolicy
+	if err := decodeAdmissionObj(req, &gdp); err != nil {
+		return err
+	}
+	if !gslbutils.ClusterContextsInitialized() {
+		return nil
+	}
+	if req.Operation == webhook.Create && gdp.Namespace == gslbutils.AVISystem {
+		if name, _ := gslbutils.GetGDPObj(); name != "" && name != gdp.Name {
+			return errors.New("a GDP object already exists, can't add another")
+		}
+	}
+	if gdp.Namespace != gslbutils.AVISystem {
+		if err := nsGDPExists(&gdp); err != nil {
+			return err
+		}
+	}
+	return GDPSanityChecks(&gdp)
+}
+
+// validateGSLBHostRule runs the same checks which are run when a GSLBHostRule object is added, a
+// GSLBHostRule for an fqdn which already has an accepted GSLBHostRule is rejected.
+func validateGSLBHostRule(req *webhook.AdmissionRequest) error {
+	var hr gslbalphav2.GSLBHostRule
+	if err := decodeAdmissionObj(req, &hr); err != nil {
+		return err
+	}
+	if !gslbutils.ClusterContextsInitialized() {
+		return nil
+	}
+	return GSLBHostRuleSanityChecks(&hr)
+}
+
+// RegisterWebhookValidators sets the validation functions for the AMKO objects in the webhook.
+func RegisterWebhookValidators() {
+	webhook.RegisterValidator(GSLBConfigKind, validateGSLBConfig)
+	webhook.RegisterValidator(GDPKind, validateGDP)
+	webhook.RegisterValidator(GSLBHostRuleKind, validateGSLBHostRule)
+}
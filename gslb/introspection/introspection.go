@@ -0,0 +1,475 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+// Package introspection serves the in-memory state of AMKO as read-only JSON on the AMKO API
+// server: the GDP filter, the accepted and rejected member cluster objects, the GS graphs, the
+// Avi cache, the retry counters of the GS graphs and the filter decisions for the objects.
+package introspection
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	filter "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gdp_filter"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+const (
+	FilterRoute  = "/api/gslb/filter"
+	ObjectsRoute = "/api/gslb/objects"
+	GraphsRoute  = "/api/gslb/graphs"
+	CacheRoute   = "/api/gslb/cache"
+	RetriesRoute = "/api/gslb/retries"
+	ExplainRoute = "/api/gslb/explain"
+	PlanRoute    = "/api/gslb/plan"
+
+	// query parameters to filter the responses
+	FQDNParam      = "fqdn"
+	ClusterParam   = "cluster"
+	NamespaceParam = "namespace"
+	TypeParam      = "type"
+	StateParam     = "state"
+	NameParam      = "name"
+
+	StateAccepted = "accepted"
+	StateRejected = "rejected"
+)
+
+// IntrospectionModel implements ApiModel and serves the in-memory state of AMKO.
+type IntrospectionModel struct{}
+
+func (m *IntrospectionModel) InitModel() {}
+
+func (m *IntrospectionModel) ApiOperationMap() []models.OperationMap {
+	routes := []struct {
+		route   string
+		handler func(w http.ResponseWriter, r *http.Request)
+	}{
+		{FilterRoute, GetFilter},
+		{ObjectsRoute, GetObjects},
+		{GraphsRoute, GetGraphs},
+		{CacheRoute, GetCache},
+		{RetriesRoute, GetRetries},
+		{ExplainRoute, GetExplain},
+		{PlanRoute, GetPlan},
+	}
+	var operationMapList []models.OperationMap
+	for _, r := range routes {
+		operationMapList = append(operationMapList, models.OperationMap{
+			Route:   r.route,
+			Method:  "GET",
+			Handler: r.handler,
+		})
+	}
+	return operationMapList
+}
+
+// queryFilter is the fqdn, cluster and namespace to filter a response with, an empty field
+// matches everything.
+type queryFilter struct {
+	fqdn      string
+	cluster   string
+	namespace string
+}
+
+func getQueryFilter(r *http.Request) queryFilter {
+	q := r.URL.Query()
+	return queryFilter{
+		fqdn:      q.Get(FQDNParam),
+		cluster:   q.Get(ClusterParam),
+		namespace: q.Get(NamespaceParam),
+	}
+}
+
+func (f queryFilter) matchesFQDN(fqdns ...string) bool {
+	if f.fqdn == "" {
+		return true
+	}
+	for _, fqdn := range fqdns {
+		if fqdn == f.fqdn {
+			return true
+		}
+	}
+	return false
+}
+
+func (f queryFilter) matchesMember(cluster, namespace string) bool {
+	return (f.cluster == "" || f.cluster == cluster) && (f.namespace == "" || f.namespace == namespace)
+}
+
+// FilterView is the GDP filter applied to the member cluster objects.
+type FilterView struct {
+	AppFilter                *gslbutils.Label               `json:"appFilter,omitempty"`
+	NamespaceFilter          *gslbutils.Label               `json:"namespaceFilter,omitempty"`
+	SelectedNamespaces       map[string][]string            `json:"selectedNamespaces,omitempty"`
+	ApplicableClusters       []string                       `json:"applicableClusters"`
+	DrainClusters            []string                       `json:"drainClusters"`
+	TrafficSplit             []gslbutils.ClusterTraffic     `json:"trafficSplit"`
+	TrafficSplitWindows      []gslbutils.TrafficSplitWindow `json:"trafficSplitWindows"`
+	ActiveTrafficSplitWindow string                         `json:"activeTrafficSplitWindow,omitempty"`
+	TrafficShift             *gslbutils.TrafficShift        `json:"trafficShift,omitempty"`
+	Checksum                 uint32                         `json:"checksum"`
+}
+
+// buildFilterView returns a copy of the global filter.
+func buildFilterView() FilterView {
+	gf := gslbutils.GetGlobalFilter()
+	gf.GlobalLock.RLock()
+	defer gf.GlobalLock.RUnlock()
+
+	view := FilterView{
+		ApplicableClusters:       append([]string{}, gf.ApplicableClusters...),
+		DrainClusters:            append([]string{}, gf.DrainClusters...),
+		TrafficSplit:             append([]gslbutils.ClusterTraffic{}, gf.TrafficSplit...),
+		TrafficSplitWindows:      append([]gslbutils.TrafficSplitWindow{}, gf.TrafficSplitWindows...),
+		ActiveTrafficSplitWindow: gf.ActiveTrafficSplitWindow,
+		Checksum:                 gf.Checksum,
+	}
+	if gf.AppFilter != nil {
+		label := gf.AppFilter.Label
+		view.AppFilter = &label
+	}
+	if gf.NSFilter != nil {
+		gf.NSFilter.Lock.RLock()
+		label := gf.NSFilter.Label
+		view.NamespaceFilter = &label
+		view.SelectedNamespaces = make(map[string][]string, len(gf.NSFilter.SelectedNS))
+		for ns, clusters := range gf.NSFilter.SelectedNS {
+			view.SelectedNamespaces[ns] = append([]string{}, clusters...)
+		}
+		gf.NSFilter.Lock.RUnlock()
+	}
+	if gf.TrafficShift != nil {
+		ts := *gf.TrafficShift
+		ts.Steps = append([]int{}, gf.TrafficShift.Steps...)
+		view.TrafficShift = &ts
+	}
+	return view
+}
+
+// GetFilter responds with the GDP filter.
+func GetFilter(w http.ResponseWriter, r *http.Request) {
+	utils.Respond(w, buildFilterView())
+}
+
+// ObjectView is an object of a member cluster in the accepted or rejected store.
+type ObjectView struct {
+	Cluster   string      `json:"cluster"`
+	Namespace string      `json:"namespace"`
+	Name      string      `json:"name"`
+	Type      string      `json:"type"`
+	State     string      `json:"state"`
+	Hostname  string      `json:"hostname"`
+	Object    interface{} `json:"object"`
+}
+
+type objectStore struct {
+	objType string
+	state   string
+	store   *gslbutils.ClusterStore
+}
+
+func getObjectStores() []objectStore {
+	return []objectStore{
+		{gslbutils.IngressType, StateAccepted, gslbutils.GetAcceptedIngressStore()},
+		{gslbutils.IngressType, StateRejected, gslbutils.GetRejectedIngressStore()},
+		{gslbutils.SvcType, StateAccepted, gslbutils.GetAcceptedLBSvcStore()},
+		{gslbutils.SvcType, StateRejected, gslbutils.GetRejectedLBSvcStore()},
+		{gslbutils.RouteType, StateAccepted, gslbutils.GetAcceptedRouteStore()},
+		{gslbutils.RouteType, StateRejected, gslbutils.GetRejectedRouteStore()},
+	}
+}
+
+// buildObjectViews returns the objects of the accepted and rejected stores which match the filter,
+// and the object type and state if they are non-empty.
+func buildObjectViews(f queryFilter, objType, state string) []ObjectView {
+	objects := []ObjectView{}
+	for _, s := range getObjectStores() {
+		if (objType != "" && objType != s.objType) || (state != "" && state != s.state) {
+			continue
+		}
+		for _, cname := range s.store.GetAllClusters() {
+			if f.cluster != "" && f.cluster != cname {
+				continue
+			}
+			for _, nsObj := range s.store.GetClusterNSObjects(cname) {
+				nsName := strings.SplitN(nsObj, "/", 2)
+				if len(nsName) != 2 || !f.matchesMember(cname, nsName[0]) {
+					continue
+				}
+				obj, ok := s.store.GetClusterNSObjectByName(cname, nsName[0], nsName[1])
+				if !ok {
+					continue
+				}
+				view := ObjectView{
+					Cluster:   cname,
+					Namespace: nsName[0],
+					Name:      nsName[1],
+					Type:      s.objType,
+					State:     s.state,
+					Object:    obj,
+				}
+				if metaObj, ok := obj.(k8sobjects.MetaObject); ok {
+					view.Hostname = metaObj.GetHostname()
+				}
+				if !f.matchesFQDN(view.Hostname) {
+					continue
+				}
+				objects = append(objects, view)
+			}
+		}
+	}
+	return objects
+}
+
+// getObjType returns the type query parameter, and responds with an error if it isn't a valid
+// object type.
+func getObjType(w http.ResponseWriter, r *http.Request) (string, bool) {
+	objType := r.URL.Query().Get(TypeParam)
+	if objType != "" && objType != gslbutils.IngressType && objType != gslbutils.SvcType &&
+		objType != gslbutils.RouteType {
+		http.Error(w, "invalid object type "+objType+", must be one of "+gslbutils.IngressType+", "+
+			gslbutils.SvcType+" or "+gslbutils.RouteType, http.StatusBadRequest)
+		return "", false
+	}
+	return objType, true
+}
+
+// GetObjects responds with the accepted and rejected objects of the member clusters, filtered by
+// the fqdn, cluster, namespace, type and state query parameters.
+func GetObjects(w http.ResponseWriter, r *http.Request) {
+	objType, ok := getObjType(w, r)
+	if !ok {
+		return
+	}
+	state := r.URL.Query().Get(StateParam)
+	if state != "" && state != StateAccepted && state != StateRejected {
+		http.Error(w, "invalid state "+state+", must be one of "+StateAccepted+" or "+StateRejected,
+			http.StatusBadRequest)
+		return
+	}
+	utils.Respond(w, buildObjectViews(getQueryFilter(r), objType, state))
+}
+
+// GraphView is a GS graph of the graph layer.
+type GraphView struct {
+	Key           string              `json:"key"`
+	Name          string              `json:"name"`
+	Tenant        string              `json:"tenant"`
+	DomainNames   []string            `json:"domainNames"`
+	Members       []nodes.AviGSK8sObj `json:"members"`
+	HealthMonitor nodes.HealthMonitor `json:"healthMonitor"`
+	Checksum      uint32              `json:"checksum"`
+	RetryCount    int                 `json:"retryCount"`
+	// Deleted is set for the graphs in the delete lister, which are yet to be deleted from the Avi
+	// controller
+	Deleted bool `json:"deleted"`
+}
+
+func buildGraphViews(f queryFilter) []GraphView {
+	graphs := []GraphView{}
+	for _, lister := range []struct {
+		lister  *nodes.AviGSGraphLister
+		deleted bool
+	}{
+		{nodes.SharedAviGSGraphLister(), false},
+		{nodes.SharedDeleteGSGraphLister(), true},
+	} {
+		keys := lister.lister.GetAll()
+		sort.Strings(keys)
+		for _, key := range keys {
+			ok, graphIntf := lister.lister.Get(key)
+			if !ok {
+				continue
+			}
+			graph, ok := graphIntf.(*nodes.AviGSObjectGraph)
+			if !ok || graph == nil {
+				continue
+			}
+			graphCopy := graph.GetCopy()
+			if !f.matchesFQDN(append([]string{graphCopy.Name}, graphCopy.DomainNames...)...) {
+				continue
+			}
+			if f.cluster != "" || f.namespace != "" {
+				matched := false
+				for _, member := range graphCopy.MemberObjs {
+					if f.matchesMember(member.Cluster, member.Namespace) {
+						matched = true
+						break
+					}
+				}
+				if !matched {
+					continue
+				}
+			}
+			graphs = append(graphs, GraphView{
+				Key:           key,
+				Name:          graphCopy.Name,
+				Tenant:        graphCopy.Tenant,
+				DomainNames:   graphCopy.DomainNames,
+				Members:       graphCopy.MemberObjs,
+				HealthMonitor: graphCopy.Hm,
+				Checksum:      graphCopy.GraphChecksum,
+				RetryCount:    graphCopy.RetryCount,
+				Deleted:       lister.deleted,
+			})
+		}
+	}
+	return graphs
+}
+
+// GetGraphs responds with the GS graphs, filtered by the fqdn, cluster and namespace query
+// parameters. A graph matches the cluster and namespace if any of its members does.
+func GetGraphs(w http.ResponseWriter, r *http.Request) {
+	utils.Respond(w, buildGraphViews(getQueryFilter(r)))
+}
+
+// RetryView is the retry counter of a GS graph, which is the number of retries left for the
+// graph in the REST layer.
+type RetryView struct {
+	Key           string `json:"key"`
+	RetryCount    int    `json:"retryCount"`
+	MaxRetryCount int    `json:"maxRetryCount"`
+	Deleted       bool   `json:"deleted"`
+}
+
+// GetRetries responds with the retry counters of the GS graphs, filtered by the fqdn, cluster and
+// namespace query parameters.
+func GetRetries(w http.ResponseWriter, r *http.Request) {
+	retries := []RetryView{}
+	for _, graph := range buildGraphViews(getQueryFilter(r)) {
+		retries = append(retries, RetryView{
+			Key:           graph.Key,
+			RetryCount:    graph.RetryCount,
+			MaxRetryCount: gslbutils.DefaultRetryCount,
+			Deleted:       graph.Deleted,
+		})
+	}
+	utils.Respond(w, retries)
+}
+
+// CacheView is the GSLB services and health monitors in the Avi cache.
+type CacheView struct {
+	GSLBServices   []avicache.AviGSCache `json:"gslbServices"`
+	HealthMonitors []avicache.AviHmObj   `json:"healthMonitors"`
+}
+
+// gsMatchesMember returns true if a member object of the GS, of the form type/cluster/ns/name,
+// matches the cluster and namespace of the filter.
+func gsMatchesMember(f queryFilter, k8sObjects []string) bool {
+	if f.cluster == "" && f.namespace == "" {
+		return true
+	}
+	for _, obj := range k8sObjects {
+		segments := strings.Split(obj, "/")
+		if len(segments) < 4 {
+			continue
+		}
+		if f.matchesMember(segments[1], segments[2]) {
+			return true
+		}
+	}
+	return false
+}
+
+func buildCacheView(f queryFilter) CacheView {
+	view := CacheView{
+		GSLBServices:   []avicache.AviGSCache{},
+		HealthMonitors: []avicache.AviHmObj{},
+	}
+	// health monitors are filtered by the GSs they belong to, if the cluster or namespace is set
+	gsNames := make(map[string]bool)
+
+	gsCache := avicache.GetAviCache()
+	for _, key := range gsCache.AviCacheGetAllKeys() {
+		obj, ok := gsCache.AviCacheGet(key)
+		if !ok {
+			continue
+		}
+		gsObj, ok := obj.(*avicache.AviGSCache)
+		if !ok || gsObj == nil {
+			continue
+		}
+		if !f.matchesFQDN(gsObj.Name) || !gsMatchesMember(f, gsObj.K8sObjects) {
+			continue
+		}
+		gsNames[gsObj.Name] = true
+		view.GSLBServices = append(view.GSLBServices, *gsObj)
+	}
+	sort.Slice(view.GSLBServices, func(i, j int) bool {
+		return view.GSLBServices[i].Name < view.GSLBServices[j].Name
+	})
+
+	hmCache := avicache.GetAviHmCache()
+	for _, key := range hmCache.AviHmGetAllKeys() {
+		obj, ok := hmCache.AviHmCacheGet(key)
+		if !ok {
+			continue
+		}
+		hmObj, ok := obj.(*avicache.AviHmObj)
+		if !ok || hmObj == nil {
+			continue
+		}
+		if f.fqdn != "" || f.cluster != "" || f.namespace != "" {
+			matched := false
+			for gsName := range gsNames {
+				if strings.Contains(hmObj.Name, "--"+gsName) {
+					matched = true
+					break
+				}
+			}
+			if !matched {
+				continue
+			}
+		}
+		view.HealthMonitors = append(view.HealthMonitors, *hmObj)
+	}
+	sort.Slice(view.HealthMonitors, func(i, j int) bool {
+		return view.HealthMonitors[i].Name < view.HealthMonitors[j].Name
+	})
+	return view
+}
+
+// GetCache responds with the GSLB services and health monitors in the Avi cache, filtered by the
+// fqdn, cluster and namespace query parameters. A GS matches the cluster and namespace if any of
+// its members does, and a health monitor matches if its GS does.
+func GetCache(w http.ResponseWriter, r *http.Request) {
+	utils.Respond(w, buildCacheView(getQueryFilter(r)))
+}
+
+// GetExplain responds with the decision traces of the GDP filter for the member cluster objects,
+// filtered by the type, cluster, namespace, name and fqdn query parameters. The name of an
+// ingress matches the decisions for all its hosts.
+func GetExplain(w http.ResponseWriter, r *http.Request) {
+	objType, ok := getObjType(w, r)
+	if !ok {
+		return
+	}
+	f := getQueryFilter(r)
+	utils.Respond(w, filter.GetDecisions(objType, f.cluster, f.namespace, r.URL.Query().Get(NameParam), f.fqdn))
+}
+
+// GetPlan responds with the rest operations planned in the dry run mode for the GS graphs, filtered
+// by the fqdn query parameter, which matches the name of the GS.
+func GetPlan(w http.ResponseWriter, r *http.Request) {
+	utils.Respond(w, rest.GetPlan(getQueryFilter(r).fqdn))
+}
@@ -17,10 +17,11 @@ package k8sobjects
 import (
 	"errors"
 	"sort"
+	"strconv"
 	"sync"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
-	gdpv1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gdpv1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
 	"k8s.io/api/networking/v1beta1"
@@ -87,6 +88,8 @@ func GetIngressHostMeta(ingress *v1beta1.Ingress, cname string) []IngressHostMet
 	ingHostMetaList := []IngressHostMeta{}
 	hostIPList := gslbutils.IngressGetIPAddrs(ingress)
 	tlsHosts := getTLSHosts(ingress)
+	trafficWeight := getObjTrafficWeight(cname, ingress.Namespace, ingress.Name, ingress.GetAnnotations())
+	drain := getObjDrain(cname, ingress.Namespace, ingress.Name, ingress.GetAnnotations())
 	for _, hip := range hostIPList {
 		metaObj := IngressHostMeta{
 			IngName:   ingress.Name,
@@ -96,6 +99,9 @@ func GetIngressHostMeta(ingress *v1beta1.Ingress, cname string) []IngressHostMet
 			Cluster:   cname,
 			ObjName:   ingress.Name + "/" + hip.Hostname,
 			TLS:       false,
+
+			TrafficWeight: trafficWeight,
+			Drain:         drain,
 		}
 		metaObj.Paths = make([]string, 0)
 		metaObj.Labels = make(map[string]string)
@@ -125,12 +131,16 @@ type IngressHostMeta struct {
 	Labels    map[string]string
 	Paths     []string
 	TLS       bool
+	// TrafficWeight is the weight set via the traffic weight annotation, 0 if not set
+	TrafficWeight int32
+	// Drain is set via the drain annotation
+	Drain bool
 }
 
 var clusterHostMeta map[string]map[string]IngressHostMeta
 
 func (ing IngressHostMeta) GetType() string {
-	return gdpv1alpha1.IngressObj
+	return gdpv1alpha2.IngressObj
 }
 
 func (ing IngressHostMeta) GetName() string {
@@ -161,6 +171,18 @@ func (ing IngressHostMeta) GetIPAddr() string {
 	return ing.IPAddr
 }
 
+func (ing IngressHostMeta) GetTrafficWeight() int32 {
+	return ing.TrafficWeight
+}
+
+func (ing IngressHostMeta) GetLabels() map[string]string {
+	return ing.Labels
+}
+
+func (ing IngressHostMeta) IsDrained() bool {
+	return ing.Drain
+}
+
 func (ing IngressHostMeta) GetPort() (int32, error) {
 	return 0, errors.New("ingress object doesn't support GetPort function")
 }
@@ -206,7 +228,8 @@ func (ing IngressHostMeta) GetIngressHostCksum() uint32 {
 	// TODO: annotations will be checked in later
 	cksum += utils.Hash(ing.Cluster) + utils.Hash(ing.Namespace) +
 		utils.Hash(ing.IngName) + utils.Hash(ing.Hostname) +
-		utils.Hash(ing.IPAddr) + utils.Hash(utils.Stringify(paths))
+		utils.Hash(ing.IPAddr) + utils.Hash(utils.Stringify(paths)) +
+		utils.Hash(strconv.Itoa(int(ing.TrafficWeight))) + utils.Hash(strconv.FormatBool(ing.Drain))
 	return cksum
 }
 
@@ -16,6 +16,8 @@ package k8sobjects
 
 import (
 	"sync"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
 )
 
 // Interface for k8s/openshift objects(e.g. route, service, ingress) with minimal information
@@ -34,6 +36,9 @@ type MetaObject interface {
 	GetProtocol() (string, error)
 	GetTLS() (bool, error)
 	IsPassthrough() bool
+	GetTrafficWeight() int32
+	IsDrained() bool
+	GetLabels() map[string]string
 }
 
 type FilterableObject interface {
@@ -50,3 +55,27 @@ type ObjHostMap struct {
 	HostMap map[string]IPHostname
 	Lock    sync.Mutex
 }
+
+// getObjTrafficWeight returns the traffic weight set via an annotation on an object, 0 if the
+// annotation is absent or invalid.
+func getObjTrafficWeight(cname, ns, name string, annotations map[string]string) int32 {
+	weight, err := gslbutils.ParseTrafficWeight(annotations)
+	if err != nil {
+		gslbutils.Warnf("cluster: %s, ns: %s, name: %s, msg: ignoring traffic weight annotation, %s",
+			cname, ns, name, err.Error())
+		return 0
+	}
+	return weight
+}
+
+// getObjDrain returns true if an object carries the drain annotation, false if the annotation is
+// absent or invalid.
+func getObjDrain(cname, ns, name string, annotations map[string]string) bool {
+	drain, err := gslbutils.ParseDrain(annotations)
+	if err != nil {
+		gslbutils.Warnf("cluster: %s, ns: %s, name: %s, msg: ignoring drain annotation, %s",
+			cname, ns, name, err.Error())
+		return false
+	}
+	return drain
+}
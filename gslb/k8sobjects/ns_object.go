@@ -16,7 +16,7 @@ package k8sobjects
 
 import (
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
-	gdpv1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gdpv1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -31,6 +31,7 @@ func GetNSMeta(ns *corev1.Namespace, cname string) NSMeta {
 	for key, value := range ns.GetLabels() {
 		metaObj.Labels[key] = value
 	}
+	metaObj.TrafficWeight = getObjTrafficWeight(cname, "", ns.Name, ns.GetAnnotations())
 	return metaObj
 }
 
@@ -40,10 +41,12 @@ type NSMeta struct {
 	Cluster string
 	Name    string
 	Labels  map[string]string
+	// TrafficWeight is the weight set via the traffic weight annotation, 0 if not set
+	TrafficWeight int32
 }
 
 func (nsObj NSMeta) GetType() string {
-	return gdpv1alpha1.NSObj
+	return gdpv1alpha2.NSObj
 }
 
 func (nsObj NSMeta) GetName() string {
@@ -19,7 +19,7 @@ import (
 	"sync"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
-	gdpv1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gdpv1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	routev1 "github.com/openshift/api/route/v1"
 )
@@ -50,6 +50,8 @@ func GetRouteMeta(route *routev1.Route, cname string) RouteMeta {
 	for key, value := range routeLabels {
 		metaObj.Labels[key] = value
 	}
+	metaObj.TrafficWeight = getObjTrafficWeight(cname, route.Namespace, route.Name, route.GetAnnotations())
+	metaObj.Drain = getObjDrain(cname, route.Namespace, route.Name, route.GetAnnotations())
 
 	if route.Spec.TLS != nil {
 		// for passthrough routes, only set the port and protocol
@@ -89,10 +91,14 @@ type RouteMeta struct {
 	Port        int32
 	Protocol    string
 	Passthrough bool
+	// TrafficWeight is the weight set via the traffic weight annotation, 0 if not set
+	TrafficWeight int32
+	// Drain is set via the drain annotation
+	Drain bool
 }
 
 func (route RouteMeta) GetType() string {
-	return gdpv1alpha1.RouteObj
+	return gdpv1alpha2.RouteObj
 }
 
 func (route RouteMeta) GetName() string {
@@ -111,6 +117,18 @@ func (route RouteMeta) GetIPAddr() string {
 	return route.IPAddr
 }
 
+func (route RouteMeta) GetTrafficWeight() int32 {
+	return route.TrafficWeight
+}
+
+func (route RouteMeta) GetLabels() map[string]string {
+	return route.Labels
+}
+
+func (route RouteMeta) IsDrained() bool {
+	return route.Drain
+}
+
 func (route RouteMeta) GetCluster() string {
 	return route.Cluster
 }
@@ -19,7 +19,7 @@ import (
 	"sync"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
-	gdpv1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gdpv1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	corev1 "k8s.io/api/core/v1"
 )
@@ -73,6 +73,10 @@ type SvcMeta struct {
 	Labels    map[string]string
 	Port      int32
 	Protocol  string
+	// TrafficWeight is the weight set via the traffic weight annotation, 0 if not set
+	TrafficWeight int32
+	// Drain is set via the drain annotation
+	Drain bool
 }
 
 // GetSvcMeta returns a trimmed down version of a svc
@@ -89,6 +93,8 @@ func GetSvcMeta(svc *corev1.Service, cname string) (SvcMeta, bool) {
 	for key, value := range svc.GetLabels() {
 		metaObj.Labels[key] = value
 	}
+	metaObj.TrafficWeight = getObjTrafficWeight(cname, svc.Namespace, svc.Name, svc.GetAnnotations())
+	metaObj.Drain = getObjDrain(cname, svc.Namespace, svc.Name, svc.GetAnnotations())
 
 	if ip == "" || hostname == "" {
 		gslbutils.Logf("cluster: %s, msg: service object %s, ns: %s, empty status IP %s or hostname %s",
@@ -121,7 +127,7 @@ func GetSvcStatusIPHostname(svc *corev1.Service) (string, string) {
 }
 
 func (svc SvcMeta) GetType() string {
-	return gdpv1alpha1.LBSvcObj
+	return gdpv1alpha2.LBSvcObj
 }
 
 func (svc SvcMeta) GetName() string {
@@ -164,6 +170,18 @@ func (svc SvcMeta) IsPassthrough() bool {
 	return false
 }
 
+func (svc SvcMeta) GetTrafficWeight() int32 {
+	return svc.TrafficWeight
+}
+
+func (svc SvcMeta) GetLabels() map[string]string {
+	return svc.Labels
+}
+
+func (svc SvcMeta) IsDrained() bool {
+	return svc.Drain
+}
+
 func (svc SvcMeta) UpdateHostMap(key string) {
 	rhm := getSvcHostMap()
 	rhm.Lock.Lock()
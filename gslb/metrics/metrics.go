@@ -0,0 +1,185 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+// Package metrics keeps the counters, gauges and histograms exported by AMKO, and serves them in the
+// Prometheus exposition format.
+package metrics
+
+import (
+	"net/http"
+	"sync"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+)
+
+const (
+	// MetricsRoute is the path on the AMKO API server on which the metrics are served
+	MetricsRoute = "/metrics"
+
+	// FullSyncBootup and FullSyncPeriodic are the types of the full sync
+	FullSyncBootup   = "bootup"
+	FullSyncPeriodic = "periodic"
+
+	// ObjectAccepted and ObjectRejected are the states of the member cluster objects
+	ObjectAccepted = "accepted"
+	ObjectRejected = "rejected"
+
+	// AdoptionAdopted and AdoptionRejected are the results of adopting an existing GSLB service
+	AdoptionAdopted  = "adopted"
+	AdoptionRejected = "rejected"
+)
+
+// DurationBuckets are the histogram buckets, in seconds, for the processing times and the latencies.
+var DurationBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// Registry is the registry served on the metrics endpoint of AMKO, along with the metrics of AMKO,
+// it has the Go runtime and process metrics.
+var Registry = prometheus.NewRegistry()
+
+var factory = promauto.With(Registry)
+
+var (
+	// QueueProcessingDuration is the time taken to process a key, per workqueue
+	QueueProcessingDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "amko_workqueue_processing_duration_seconds",
+		Help:    "Time taken to process a key of the workqueue.",
+		Buckets: DurationBuckets,
+	}, []string{"queue"})
+
+	// AviRestRequests is the number of REST calls made to the Avi controller, per method, object and
+	// status code. The code is "timeout" if the call timed out and "error" if no status code was
+	// returned.
+	AviRestRequests = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "amko_avi_rest_requests_total",
+		Help: "Number of REST calls made to the Avi controller.",
+	}, []string{"method", "object", "code"})
+
+	// AviRestRequestDuration is the latency of the REST calls made to the Avi controller, per method
+	// and object
+	AviRestRequestDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "amko_avi_rest_request_duration_seconds",
+		Help:    "Latency of the REST calls made to the Avi controller.",
+		Buckets: DurationBuckets,
+	}, []string{"method", "object"})
+
+	// RetriesExhausted is the number of keys whose retries were exhausted in the REST layer
+	RetriesExhausted = factory.NewCounter(prometheus.CounterOpts{
+		Name: "amko_rest_retries_exhausted_total",
+		Help: "Number of keys dropped by the REST layer after exhausting their retries.",
+	})
+
+	// GSAdoptions is the number of existing GSLB services adopted, or failed to be adopted, by AMKO
+	GSAdoptions = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "amko_gs_adoptions_total",
+		Help: "Number of existing GSLB services adopted by AMKO.",
+	}, []string{"result"})
+
+	// GCDeletions is the number of orphaned objects deleted by the garbage collection, per type of
+	// the object
+	GCDeletions = factory.NewCounterVec(prometheus.CounterOpts{
+		Name: "amko_gc_deletions_total",
+		Help: "Number of orphaned GSLB services and health monitors deleted by the garbage collection.",
+	}, []string{"type"})
+
+	// FullSyncDuration is the time taken by a full sync, per type of the full sync
+	FullSyncDuration = factory.NewHistogramVec(prometheus.HistogramOpts{
+		Name:    "amko_full_sync_duration_seconds",
+		Help:    "Time taken by a full sync of the member cluster objects to the Avi controller.",
+		Buckets: []float64{0.1, 0.5, 1, 5, 10, 30, 60, 120, 300, 600},
+	}, []string{"type"})
+)
+
+func init() {
+	Registry.MustRegister(prometheus.NewGoCollector(), prometheus.NewProcessCollector(prometheus.ProcessCollectorOpts{}))
+}
+
+// ObserveSince adds the time elapsed since start, in seconds, to the histogram of the label values.
+func ObserveSince(h *prometheus.HistogramVec, start time.Time, labelValues ...string) {
+	h.WithLabelValues(labelValues...).Observe(time.Since(start).Seconds())
+}
+
+// InstrumentSyncFunc returns a sync function of a workqueue which records the processing time of
+// every key of the queue.
+func InstrumentSyncFunc(queue string, syncFunc func(string, *sync.WaitGroup) error) func(string, *sync.WaitGroup) error {
+	return func(key string, wg *sync.WaitGroup) error {
+		defer ObserveSince(QueueProcessingDuration, time.Now(), queue)
+		return syncFunc(key, wg)
+	}
+}
+
+// Sample is a single value of a metric collected at scrape time, with the values of its labels
+// in the order of the label names of the metric.
+type Sample struct {
+	LabelValues []string
+	Value       float64
+}
+
+// GaugeFunc is a gauge whose samples are collected by a function when the metrics are scraped. It's
+// used for the values which AMKO already keeps, like the length of a queue or a cache.
+type GaugeFunc struct {
+	desc    *prometheus.Desc
+	collect func() []Sample
+}
+
+// NewGaugeFunc returns a gauge with the label names, collect is called on every scrape and must
+// return the label values of each sample in the order of the label names. The gauge has to be
+// registered, e.g. in Registry.
+func NewGaugeFunc(name, help string, collect func() []Sample, labelNames ...string) *GaugeFunc {
+	return &GaugeFunc{
+		desc:    prometheus.NewDesc(name, help, labelNames, nil),
+		collect: collect,
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (g *GaugeFunc) Describe(ch chan<- *prometheus.Desc) {
+	ch <- g.desc
+}
+
+// Collect implements prometheus.Collector.
+func (g *GaugeFunc) Collect(ch chan<- prometheus.Metric) {
+	for _, sample := range g.collect() {
+		metric, err := prometheus.NewConstMetric(g.desc, prometheus.GaugeValue, sample.Value, sample.LabelValues...)
+		if err != nil {
+			metric = prometheus.NewInvalidMetric(g.desc, err)
+		}
+		ch <- metric
+	}
+}
+
+// MetricsModel implements ApiModel and serves the metrics of the registry.
+type MetricsModel struct{}
+
+func (m *MetricsModel) InitModel() {}
+
+func (m *MetricsModel) ApiOperationMap() []models.OperationMap {
+	get := models.OperationMap{
+		Route:   MetricsRoute,
+		Method:  "GET",
+		Handler: ServeMetrics,
+	}
+	return []models.OperationMap{get}
+}
+
+var metricsHandler = promhttp.HandlerFor(Registry, promhttp.HandlerOpts{})
+
+// ServeMetrics writes the metrics of the registry, in the exposition format negotiated with the
+// scraper.
+func ServeMetrics(w http.ResponseWriter, r *http.Request) {
+	metricsHandler.ServeHTTP(w, r)
+}
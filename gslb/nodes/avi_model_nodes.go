@@ -15,7 +15,6 @@
 package nodes
 
 import (
-	"strconv"
 	"sync"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
@@ -53,6 +52,11 @@ func SharedAviGSGraphLister() *AviGSGraphLister {
 	return aviGSGraphInstance
 }
 
+// NewAviGSGraphLister returns a GS graph lister which isn't shared with the layers of AMKO.
+func NewAviGSGraphLister() *AviGSGraphLister {
+	return &AviGSGraphLister{AviGSGraphStore: gslbutils.NewObjectMapStore()}
+}
+
 func (a *AviGSGraphLister) Save(gsName string, graph interface{}) {
 	gslbutils.Logf("gsName: %s, msg: %s", gsName, "saving GSLB graph")
 
@@ -80,6 +84,8 @@ type AviGSK8sObj struct {
 	Namespace string
 	IPAddr    string
 	Weight    int32
+	// Drained members are kept in the GS, but are disabled
+	Drained bool
 	// Port and protocol will be only used by LB service
 	Port  int32
 	Proto string
@@ -97,6 +103,7 @@ func (gsk8sObj AviGSK8sObj) getCopy() AviGSK8sObj {
 		Namespace: gsk8sObj.Namespace,
 		IPAddr:    gsk8sObj.IPAddr,
 		Weight:    gsk8sObj.Weight,
+		Drained:   gsk8sObj.Drained,
 		Port:      gsk8sObj.Port,
 		Proto:     gsk8sObj.Proto,
 		TLS:       gsk8sObj.TLS,
@@ -182,12 +189,12 @@ func (v *AviGSObjectGraph) GetHmChecksum() uint32 {
 func (v *AviGSObjectGraph) CalculateChecksum() {
 	// A sum of fields for this GS
 	var memberIPs []string
-	var memberObjs []string
 
 	for _, gsMember := range v.MemberObjs {
-		memberIPs = append(memberIPs, gsMember.IPAddr+"-"+strconv.Itoa(int(gsMember.Weight)))
-		memberObjs = append(memberObjs, gsMember.ObjType+"/"+gsMember.Cluster+"/"+gsMember.Namespace+"/"+gsMember.Name)
+		memberIPs = append(memberIPs, gslbutils.GetGSMemberChecksumStr(gsMember.IPAddr, gsMember.Weight,
+			!gsMember.Drained))
 	}
+	memberObjs := v.GetMemberObjList()
 
 	hmNames := []string{}
 	if v.Hm.Name != "" {
@@ -198,11 +205,21 @@ func (v *AviGSObjectGraph) CalculateChecksum() {
 	v.GraphChecksum = gslbutils.GetGSLBServiceChecksum(memberIPs, v.DomainNames, memberObjs, hmNames)
 }
 
-// GetMemberRouteList returns a list of member objects
+// GetMemberObjRefs returns the references to the member objects, as written in the GS description
+func (v *AviGSObjectGraph) GetMemberObjRefs() []gslbutils.GSMemberObj {
+	var memberObjs []gslbutils.GSMemberObj
+	for _, obj := range v.MemberObjs {
+		memberObjs = append(memberObjs, gslbutils.GSMemberObj{ObjType: obj.ObjType, Cluster: obj.Cluster,
+			Namespace: obj.Namespace, Name: obj.Name})
+	}
+	return memberObjs
+}
+
+// GetMemberObjList returns a list of member objects
 func (v *AviGSObjectGraph) GetMemberObjList() []string {
 	var memberObjs []string
-	for _, obj := range v.MemberObjs {
-		memberObjs = append(memberObjs, obj.ObjType+"/"+obj.Cluster+"/"+obj.Namespace+"/"+obj.Name)
+	for _, obj := range v.GetMemberObjRefs() {
+		memberObjs = append(memberObjs, obj.String())
 	}
 	return memberObjs
 }
@@ -295,7 +312,8 @@ func (v *AviGSObjectGraph) buildAndAttachHealthMonitors(metaObj k8sobjects.MetaO
 	}
 }
 
-func (v *AviGSObjectGraph) ConstructAviGSGraph(gsName, key string, metaObj k8sobjects.MetaObject, memberWeight int32) {
+func (v *AviGSObjectGraph) ConstructAviGSGraph(gsName, key string, metaObj k8sobjects.MetaObject, memberWeight int32,
+	drained bool) {
 	v.Lock.Lock()
 	defer v.Lock.Unlock()
 	hosts := []string{metaObj.GetHostname()}
@@ -311,6 +329,7 @@ func (v *AviGSObjectGraph) ConstructAviGSGraph(gsName, key string, metaObj k8sob
 			ObjType:   metaObj.GetType(),
 			IPAddr:    metaObj.GetIPAddr(),
 			Weight:    memberWeight,
+			Drained:   drained,
 			Name:      metaObj.GetName(),
 			Namespace: metaObj.GetNamespace(),
 			TLS:       tls,
@@ -393,7 +412,7 @@ func (v *AviGSObjectGraph) updateGSHmPathListAndProtocol() {
 	}
 }
 
-func (v *AviGSObjectGraph) UpdateGSMember(metaObj k8sobjects.MetaObject, weight int32) {
+func (v *AviGSObjectGraph) UpdateGSMember(metaObj k8sobjects.MetaObject, weight int32, drained bool) {
 	v.Lock.Lock()
 	defer v.Lock.Unlock()
 
@@ -429,6 +448,7 @@ func (v *AviGSObjectGraph) UpdateGSMember(metaObj k8sobjects.MetaObject, weight
 		// if we reach here, it means this is the member we need to update
 		v.MemberObjs[idx].IPAddr = metaObj.GetIPAddr()
 		v.MemberObjs[idx].Weight = weight
+		v.MemberObjs[idx].Drained = drained
 		gslbutils.Debugf("gsName: %s, msg: updating member for type %s", v.Name, metaObj.GetType())
 		if objType == gslbutils.SvcType || metaObj.IsPassthrough() {
 			v.MemberObjs[idx].Port = svcPort
@@ -454,6 +474,7 @@ func (v *AviGSObjectGraph) UpdateGSMember(metaObj k8sobjects.MetaObject, weight
 		Name:      metaObj.GetName(),
 		IPAddr:    metaObj.GetIPAddr(),
 		Weight:    weight,
+		Drained:   drained,
 		ObjType:   metaObj.GetType(),
 		Port:      svcPort,
 		Proto:     svcProtocol,
@@ -545,6 +566,7 @@ func (v *AviGSObjectGraph) GetMemberObjs() []AviGSK8sObj {
 		objs[idx].Namespace = v.MemberObjs[idx].Namespace
 		objs[idx].IPAddr = v.MemberObjs[idx].IPAddr
 		objs[idx].Weight = v.MemberObjs[idx].Weight
+		objs[idx].Drained = v.MemberObjs[idx].Drained
 		objs[idx].ObjType = v.MemberObjs[idx].ObjType
 	}
 	return objs
@@ -569,6 +591,7 @@ func (v *AviGSObjectGraph) GetUniqueMemberObjs() []AviGSK8sObj {
 			Namespace: memberObj.Namespace,
 			IPAddr:    memberObj.IPAddr,
 			Weight:    memberObj.Weight,
+			Drained:   memberObj.Drained,
 		})
 		memberVips = append(memberVips, memberObj.IPAddr)
 	}
@@ -39,20 +39,26 @@ func PublishKeyToRestLayer(tenant, gsName, key string, sharedQueue *utils.Worker
 	gslbutils.Logf("key: %s, modelName: %s, msg: %s", key, modelName, "published key to rest layer")
 }
 
-func GetObjTrafficRatio(ns, cname string) int32 {
-	globalFilter := gslbutils.GetGlobalFilter()
-	if globalFilter == nil {
-		// return default traffic ratio
-		gslbutils.Errf("ns: %s, cname: %s, msg: global filter can't be nil at this stage", ns, cname)
-		return 1
-	}
-	val, err := globalFilter.GetTrafficWeight(ns, cname)
-	if err != nil {
-		gslbutils.Warnf("ns: %s, cname: %s, msg: error occured while fetching traffic info for this cluster, %s",
-			ns, cname, err.Error())
-		return 1
+// GetObjTrafficRatio returns the traffic ratio for a member object. The ratio is picked up from
+// the object's annotation, a GSLBHostRule for the hostname, the namespace and the GDP object, in
+// that order.
+func GetObjTrafficRatio(metaObj k8sobjects.MetaObject) int32 {
+	weight, src := gslbutils.ResolveTrafficWeight(metaObj.GetTrafficWeight(), metaObj.GetHostname(),
+		metaObj.GetNamespace(), metaObj.GetCluster())
+	gslbutils.Debugf("cluster: %s, ns: %s, name: %s, weight: %d, source: %s, msg: resolved traffic weight",
+		metaObj.GetCluster(), metaObj.GetNamespace(), metaObj.GetName(), weight, src.String())
+	return weight
+}
+
+// IsObjDrained returns true if the member for this object has to be disabled, either because of the
+// drain annotation on the object or because its cluster is in drain mode.
+func IsObjDrained(metaObj k8sobjects.MetaObject) bool {
+	drained := gslbutils.IsMemberDrained(metaObj.IsDrained(), metaObj.GetCluster())
+	if drained {
+		gslbutils.Debugf("cluster: %s, ns: %s, name: %s, msg: member is drained", metaObj.GetCluster(),
+			metaObj.GetNamespace(), metaObj.GetName())
 	}
-	return val
+	return drained
 }
 
 func getObjFromStore(objType, cname, ns, objName, key, storeType string) interface{} {
@@ -114,27 +120,23 @@ func PublishAllGraphKeys() {
 	}
 }
 
-func AddUpdateObjOperation(key, cname, ns, objType, objName string, wq *utils.WorkerQueue,
-	fullSync bool, agl *AviGSGraphLister) {
-
+// AddOrUpdateGSMember adds the member for an accepted object to the GS graph for its hostname in
+// agl, the graph is constructed if it doesn't exist. Returns the name of the GS, and false if the
+// object can't be a member or if the graph didn't change.
+func AddOrUpdateGSMember(key string, metaObj k8sobjects.MetaObject, agl *AviGSGraphLister) (string, bool) {
 	var prevChecksum, newChecksum uint32
-	obj := getObjFromStore(objType, cname, ns, objName, key, gslbutils.AcceptedStore)
-	if obj == nil {
-		// error message already logged in the above function
-		return
-	}
-	metaObj := obj.(k8sobjects.MetaObject)
 	if metaObj.GetHostname() == "" {
 		gslbutils.Errf("key: %s, msg: %s", key, "no hostname for object, not supported")
-		return
+		return "", false
 	}
 	if metaObj.GetIPAddr() == "" {
 		// IP Address not found, no use adding this as a GS
 		gslbutils.Errf("key: %s, msg: %s", key, "no IP address found for the object")
-		return
+		return "", false
 	}
 	// get the traffic ratio for this member
-	memberWeight := GetObjTrafficRatio(ns, cname)
+	memberWeight := GetObjTrafficRatio(metaObj)
+	memberDrained := IsObjDrained(metaObj)
 	gsName := DeriveGSLBServiceName(metaObj.GetHostname())
 	modelName := utils.ADMIN_NS + "/" + gsName
 	found, aviGS := agl.Get(modelName)
@@ -143,7 +145,7 @@ func AddUpdateObjOperation(key, cname, ns, objType, objName string, wq *utils.Wo
 		aviGS = NewAviGSObjectGraph()
 		// Note: For now, the hostname is used as a way to create the GSLB services. This is on the
 		// assumption that the hostnames are same for a route across all clusters.
-		aviGS.(*AviGSObjectGraph).ConstructAviGSGraph(gsName, key, metaObj, memberWeight)
+		aviGS.(*AviGSObjectGraph).ConstructAviGSGraph(gsName, key, metaObj, memberWeight, memberDrained)
 		gslbutils.Debugf(spew.Sprintf("key: %s, gsName: %s, model: %v, msg: constructed new model", key, modelName,
 			*(aviGS.(*AviGSObjectGraph))))
 		agl.Save(modelName, aviGS.(*AviGSObjectGraph))
@@ -153,7 +155,7 @@ func AddUpdateObjOperation(key, cname, ns, objType, objName string, wq *utils.Wo
 		// since the object was found, fetch the current checksum
 		prevChecksum = gsGraph.GetChecksum()
 		// GSGraph found, so, only need to update the member of the GSGraph's GSNode
-		aviGS.(*AviGSObjectGraph).UpdateGSMember(metaObj, memberWeight)
+		aviGS.(*AviGSObjectGraph).UpdateGSMember(metaObj, memberWeight, memberDrained)
 		// Get the new checksum after the updates
 		newChecksum = gsGraph.GetChecksum()
 		newHmChecksum := gsGraph.GetHmChecksum()
@@ -165,13 +167,29 @@ func AddUpdateObjOperation(key, cname, ns, objType, objName string, wq *utils.Wo
 			// Checksums are same, return
 			gslbutils.Debugf(spew.Sprintf("key: %s, gsName: %s, model: %v, msg: %s", key, gsName, *gsGraph,
 				"the model for this key has identical checksums"))
-			return
+			return gsName, false
 		}
 		aviGS.(*AviGSObjectGraph).SetRetryCounter()
 		gslbutils.Debugf(spew.Sprintf("key: %s, gsName: %s, model: %v, msg: %s", key, gsName, *gsGraph,
 			"updated the model"))
 		agl.Save(modelName, aviGS.(*AviGSObjectGraph))
 	}
+	return gsName, true
+}
+
+func AddUpdateObjOperation(key, cname, ns, objType, objName string, wq *utils.WorkerQueue,
+	fullSync bool, agl *AviGSGraphLister) {
+
+	obj := getObjFromStore(objType, cname, ns, objName, key, gslbutils.AcceptedStore)
+	if obj == nil {
+		// error message already logged in the above function
+		return
+	}
+	metaObj := obj.(k8sobjects.MetaObject)
+	gsName, changed := AddOrUpdateGSMember(key, metaObj, agl)
+	if !changed {
+		return
+	}
 	// Update the hostname in the RouteHostMap
 	metaObj.UpdateHostMap(cname + "/" + ns + "/" + objName)
 
@@ -16,6 +16,7 @@ package rest
 
 import (
 	"errors"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
@@ -23,13 +24,16 @@ import (
 	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
 
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	"github.com/avinetworks/sdk/go/clients"
 	avimodels "github.com/avinetworks/sdk/go/models"
 	"github.com/avinetworks/sdk/go/session"
 	"github.com/davecgh/go-spew/spew"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
 )
 
 const (
@@ -82,6 +86,10 @@ func (restOp *RestOperations) deleteAllStaleHMsForGS(key string) {
 
 func (restOp *RestOperations) DqNodes(key string) {
 	gslbutils.Logf("key: %s, msg: starting rest layer sync", key)
+	if gslbutils.IsDryRun() {
+		// the operations are planned again for the key
+		resetPlan(key)
+	}
 	// got the key from graph layer, let's fetch the model
 	// if the key is only in the delete cache, then set deleteOp to true, else false
 	deleteOp := false
@@ -123,6 +131,7 @@ func (restOp *RestOperations) DqNodes(key string) {
 	if ct <= 0 {
 		aviModel.SetRetryCounter()
 		gslbutils.Logf("key: %s, msg: retry counter exhausted, resetting counter", key)
+		metrics.RetriesExhausted.Inc()
 		return
 	}
 	aviModel.DecrementRetryCounter()
@@ -319,6 +328,9 @@ func (restOp *RestOperations) RestOperation(gsName, tenant string, aviGSGraph *n
 		gslbutils.Errf("key: %s, msg: can't execute rest operation as controller is not a leader", gsKey)
 		return
 	}
+	if gsCacheObj == nil && gslbutils.IsGSAdoptionEnabled(aviGSGraph.Name) {
+		gsCacheObj = restOp.adoptGS(aviGSGraph, gsKey, key)
+	}
 	var err error
 	if gsCacheObj != nil {
 		if len(pathNames) > 0 {
@@ -400,8 +412,31 @@ func (restOp *RestOperations) RestOperation(gsName, tenant string, aviGSGraph *n
 	restOp.ExecuteRestAndPopulateCache(operation, &gsKey, nil, key)
 }
 
+// BuildGSCreateOperations returns the rest operations which create a GS graph on an Avi controller
+// which has neither the GS nor its health monitors, in the order in which RestOperation executes them.
+// The operations are only built, the caches aren't looked up.
+func BuildGSCreateOperations(aviGSGraph *nodes.AviGSObjectGraph) []*utils.RestOp {
+	var restOp RestOperations
+	var operations []*utils.RestOp
+	key := aviGSGraph.Tenant + "/" + aviGSGraph.Name
+	pathNames := aviGSGraph.GetHmPathNamesList()
+	if len(pathNames) > 0 {
+		for _, hmName := range pathNames {
+			if op := restOp.AviGsHmBuild(aviGSGraph, utils.RestPost, nil, key, hmName); op != nil {
+				operations = append(operations, op)
+			}
+		}
+	} else if aviGSGraph.IsHmTypeCustom() {
+		if op := restOp.AviGsHmBuild(aviGSGraph, utils.RestPost, nil, key, ""); op != nil {
+			operations = append(operations, op)
+		}
+	}
+	return append(operations, restOp.AviGSBuild(aviGSGraph, utils.RestPost, nil, key, true))
+}
+
 func AviRestOperateWrapper(restOp *RestOperations, aviClient *clients.AviClient, operation *utils.RestOp) error {
 	restTimeoutChan := make(chan error, 1)
+	start := time.Now()
 
 	go func() {
 		err := restOp.aviRestPoolClient.AviRestOperate(aviClient, []*utils.RestOp{operation})
@@ -410,19 +445,45 @@ func AviRestOperateWrapper(restOp *RestOperations, aviClient *clients.AviClient,
 
 	select {
 	case err := <-restTimeoutChan:
+		observeRestCall(operation, start, restStatusCode(err))
 		return err
 	case <-time.After(gslbutils.RestTimeoutSecs * time.Second):
 		gslbutils.Errf(spew.Sprintf("operation: %v, err: rest timeout occured", operation))
+		observeRestCall(operation, start, "timeout")
 		return errors.New("rest timeout occured")
 	}
 }
 
+// restStatusCode returns the HTTP status code of a rest call as the code label of the rest metrics.
+func restStatusCode(err error) string {
+	if err == nil {
+		return "200"
+	}
+	webSyncErr, ok := err.(*utils.WebSyncError)
+	if !ok {
+		return "error"
+	}
+	aviError, ok := webSyncErr.GetWebAPIError().(session.AviError)
+	if !ok {
+		return "error"
+	}
+	return strconv.Itoa(aviError.HttpStatusCode)
+}
+
+func observeRestCall(operation *utils.RestOp, start time.Time, code string) {
+	metrics.AviRestRequests.WithLabelValues(string(operation.Method), operation.Model, code).Inc()
+	metrics.ObserveSince(metrics.AviRestRequestDuration, start, string(operation.Method), operation.Model)
+}
+
 func (restOp *RestOperations) ExecuteRestAndPopulateCache(operation *utils.RestOp, gsKey, hmKey *avicache.TenantName,
 	key string) {
 	// Choose a AVI client based on the model name hash. This would ensure that the same worker queue processes updates for a
 	// given GS everytime.
 	bkt := utils.Bkt(key, gslbutils.NumRestWorkers)
 	gslbutils.Logf("key: %s, queue: %d, msg: processing in rest queue", key, bkt)
+	if restOp.planOperation(operation, key) {
+		return
+	}
 
 	if len(restOp.aviRestPoolClient.AviClient) > 0 {
 		aviClient := restOp.aviRestPoolClient.AviClient[bkt]
@@ -449,7 +510,9 @@ func (restOp *RestOperations) ExecuteRestAndPopulateCache(operation *utils.RestO
 			case "HealthMonitor":
 				restOp.AviGSHmCacheAdd(operation, key)
 			case "GSLBService":
-				restOp.AviGSCacheAdd(operation, key)
+				if err := restOp.AviGSCacheAdd(operation, key); err == nil {
+					RequestGDPDrainStatusUpdate()
+				}
 			default:
 				gslbutils.Errf("key: %s, method: %s, model: %s, msg: invalid model", key, operation.Method,
 					operation.Model)
@@ -654,7 +717,7 @@ func (restOp *RestOperations) AviGsHmBuild(gsMeta *nodes.AviGSObjectGraph, restM
 	isFederated := true
 	allowDup := true
 	tenantRef := gslbutils.GetAviAdminTenantRef()
-	description := "created by: amko"
+	description := gslbutils.AmkoHmDescription
 	sendInterval := int32(10)
 	receiveTimeout := int32(4)
 	successfulChecks := int32(3)
@@ -749,7 +812,8 @@ func (restOp *RestOperations) AviGSBuild(gsMeta *nodes.AviGSObjectGraph, restMet
 		if member.IPAddr == "" {
 			continue
 		}
-		enabled := true
+		// drained members are kept in the GS, but disabled
+		enabled := !member.Drained
 		ipVersion := "V4"
 		ipAddr := member.IPAddr
 		ratio := member.Weight
@@ -791,7 +855,7 @@ func (restOp *RestOperations) AviGSBuild(gsMeta *nodes.AviGSObjectGraph, restMet
 	tenantRef := gslbutils.GetAviAdminTenantRef()
 	useEdnsClientSubnet := true
 	wildcardMatch := false
-	description := strings.Join(gsMeta.GetMemberObjList(), ",")
+	description := gslbutils.EncodeGSDescription(gsMeta.GetMemberObjRefs())
 
 	aviGslbSvc := avimodels.GslbService{
 		ControllerHealthStatusEnabled: &ctrlHealthStatusEnabled,
@@ -903,7 +967,10 @@ func (restOp *RestOperations) deleteHmIfRequired(gsName, tenant, key string, gsC
 	aviclient := restOp.aviRestPoolClient.AviClient[bkt]
 	if !gslbutils.IsControllerLeader() {
 		gslbutils.Errf("key: %s, msg: %s", key, "can't execute rest operation, as controller is not a leader")
-		gslbutils.UpdateGSLBConfigStatus(ControllerNotLeaderErr)
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionControllerReachable, metav1.ConditionFalse,
+			gslbalphav2.ReasonNotLeader, ControllerNotLeaderErr)
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse,
+			gslbalphav2.ReasonNotLeader, ControllerNotLeaderErr)
 		return nil
 	}
 
@@ -929,6 +996,9 @@ func (restOp *RestOperations) deleteHmIfRequired(gsName, tenant, key string, gsC
 	}
 	hmKey := avicache.TenantName{Tenant: utils.ADMIN_NS, Name: hmName}
 	operation := restOp.AviGsHmDel(hmCacheObj.UUID, hmCacheObj.Tenant, key, hmCacheObj.Name)
+	if restOp.planOperation(operation, key) {
+		return nil
+	}
 	restOps = operation
 	err := AviRestOperateWrapper(restOp, aviclient, restOps)
 	if err != nil {
@@ -959,13 +1029,24 @@ func (restOp *RestOperations) deleteGSOper(gsCacheObj *avicache.AviGSCache, tena
 	aviclient := restOp.aviRestPoolClient.AviClient[bkt]
 	if !gslbutils.IsControllerLeader() {
 		gslbutils.Errf("key: %s, msg: %s", key, "can't execute rest operation, as controller is not a leader")
-		gslbutils.UpdateGSLBConfigStatus(ControllerNotLeaderErr)
+		gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionControllerReachable, metav1.ConditionFalse,
+			gslbalphav2.ReasonNotLeader, ControllerNotLeaderErr)
+		gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionAccepted, metav1.ConditionFalse,
+			gslbalphav2.ReasonNotLeader, ControllerNotLeaderErr)
 		return
 	}
 	gsName := gsCacheObj.Name
 	gsKey := avicache.TenantName{Tenant: tenant, Name: gsCacheObj.Name}
 	if gsCacheObj != nil {
 		operation := restOp.AviGSDel(gsCacheObj.Uuid, tenant, key, gsCacheObj.Name)
+		if restOp.planOperation(operation, key) {
+			// the health monitors of the GS are planned to be deleted as well, the GS graph is retained
+			// in the delete cache, so that it's planned again on the next sync
+			for _, hmName := range gsCacheObj.HealthMonitorNames {
+				restOp.deleteHmIfRequired(gsName, tenant, key, gsCacheObj, gsKey, hmName)
+			}
+			return
+		}
 		restOps = operation
 		err := AviRestOperateWrapper(restOp, aviclient, restOps)
 		gslbutils.Debugf("key: %s, GSLBService: %s, msg: avi rest operate wrapper response %v", key, gsCacheObj.Uuid, err)
@@ -988,6 +1069,7 @@ func (restOp *RestOperations) deleteGSOper(gsCacheObj *avicache.AviGSCache, tena
 
 		// Clear all the cache objects which were deleted
 		restOp.AviGSCacheDel(restOp.cache, operation, key)
+		RequestGDPDrainStatusUpdate()
 
 		// delete all HMs for this GS
 		for _, hmName := range gsCacheObj.HealthMonitorNames {
@@ -1046,6 +1128,7 @@ func (restOp *RestOperations) AviGSHmCacheAdd(operation *utils.RestOp, key strin
 		return errors.New("monitor port not present in response")
 	}
 	port := int32(portF)
+	description, _ := respElem["description"].(string)
 
 	cksum := gslbutils.GetGSLBHmChecksum(name, hmType, port)
 	k := avicache.TenantName{Tenant: operation.Tenant, Name: name}
@@ -1060,6 +1143,7 @@ func (restOp *RestOperations) AviGSHmCacheAdd(operation *utils.RestOp, key strin
 			hmCacheObj.Name = name
 			hmCacheObj.Type = hmType
 			hmCacheObj.Port = port
+			hmCacheObj.Description = description
 			gslbutils.Logf(spew.Sprintf("key: %s, cacheKey: %v, value: %v, msg: updated HM cache\n", key, k,
 				utils.Stringify(hmCacheObj)))
 		} else {
@@ -1079,6 +1163,7 @@ func (restOp *RestOperations) AviGSHmCacheAdd(operation *utils.RestOp, key strin
 			UUID:             uuid,
 			Type:             hmType,
 			Port:             port,
+			Description:      description,
 			CloudConfigCksum: cksum,
 		}
 		restOp.hmCache.AviHmCacheAdd(k, &hmCacheObj)
@@ -1089,6 +1174,25 @@ func (restOp *RestOperations) AviGSHmCacheAdd(operation *utils.RestOp, key strin
 	return nil
 }
 
+// getGraphMemberObjs returns the member objects of the GS graph for key. It's used for a GS whose
+// description has only the checksum of the member objects, the graph's member objects are returned
+// only if the graph has the same checksum as the GS.
+func getGraphMemberObjs(key string, cksum uint32) []string {
+	ok, aviModelIntf := nodes.SharedAviGSGraphLister().Get(key)
+	if !ok {
+		return []string{}
+	}
+	gsGraph, ok := aviModelIntf.(*nodes.AviGSObjectGraph)
+	if !ok {
+		return []string{}
+	}
+	gsCopy := gsGraph.GetCopy()
+	if gsCopy.GraphChecksum != cksum {
+		return []string{}
+	}
+	return gsCopy.GetMemberObjList()
+}
+
 func (restOp *RestOperations) AviGSCacheAdd(operation *utils.RestOp, key string) error {
 	if (operation.Err != nil) || (operation.Response == nil) {
 		gslbutils.Warnf("key: %s, response: %s, msg: rest operation has err or no response for GS: %s", key,
@@ -1116,6 +1220,9 @@ func (restOp *RestOperations) AviGSCacheAdd(operation *utils.RestOp, key string)
 	if err != nil {
 		gslbutils.Errf("key: %s, resp: %v, msg: error in getting checksum for gslb svc: %s", key, respElem, err)
 	}
+	if len(memberObjs) == 0 {
+		memberObjs = getGraphMemberObjs(key, cksum)
+	}
 	gslbutils.Debugf("key: %s, resp: %s, cksum: %d, msg: GS information", key, utils.Stringify(respElem), cksum)
 	k := avicache.TenantName{Tenant: operation.Tenant, Name: name}
 	gsCache, ok := restOp.cache.AviCacheGet(k)
@@ -1166,6 +1273,17 @@ func (restOp *RestOperations) AviGSCacheAdd(operation *utils.RestOp, key string)
 }
 
 func SyncFromNodesLayer(key string, wg *sync.WaitGroup) error {
+	// a standby AMKO replica doesn't write to the controller, the keys are published again once it
+	// becomes the leader
+	if !gslbutils.IsAMKOLeader() {
+		gslbutils.Debugf("key: %s, msg: not the AMKO leader, skipping the key in rest layer", key)
+		return nil
+	}
+	// the keys skipped while the reconciliation is paused are published again once it's resumed
+	if gslbutils.IsReconcilePaused() {
+		gslbutils.Logf("key: %s, msg: reconciliation is paused, skipping the key in rest layer", key)
+		return nil
+	}
 	cache := avicache.GetAviCache()
 	hmCache := avicache.GetAviHmCache()
 	aviclient := avicache.SharedAviClients()
@@ -0,0 +1,148 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package rest
+
+import (
+	"reflect"
+	"sync"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+
+	gdpalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+var (
+	drainStatusLock sync.Mutex
+	// lastDrainStatus is the drain status last written to the GDP object
+	lastDrainStatus []gdpalphav2.ClusterDrainStatus
+	// drainStatusPending is set if a GSLB service was written or deleted since the last update of
+	// the drain status
+	drainStatusPending gslbutils.StatusFlag
+)
+
+func isMemberDisabledInCache(gsCacheObj *avicache.AviGSCache, ipAddr string) bool {
+	if gsCacheObj == nil {
+		return false
+	}
+	for _, member := range gsCacheObj.Members {
+		if member.IPAddr == ipAddr {
+			return !member.Enabled
+		}
+	}
+	return false
+}
+
+// GetClustersDrainStatus returns the drain progress for each of the clusters. For a cluster, all
+// the GS members from that cluster are counted, and out of those, the members which are disabled
+// on the Avi controller (as per the GS cache).
+func GetClustersDrainStatus(clusters []string) []gdpalphav2.ClusterDrainStatus {
+	drainStatus := make([]gdpalphav2.ClusterDrainStatus, len(clusters))
+	clusterIdx := make(map[string]int)
+	for idx, cname := range clusters {
+		drainStatus[idx].Cluster = cname
+		clusterIdx[cname] = idx
+	}
+	if len(clusters) == 0 {
+		return drainStatus
+	}
+
+	agl := nodes.SharedAviGSGraphLister()
+	aviCache := avicache.GetAviCache()
+	for _, modelName := range agl.GetAll() {
+		found, obj := agl.Get(modelName)
+		if !found {
+			continue
+		}
+		gsGraph := obj.(*nodes.AviGSObjectGraph).GetCopy()
+		var gsCacheObj *avicache.AviGSCache
+		if gsCache, ok := aviCache.AviCacheGet(avicache.TenantName{Tenant: gsGraph.Tenant, Name: gsGraph.Name}); ok {
+			gsCacheObj, _ = gsCache.(*avicache.AviGSCache)
+		}
+		for _, member := range gsGraph.MemberObjs {
+			idx, ok := clusterIdx[member.Cluster]
+			if !ok {
+				continue
+			}
+			drainStatus[idx].Members++
+			if isMemberDisabledInCache(gsCacheObj, member.IPAddr) {
+				drainStatus[idx].DisabledMembers++
+			}
+		}
+	}
+	return drainStatus
+}
+
+// RequestGDPDrainStatusUpdate marks the drain status of the GDP object as outdated. It's called for
+// every GSLB service written or deleted, so it only sets a flag, and the drain status is updated by
+// the next run of UpdatePendingGDPDrainStatus.
+func RequestGDPDrainStatusUpdate() {
+	drainStatusPending.Set(true)
+}
+
+// UpdatePendingGDPDrainStatus updates the drain status of the GDP object, if an update was requested
+// since its last run. It's run periodically, so that the changes of a burst of GSLB services are
+// written together.
+func UpdatePendingGDPDrainStatus() {
+	if !drainStatusPending.IsSet() {
+		return
+	}
+	// clear the flag before computing the status, a request coming in meanwhile is handled by the
+	// next run
+	drainStatusPending.Set(false)
+	UpdateGDPDrainStatus("drainstatus")
+}
+
+// UpdateGDPDrainStatus computes the drain progress of the clusters in drain mode and writes it
+// to the status of the accepted GDP object, if it changed.
+func UpdateGDPDrainStatus(key string) {
+	drainClusters := gslbutils.GetGlobalFilter().GetDrainClusters()
+
+	drainStatusLock.Lock()
+	defer drainStatusLock.Unlock()
+	if len(drainClusters) == 0 && len(lastDrainStatus) == 0 {
+		return
+	}
+	drainStatus := GetClustersDrainStatus(drainClusters)
+	if reflect.DeepEqual(drainStatus, lastDrainStatus) {
+		return
+	}
+	gslbutils.Logf("key: %s, drainStatus: %s, msg: drain status changed", key, utils.Stringify(drainStatus))
+
+	name, ns := gslbutils.GetGDPObj()
+	if name == "" {
+		// the GDP object was deleted, nothing to update
+		gslbutils.Debugf("key: %s, msg: no accepted GDP object, won't update the drain status", key)
+		lastDrainStatus = drainStatus
+		return
+	}
+	err := gslbutils.UpdateGDPStatus(ns, name, func(gdp *gdpalphav2.GlobalDeploymentPolicy) bool {
+		if reflect.DeepEqual(gdp.Status.DrainStatus, drainStatus) {
+			return false
+		}
+		gdp.Status.DrainStatus = drainStatus
+		return true
+	})
+	if err != nil {
+		gslbutils.Errf("key: %s, ns: %s, gdp: %s, msg: error in updating the drain status: %s", key, ns, name, err)
+		// retry on the next run of the drain status worker
+		RequestGDPDrainStatusUpdate()
+		return
+	}
+	lastDrainStatus = drainStatus
+}
@@ -0,0 +1,40 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package rest
+
+import (
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// DeleteOrphanedHM deletes the health monitor hm, which was created by AMKO and doesn't belong to
+// any GS, from the Avi controller and the hm cache. The GS keys can't be used to delete such health
+// monitors, as their names may not map to a GS.
+func DeleteOrphanedHM(hm *avicache.AviHmObj) error {
+	restOp := NewRestOperations(avicache.GetAviCache(), avicache.GetAviHmCache(), avicache.SharedAviClients())
+	key := hm.Tenant + "/" + hm.Name
+	operation := restOp.AviGsHmDel(hm.UUID, hm.Tenant, key, hm.Name)
+	bkt := utils.Bkt(key, gslbutils.NumRestWorkers)
+	err := AviRestOperateWrapper(restOp, restOp.aviRestPoolClient.AviClient[bkt], operation)
+	// the health monitor is already deleted, if it's not found
+	if err != nil && restStatusCode(err) != "404" {
+		gslbutils.Errf("key: %s, uuid: %s, msg: error in deleting the orphaned health monitor: %s", key, hm.UUID, err)
+		return err
+	}
+	restOp.AviGSHmCacheDel(restOp.hmCache, operation, key)
+	return nil
+}
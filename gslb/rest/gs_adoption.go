@@ -0,0 +1,185 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/url"
+	"strings"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+
+	"github.com/avinetworks/sdk/go/clients"
+	avimodels "github.com/avinetworks/sdk/go/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// getGSCollection returns the GSLB services listed by uri, following the next pages if any.
+func getGSCollection(client *clients.AviClient, uri string) ([]avimodels.GslbService, error) {
+	var gsList []avimodels.GslbService
+	for {
+		result, err := avicache.AviGetCollectionRaw(client, uri)
+		if err != nil {
+			return nil, errors.New("GS get URI " + uri + " returned error: " + err.Error())
+		}
+		elems := make([]json.RawMessage, result.Count)
+		if err := json.Unmarshal(result.Results, &elems); err != nil {
+			return nil, errors.New("failed to unmarshal gslb service data, err: " + err.Error())
+		}
+		for _, elem := range elems {
+			gs := avimodels.GslbService{}
+			if err := json.Unmarshal(elem, &gs); err != nil || gs.Name == nil || gs.UUID == nil {
+				continue
+			}
+			gsList = append(gsList, gs)
+		}
+		nextURI := strings.Split(result.Next, "/api/gslbservice")
+		if result.Next == "" || len(nextURI) < 2 {
+			break
+		}
+		uri = "/api/gslbservice" + nextURI[1]
+	}
+	return gsList, nil
+}
+
+// findGSForFqdn returns the GSLB service on the Avi controller named gsName, or with fqdn as one of
+// its domain names, irrespective of who created it. Returns nil if there's no such GSLB service. The
+// GSLB services are filtered by the Avi controller, by name and by domain name, so that the lookup
+// doesn't list all the GSLB services.
+func findGSForFqdn(client *clients.AviClient, gsName, fqdn string) (*avimodels.GslbService, error) {
+	var found []avimodels.GslbService
+	foundUUIDs := make(map[string]bool)
+	for _, uri := range []string{
+		"/api/gslbservice?name=" + url.QueryEscape(gsName),
+		"/api/gslbservice?domain_names=" + url.QueryEscape(fqdn),
+	} {
+		gsList, err := getGSCollection(client, uri)
+		if err != nil {
+			return nil, err
+		}
+		for _, gs := range gsList {
+			// the filters are checked again, the same GS can be returned by both the lookups
+			if *gs.Name != gsName && !gslbutils.PresentInList(fqdn, gs.DomainNames) {
+				continue
+			}
+			if foundUUIDs[*gs.UUID] {
+				continue
+			}
+			foundUUIDs[*gs.UUID] = true
+			found = append(found, gs)
+		}
+	}
+	switch len(found) {
+	case 0:
+		return nil, nil
+	case 1:
+		return &found[0], nil
+	}
+	names := make([]string, len(found))
+	for i := range found {
+		names[i] = *found[i].Name
+	}
+	return nil, errors.New("more than one GSLB service found for fqdn " + fqdn + ": " + strings.Join(names, ","))
+}
+
+// validateGSForAdoption returns an error if the GSLB service can't be taken over by AMKO for fqdn,
+// i.e., if it's created by another user or serves any other domain names, which AMKO would remove.
+func validateGSForAdoption(gs *avimodels.GslbService, fqdn string) error {
+	if gs.CreatedBy != nil && *gs.CreatedBy != "" && *gs.CreatedBy != gslbutils.AmkoUser {
+		return errors.New("GSLB service " + *gs.Name + " is created by " + *gs.CreatedBy)
+	}
+	if len(gs.DomainNames) != 1 || gs.DomainNames[0] != fqdn {
+		return errors.New("GSLB service " + *gs.Name + " has the domain names " + strings.Join(gs.DomainNames, ",") +
+			", only " + fqdn + " is allowed")
+	}
+	if gs.TenantRef != nil && !strings.HasSuffix(strings.Split(*gs.TenantRef, "#")[0], "/tenant/"+utils.ADMIN_NS) {
+		return errors.New("GSLB service " + *gs.Name + " is not in the " + utils.ADMIN_NS + " tenant")
+	}
+	return nil
+}
+
+// adoptGS looks up an existing GSLB service for the fqdn of a GS graph, which isn't in the cache as
+// it wasn't created by AMKO. If it can be adopted, it's added to the cache with the key of the GS
+// graph, so that the GS graph is synced to it with a PUT instead of creating a new GS. The PUT
+// rewrites its created_by, description, members and health monitors, and renames it to the name of
+// the GS graph, without deleting it. In the dry run mode, the adoption is only planned, and the
+// cache isn't updated. Returns nil if there's no GS to adopt.
+func (restOp *RestOperations) adoptGS(aviGSGraph *nodes.AviGSObjectGraph, gsKey avicache.TenantName,
+	key string) *avicache.AviGSCache {
+	if len(restOp.aviRestPoolClient.AviClient) == 0 {
+		return nil
+	}
+	aviClient := restOp.aviRestPoolClient.AviClient[utils.Bkt(key, gslbutils.NumRestWorkers)]
+	fqdn := aviGSGraph.Name
+	gs, err := findGSForFqdn(aviClient, gsKey.Name, fqdn)
+	if err != nil {
+		gslbutils.Errf("key: %s, fqdn: %s, msg: error in looking up a GSLB service to adopt: %s", key, fqdn, err)
+		metrics.GSAdoptions.WithLabelValues(metrics.AdoptionRejected).Inc()
+		return nil
+	}
+	if gs == nil {
+		gslbutils.Logf("key: %s, fqdn: %s, msg: no existing GSLB service to adopt, a new one will be created", key, fqdn)
+		return nil
+	}
+	if err := validateGSForAdoption(gs, fqdn); err != nil {
+		gslbutils.Errf("key: %s, fqdn: %s, msg: can't adopt the GSLB service: %s", key, fqdn, err)
+		metrics.GSAdoptions.WithLabelValues(metrics.AdoptionRejected).Inc()
+		return nil
+	}
+
+	var members []avicache.GSMember
+	for _, group := range gs.Groups {
+		for _, member := range group.Members {
+			if member.IP == nil || member.IP.Addr == nil {
+				continue
+			}
+			gsMember := avicache.GSMember{IPAddr: *member.IP.Addr, Enabled: member.Enabled == nil || *member.Enabled}
+			if member.Ratio != nil {
+				gsMember.Weight = *member.Ratio
+			}
+			members = append(members, gsMember)
+		}
+	}
+	// the checksum is left unset, so that the GS is always updated
+	gsCacheObj := &avicache.AviGSCache{
+		Name:    gsKey.Name,
+		Tenant:  gsKey.Tenant,
+		Uuid:    *gs.UUID,
+		Members: members,
+	}
+	// the Avi cache isn't updated in the dry run mode, the adoption is only recorded in the plan of
+	// the key, along with the PUT which would follow it
+	if gslbutils.IsDryRun() {
+		addPlannedOperation(key, PlannedOperation{
+			Method: PlannedAdoption,
+			Model:  "GSLBService",
+			Tenant: gsKey.Tenant,
+			Name:   *gs.Name,
+			Path:   "/api/gslbservice/" + *gs.UUID,
+		})
+		gslbutils.Logf("key: %s, fqdn: %s, gsName: %s, uuid: %s, msg: dry run, planned the adoption of the existing GSLB service",
+			key, fqdn, *gs.Name, *gs.UUID)
+		return gsCacheObj
+	}
+	restOp.cache.AviCacheAdd(gsKey, gsCacheObj)
+	gslbutils.Logf("key: %s, fqdn: %s, gsName: %s, uuid: %s, msg: adopted the existing GSLB service, it will be updated",
+		key, fqdn, *gs.Name, *gs.UUID)
+	metrics.GSAdoptions.WithLabelValues(metrics.AdoptionAdopted).Inc()
+	return gsCacheObj
+}
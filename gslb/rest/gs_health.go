@@ -0,0 +1,97 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package rest
+
+import (
+	"errors"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+)
+
+func operStateToHealth(operState string) string {
+	switch operState {
+	case avicache.OperUp:
+		return gslbutils.HealthUp
+	case avicache.OperDown:
+		return gslbutils.HealthDown
+	case avicache.OperDisabled:
+		return gslbutils.HealthDisabled
+	}
+	return gslbutils.HealthUnknown
+}
+
+// GetGSHealth fetches the runtime of all the GSLB services created by AMKO and returns their health
+// and the health of their members, keyed by the GS name. GSLB services which aren't created on
+// the Avi controller yet are skipped.
+func GetGSHealth() (map[string]gslbalphav2.GSLBServiceHealth, error) {
+	gsHealth := make(map[string]gslbalphav2.GSLBServiceHealth)
+	aviRestClientPool := avicache.SharedAviClients()
+	if len(aviRestClientPool.AviClient) < 1 {
+		return gsHealth, errors.New("no avi clients initialized")
+	}
+	aviClient := aviRestClientPool.AviClient[0]
+
+	agl := nodes.SharedAviGSGraphLister()
+	aviCache := avicache.GetAviCache()
+	for _, modelName := range agl.GetAll() {
+		found, obj := agl.Get(modelName)
+		if !found {
+			continue
+		}
+		gsGraph := obj.(*nodes.AviGSObjectGraph).GetCopy()
+		gsCache, ok := aviCache.AviCacheGet(avicache.TenantName{Tenant: gsGraph.Tenant, Name: gsGraph.Name})
+		if !ok {
+			continue
+		}
+		gsCacheObj, ok := gsCache.(*avicache.AviGSCache)
+		if !ok || gsCacheObj.Uuid == "" {
+			continue
+		}
+		gsState, err := avicache.GetGSRuntimeState(aviClient, gsCacheObj.Uuid)
+		if err != nil {
+			gslbutils.Warnf("gsName: %s, msg: couldn't fetch the GS runtime, err: %s", gsGraph.Name, err)
+			continue
+		}
+
+		fqdn := gsGraph.Name
+		if len(gsGraph.DomainNames) > 0 {
+			fqdn = gsGraph.DomainNames[0]
+		}
+		health := gslbalphav2.GSLBServiceHealth{
+			Fqdn:  fqdn,
+			UUID:  gsCacheObj.Uuid,
+			State: operStateToHealth(gsState.OperState),
+		}
+		for _, member := range gsGraph.MemberObjs {
+			memberState := operStateToHealth(gsState.Members[member.IPAddr])
+			if member.Drained && memberState == gslbutils.HealthUnknown {
+				memberState = gslbutils.HealthDisabled
+			}
+			health.Members = append(health.Members, gslbalphav2.GSLBServiceMemberHealth{
+				Cluster:   member.Cluster,
+				ObjType:   member.ObjType,
+				Namespace: member.Namespace,
+				Name:      member.Name,
+				IPAddr:    member.IPAddr,
+				State:     memberState,
+			})
+		}
+		gsHealth[gsGraph.Name] = health
+	}
+	return gsHealth, nil
+}
@@ -0,0 +1,298 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package rest
+
+import (
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	avimodels "github.com/avinetworks/sdk/go/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// FieldDiff is the change of a field of an Avi object, from its value in the Avi cache to its
+// planned value. An empty value means that the field isn't set.
+type FieldDiff struct {
+	Field   string `json:"field"`
+	Current string `json:"current"`
+	Planned string `json:"planned"`
+}
+
+// PlannedAdoption is the method of a planned operation which adopts an existing GSLB service, which
+// isn't created by AMKO, for the fqdn of a GS graph.
+const PlannedAdoption = "ADOPT"
+
+// PlannedOperation is a rest operation which would have been executed on the Avi controller, if
+// AMKO wasn't running in the dry run mode.
+type PlannedOperation struct {
+	Method string      `json:"method"`
+	Model  string      `json:"model"`
+	Tenant string      `json:"tenant"`
+	Name   string      `json:"name"`
+	Path   string      `json:"path"`
+	Diff   []FieldDiff `json:"diff"`
+}
+
+// GSPlan is the rest operations planned for the key of a GS graph, in the order in which they
+// would have been executed.
+type GSPlan struct {
+	Key        string             `json:"key"`
+	Operations []PlannedOperation `json:"operations"`
+	Time       time.Time          `json:"time"`
+}
+
+// planStore keeps the operations planned by the latest sync of every key in the dry run mode. The
+// Avi cache isn't updated in the dry run mode, so a key is planned again on every sync till the Avi
+// controller is in sync with its GS graph.
+type planStore struct {
+	lock  sync.RWMutex
+	plans map[string]*GSPlan
+}
+
+var plans = planStore{plans: make(map[string]*GSPlan)}
+
+// resetPlan removes the operations planned for a key, before it's synced again.
+func resetPlan(key string) {
+	plans.lock.Lock()
+	defer plans.lock.Unlock()
+	delete(plans.plans, key)
+}
+
+func addPlannedOperation(key string, op PlannedOperation) {
+	plans.lock.Lock()
+	defer plans.lock.Unlock()
+	plan, ok := plans.plans[key]
+	if !ok {
+		plan = &GSPlan{Key: key}
+		plans.plans[key] = plan
+	}
+	plan.Time = time.Now()
+	// an object can be updated more than once in a sync, as the Avi cache isn't updated in between
+	for i, planned := range plan.Operations {
+		if planned.Method == op.Method && planned.Model == op.Model && planned.Name == op.Name {
+			plan.Operations[i] = op
+			return
+		}
+	}
+	plan.Operations = append(plan.Operations, op)
+}
+
+// GetPlan returns the plans of the keys, sorted by key, for the GS name if it's non-empty.
+func GetPlan(gsName string) []GSPlan {
+	plans.lock.RLock()
+	defer plans.lock.RUnlock()
+
+	keys := make([]string, 0, len(plans.plans))
+	for key := range plans.plans {
+		if gsName != "" {
+			if _, name := utils.ExtractNamespaceObjectName(key); name != gsName {
+				continue
+			}
+		}
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	result := make([]GSPlan, 0, len(keys))
+	for _, key := range keys {
+		plan := *plans.plans[key]
+		plan.Operations = append([]PlannedOperation{}, plan.Operations...)
+		result = append(result, plan)
+	}
+	return result
+}
+
+// planOperation records the operation in the plan of the key instead of executing it, if AMKO is
+// running in the dry run mode. Returns true if the operation was planned.
+func (restOp *RestOperations) planOperation(operation *utils.RestOp, key string) bool {
+	if !gslbutils.IsDryRun() {
+		return false
+	}
+	planned := PlannedOperation{
+		Method: string(operation.Method),
+		Model:  operation.Model,
+		Tenant: operation.Tenant,
+		Name:   operation.ObjName,
+		Path:   operation.Path,
+	}
+	switch operation.Model {
+	case "GSLBService":
+		gs := getGSFromOperation(operation)
+		var current *avicache.AviGSCache
+		if gsObj, ok := restOp.cache.AviCacheGet(avicache.TenantName{Tenant: operation.Tenant,
+			Name: operation.ObjName}); ok {
+			current, _ = gsObj.(*avicache.AviGSCache)
+		}
+		planned.Diff = gsDiff(gs, current)
+	case "HealthMonitor":
+		hm := getHmFromOperation(operation)
+		// the operations to create and update a health monitor are named after the GS
+		if hm != nil && hm.Name != nil {
+			planned.Name = *hm.Name
+		}
+		var current *avicache.AviHmObj
+		if hmObj, ok := restOp.hmCache.AviHmCacheGet(avicache.TenantName{Tenant: operation.Tenant,
+			Name: planned.Name}); ok {
+			current, _ = hmObj.(*avicache.AviHmObj)
+		}
+		planned.Diff = hmDiff(hm, current)
+	}
+	gslbutils.Logf("key: %s, method: %s, model: %s, name: %s, msg: dry run, planned the rest operation", key,
+		planned.Method, planned.Model, planned.Name)
+	addPlannedOperation(key, planned)
+	return true
+}
+
+func getGSFromOperation(operation *utils.RestOp) *avimodels.GslbService {
+	switch obj := operation.Obj.(type) {
+	case avimodels.GslbService:
+		return &obj
+	case *avimodels.GslbService:
+		return obj
+	}
+	return nil
+}
+
+func getHmFromOperation(operation *utils.RestOp) *avimodels.HealthMonitor {
+	switch obj := operation.Obj.(type) {
+	case avimodels.HealthMonitor:
+		return &obj
+	case *avimodels.HealthMonitor:
+		return obj
+	}
+	return nil
+}
+
+func addDiff(diffs []FieldDiff, field, current, planned string) []FieldDiff {
+	if current == planned {
+		return diffs
+	}
+	return append(diffs, FieldDiff{Field: field, Current: current, Planned: planned})
+}
+
+func memberDesc(weight int32, enabled bool) string {
+	return "ratio=" + strconv.Itoa(int(weight)) + ", enabled=" + strconv.FormatBool(enabled)
+}
+
+func sortedJoin(values []string) string {
+	sorted := append([]string{}, values...)
+	sort.Strings(sorted)
+	return strings.Join(sorted, ",")
+}
+
+// gsDiff returns the changes of the fields of a GS which are kept in the Avi cache, gs is nil for a
+// deleted GS and current is nil for a new GS.
+func gsDiff(gs *avimodels.GslbService, current *avicache.AviGSCache) []FieldDiff {
+	var diffs []FieldDiff
+	currentMembers := make(map[string]avicache.GSMember)
+	var currentObjs, currentHms []string
+	if current != nil {
+		for _, member := range current.Members {
+			currentMembers[member.IPAddr] = member
+		}
+		currentObjs, currentHms = current.K8sObjects, current.HealthMonitorNames
+	}
+	plannedMembers := make(map[string]avicache.GSMember)
+	var plannedObjs, plannedHms []string
+	if gs != nil {
+		if current == nil {
+			diffs = addDiff(diffs, "domainNames", "", strings.Join(gs.DomainNames, ","))
+		}
+		for _, group := range gs.Groups {
+			for _, member := range group.Members {
+				if member.IP == nil || member.IP.Addr == nil {
+					continue
+				}
+				m := avicache.GSMember{IPAddr: *member.IP.Addr, Enabled: true}
+				if member.Ratio != nil {
+					m.Weight = *member.Ratio
+				}
+				if member.Enabled != nil {
+					m.Enabled = *member.Enabled
+				}
+				plannedMembers[m.IPAddr] = m
+			}
+		}
+		if gs.Description != nil && *gs.Description != "" {
+			if desc, err := gslbutils.DecodeGSDescription(*gs.Description); err == nil {
+				plannedObjs = desc.MemberObjList()
+				// the description has only the checksum of the member objects, compare that instead
+				if desc.Truncated {
+					plannedObjs = []string{strconv.Itoa(desc.MemberCount) + " objects"}
+					if gslbutils.GetGSMemberObjsChecksum(append([]string{}, currentObjs...)) == desc.MembersChecksum() {
+						plannedObjs = currentObjs
+					}
+				}
+			}
+		}
+		for _, hmRef := range gs.HealthMonitorRefs {
+			plannedHms = append(plannedHms, strings.TrimPrefix(hmRef, "/api/healthmonitor?name="))
+		}
+	}
+
+	ips := make([]string, 0, len(currentMembers)+len(plannedMembers))
+	for ip := range currentMembers {
+		ips = append(ips, ip)
+	}
+	for ip := range plannedMembers {
+		if _, ok := currentMembers[ip]; !ok {
+			ips = append(ips, ip)
+		}
+	}
+	sort.Strings(ips)
+	for _, ip := range ips {
+		cm, inCurrent := currentMembers[ip]
+		pm, inPlanned := plannedMembers[ip]
+		switch {
+		case !inPlanned:
+			diffs = addDiff(diffs, "member "+ip, memberDesc(cm.Weight, cm.Enabled), "")
+		case !inCurrent:
+			diffs = addDiff(diffs, "member "+ip, "", memberDesc(pm.Weight, pm.Enabled))
+		default:
+			diffs = addDiff(diffs, "member "+ip+" ratio", strconv.Itoa(int(cm.Weight)), strconv.Itoa(int(pm.Weight)))
+			diffs = addDiff(diffs, "member "+ip+" enabled", strconv.FormatBool(cm.Enabled),
+				strconv.FormatBool(pm.Enabled))
+		}
+	}
+	diffs = addDiff(diffs, "memberObjects", sortedJoin(currentObjs), sortedJoin(plannedObjs))
+	diffs = addDiff(diffs, "healthMonitors", sortedJoin(currentHms), sortedJoin(plannedHms))
+	return diffs
+}
+
+// hmDiff returns the changes of the fields of a health monitor which are kept in the Avi cache, hm
+// is nil for a deleted health monitor and current is nil for a new health monitor.
+func hmDiff(hm *avimodels.HealthMonitor, current *avicache.AviHmObj) []FieldDiff {
+	var currentType, currentPort, plannedType, plannedPort string
+	if current != nil {
+		currentType, currentPort = current.Type, strconv.Itoa(int(current.Port))
+	}
+	if hm != nil {
+		if hm.Type != nil {
+			plannedType = *hm.Type
+		}
+		if hm.MonitorPort != nil {
+			plannedPort = strconv.Itoa(int(*hm.MonitorPort))
+		}
+	}
+	var diffs []FieldDiff
+	diffs = addDiff(diffs, "type", currentType, plannedType)
+	diffs = addDiff(diffs, "monitorPort", currentPort, plannedPort)
+	return diffs
+}
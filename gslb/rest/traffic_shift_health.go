@@ -0,0 +1,77 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package rest
+
+import (
+	"errors"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+)
+
+// GetClusterMembersHealth fetches the runtime of all the GSLB services which have enabled members
+// from cluster cname, and counts the members of this cluster which are up and down as per the
+// Avi controller. Members whose GSLB services aren't created yet are only counted as members.
+func GetClusterMembersHealth(cname string) (gslbutils.TargetHealth, error) {
+	health := gslbutils.TargetHealth{}
+	aviRestClientPool := avicache.SharedAviClients()
+	if len(aviRestClientPool.AviClient) < 1 {
+		return health, errors.New("no avi clients initialized")
+	}
+	aviClient := aviRestClientPool.AviClient[0]
+
+	agl := nodes.SharedAviGSGraphLister()
+	aviCache := avicache.GetAviCache()
+	for _, modelName := range agl.GetAll() {
+		found, obj := agl.Get(modelName)
+		if !found {
+			continue
+		}
+		gsGraph := obj.(*nodes.AviGSObjectGraph).GetCopy()
+		memberIPs := []string{}
+		for _, member := range gsGraph.MemberObjs {
+			if member.Cluster == cname && !member.Drained {
+				memberIPs = append(memberIPs, member.IPAddr)
+			}
+		}
+		if len(memberIPs) == 0 {
+			continue
+		}
+		health.Members += len(memberIPs)
+
+		gsCache, ok := aviCache.AviCacheGet(avicache.TenantName{Tenant: gsGraph.Tenant, Name: gsGraph.Name})
+		if !ok {
+			continue
+		}
+		gsCacheObj, ok := gsCache.(*avicache.AviGSCache)
+		if !ok || gsCacheObj.Uuid == "" {
+			continue
+		}
+		gsState, err := avicache.GetGSRuntimeState(aviClient, gsCacheObj.Uuid)
+		if err != nil {
+			return health, err
+		}
+		for _, ipAddr := range memberIPs {
+			switch gsState.Members[ipAddr] {
+			case avicache.OperUp:
+				health.UpMembers++
+			case avicache.OperDown:
+				health.DownMembers++
+			}
+		}
+	}
+	return health, nil
+}
@@ -130,6 +130,42 @@ func AddSvcMeta(t *testing.T, name, ns, host, svc, ip, cname string, create bool
 	return svcMeta
 }
 
+func AddSvcMetaWithWeight(t *testing.T, name, ns, host, svc, ip, cname string, weight int32) k8sobjects.SvcMeta {
+	acceptedSvcStore := gslbutils.GetAcceptedLBSvcStore()
+	key := ingestion.GetSvcKey(gslbutils.ObjectAdd, cname, ns, name)
+	svcMeta := k8sobjects.SvcMeta{
+		Name:          name,
+		Namespace:     ns,
+		Hostname:      host,
+		IPAddr:        ip,
+		Cluster:       cname,
+		Port:          80,
+		Protocol:      "TCP",
+		TrafficWeight: weight,
+	}
+	acceptedSvcStore.AddOrUpdate(svcMeta, cname, ns, name)
+	addKeyToIngestionQueue(ns, key)
+	return svcMeta
+}
+
+func AddSvcMetaWithDrain(t *testing.T, name, ns, host, svc, ip, cname string, drain bool) k8sobjects.SvcMeta {
+	acceptedSvcStore := gslbutils.GetAcceptedLBSvcStore()
+	key := ingestion.GetSvcKey(gslbutils.ObjectAdd, cname, ns, name)
+	svcMeta := k8sobjects.SvcMeta{
+		Name:      name,
+		Namespace: ns,
+		Hostname:  host,
+		IPAddr:    ip,
+		Cluster:   cname,
+		Port:      80,
+		Protocol:  "TCP",
+		Drain:     drain,
+	}
+	acceptedSvcStore.AddOrUpdate(svcMeta, cname, ns, name)
+	addKeyToIngestionQueue(ns, key)
+	return svcMeta
+}
+
 func AddIngressMeta(t *testing.T, name, ns, host, svc, ip, cname string, create bool) k8sobjects.IngressHostMeta {
 	acceptedIngStore := gslbutils.GetAcceptedIngressStore()
 	objName := name + "/" + host
@@ -522,3 +558,99 @@ func TestGSGraphsForMultiSvcUpdate(t *testing.T) {
 	ok, msg = waitAndVerify(t, utils.ADMIN_NS+"/"+updatedSvc2.Hostname, false)
 	verifyGsGraph(t, updatedSvc2, false, 0, false)
 }
+
+func TestGSGraphsForSvcTrafficWeight(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	prefix := "stw-"
+	acceptedSvcStore := gslbutils.GetAcceptedLBSvcStore()
+	hostname := prefix + "host1.avi.com"
+	fooSvc := prefix + "foo-svc"
+	barSvc := prefix + "bar-svc"
+
+	// weight for the foo svc comes from the object, for the bar svc from the namespace
+	gslbutils.GetTrafficWeightOverrides().SetNSAnnotationWeight(BarCluster, DefNS, 4)
+	svc1 := AddSvcMetaWithWeight(t, fooSvc, DefNS, hostname, DefSvc, "10.10.10.10", FooCluster, 7)
+	ok, msg := waitAndVerify(t, utils.ADMIN_NS+"/"+svc1.Hostname, false)
+	if !ok {
+		t.Fatalf("%s", msg)
+	}
+	svc2 := AddSvcMetaWithWeight(t, barSvc, DefNS, hostname, DefSvc, "10.10.10.20", BarCluster, 0)
+	ok, msg = waitAndVerify(t, utils.ADMIN_NS+"/"+svc2.Hostname, false)
+	if !ok {
+		t.Fatalf("%s", msg)
+	}
+	verifyGsGraph(t, svc1, true, 2, true)
+
+	modelName := utils.ADMIN_NS + "/" + nodes.DeriveGSLBServiceName(hostname)
+	_, aviModelIntf := nodes.SharedAviGSGraphLister().Get(modelName)
+	for _, member := range aviModelIntf.(*nodes.AviGSObjectGraph).MemberObjs {
+		if member.Cluster == FooCluster {
+			g.Expect(member.Weight).To(gomega.Equal(int32(7)))
+		} else {
+			g.Expect(member.Weight).To(gomega.Equal(int32(4)))
+		}
+	}
+	gslbutils.GetTrafficWeightOverrides().DeleteNSAnnotationWeight(BarCluster, DefNS)
+
+	// delete the svcs
+	acceptedSvcStore.DeleteClusterNSObj(svc1.Cluster, svc1.Namespace, svc1.Name)
+	addKeyToIngestionQueue(DefNS, GetSvcKey(gslbutils.ObjectDelete, svc1))
+	acceptedSvcStore.DeleteClusterNSObj(svc2.Cluster, svc2.Namespace, svc2.Name)
+	addKeyToIngestionQueue(DefNS, GetSvcKey(gslbutils.ObjectDelete, svc2))
+}
+
+func TestGSGraphsForSvcDrain(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	prefix := "sdr-"
+	acceptedSvcStore := gslbutils.GetAcceptedLBSvcStore()
+	hostname := prefix + "host1.avi.com"
+	fooSvc := prefix + "foo-svc"
+	barSvc := prefix + "bar-svc"
+
+	// the foo svc is drained via the annotation, the bar svc is drained as its cluster is in drain mode
+	gf := gslbutils.GetGlobalFilter()
+	gf.GlobalLock.Lock()
+	gf.DrainClusters = []string{BarCluster}
+	gf.GlobalLock.Unlock()
+	defer func() {
+		gf.GlobalLock.Lock()
+		gf.DrainClusters = []string{}
+		gf.GlobalLock.Unlock()
+	}()
+
+	svc1 := AddSvcMetaWithDrain(t, fooSvc, DefNS, hostname, DefSvc, "10.10.10.10", FooCluster, true)
+	ok, msg := waitAndVerify(t, utils.ADMIN_NS+"/"+svc1.Hostname, false)
+	if !ok {
+		t.Fatalf("%s", msg)
+	}
+	svc2 := AddSvcMetaWithDrain(t, barSvc, DefNS, hostname, DefSvc, "10.10.10.20", BarCluster, false)
+	ok, msg = waitAndVerify(t, utils.ADMIN_NS+"/"+svc2.Hostname, false)
+	if !ok {
+		t.Fatalf("%s", msg)
+	}
+	verifyGsGraph(t, svc1, true, 2, true)
+
+	modelName := utils.ADMIN_NS + "/" + nodes.DeriveGSLBServiceName(hostname)
+	_, aviModelIntf := nodes.SharedAviGSGraphLister().Get(modelName)
+	gsGraph := aviModelIntf.(*nodes.AviGSObjectGraph)
+	for _, member := range gsGraph.GetMemberObjs() {
+		g.Expect(member.Drained).To(gomega.Equal(true))
+	}
+
+	// removing the annotation must enable the member and change the checksum of the GS
+	prevChecksum := gsGraph.GetChecksum()
+	svc1 = AddSvcMetaWithDrain(t, fooSvc, DefNS, hostname, DefSvc, "10.10.10.10", FooCluster, false)
+	ok, msg = waitAndVerify(t, utils.ADMIN_NS+"/"+svc1.Hostname, false)
+	if !ok {
+		t.Fatalf("%s", msg)
+	}
+	g.Expect(gsGraph.GetGSMember(FooCluster, DefNS, fooSvc).Drained).To(gomega.Equal(false))
+	g.Expect(gsGraph.GetGSMember(BarCluster, DefNS, barSvc).Drained).To(gomega.Equal(true))
+	g.Expect(gsGraph.GetChecksum()).NotTo(gomega.Equal(prevChecksum))
+
+	// delete the svcs
+	acceptedSvcStore.DeleteClusterNSObj(svc1.Cluster, svc1.Namespace, svc1.Name)
+	addKeyToIngestionQueue(DefNS, GetSvcKey(gslbutils.ObjectDelete, svc1))
+	acceptedSvcStore.DeleteClusterNSObj(svc2.Cluster, svc2.Namespace, svc2.Name)
+	addKeyToIngestionQueue(DefNS, GetSvcKey(gslbutils.ObjectDelete, svc2))
+}
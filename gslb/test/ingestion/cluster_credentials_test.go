@@ -0,0 +1,119 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"io/ioutil"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestBuildSecretConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	kubeconfigData, err := ioutil.ReadFile("./testdata/test-kube-config")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+
+	// the context of the cluster is picked from the kubeconfig
+	cfg, err := gslbingestion.BuildSecretConfig("exp-scratch", map[string][]byte{gslbingestion.KubeConfigKey: kubeconfigData})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(cfg.Host).To(gomega.Equal("https://10.52.3.70:8443"))
+
+	cfg, err = gslbingestion.BuildSecretConfig("cluster1", map[string][]byte{
+		gslbingestion.ServerKey: []byte("https://10.10.10.10:6443"),
+		gslbingestion.TokenKey:  []byte("test-token"),
+		gslbingestion.CACertKey: []byte("test-ca"),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(cfg.Host).To(gomega.Equal("https://10.10.10.10:6443"))
+	g.Expect(cfg.BearerToken).To(gomega.Equal("test-token"))
+	g.Expect(cfg.TLSClientConfig.CAData).To(gomega.Equal([]byte("test-ca")))
+
+	cfg, err = gslbingestion.BuildSecretConfig("cluster1", map[string][]byte{
+		gslbingestion.ServerKey:     []byte("https://10.10.10.10:6443"),
+		gslbingestion.ClientCertKey: []byte("test-cert"),
+		gslbingestion.ClientKeyKey:  []byte("test-key"),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(cfg.TLSClientConfig.CertData).To(gomega.Equal([]byte("test-cert")))
+	g.Expect(cfg.TLSClientConfig.KeyData).To(gomega.Equal([]byte("test-key")))
+
+	_, err = gslbingestion.BuildSecretConfig("cluster1", map[string][]byte{gslbingestion.TokenKey: []byte("test-token")})
+	g.Expect(err).To(gomega.HaveOccurred())
+	_, err = gslbingestion.BuildSecretConfig("cluster1", map[string][]byte{
+		gslbingestion.ServerKey:     []byte("https://10.10.10.10:6443"),
+		gslbingestion.ClientCertKey: []byte("test-cert"),
+	})
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func getMemberClusterReadyMsg(cname string) string {
+	for _, member := range gslbutils.GetGSLBConfigStatus().MemberClusters {
+		if member.ClusterContext != cname {
+			continue
+		}
+		if cond := gslbalphav2.FindCondition(member.Conditions, gslbalphav2.ConditionReady); cond != nil {
+			return cond.Message
+		}
+	}
+	return ""
+}
+
+func TestMemberClusterCredentialsRotation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	cname, secretName := "rotated-cluster", "rotated-cluster-secret"
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: secretName, Namespace: gslbutils.AVISystem},
+		Data: map[string][]byte{
+			gslbingestion.ServerKey: []byte("https://127.0.0.1:1"),
+			gslbingestion.TokenKey:  []byte("old-token"),
+		},
+	}
+	gslbutils.GlobalKubeClient = k8sfake.NewSimpleClientset(secret)
+	defer func() { gslbutils.GlobalKubeClient = nil }()
+
+	// the clients are built from the secret, but the API server isn't reachable
+	gslbingestion.AddMemberCluster(cname, secretName, nil, nil)
+	gslbingestion.EvaluateMemberClusterHealth()
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterDisconnected, metav1.ConditionFalse)
+	g.Expect(getMemberClusterReadyMsg(cname)).To(gomega.ContainSubstring("can't access the services api"))
+
+	// the clients aren't rebuilt if the credentials haven't changed, secrets of other clusters
+	// are ignored
+	gslbingestion.UpdateMemberClusterCredentials(secret)
+	otherSecret := secret.DeepCopy()
+	otherSecret.Name = "other-secret"
+	otherSecret.Data = map[string][]byte{}
+	gslbingestion.UpdateMemberClusterCredentials(otherSecret)
+	g.Expect(getMemberClusterReadyMsg(cname)).To(gomega.ContainSubstring("can't access the services api"))
+
+	// the clients are rebuilt from the rotated credentials
+	rotatedSecret := secret.DeepCopy()
+	rotatedSecret.Data = map[string][]byte{gslbingestion.TokenKey: []byte("new-token")}
+	_, err := gslbutils.GlobalKubeClient.CoreV1().Secrets(gslbutils.AVISystem).Update(rotatedSecret)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	gslbingestion.UpdateMemberClusterCredentials(rotatedSecret)
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterDisconnected, metav1.ConditionFalse)
+	g.Expect(getMemberClusterReadyMsg(cname)).To(gomega.ContainSubstring("invalid credentials secret " + secretName))
+}
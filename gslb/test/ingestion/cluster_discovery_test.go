@@ -0,0 +1,154 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+const (
+	clusterAPINamespace   = "capi-clusters"
+	unreachableTestServer = "https://127.0.0.1:1"
+)
+
+// getClusterAPISecret returns a Cluster API secret of cluster cname, with a kubeconfig for an
+// unreachable API server.
+func getClusterAPISecret(cname, suffix string, labels map[string]string) *corev1.Secret {
+	kubeconfig := `apiVersion: v1
+kind: Config
+clusters:
+- name: ` + cname + `
+  cluster:
+    server: ` + unreachableTestServer + `
+contexts:
+- name: ` + cname + `-admin@` + cname + `
+  context:
+    cluster: ` + cname + `
+    user: ` + cname + `-admin
+current-context: ` + cname + `-admin@` + cname + `
+users:
+- name: ` + cname + `-admin
+  user:
+    token: test-token
+`
+	secretLabels := map[string]string{"cluster.x-k8s.io/cluster-name": cname}
+	for k, v := range labels {
+		secretLabels[k] = v
+	}
+	return &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: cname + suffix, Namespace: clusterAPINamespace, Labels: secretLabels},
+		Data:       map[string][]byte{"value": []byte(kubeconfig)},
+	}
+}
+
+func TestClusterAPIClusterDiscovery(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	amkoLabel := map[string]string{"amko": "true"}
+	gslbutils.GlobalKubeClient = k8sfake.NewSimpleClientset(
+		getClusterAPISecret("capi1", "-kubeconfig", amkoLabel),
+		// the other secrets of a Cluster API cluster aren't kubeconfigs
+		getClusterAPISecret("capi1", "-ca", amkoLabel),
+		// not selected by the label of the discovery
+		getClusterAPISecret("capi2", "-kubeconfig", nil))
+	defer func() { gslbutils.GlobalKubeClient = nil }()
+
+	discovery := &gslbalphav2.ClusterDiscovery{Format: gslbingestion.ClusterAPIFormat, Label: amkoLabel}
+	// the discovered clusters aren't reachable, so no controllers are returned, but they are
+	// still added to the GSLB configuration
+	g.Expect(gslbingestion.InitializeGSLBClusters(nil, discovery)).To(gomega.HaveLen(0))
+	verifyMemberClusterState(g, "capi1", gslbalphav2.ClusterDisconnected, metav1.ConditionFalse)
+	g.Expect(getMemberClusterReadyMsg("capi1")).To(gomega.ContainSubstring("can't access the services api"))
+	g.Expect(gslbutils.IsClusterContextPresent("capi1")).To(gomega.Equal(true))
+	_, ok := gslbingestion.GetMemberClusterState("capi2")
+	g.Expect(ok).To(gomega.Equal(false))
+	g.Expect(gslbutils.IsClusterContextPresent("capi2")).To(gomega.Equal(false))
+
+	// a cluster is added when its secret is created
+	capi3Secret := getClusterAPISecret("capi3", "-kubeconfig", amkoLabel)
+	gslbingestion.AddDiscoveredCluster(gslbingestion.ClusterAPIFormat, capi3Secret)
+	verifyMemberClusterState(g, "capi3", gslbalphav2.ClusterDisconnected, metav1.ConditionFalse)
+	g.Expect(gslbutils.IsClusterContextPresent("capi3")).To(gomega.Equal(true))
+
+	// a cluster is removed along with its GS members when its secret is deleted
+	addTestStaleSvc("capi3", "default", "capi3-svc")
+	gslbingestion.DeleteDiscoveredCluster(capi3Secret)
+	verifyStaleMemberKey(t, gslbutils.ObjectDelete, "capi3", "default", "capi3-svc")
+	_, ok = gslbingestion.GetMemberClusterState("capi3")
+	g.Expect(ok).To(gomega.Equal(false))
+	g.Expect(gslbutils.IsClusterContextPresent("capi3")).To(gomega.Equal(false))
+	for _, member := range gslbutils.GetGSLBConfigStatus().MemberClusters {
+		g.Expect(member.ClusterContext).NotTo(gomega.Equal("capi3"))
+	}
+
+	gslbingestion.DeleteDiscoveredCluster(getClusterAPISecret("capi1", "-kubeconfig", amkoLabel))
+	g.Expect(gslbutils.IsClusterContextPresent("capi1")).To(gomega.Equal(false))
+}
+
+func TestArgoCDClusterDiscovery(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	argoLabels := map[string]string{"argocd.argoproj.io/secret-type": "cluster"}
+	argoSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-argo1", Namespace: "argocd", Labels: argoLabels},
+		Data: map[string][]byte{
+			"name":   []byte("argo1"),
+			"server": []byte(unreachableTestServer),
+			"config": []byte(`{"bearerToken": "test-token", "tlsClientConfig": {"caData": "dGVzdC1jYQ=="}}`),
+		},
+	}
+	invalidArgoSecret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "cluster-argo2", Namespace: "argocd", Labels: argoLabels},
+		Data: map[string][]byte{
+			"name":   []byte("argo2"),
+			"server": []byte(unreachableTestServer),
+			"config": []byte(`{"tlsClientConfig": {"insecure": true}}`),
+		},
+	}
+	gslbutils.GlobalKubeClient = k8sfake.NewSimpleClientset(argoSecret, invalidArgoSecret)
+	defer func() { gslbutils.GlobalKubeClient = nil }()
+
+	discovery := &gslbalphav2.ClusterDiscovery{Format: gslbingestion.ArgoCDFormat, Namespace: "argocd"}
+	gslbingestion.InitializeGSLBClusters(nil, discovery)
+	verifyMemberClusterState(g, "argo1", gslbalphav2.ClusterDisconnected, metav1.ConditionFalse)
+	g.Expect(getMemberClusterReadyMsg("argo1")).To(gomega.ContainSubstring("can't access the services api"))
+	verifyMemberClusterState(g, "argo2", gslbalphav2.ClusterDisconnected, metav1.ConditionFalse)
+	g.Expect(getMemberClusterReadyMsg("argo2")).To(gomega.ContainSubstring("invalid credentials secret cluster-argo2"))
+
+	// a discovered cluster isn't replaced by another secret for a cluster of the same name, and
+	// isn't removed when that secret is deleted
+	sameNameSecret := argoSecret.DeepCopy()
+	sameNameSecret.Name = "cluster-argo1-copy"
+	gslbingestion.AddDiscoveredCluster(gslbingestion.ArgoCDFormat, sameNameSecret)
+	gslbingestion.DeleteDiscoveredCluster(sameNameSecret)
+	g.Expect(gslbutils.IsClusterContextPresent("argo1")).To(gomega.Equal(true))
+
+	gslbingestion.DeleteDiscoveredCluster(argoSecret)
+	gslbingestion.DeleteDiscoveredCluster(invalidArgoSecret)
+	g.Expect(gslbutils.IsClusterContextPresent("argo1")).To(gomega.Equal(false))
+	g.Expect(gslbutils.IsClusterContextPresent("argo2")).To(gomega.Equal(false))
+}
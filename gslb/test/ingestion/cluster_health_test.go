@@ -0,0 +1,154 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"errors"
+	"sync/atomic"
+	"testing"
+
+	"github.com/onsi/gomega"
+	oshiftfake "github.com/openshift/client-go/route/clientset/versioned/fake"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+func verifyMemberClusterState(g *gomega.GomegaWithT, cname, state string, condStatus metav1.ConditionStatus) {
+	currState, ok := gslbingestion.GetMemberClusterState(cname)
+	g.Expect(ok).To(gomega.Equal(true))
+	g.Expect(currState).To(gomega.Equal(state))
+
+	var member *gslbalphav2.MemberClusterStatus
+	status := gslbutils.GetGSLBConfigStatus()
+	for i := range status.MemberClusters {
+		if status.MemberClusters[i].ClusterContext == cname {
+			member = &status.MemberClusters[i]
+		}
+	}
+	g.Expect(member).NotTo(gomega.BeNil())
+	g.Expect(member.State).To(gomega.Equal(state))
+	cond := gslbalphav2.FindCondition(member.Conditions, gslbalphav2.ConditionReady)
+	g.Expect(cond).NotTo(gomega.BeNil())
+	g.Expect(cond.Status).To(gomega.Equal(condStatus))
+}
+
+// addTestMemberCluster adds a member cluster with fake clients to the cluster health checks, the
+// cluster is unreachable while the returned value is set to 1.
+func addTestMemberCluster(cname string) *int32 {
+	var unreachable int32
+	healthKubeClient := k8sfake.NewSimpleClientset()
+	healthKubeClient.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		if atomic.LoadInt32(&unreachable) == 1 {
+			return true, nil, errors.New("connection refused")
+		}
+		return false, nil, nil
+	})
+	gslbingestion.AddMemberCluster(cname, "", healthKubeClient, oshiftfake.NewSimpleClientset())
+	return &unreachable
+}
+
+// disconnectTestMemberCluster makes a connected cluster unreachable and fails its health checks till
+// it's disconnected.
+func disconnectTestMemberCluster(g *gomega.GomegaWithT, cname string, unreachable *int32) {
+	atomic.StoreInt32(unreachable, 1)
+	for i := 1; i < gslbutils.ClusterDisconnectThreshold; i++ {
+		gslbingestion.EvaluateMemberClusterHealth()
+		verifyMemberClusterState(g, cname, gslbalphav2.ClusterDegraded, metav1.ConditionUnknown)
+	}
+	gslbingestion.EvaluateMemberClusterHealth()
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterDisconnected, metav1.ConditionFalse)
+}
+
+func TestMemberClusterHealth(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	cname := "health-cluster"
+	unreachable := addTestMemberCluster(cname)
+
+	gslbingestion.EvaluateMemberClusterHealth()
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterConnected, metav1.ConditionTrue)
+
+	// the cluster is degraded till it fails the health checks ClusterDisconnectThreshold times
+	disconnectTestMemberCluster(g, cname, unreachable)
+
+	// the informers are set up again once the cluster is reachable
+	atomic.StoreInt32(unreachable, 0)
+	gslbingestion.EvaluateMemberClusterHealth()
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterConnected, metav1.ConditionTrue)
+}
+
+func TestMemberClusterUnreachableAtBootup(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	// a cluster without clients and without a credentials secret can't be connected, it's
+	// disconnected on the first health check
+	cname := "unreachable-cluster"
+	gslbingestion.AddMemberCluster(cname, "non-existent-secret", nil, nil)
+	gslbingestion.EvaluateMemberClusterHealth()
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterDisconnected, metav1.ConditionFalse)
+}
+
+func TestMemberClusterHealthUnresponsive(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	// the API server of the cluster doesn't respond till it's released
+	cname := "unresponsive-cluster"
+	checked := make(chan struct{}, 1)
+	release := make(chan struct{})
+	kubeClient := k8sfake.NewSimpleClientset()
+	kubeClient.PrependReactor("list", "services", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		select {
+		case checked <- struct{}{}:
+		default:
+		}
+		<-release
+		return false, nil, nil
+	})
+	gslbingestion.AddMemberCluster(cname, "", kubeClient, oshiftfake.NewSimpleClientset())
+	otherCname := "responsive-cluster"
+	addTestMemberCluster(otherCname)
+
+	done := make(chan struct{})
+	go func() {
+		gslbingestion.EvaluateMemberClusterHealth()
+		close(done)
+	}()
+	<-checked
+
+	// the state of the clusters can be read while the cluster is health checked
+	stateRead := make(chan struct{})
+	go func() {
+		gslbingestion.GetMemberClusterState(otherCname)
+		close(stateRead)
+	}()
+	g.Eventually(stateRead, "2s").Should(gomega.BeClosed())
+	g.Consistently(done, "100ms").ShouldNot(gomega.BeClosed())
+
+	close(release)
+	g.Eventually(done, "2s").Should(gomega.BeClosed())
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterConnected, metav1.ConditionTrue)
+	verifyMemberClusterState(g, otherCname, gslbalphav2.ClusterConnected, metav1.ConditionTrue)
+}
@@ -0,0 +1,99 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+)
+
+func TestParseControllerCredentials(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+
+	creds, err := gslbingestion.ParseControllerCredentials(map[string][]byte{
+		gslbingestion.UsernameKey: []byte("admin"),
+		gslbingestion.PasswordKey: []byte("avi123"),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(creds.Username).To(gomega.Equal("admin"))
+	g.Expect(creds.Password).To(gomega.Equal("avi123"))
+
+	creds, err = gslbingestion.ParseControllerCredentials(map[string][]byte{
+		gslbingestion.UsernameKey: []byte("admin"),
+		gslbingestion.TokenKey:    []byte("test-token"),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(creds.Token).To(gomega.Equal("test-token"))
+
+	creds, err = gslbingestion.ParseControllerCredentials(map[string][]byte{
+		gslbingestion.UsernameKey:   []byte("admin"),
+		gslbingestion.ClientCertKey: []byte("test-cert"),
+		gslbingestion.ClientKeyKey:  []byte("test-key"),
+	})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(creds.ClientCert).To(gomega.Equal([]byte("test-cert")))
+	g.Expect(creds.ClientKey).To(gomega.Equal([]byte("test-key")))
+
+	// the username is required
+	_, err = gslbingestion.ParseControllerCredentials(map[string][]byte{gslbingestion.PasswordKey: []byte("avi123")})
+	g.Expect(err).To(gomega.HaveOccurred())
+
+	// a client certificate without the key
+	_, err = gslbingestion.ParseControllerCredentials(map[string][]byte{
+		gslbingestion.UsernameKey:   []byte("admin"),
+		gslbingestion.ClientCertKey: []byte("test-cert"),
+	})
+	g.Expect(err).To(gomega.HaveOccurred())
+
+	// no password, token or client certificate
+	_, err = gslbingestion.ParseControllerCredentials(map[string][]byte{gslbingestion.UsernameKey: []byte("admin")})
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestParseControllerTLS(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	secret := &corev1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Name: "avi-ca", Namespace: gslbutils.AVISystem},
+		Data:       map[string][]byte{gslbingestion.CACertKey: []byte("test-ca")},
+	}
+	gslbutils.GlobalKubeClient = k8sfake.NewSimpleClientset(secret)
+	defer func() { gslbutils.GlobalKubeClient = nil }()
+
+	// the certificate isn't verified without the TLS config
+	tlsCfg, err := gslbingestion.ParseControllerTLS(nil)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(tlsCfg.Verification).To(gomega.Equal(gslbutils.TLSVerificationNone))
+
+	tlsCfg, err = gslbingestion.ParseControllerTLS(&gslbalphav2.ControllerTLS{CASecret: "avi-ca", ServerName: "avi.com"})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(tlsCfg.Verification).To(gomega.Equal(gslbutils.TLSVerificationFull))
+	g.Expect(tlsCfg.CAData).To(gomega.Equal([]byte("test-ca")))
+	g.Expect(tlsCfg.ServerName).To(gomega.Equal("avi.com"))
+
+	tlsCfg, err = gslbingestion.ParseControllerTLS(&gslbalphav2.ControllerTLS{Verification: gslbutils.TLSVerificationCAOnly})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(tlsCfg.Verification).To(gomega.Equal(gslbutils.TLSVerificationCAOnly))
+	g.Expect(tlsCfg.CAData).To(gomega.BeEmpty())
+
+	_, err = gslbingestion.ParseControllerTLS(&gslbalphav2.ControllerTLS{CASecret: "missing-ca"})
+	g.Expect(err).To(gomega.HaveOccurred())
+}
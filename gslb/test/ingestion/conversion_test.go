@@ -0,0 +1,249 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/webhook"
+	gslbalphav1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// sendConversionReview posts a conversion review for objs to the webhook handler and returns the
+// response.
+func sendConversionReview(t *testing.T, desiredAPIVersion string, objs ...interface{}) *webhook.ConversionResponse {
+	review := webhook.ConversionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "apiextensions.k8s.io/v1", Kind: "ConversionReview"},
+		Request: &webhook.ConversionRequest{
+			UID:               "test-uid",
+			DesiredAPIVersion: desiredAPIVersion,
+		},
+	}
+	for _, obj := range objs {
+		raw, err := json.Marshal(obj)
+		if err != nil {
+			t.Fatalf("error in marshalling object: %v", err)
+		}
+		review.Request.Objects = append(review.Request.Objects, runtime.RawExtension{Raw: raw})
+	}
+	body, _ := json.Marshal(review)
+	req := httptest.NewRequest(http.MethodPost, webhook.ConvertPath, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	webhook.ServeConvert(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected webhook response code %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp webhook.ConversionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Response == nil {
+		t.Fatalf("malformed webhook response: %s", rec.Body.String())
+	}
+	if resp.Response.UID != "test-uid" {
+		t.Fatalf("unexpected uid in webhook response: %s", resp.Response.UID)
+	}
+	return resp.Response
+}
+
+func getV1alpha1TestObjects() (*gslbalphav1.GSLBConfig, *gslbalphav1.GlobalDeploymentPolicy, *gslbalphav1.GSLBHostRule) {
+	v1 := gslbalphav1.SchemeGroupVersion.String()
+	gc := &gslbalphav1.GSLBConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1, Kind: "GSLBConfig"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gc", Namespace: "avi-system"},
+		Spec: gslbalphav1.GSLBConfigSpec{
+			GSLBLeader:        gslbalphav1.GSLBLeader{ControllerIP: "10.10.10.10", Credentials: "gslb-avi-secret"},
+			MemberClusters:    []gslbalphav1.MemberCluster{{ClusterContext: "cluster1"}, {ClusterContext: "cluster2"}},
+			LogLevel:          "INFO",
+			GarbageCollection: &gslbalphav1.GarbageCollection{GracePeriod: 600, MaxDeletions: 5, DryRun: true},
+		},
+		Status: gslbalphav1.GSLBConfigStatus{State: "success: gslb config accepted"},
+	}
+	gdp := &gslbalphav1.GlobalDeploymentPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1, Kind: "GlobalDeploymentPolicy"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-gdp", Namespace: "avi-system"},
+		Spec: gslbalphav1.GDPSpec{
+			MatchRules: gslbalphav1.MatchRules{
+				AppSelector: gslbalphav1.AppSelector{Label: map[string]string{"app": "gslb"}},
+			},
+			MatchClusters: []string{"cluster1", "cluster2"},
+			TrafficSplit:  []gslbalphav1.TrafficSplitElem{{Cluster: "cluster1", Weight: 8}, {Cluster: "cluster2", Weight: 2}},
+			DrainClusters: []string{"cluster2"},
+		},
+		Status: gslbalphav1.GDPStatus{
+			ErrorStatus: "error: drain cluster cluster3 not present in GSLBConfig",
+			DrainStatus: []gslbalphav1.ClusterDrainStatus{{Cluster: "cluster2", Members: 2, DisabledMembers: 1}},
+		},
+	}
+	hr := &gslbalphav1.GSLBHostRule{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1, Kind: "GSLBHostRule"},
+		ObjectMeta: metav1.ObjectMeta{Name: "test-hr", Namespace: "avi-system"},
+		Spec: gslbalphav1.GSLBHostRuleSpec{
+			Fqdn:                   "foo.avi.com",
+			TTL:                    30,
+			SitePersistenceEnabled: true,
+			TrafficSplit:           []gslbalphav1.TrafficSplitElem{{Cluster: "cluster1", Weight: 5}},
+			AdoptExistingGS:        true,
+		},
+		Status: gslbalphav1.GSLBHostRuleStatus{Status: "Accepted"},
+	}
+	return gc, gdp, hr
+}
+
+func TestConversionToV1alpha2(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gc, gdp, hr := getV1alpha1TestObjects()
+
+	resp := sendConversionReview(t, gslbalphav2.SchemeGroupVersion.String(), gc, gdp, hr)
+	g.Expect(resp.Result.Status).To(gomega.Equal(metav1.StatusSuccess))
+	g.Expect(resp.ConvertedObjects).To(gomega.HaveLen(3))
+
+	var newGc gslbalphav2.GSLBConfig
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[0].Raw, &newGc)).To(gomega.Succeed())
+	g.Expect(newGc.APIVersion).To(gomega.Equal(gslbalphav2.SchemeGroupVersion.String()))
+	g.Expect(newGc.Spec.MemberClusters).To(gomega.HaveLen(2))
+	g.Expect(newGc.Spec.GarbageCollection).To(gomega.Equal(&gslbalphav2.GarbageCollection{GracePeriod: 600,
+		MaxDeletions: 5, DryRun: true}))
+	g.Expect(gslbalphav2.IsConditionTrue(newGc.Status.Conditions, gslbalphav2.ConditionAccepted)).To(gomega.Equal(true))
+
+	var newGdp gslbalphav2.GlobalDeploymentPolicy
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[1].Raw, &newGdp)).To(gomega.Succeed())
+	g.Expect(newGdp.Spec.MatchRules.AppSelector.Label).To(gomega.Equal(map[string]string{"app": "gslb"}))
+	g.Expect(newGdp.Spec.TrafficSplit).To(gomega.Equal([]gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 8},
+		{Cluster: "cluster2", Weight: 2}}))
+	cond := gslbalphav2.FindCondition(newGdp.Status.Conditions, gslbalphav2.ConditionAccepted)
+	g.Expect(cond).NotTo(gomega.BeNil())
+	g.Expect(cond.Status).To(gomega.Equal(metav1.ConditionFalse))
+	g.Expect(cond.Reason).To(gomega.Equal(gslbalphav2.ReasonRejected))
+	g.Expect(cond.Message).To(gomega.Equal(gdp.Status.ErrorStatus))
+	g.Expect(newGdp.Status.DrainStatus).To(gomega.HaveLen(1))
+
+	var newHr gslbalphav2.GSLBHostRule
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[2].Raw, &newHr)).To(gomega.Succeed())
+	g.Expect(newHr.Spec.SitePersistence).To(gomega.Equal(&gslbalphav2.SitePersistence{Enabled: true}))
+	g.Expect(newHr.Spec.AdoptExistingGS).To(gomega.BeTrue())
+	g.Expect(getAcceptedReason(newHr.Status.Conditions)).To(gomega.Equal(gslbalphav2.ReasonAccepted))
+}
+
+func TestConversionRoundTrip(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gc, gdp, hr := getV1alpha1TestObjects()
+
+	resp := sendConversionReview(t, gslbalphav2.SchemeGroupVersion.String(), gc, gdp, hr)
+	g.Expect(resp.Result.Status).To(gomega.Equal(metav1.StatusSuccess))
+	var newGdp gslbalphav2.GlobalDeploymentPolicy
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[1].Raw, &newGdp)).To(gomega.Succeed())
+	transitionTime := gslbalphav2.FindCondition(newGdp.Status.Conditions, gslbalphav2.ConditionAccepted).LastTransitionTime
+
+	var objs []interface{}
+	for _, obj := range resp.ConvertedObjects {
+		objs = append(objs, obj)
+	}
+	resp = sendConversionReview(t, gslbalphav1.SchemeGroupVersion.String(), objs...)
+	g.Expect(resp.Result.Status).To(gomega.Equal(metav1.StatusSuccess))
+
+	var oldGc gslbalphav1.GSLBConfig
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[0].Raw, &oldGc)).To(gomega.Succeed())
+	g.Expect(oldGc.Spec).To(gomega.Equal(gc.Spec))
+	g.Expect(oldGc.Status).To(gomega.Equal(gc.Status))
+
+	var oldGdp gslbalphav1.GlobalDeploymentPolicy
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[1].Raw, &oldGdp)).To(gomega.Succeed())
+	g.Expect(oldGdp.Spec).To(gomega.Equal(gdp.Spec))
+	g.Expect(oldGdp.Status).To(gomega.Equal(gdp.Status))
+	g.Expect(oldGdp.Annotations).To(gomega.HaveKey(gslbalphav2.StatusAnnotation))
+
+	var oldHr gslbalphav1.GSLBHostRule
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[2].Raw, &oldHr)).To(gomega.Succeed())
+	g.Expect(oldHr.Spec).To(gomega.Equal(hr.Spec))
+	g.Expect(oldHr.Status).To(gomega.Equal(hr.Status))
+
+	// the conditions are restored from the annotation when the object is converted back
+	resp = sendConversionReview(t, gslbalphav2.SchemeGroupVersion.String(), &oldGdp)
+	g.Expect(resp.Result.Status).To(gomega.Equal(metav1.StatusSuccess))
+	newGdp = gslbalphav2.GlobalDeploymentPolicy{}
+	g.Expect(json.Unmarshal(resp.ConvertedObjects[0].Raw, &newGdp)).To(gomega.Succeed())
+	g.Expect(newGdp.Annotations).NotTo(gomega.HaveKey(gslbalphav2.StatusAnnotation))
+	cond := gslbalphav2.FindCondition(newGdp.Status.Conditions, gslbalphav2.ConditionAccepted)
+	g.Expect(cond.LastTransitionTime.Equal(&transitionTime)).To(gomega.Equal(true))
+}
+
+func TestConversionUnsupportedVersion(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gc, _, _ := getV1alpha1TestObjects()
+
+	resp := sendConversionReview(t, "amko.vmware.com/v1beta1", gc)
+	g.Expect(resp.Result.Status).To(gomega.Equal(metav1.StatusFailure))
+	g.Expect(resp.Result.Message).To(gomega.Equal("unsupported api version amko.vmware.com/v1beta1"))
+	g.Expect(resp.ConvertedObjects).To(gomega.BeEmpty())
+}
+
+func TestConversionPatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var patch struct {
+		Spec struct {
+			Conversion struct {
+				Strategy string `json:"strategy"`
+				Webhook  struct {
+					ClientConfig struct {
+						Service struct {
+							Name      string `json:"name"`
+							Namespace string `json:"namespace"`
+							Path      string `json:"path"`
+						} `json:"service"`
+						CABundle []byte `json:"caBundle"`
+					} `json:"clientConfig"`
+				} `json:"webhook"`
+			} `json:"conversion"`
+		} `json:"spec"`
+	}
+	g.Expect(json.Unmarshal(webhook.ConversionPatch("amko-ns", []byte("test-ca")), &patch)).To(gomega.Succeed())
+
+	// the CRDs are installed without a conversion webhook, the patch sets all of it
+	conversion := patch.Spec.Conversion
+	g.Expect(conversion.Strategy).To(gomega.Equal("Webhook"))
+	g.Expect(conversion.Webhook.ClientConfig.Service.Name).To(gomega.Equal(webhook.WebhookServiceName))
+	g.Expect(conversion.Webhook.ClientConfig.Service.Namespace).To(gomega.Equal("amko-ns"))
+	g.Expect(conversion.Webhook.ClientConfig.Service.Path).To(gomega.Equal(webhook.ConvertPath))
+	g.Expect(string(conversion.Webhook.ClientConfig.CABundle)).To(gomega.Equal("test-ca"))
+}
+
+func TestStorageVersionPatch(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	var patch []struct {
+		Op    string      `json:"op"`
+		Path  string      `json:"path"`
+		Value interface{} `json:"value"`
+	}
+	g.Expect(json.Unmarshal(webhook.StorageVersionPatch(), &patch)).To(gomega.Succeed())
+	g.Expect(patch).To(gomega.HaveLen(4))
+
+	// the storage version is switched only if the versions of the CRD are in the expected order
+	g.Expect(patch[0].Op).To(gomega.Equal("test"))
+	g.Expect(patch[0].Path).To(gomega.Equal("/spec/versions/0/name"))
+	g.Expect(patch[0].Value).To(gomega.Equal("v1alpha1"))
+	g.Expect(patch[1].Op).To(gomega.Equal("test"))
+	g.Expect(patch[1].Path).To(gomega.Equal("/spec/versions/1/name"))
+	g.Expect(patch[1].Value).To(gomega.Equal("v1alpha2"))
+	g.Expect(patch[2].Path).To(gomega.Equal("/spec/versions/0/storage"))
+	g.Expect(patch[2].Value).To(gomega.Equal(false))
+	g.Expect(patch[3].Path).To(gomega.Equal("/spec/versions/1/storage"))
+	g.Expect(patch[3].Value).To(gomega.Equal(true))
+}
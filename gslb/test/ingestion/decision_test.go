@@ -0,0 +1,106 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	filter "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gdp_filter"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+
+	"github.com/onsi/gomega"
+	corev1 "k8s.io/api/core/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	k8stesting "k8s.io/client-go/testing"
+)
+
+// recordEventsInNamespace makes the fake client store the events created by the event sink, which
+// creates them without a namespace in the request, in the namespace of the event.
+func recordEventsInNamespace() {
+	fooKubeClient.PrependReactor("create", "events", func(action k8stesting.Action) (bool, runtime.Object, error) {
+		event := action.(k8stesting.CreateAction).GetObject().(*corev1.Event)
+		err := fooKubeClient.Tracker().Create(action.GetResource(), event, event.Namespace)
+		return true, event, err
+	})
+}
+
+// getDecisionEvents returns the reasons of the GDP filter decision events written for an object.
+func getDecisionEvents(t *testing.T, ns, name string) []string {
+	events, err := fooKubeClient.CoreV1().Events(ns).List(metav1.ListOptions{})
+	if err != nil {
+		t.Fatalf("error in listing the events: %v", err)
+	}
+	var reasons []string
+	for _, event := range events.Items {
+		if event.InvolvedObject.Name == name && (event.Reason == gslbingestion.DecisionAcceptedReason ||
+			event.Reason == gslbingestion.DecisionRejectedReason) {
+			reasons = append(reasons, event.Reason)
+		}
+	}
+	return reasons
+}
+
+func TestSvcDecisionExplained(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	testPrefix := "dcn-"
+	svcName := testPrefix + "def-svc"
+	ns := "default"
+	host := testPrefix + TestDomain1
+	ipAddr := "10.10.10.10"
+	cname := "cluster1"
+
+	gslbutils.AddClusterContext("cluster1")
+	gslbutils.AddClusterContext("cluster2")
+	gdp := addGDPAndGSLBForSvc(t)
+	recordEventsInNamespace()
+
+	// the service doesn't have the label of the appSelector
+	svcObj := BuildSvcObj(svcName, ns, cname, host, ipAddr, true, corev1.ServiceTypeLoadBalancer)
+	svcObj.Labels = map[string]string{"key": "other"}
+	if _, err := fooKubeClient.CoreV1().Services(ns).Create(svcObj); err != nil {
+		t.Fatalf("error in creating service: %v", err)
+	}
+	buildSvcKeyAndVerify(t, true, "ADD", cname, ns, svcName)
+
+	decisions := filter.GetDecisions(gslbutils.SvcType, cname, ns, svcName, "")
+	g.Expect(decisions).To(gomega.HaveLen(1))
+	g.Expect(decisions[0].Accepted).To(gomega.BeFalse())
+	g.Expect(decisions[0].Steps[len(decisions[0].Steps)-1].Check).To(gomega.Equal(filter.CheckAppSelector))
+	g.Eventually(func() []string {
+		return getDecisionEvents(t, ns, svcName)
+	}, 5*time.Second).Should(gomega.ContainElement(gslbingestion.DecisionRejectedReason))
+
+	// the decision changes once the service has the label
+	svcObj.Labels = map[string]string{"key": "value"}
+	svcObj.ResourceVersion = "101"
+	K8sUpdateSvc(t, fooKubeClient, ns, cname, svcObj)
+	buildSvcKeyAndVerify(t, false, "UPDATE", cname, ns, svcName)
+
+	decisions = filter.GetDecisions(gslbutils.SvcType, cname, ns, svcName, "")
+	g.Expect(decisions).To(gomega.HaveLen(1))
+	g.Expect(decisions[0].Accepted).To(gomega.BeTrue())
+	g.Expect(decisions[0].GSName).To(gomega.Equal(host))
+	g.Eventually(func() []string {
+		return getDecisionEvents(t, ns, svcName)
+	}, 5*time.Second).Should(gomega.ContainElement(gslbingestion.DecisionAcceptedReason))
+
+	K8sDeleteSvc(t, fooKubeClient, svcName, ns)
+	buildSvcKeyAndVerify(t, false, "DELETE", cname, ns, svcName)
+	g.Expect(filter.GetDecisions(gslbutils.SvcType, cname, ns, svcName, "")).To(gomega.HaveLen(0))
+	DeleteTestGDPObj(gdp)
+}
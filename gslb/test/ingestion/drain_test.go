@@ -0,0 +1,72 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+)
+
+// Adding a cluster to the drain list of the GDP object must only re-publish the objects of that
+// cluster, and removing it must re-publish them again.
+func TestGDPDrainCluster(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	testPrefix := "drc-"
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	AddTestGDPObj(gdp)
+
+	hosts, deleteKeys := addAndVerifyTrafficWeightIngresses(t, testPrefix)
+
+	oldGdp := gdp.DeepCopy()
+	gdp.Spec.DrainClusters = []string{"cluster2"}
+	gdp.ResourceVersion = "101"
+	UpdateTestGDPObj(oldGdp, gdp)
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
+	g.Expect(gslbutils.GetGlobalFilter().IsClusterDrained("cluster2")).To(gomega.Equal(true))
+	g.Expect(gslbutils.GetGlobalFilter().IsClusterDrained("cluster1")).To(gomega.Equal(false))
+
+	updateKeys := []string{GetIngressKey("UPDATE", "cluster2", "default", testPrefix+"def-ing1", hosts[0]),
+		GetIngressKey("UPDATE", "cluster2", "default", testPrefix+"def-ing2", hosts[1])}
+	VerifyAllKeys(t, updateKeys, false)
+	// no keys for cluster1
+	passed, errStr := waitAndVerify(t, []string{}, true)
+	if !passed {
+		t.Fatal(errStr)
+	}
+
+	oldGdp = gdp.DeepCopy()
+	gdp.Spec.DrainClusters = nil
+	gdp.ResourceVersion = "102"
+	UpdateTestGDPObj(oldGdp, gdp)
+	g.Expect(gslbutils.GetGlobalFilter().IsClusterDrained("cluster2")).To(gomega.Equal(false))
+	VerifyAllKeys(t, updateKeys, false)
+
+	deleteTrafficWeightIngresses(t, testPrefix, deleteKeys)
+	DeleteTestGDPObj(gdp)
+}
+
+func TestGDPDrainClusterNotPresent(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	gdp.Spec.DrainClusters = []string{"cluster3"}
+	AddTestGDPObj(gdp)
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal("drain cluster cluster3 not present in GSLBConfig"))
+	DeleteTestGDPObj(gdp)
+}
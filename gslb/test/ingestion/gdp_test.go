@@ -21,7 +21,7 @@ import (
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
 
 	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
-	gslbalphav1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 	gslbfake "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned/fake"
 	gslbinformers "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/informers/externalversions"
 
@@ -54,7 +54,7 @@ func updateSomething(old, new interface{}, k8swq []workqueue.RateLimitingInterfa
 
 }
 
-func updateTestGDPObject(gdp *gslbalphav1.GlobalDeploymentPolicy, clusterList []string, version string) {
+func updateTestGDPObject(gdp *gslbalphav2.GlobalDeploymentPolicy, clusterList []string, version string) {
 	gdp.Spec.MatchClusters = clusterList
 	gdp.ObjectMeta.ResourceVersion = version
 }
@@ -220,7 +220,7 @@ func TestMultipleGDPObjectsForSameNS(t *testing.T) {
 	VerifyAllKeys(t, allKeys, false)
 
 	t.Logf("verifying GDP status")
-	g.Expect(gdp.Status.ErrorStatus).To(gomega.Equal("success"))
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
 
 	// Let's add another GDP object
 	anotherGdp := getTestGDPObject(true, false)
@@ -230,7 +230,7 @@ func TestMultipleGDPObjectsForSameNS(t *testing.T) {
 	AddTestGDPObj(anotherGdp)
 
 	// check the status of this new object
-	g.Expect(anotherGdp.Status.ErrorStatus).To(gomega.Equal("a GDP object already exists, can't add another"))
+	g.Expect(getAcceptedMsg(anotherGdp.Status.Conditions)).To(gomega.Equal("a GDP object already exists, can't add another"))
 
 	t.Logf("Deleting ingresses for cluster1")
 	DeleteMultipleIngresses(t, fooKubeClient, ingList1)
@@ -300,7 +300,7 @@ func TestUpdateGDPSelectFew(t *testing.T) {
 	VerifyAllKeys(t, allKeys, false)
 
 	t.Logf("verifying GDP status")
-	g.Expect(gdp.Status.ErrorStatus).To(gomega.Equal("success"))
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
 
 	t.Logf("Deleting ingresses for cluster1")
 	DeleteMultipleIngresses(t, fooKubeClient, ingList1)
@@ -356,7 +356,7 @@ func TestUpdateGDPSelectFromOneCluster(t *testing.T) {
 	VerifyAllKeys(t, keys1, false)
 
 	t.Logf("verifying GDP status")
-	g.Expect(gdp.Status.ErrorStatus).To(gomega.Equal("success"))
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
 
 	t.Logf("Deleting ingresses for cluster1")
 	DeleteMultipleIngresses(t, fooKubeClient, ingList1)
@@ -419,7 +419,7 @@ func TestUpdateGDPSwitchClusters(t *testing.T) {
 	VerifyAllKeys(t, allKeys, false)
 
 	t.Logf("verifying GDP status")
-	g.Expect(gdp.Status.ErrorStatus).To(gomega.Equal("success"))
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
 
 	t.Logf("Deleting ingresses for cluster1")
 	DeleteMultipleIngresses(t, fooKubeClient, ingList1)
@@ -466,7 +466,7 @@ func TestGDPMisnameClusters(t *testing.T) {
 	VerifyAllKeys(t, allKeys, true)
 
 	t.Logf("verifying status message")
-	g.Expect(gdp.Status.ErrorStatus).To(gomega.Equal("cluster context abc not present in GSLBConfig"))
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal("cluster context abc not present in GSLBConfig"))
 
 	t.Logf("Deleting ingresses for cluster1")
 	DeleteMultipleIngresses(t, fooKubeClient, ingList1)
@@ -574,14 +574,14 @@ func DeleteMultipleIngresses(t *testing.T, kc *k8sfake.Clientset, ingList []*ext
 	}
 }
 
-func UpdateGDPMatchRuleAppLabel(gdp *gslbalphav1.GlobalDeploymentPolicy, key, value string) {
+func UpdateGDPMatchRuleAppLabel(gdp *gslbalphav2.GlobalDeploymentPolicy, key, value string) {
 	if len(gdp.Spec.MatchRules.AppSelector.Label) == 0 {
 		gdp.Spec.MatchRules.AppSelector.Label = make(map[string]string)
 	}
 	gdp.Spec.MatchRules.AppSelector.Label[key] = value
 }
 
-func AddTestGDPObj(gdp *gslbalphav1.GlobalDeploymentPolicy) {
+func AddTestGDPObj(gdp *gslbalphav2.GlobalDeploymentPolicy) {
 	ingestionQueue := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
 	gslbingestion.AddGDPObj(gdp, ingestionQueue.Workqueue, 2)
 }
@@ -596,7 +596,7 @@ func VerifyAllKeys(t *testing.T, allKeys []string, timeoutExpected bool) {
 	}
 }
 
-func UpdateTestGDPObj(oldGdp, gdp *gslbalphav1.GlobalDeploymentPolicy) {
+func UpdateTestGDPObj(oldGdp, gdp *gslbalphav2.GlobalDeploymentPolicy) {
 	ingestionQ := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
 	gslbingestion.UpdateGDPObj(oldGdp, gdp, ingestionQ.Workqueue, 2)
 }
@@ -0,0 +1,57 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// The adoption of an existing GS is enabled for the fqdn of a GSLBHostRule only while it's accepted.
+func TestGSLBHostRuleAdoptExistingGS(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	fqdn := "adopt-hr.avi.com"
+	buildAndAddTestGSLBObject(t)
+	ingestionQ := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+
+	hr := getTestGSLBHostRule("adopt-hr", fqdn, nil)
+	hr.Spec.AdoptExistingGS = true
+	gslbingestion.AddGSLBHostRuleObj(hr, ingestionQ.Workqueue, 2)
+	g.Expect(gslbutils.IsGSAdoptionEnabled(fqdn)).To(gomega.BeTrue())
+
+	updatedHr := hr.DeepCopy()
+	updatedHr.ResourceVersion = "101"
+	updatedHr.Spec.AdoptExistingGS = false
+	gslbingestion.UpdateGSLBHostRuleObj(hr, updatedHr, ingestionQ.Workqueue, 2)
+	g.Expect(gslbutils.IsGSAdoptionEnabled(fqdn)).To(gomega.BeFalse())
+
+	// a rejected GSLBHostRule doesn't enable the adoption
+	anotherHr := getTestGSLBHostRule("adopt-hr2", fqdn, nil)
+	anotherHr.Spec.AdoptExistingGS = true
+	gslbingestion.AddGSLBHostRuleObj(anotherHr, ingestionQ.Workqueue, 2)
+	g.Expect(gslbingestion.GSLBHostRuleSanityChecks(anotherHr)).To(gomega.HaveOccurred())
+	g.Expect(gslbutils.IsGSAdoptionEnabled(fqdn)).To(gomega.BeFalse())
+
+	hr.ResourceVersion = "102"
+	gslbingestion.UpdateGSLBHostRuleObj(updatedHr, hr, ingestionQ.Workqueue, 2)
+	g.Expect(gslbutils.IsGSAdoptionEnabled(fqdn)).To(gomega.BeTrue())
+	gslbingestion.DeleteGSLBHostRuleObj(hr, ingestionQ.Workqueue, 2)
+	g.Expect(gslbutils.IsGSAdoptionEnabled(fqdn)).To(gomega.BeFalse())
+}
@@ -0,0 +1,89 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+)
+
+func getGSHealthAnnotation(t *testing.T, cs kubernetes.Interface, name string) (string, bool) {
+	ing, err := cs.ExtensionsV1beta1().Ingresses("default").Get(name, metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error in fetching ingress %s: %v", name, err)
+	}
+	val, ok := ing.Annotations[gslbutils.GSHealthAnnotation]
+	return val, ok
+}
+
+// Once enabled, the runtime health of a GS must be annotated on the ingresses of its members, and
+// the annotation must be removed once the ingress isn't a member anymore.
+func TestGSHealthAnnotation(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	testPrefix := "gsh-"
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	AddTestGDPObj(gdp)
+	hosts, deleteKeys := addAndVerifyTrafficWeightIngresses(t, testPrefix)
+	ingName := testPrefix + "def-ing1"
+
+	gsHealth := map[string]gslbalphav2.GSLBServiceHealth{
+		hosts[0]: {
+			Fqdn:  hosts[0],
+			UUID:  "gslbservice-1",
+			State: gslbutils.HealthUp,
+			Members: []gslbalphav2.GSLBServiceMemberHealth{
+				{Cluster: "cluster1", ObjType: gslbutils.IngressType, Namespace: "default",
+					Name: ingName + "/" + hosts[0], IPAddr: "10.10.10.10", State: gslbutils.HealthUp},
+				{Cluster: "cluster2", ObjType: gslbutils.IngressType, Namespace: "default",
+					Name: ingName + "/" + hosts[0], IPAddr: "10.10.10.10", State: gslbutils.HealthDown},
+			},
+		},
+	}
+	// the annotation is opt-in
+	gslbingestion.PublishGSHealth(gsHealth)
+	_, ok := getGSHealthAnnotation(t, fooKubeClient, ingName)
+	g.Expect(ok).To(gomega.Equal(false))
+
+	gslbutils.SetGSHealthAnnotations(true)
+	defer gslbutils.SetGSHealthAnnotations(false)
+	gslbingestion.PublishGSHealth(gsHealth)
+
+	val, ok := getGSHealthAnnotation(t, fooKubeClient, ingName)
+	g.Expect(ok).To(gomega.Equal(true))
+	g.Expect(val).To(gomega.Equal(`{"` + hosts[0] + `":{"gsState":"Up","memberState":"Up"}}`))
+	val, ok = getGSHealthAnnotation(t, barKubeClient, ingName)
+	g.Expect(ok).To(gomega.Equal(true))
+	g.Expect(val).To(gomega.Equal(`{"` + hosts[0] + `":{"gsState":"Up","memberState":"Down"}}`))
+	// the other ingress isn't a member of any GS in the health
+	_, ok = getGSHealthAnnotation(t, fooKubeClient, testPrefix+"def-ing2")
+	g.Expect(ok).To(gomega.Equal(false))
+
+	// the GS has no members left, the annotations must be removed
+	gslbingestion.PublishGSHealth(map[string]gslbalphav2.GSLBServiceHealth{})
+	_, ok = getGSHealthAnnotation(t, fooKubeClient, ingName)
+	g.Expect(ok).To(gomega.Equal(false))
+	_, ok = getGSHealthAnnotation(t, barKubeClient, ingName)
+	g.Expect(ok).To(gomega.Equal(false))
+
+	deleteTrafficWeightIngresses(t, testPrefix, deleteKeys)
+	DeleteTestGDPObj(gdp)
+}
@@ -0,0 +1,77 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestAddInvalidGSLBConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	// each of these is rejected instead of crashing AMKO
+	invalidSpecs := []func(gc *gslbalphav2.GSLBConfig){
+		func(gc *gslbalphav2.GSLBConfig) { gc.Spec.LogLevel = "VERBOSE" },
+		func(gc *gslbalphav2.GSLBConfig) { gc.Spec.MemberClusters[1].ClusterContext = "cluster1" },
+		func(gc *gslbalphav2.GSLBConfig) { gc.Spec.MemberClusters[1].ClusterContext = "" },
+		func(gc *gslbalphav2.GSLBConfig) {
+			gc.Spec.GarbageCollection = &gslbalphav2.GarbageCollection{MaxDeletions: -1}
+		},
+	}
+	for _, setInvalidSpec := range invalidSpecs {
+		gslbutils.SetGSLBConfigObj(nil)
+		gc := getTestGSLBObject()
+		setInvalidSpec(gc)
+
+		g.Expect(func() { gslbingestion.AddGSLBConfigObject(gc) }).NotTo(gomega.Panic())
+		status := gslbutils.GetGSLBConfigStatus()
+		g.Expect(gslbalphav2.IsConditionTrue(status.Conditions, gslbalphav2.ConditionAccepted)).To(gomega.BeFalse())
+		g.Expect(getAcceptedReason(status.Conditions)).To(gomega.Equal(gslbalphav2.ReasonInvalidConfig))
+		g.Expect(gslbutils.IsGSLBConfigSet()).To(gomega.BeFalse())
+	}
+}
+
+func TestInitializeMultipleGSLBConfigs(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(nil)
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	created := time.Now()
+	oldGC := getTestGSLBObject()
+	oldGC.Name = "gslb-config-b"
+	oldGC.CreationTimestamp = metav1.NewTime(created)
+	// the oldest object is added, it's invalid, so that the test doesn't start the informers
+	oldGC.Spec.LogLevel = "VERBOSE"
+	newGC := getTestGSLBObject()
+	newGC.Name = "gslb-config-a"
+	newGC.CreationTimestamp = metav1.NewTime(created.Add(time.Minute))
+	gcList := &gslbalphav2.GSLBConfigList{Items: []gslbalphav2.GSLBConfig{*newGC, *oldGC}}
+
+	g.Expect(func() { gslbingestion.InitializeGSLBConfigs(gcList) }).NotTo(gomega.Panic())
+
+	name, _ := gslbutils.GetGSLBConfigNameAndNS()
+	g.Expect(name).To(gomega.Equal("gslb-config-b"))
+	g.Expect(getAcceptedReason(gslbutils.GetGSLBConfigStatus().Conditions)).To(gomega.Equal(gslbalphav2.ReasonInvalidConfig))
+	g.Expect(getAcceptedReason(gcList.Items[0].Status.Conditions)).To(gomega.Equal(gslbalphav2.ReasonAlreadyExists))
+	g.Expect(getAcceptedMsg(gcList.Items[0].Status.Conditions)).To(gomega.Equal(gslbingestion.AlreadySetMsg))
+}
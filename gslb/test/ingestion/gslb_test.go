@@ -16,7 +16,6 @@ package ingestion
 
 import (
 	"io/ioutil"
-	"os"
 	"testing"
 	"time"
 
@@ -44,15 +43,25 @@ func TestGSLBNewController(t *testing.T) {
 	go gslbCtrl.Run(gslbingestion.GetStopChannel())
 }
 
-// Unit test to see if a kube config can be generated from a encoded secret.
+// Unit test to see if a kube config can be built for a context from the kubeconfig data.
 func TestGSLBKubeConfig(t *testing.T) {
 	kubeconfigData, err := ioutil.ReadFile("./testdata/test-kube-config")
 	if err != nil {
 		t.Fatal(err)
 	}
-	os.Setenv("GSLB_CONFIG", string(kubeconfigData))
-	err = gslbingestion.GenerateKubeConfig()
+	cfg, err := gslbingestion.BuildContextConfig(kubeconfigData, "exp-scratch")
 	if err != nil {
-		t.Fatalf("Failure in generating GSLB Kube config: %s", err.Error())
+		t.Fatalf("Failure in building GSLB Kube config: %s", err.Error())
+	}
+	if cfg.Host != "https://10.52.3.70:8443" {
+		t.Fatalf("unexpected server for context exp-scratch: %s", cfg.Host)
+	}
+	// the current context is used if the kubeconfig doesn't have the context
+	cfg, err = gslbingestion.BuildContextConfig(kubeconfigData, "unknown-context")
+	if err != nil {
+		t.Fatalf("Failure in building GSLB Kube config: %s", err.Error())
+	}
+	if cfg.Host != "https://hksosemaster1:8443" {
+		t.Fatalf("unexpected server for the current context: %s", cfg.Host)
 	}
 }
@@ -19,7 +19,7 @@ import (
 	"testing"
 	"time"
 
-	gslbalphav1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	gslbfake "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned/fake"
 
@@ -54,34 +54,34 @@ const (
 	TestSvc     = "foo-svc"
 )
 
-func getTestGSLBObject() *gslbalphav1.GSLBConfig {
-	memberClusters := []gslbalphav1.MemberCluster{
-		gslbalphav1.MemberCluster{
+func getTestGSLBObject() *gslbalphav2.GSLBConfig {
+	memberClusters := []gslbalphav2.MemberCluster{
+		gslbalphav2.MemberCluster{
 			ClusterContext: "cluster1",
 		},
-		gslbalphav1.MemberCluster{
+		gslbalphav2.MemberCluster{
 			ClusterContext: "cluster2",
 		},
 	}
-	gslbConfigObj := &gslbalphav1.GSLBConfig{
+	gslbConfigObj := &gslbalphav2.GSLBConfig{
 		ObjectMeta: metav1.ObjectMeta{
 			Namespace:       "avi-system",
 			Name:            "gslb-config-1",
 			ResourceVersion: "10",
 		},
-		Spec: gslbalphav1.GSLBConfigSpec{
-			GSLBLeader:     gslbalphav1.GSLBLeader{"", "", ""},
+		Spec: gslbalphav2.GSLBConfigSpec{
+			GSLBLeader:     gslbalphav2.GSLBLeader{},
 			MemberClusters: memberClusters,
 		},
 	}
 	return gslbConfigObj
 }
 
-func getTestGDPObject(appLabelReq, nsLabelReq bool) *gslbalphav1.GlobalDeploymentPolicy {
+func getTestGDPObject(appLabelReq, nsLabelReq bool) *gslbalphav2.GlobalDeploymentPolicy {
 	ns := gslbutils.AVISystem
-	matchRules := gslbalphav1.MatchRules{
-		AppSelector:       gslbalphav1.AppSelector{},
-		NamespaceSelector: gslbalphav1.NamespaceSelector{},
+	matchRules := gslbalphav2.MatchRules{
+		AppSelector:       gslbalphav2.AppSelector{},
+		NamespaceSelector: gslbalphav2.NamespaceSelector{},
 	}
 
 	if appLabelReq {
@@ -94,7 +94,7 @@ func getTestGDPObject(appLabelReq, nsLabelReq bool) *gslbalphav1.GlobalDeploymen
 	}
 
 	matchClusters := []string{"cluster1", "cluster2"}
-	gdpSpec := gslbalphav1.GDPSpec{
+	gdpSpec := gslbalphav2.GDPSpec{
 		MatchRules:    matchRules,
 		MatchClusters: matchClusters,
 	}
@@ -103,13 +103,29 @@ func getTestGDPObject(appLabelReq, nsLabelReq bool) *gslbalphav1.GlobalDeploymen
 		Namespace:       ns,
 		ResourceVersion: "100",
 	}
-	gdp := gslbalphav1.GlobalDeploymentPolicy{
+	gdp := gslbalphav2.GlobalDeploymentPolicy{
 		ObjectMeta: gdpMeta,
 		Spec:       gdpSpec,
 	}
 	return &gdp
 }
 
+// getAcceptedMsg returns the message of the Accepted condition of an object.
+func getAcceptedMsg(conditions []gslbalphav2.Condition) string {
+	if cond := gslbalphav2.FindCondition(conditions, gslbalphav2.ConditionAccepted); cond != nil {
+		return cond.Message
+	}
+	return ""
+}
+
+// getAcceptedReason returns the reason of the Accepted condition of an object.
+func getAcceptedReason(conditions []gslbalphav2.Condition) string {
+	if cond := gslbalphav2.FindCondition(conditions, gslbalphav2.ConditionAccepted); cond != nil {
+		return cond.Reason
+	}
+	return ""
+}
+
 func inKeyList(key string, data []string) bool {
 	for _, d := range data {
 		if key == d {
@@ -232,7 +248,7 @@ func buildRouteKeyAndVerify(t *testing.T, timeoutExpected bool, op, cname, ns, n
 	}
 }
 
-func addGDPAndGSLBForIngress(t *testing.T) *gslbalphav1.GlobalDeploymentPolicy {
+func addGDPAndGSLBForIngress(t *testing.T) *gslbalphav2.GlobalDeploymentPolicy {
 	gslbObj := getTestGSLBObject()
 	gc, err := gslbingestion.IsGSLBConfigValid(gslbObj)
 	if err != nil {
@@ -249,7 +265,7 @@ func addGDPAndGSLBForIngress(t *testing.T) *gslbalphav1.GlobalDeploymentPolicy {
 	return gdp
 }
 
-func DeleteTestGDPObj(gdp *gslbalphav1.GlobalDeploymentPolicy) {
+func DeleteTestGDPObj(gdp *gslbalphav2.GlobalDeploymentPolicy) {
 	ingestionQ := containerutils.SharedWorkQueue().GetQueueByName(containerutils.ObjectIngestionLayer)
 	gslbingestion.DeleteGDPObj(gdp, ingestionQ.Workqueue, 2)
 }
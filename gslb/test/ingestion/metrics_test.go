@@ -0,0 +1,82 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"bufio"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	corev1 "k8s.io/api/core/v1"
+)
+
+// scrapeMetric returns the value of the series from the metrics endpoint, and whether the series
+// is present.
+func scrapeMetric(t *testing.T, series string) (float64, bool) {
+	rec := httptest.NewRecorder()
+	metrics.ServeMetrics(rec, httptest.NewRequest("GET", metrics.MetricsRoute, nil))
+	scanner := bufio.NewScanner(rec.Body)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, series+" ") {
+			continue
+		}
+		value, err := strconv.ParseFloat(strings.TrimPrefix(line, series+" "), 64)
+		if err != nil {
+			t.Fatalf("error in parsing the value of %s: %v", series, err)
+		}
+		return value, true
+	}
+	return 0, false
+}
+
+func TestMemberObjectMetrics(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	testPrefix := "mtr-"
+	svcName := testPrefix + "def-svc"
+	ns := "default"
+	host := testPrefix + TestDomain1
+	ipAddr := "10.10.10.10"
+	cname := "cluster1"
+	acceptedSeries := `amko_member_objects{cluster="cluster1",state="accepted",type="` + gslbutils.SvcType + `"}`
+
+	gslbingestion.RegisterMetricsCollectors()
+	gdp := addGDPAndGSLBForSvc(t)
+	// the series of a cluster isn't present if it has no objects
+	prevAccepted, _ := scrapeMetric(t, acceptedSeries)
+
+	K8sAddSvc(t, fooKubeClient, svcName, ns, cname, host, ipAddr, corev1.ServiceTypeLoadBalancer)
+	buildSvcKeyAndVerify(t, false, "ADD", cname, ns, svcName)
+	accepted, _ := scrapeMetric(t, acceptedSeries)
+	g.Expect(accepted).To(gomega.Equal(prevAccepted + 1))
+	_, found := scrapeMetric(t, `amko_workqueue_depth{queue="`+utils.ObjectIngestionLayer+`"}`)
+	g.Expect(found).To(gomega.BeTrue())
+	_, found = scrapeMetric(t, `amko_avi_cache_objects{object="GSLBService"}`)
+	g.Expect(found).To(gomega.BeTrue())
+
+	K8sDeleteSvc(t, fooKubeClient, svcName, ns)
+	buildSvcKeyAndVerify(t, false, "DELETE", cname, ns, svcName)
+	accepted, _ = scrapeMetric(t, acceptedSeries)
+	g.Expect(accepted).To(gomega.Equal(prevAccepted))
+	DeleteTestGDPObj(gdp)
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// addTestStaleSvc adds an accepted LB service of cluster cname to the store, without creating it in
+// the cluster.
+func addTestStaleSvc(cname, ns, name string) {
+	svcMeta := k8sobjects.SvcMeta{
+		Cluster:   cname,
+		Name:      name,
+		Namespace: ns,
+		Hostname:  name + "." + TestDomain1,
+		IPAddr:    "10.10.10.10",
+	}
+	gslbutils.GetAcceptedLBSvcStore().AddOrUpdate(svcMeta, cname, ns, name)
+}
+
+func getStaleMembers(cname string) string {
+	for _, member := range gslbutils.GetGSLBConfigStatus().MemberClusters {
+		if member.ClusterContext == cname {
+			return member.StaleMembers
+		}
+	}
+	return ""
+}
+
+func verifyStaleMemberKey(t *testing.T, op, cname, ns, name string) {
+	key := gslbutils.MultiClusterKey(op, gslbutils.SvcType, cname, ns, name)
+	if passed, errStr := waitAndVerify(t, []string{key}, false); !passed {
+		t.Fatal(errStr)
+	}
+}
+
+func TestStaleMemberPolicyDisable(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+	gslbutils.SetStaleMemberPolicy(&gslbalphav2.StaleMemberPolicy{Action: gslbutils.StaleMemberDisable, GracePeriod: 60})
+	defer gslbutils.SetStaleMemberPolicy(nil)
+
+	cname, ns, name := "stale-cluster", "default", "stale-svc"
+	unreachable := addTestMemberCluster(cname)
+	gslbingestion.EvaluateMemberClusterHealth()
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterConnected, metav1.ConditionTrue)
+	addTestStaleSvc(cname, ns, name)
+	disconnectTestMemberCluster(g, cname, unreachable)
+
+	// the members are kept till the grace period
+	g.Expect(gslbingestion.ApplyStaleMemberPolicy(time.Now())).To(gomega.Equal(false))
+	g.Expect(gslbutils.IsMemberDrained(false, cname)).To(gomega.Equal(false))
+
+	g.Expect(gslbingestion.ApplyStaleMemberPolicy(time.Now().Add(61 * time.Second))).To(gomega.Equal(true))
+	verifyStaleMemberKey(t, gslbutils.ObjectUpdate, cname, ns, name)
+	g.Expect(gslbutils.IsMemberDrained(false, cname)).To(gomega.Equal(true))
+	g.Expect(getStaleMembers(cname)).To(gomega.Equal(gslbalphav2.StaleMembersDisabled))
+	// the policy is applied only once
+	g.Expect(gslbingestion.ApplyStaleMemberPolicy(time.Now().Add(120 * time.Second))).To(gomega.Equal(false))
+
+	// once the cluster is reachable, the members are enabled, and the service which was deleted
+	// while the cluster was disconnected is removed after the informers are synced
+	atomic.StoreInt32(unreachable, 0)
+	gslbingestion.EvaluateMemberClusterHealth()
+	verifyMemberClusterState(g, cname, gslbalphav2.ClusterConnected, metav1.ConditionTrue)
+	g.Expect(gslbutils.IsMemberDrained(false, cname)).To(gomega.Equal(false))
+	g.Expect(getStaleMembers(cname)).To(gomega.Equal(""))
+	verifyStaleMemberKey(t, gslbutils.ObjectDelete, cname, ns, name)
+	_, ok := gslbutils.GetAcceptedLBSvcStore().GetClusterNSObjectByName(cname, ns, name)
+	g.Expect(ok).To(gomega.Equal(false))
+}
+
+func TestStaleMemberPolicyRemove(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+	gslbutils.SetStaleMemberPolicy(&gslbalphav2.StaleMemberPolicy{Action: gslbutils.StaleMemberRemove})
+	defer gslbutils.SetStaleMemberPolicy(nil)
+
+	cname, ns, name := "removed-cluster", "default", "removed-svc"
+	unreachable := addTestMemberCluster(cname)
+	gslbingestion.EvaluateMemberClusterHealth()
+	addTestStaleSvc(cname, ns, name)
+	disconnectTestMemberCluster(g, cname, unreachable)
+
+	// the default grace period applies if the policy doesn't set it
+	gracePeriod := gslbutils.DefaultStaleMemberGracePeriod * time.Second
+	g.Expect(gslbingestion.ApplyStaleMemberPolicy(time.Now().Add(gracePeriod / 2))).To(gomega.Equal(false))
+	g.Expect(gslbingestion.ApplyStaleMemberPolicy(time.Now().Add(gracePeriod + time.Second))).To(gomega.Equal(true))
+	verifyStaleMemberKey(t, gslbutils.ObjectDelete, cname, ns, name)
+	_, ok := gslbutils.GetAcceptedLBSvcStore().GetClusterNSObjectByName(cname, ns, name)
+	g.Expect(ok).To(gomega.Equal(false))
+	g.Expect(getStaleMembers(cname)).To(gomega.Equal(gslbalphav2.StaleMembersRemoved))
+	// the members aren't disabled by the remove action
+	g.Expect(gslbutils.IsMemberDrained(false, cname)).To(gomega.Equal(false))
+}
+
+func TestStaleMemberPolicyKeep(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	cname, ns, name := "kept-cluster", "default", "kept-svc"
+	unreachable := addTestMemberCluster(cname)
+	gslbingestion.EvaluateMemberClusterHealth()
+	addTestStaleSvc(cname, ns, name)
+	disconnectTestMemberCluster(g, cname, unreachable)
+
+	g.Expect(gslbingestion.ApplyStaleMemberPolicy(time.Now().Add(time.Hour))).To(gomega.Equal(false))
+	_, ok := gslbutils.GetAcceptedLBSvcStore().GetClusterNSObjectByName(cname, ns, name)
+	g.Expect(ok).To(gomega.Equal(true))
+	g.Expect(gslbutils.IsMemberDrained(false, cname)).To(gomega.Equal(false))
+	gslbutils.GetAcceptedLBSvcStore().DeleteClusterNSObj(cname, ns, name)
+}
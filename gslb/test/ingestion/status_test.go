@@ -0,0 +1,81 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func TestGSLBConfigStatusConditions(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gc := getTestGSLBObject()
+	gc.Generation = 3
+	gslbutils.SetGSLBConfigObj(gc)
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionControllerReachable, metav1.ConditionTrue,
+		gslbalphav2.ReasonConnected, "connected")
+	g.Expect(gslbutils.UpdateGSLBConfigStatus(gslbalphav2.ConditionSynced, metav1.ConditionUnknown,
+		gslbalphav2.ReasonSyncing, "syncing")).To(gomega.Succeed())
+	status := gslbutils.GetGSLBConfigStatus()
+	g.Expect(status.ObservedGeneration).To(gomega.Equal(int64(3)))
+	g.Expect(gslbalphav2.IsConditionTrue(status.Conditions, gslbalphav2.ConditionControllerReachable)).To(gomega.Equal(true))
+	cond := gslbalphav2.FindCondition(status.Conditions, gslbalphav2.ConditionSynced)
+	g.Expect(cond).NotTo(gomega.BeNil())
+	g.Expect(cond.Status).To(gomega.Equal(metav1.ConditionUnknown))
+	g.Expect(cond.ObservedGeneration).To(gomega.Equal(int64(3)))
+	transitionTime := cond.LastTransitionTime
+
+	// the transition time only changes along with the status of a condition
+	gslbutils.SetGSLBConfigCondition(gslbalphav2.ConditionSynced, metav1.ConditionUnknown,
+		gslbalphav2.ReasonSyncing, "still syncing")
+	cond = gslbalphav2.FindCondition(gslbutils.GetGSLBConfigStatus().Conditions, gslbalphav2.ConditionSynced)
+	g.Expect(cond.Message).To(gomega.Equal("still syncing"))
+	g.Expect(cond.LastTransitionTime.Equal(&transitionTime)).To(gomega.Equal(true))
+}
+
+func TestGSLBConfigMemberClusterStatus(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbutils.SetGSLBConfigObj(getTestGSLBObject())
+	defer gslbutils.SetGSLBConfigObj(nil)
+
+	g.Expect(gslbutils.NotReadyMemberClusters()).To(gomega.Equal([]string{"cluster1", "cluster2"}))
+
+	gslbutils.SetMemberClusterStatus("cluster1", gslbalphav2.ClusterConnected, "connected")
+	gslbutils.SetMemberClusterStatus("cluster2", gslbalphav2.ClusterDegraded, "cluster health check failed")
+	g.Expect(gslbutils.NotReadyMemberClusters()).To(gomega.Equal([]string{"cluster2"}))
+
+	status := gslbutils.GetGSLBConfigStatus()
+	g.Expect(status.MemberClusters).To(gomega.HaveLen(2))
+	g.Expect(status.MemberClusters[1].State).To(gomega.Equal(gslbalphav2.ClusterDegraded))
+	cond := gslbalphav2.FindCondition(status.MemberClusters[1].Conditions, gslbalphav2.ConditionReady)
+	g.Expect(cond.Status).To(gomega.Equal(metav1.ConditionUnknown))
+	g.Expect(cond.Reason).To(gomega.Equal(gslbalphav2.ReasonDegraded))
+
+	gslbutils.SetMemberClusterStatus("cluster2", gslbalphav2.ClusterDisconnected, "cluster health check failed")
+	cond = gslbalphav2.FindCondition(gslbutils.GetGSLBConfigStatus().MemberClusters[1].Conditions,
+		gslbalphav2.ConditionReady)
+	g.Expect(cond.Status).To(gomega.Equal(metav1.ConditionFalse))
+	g.Expect(cond.Reason).To(gomega.Equal(gslbalphav2.ReasonDisconnected))
+
+	gslbutils.SetMemberClusterStatus("cluster2", gslbalphav2.ClusterConnected, "connected")
+	g.Expect(gslbutils.NotReadyMemberClusters()).To(gomega.BeEmpty())
+	g.Expect(gslbutils.GetGSLBConfigStatus().MemberClusters).To(gomega.HaveLen(2))
+}
@@ -0,0 +1,137 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+)
+
+func verifyTrafficShiftWeights(g *gomega.GomegaWithT, host string, sourceWeight, targetWeight int32) {
+	weight, _ := gslbutils.ResolveTrafficWeight(0, host, "default", "cluster1")
+	g.Expect(weight).To(gomega.Equal(sourceWeight))
+	weight, _ = gslbutils.ResolveTrafficWeight(0, host, "default", "cluster2")
+	g.Expect(weight).To(gomega.Equal(targetWeight))
+}
+
+// A traffic shift must advance only after the target cluster's members are healthy for the soak
+// time, and must roll back if any of them go down.
+func TestTrafficShiftAdvanceAndRollback(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	testPrefix := "tsh-"
+	now := time.Now()
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	gdp.Spec.TrafficShift = &gslbalphav2.TrafficShift{
+		SourceCluster: "cluster1",
+		TargetCluster: "cluster2",
+		Steps:         []int{10, 50, 100},
+		SoakTime:      60,
+	}
+	AddTestGDPObj(gdp)
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
+	g.Expect(gslbutils.GetGlobalFilter().GetTrafficShift().TargetPercent()).To(gomega.Equal(10))
+
+	hosts, deleteKeys := addAndVerifyTrafficWeightIngresses(t, testPrefix)
+	verifyTrafficShiftWeights(g, hosts[0], 18, 2)
+	updateKeys := []string{GetIngressKey("UPDATE", "cluster1", "default", testPrefix+"def-ing1", hosts[0]),
+		GetIngressKey("UPDATE", "cluster1", "default", testPrefix+"def-ing2", hosts[1]),
+		GetIngressKey("UPDATE", "cluster2", "default", testPrefix+"def-ing1", hosts[0]),
+		GetIngressKey("UPDATE", "cluster2", "default", testPrefix+"def-ing2", hosts[1])}
+	healthy := gslbutils.TargetHealth{Members: 2, UpMembers: 2}
+
+	// the soak time starts, nothing changes
+	gslbingestion.ApplyTrafficShift(now, healthy)
+	passed, errStr := waitAndVerify(t, []string{}, true)
+	if !passed {
+		t.Fatal(errStr)
+	}
+
+	// the members are healthy for the soak time, move to the next step
+	gslbingestion.ApplyTrafficShift(now.Add(61*time.Second), healthy)
+	VerifyAllKeys(t, updateKeys, false)
+	verifyTrafficShiftWeights(g, hosts[0], 10, 10)
+	g.Expect(gslbutils.GetGlobalFilter().GetTrafficShift().Step).To(gomega.Equal(1))
+
+	// a member goes down, all the traffic goes back to the source cluster
+	gslbingestion.ApplyTrafficShift(now.Add(70*time.Second), gslbutils.TargetHealth{Members: 2, UpMembers: 1,
+		DownMembers: 1})
+	VerifyAllKeys(t, updateKeys, false)
+	ts := gslbutils.GetGlobalFilter().GetTrafficShift()
+	g.Expect(ts.State).To(gomega.Equal(gslbutils.TrafficShiftRolledBack))
+	g.Expect(ts.TargetPercent()).To(gomega.Equal(0))
+	g.Expect(gslbutils.IsMemberDrained(false, "cluster2")).To(gomega.Equal(true))
+	g.Expect(gslbutils.IsMemberDrained(false, "cluster1")).To(gomega.Equal(false))
+	weight, _ := gslbutils.ResolveTrafficWeight(0, hosts[0], "default", "cluster1")
+	g.Expect(weight).To(gomega.Equal(int32(20)))
+
+	// a rolled back shift doesn't move ahead anymore
+	gslbingestion.ApplyTrafficShift(now.Add(200*time.Second), healthy)
+	passed, errStr = waitAndVerify(t, []string{}, true)
+	if !passed {
+		t.Fatal(errStr)
+	}
+
+	deleteTrafficWeightIngresses(t, testPrefix, deleteKeys)
+	DeleteTestGDPObj(gdp)
+}
+
+func TestTrafficShiftComplete(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	now := time.Now()
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	gdp.Spec.TrafficShift = &gslbalphav2.TrafficShift{
+		SourceCluster: "cluster1",
+		TargetCluster: "cluster2",
+		Steps:         []int{50, 100},
+	}
+	AddTestGDPObj(gdp)
+	healthy := gslbutils.TargetHealth{Members: 1, UpMembers: 1}
+
+	// members which aren't up yet neither advance nor roll back the shift
+	gslbingestion.ApplyTrafficShift(now, gslbutils.TargetHealth{Members: 1})
+	g.Expect(gslbutils.GetGlobalFilter().GetTrafficShift().Step).To(gomega.Equal(0))
+
+	gslbingestion.ApplyTrafficShift(now, healthy)
+	ts := gslbutils.GetGlobalFilter().GetTrafficShift()
+	g.Expect(ts.Step).To(gomega.Equal(1))
+	g.Expect(gslbutils.IsMemberDrained(false, "cluster1")).To(gomega.Equal(true))
+
+	gslbingestion.ApplyTrafficShift(now.Add(time.Second), healthy)
+	ts = gslbutils.GetGlobalFilter().GetTrafficShift()
+	g.Expect(ts.State).To(gomega.Equal(gslbutils.TrafficShiftCompleted))
+	g.Expect(ts.TargetPercent()).To(gomega.Equal(100))
+	DeleteTestGDPObj(gdp)
+}
+
+func TestTrafficShiftInvalidSteps(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	gdp.Spec.TrafficShift = &gslbalphav2.TrafficShift{
+		SourceCluster: "cluster1",
+		TargetCluster: "cluster2",
+		Steps:         []int{50, 10},
+	}
+	AddTestGDPObj(gdp)
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal("traffic shift steps must be in increasing order"))
+	DeleteTestGDPObj(gdp)
+}
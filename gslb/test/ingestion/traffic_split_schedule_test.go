@@ -0,0 +1,105 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getTestTrafficSplitWindow(name string, start, end time.Time, ts []gslbalphav2.ClusterWeight) gslbalphav2.ScheduledTrafficSplit {
+	return gslbalphav2.ScheduledTrafficSplit{
+		Name:         name,
+		Start:        metav1.NewTime(start),
+		End:          metav1.NewTime(end),
+		TrafficSplit: ts,
+	}
+}
+
+// When a traffic split window opens or closes, only the objects of the clusters whose weights
+// change must be re-published.
+func TestScheduledTrafficSplit(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	testPrefix := "sts-"
+	now := time.Now()
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	gdp.Spec.TrafficSplit = []gslbalphav2.ClusterWeight{
+		{Cluster: "cluster1", Weight: 5},
+		{Cluster: "cluster2", Weight: 5},
+	}
+	gdp.Spec.ScheduledTrafficSplit = []gslbalphav2.ScheduledTrafficSplit{
+		getTestTrafficSplitWindow("maintenance", now.Add(time.Hour), now.Add(2*time.Hour),
+			[]gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 15}, {Cluster: "cluster2", Weight: 5}}),
+	}
+	AddTestGDPObj(gdp)
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
+	g.Expect(gslbutils.GetGlobalFilter().GetActiveTrafficSplitWindow()).To(gomega.Equal(""))
+
+	hosts, deleteKeys := addAndVerifyTrafficWeightIngresses(t, testPrefix)
+	updateKeys := []string{GetIngressKey("UPDATE", "cluster1", "default", testPrefix+"def-ing1", hosts[0]),
+		GetIngressKey("UPDATE", "cluster1", "default", testPrefix+"def-ing2", hosts[1])}
+
+	// the window opens
+	gslbingestion.ApplyTrafficSplitWindows(now.Add(90 * time.Minute))
+	g.Expect(gslbutils.GetGlobalFilter().GetActiveTrafficSplitWindow()).To(gomega.Equal("maintenance"))
+	VerifyAllKeys(t, updateKeys, false)
+	// no keys for cluster2
+	passed, errStr := waitAndVerify(t, []string{}, true)
+	if !passed {
+		t.Fatal(errStr)
+	}
+	weight, src := gslbutils.ResolveTrafficWeight(0, hosts[0], "default", "cluster1")
+	g.Expect(weight).To(gomega.Equal(int32(15)))
+	g.Expect(src).To(gomega.Equal(gslbutils.WeightFromGDP))
+
+	// evaluating again in the same window doesn't change anything
+	gslbingestion.ApplyTrafficSplitWindows(now.Add(100 * time.Minute))
+	passed, errStr = waitAndVerify(t, []string{}, true)
+	if !passed {
+		t.Fatal(errStr)
+	}
+
+	// the window closes, fall back to the default traffic split
+	gslbingestion.ApplyTrafficSplitWindows(now.Add(3 * time.Hour))
+	g.Expect(gslbutils.GetGlobalFilter().GetActiveTrafficSplitWindow()).To(gomega.Equal(""))
+	VerifyAllKeys(t, updateKeys, false)
+	weight, _ = gslbutils.ResolveTrafficWeight(0, hosts[0], "default", "cluster1")
+	g.Expect(weight).To(gomega.Equal(int32(5)))
+
+	deleteTrafficWeightIngresses(t, testPrefix, deleteKeys)
+	DeleteTestGDPObj(gdp)
+}
+
+func TestScheduledTrafficSplitOverlap(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	now := time.Now()
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	ts := []gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 15}}
+	gdp.Spec.ScheduledTrafficSplit = []gslbalphav2.ScheduledTrafficSplit{
+		getTestTrafficSplitWindow("w1", now, now.Add(2*time.Hour), ts),
+		getTestTrafficSplitWindow("w2", now.Add(time.Hour), now.Add(3*time.Hour), ts),
+	}
+	AddTestGDPObj(gdp)
+	g.Expect(getAcceptedMsg(gdp.Status.Conditions)).To(gomega.Equal("scheduled traffic splits w1 and w2 overlap"))
+	DeleteTestGDPObj(gdp)
+}
@@ -0,0 +1,227 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"testing"
+
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+func getTestGSLBHostRule(name, fqdn string, ts []gslbalphav2.ClusterWeight) *gslbalphav2.GSLBHostRule {
+	return &gslbalphav2.GSLBHostRule{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:            name,
+			Namespace:       gslbutils.AVISystem,
+			ResourceVersion: "100",
+		},
+		Spec: gslbalphav2.GSLBHostRuleSpec{
+			Fqdn:         fqdn,
+			TrafficSplit: ts,
+		},
+	}
+}
+
+func addAndVerifyTrafficWeightIngresses(t *testing.T, testPrefix string) ([]string, []string) {
+	ingNameList := []string{testPrefix + "def-ing1", testPrefix + "def-ing2"}
+	hosts := []string{testPrefix + TestDomain1, testPrefix + TestDomain2}
+	ipAddrs := []string{"10.10.10.10", "10.10.10.11"}
+
+	ingList1, allKeys1 := CreateMultipleIngresses(t, fooKubeClient, ingNameList, hosts, ipAddrs, "default", "test-svc", "cluster1")
+	ingList2, allKeys2 := CreateMultipleIngresses(t, barKubeClient, ingNameList, hosts, ipAddrs, "default", "test-svc", "cluster2")
+	VerifyAllKeys(t, append(allKeys1, allKeys2...), false)
+
+	deleteKeys := append(GetMultipleIngDeleteKeys(t, ingList1, "cluster1", "default"),
+		GetMultipleIngDeleteKeys(t, ingList2, "cluster2", "default")...)
+	return hosts, deleteKeys
+}
+
+func deleteTrafficWeightIngresses(t *testing.T, testPrefix string, deleteKeys []string) {
+	for _, name := range []string{testPrefix + "def-ing1", testPrefix + "def-ing2"} {
+		k8sDeleteIngress(t, fooKubeClient, name, "default")
+		k8sDeleteIngress(t, barKubeClient, name, "default")
+	}
+	VerifyAllKeys(t, deleteKeys, false)
+}
+
+// Changing the weight of one cluster in the GDP object must only re-publish the objects of that
+// cluster.
+func TestGDPTrafficWeightChangeForOneCluster(t *testing.T) {
+	testPrefix := "twc-"
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	gdp.Spec.TrafficSplit = []gslbalphav2.ClusterWeight{
+		{Cluster: "cluster1", Weight: 5},
+		{Cluster: "cluster2", Weight: 5},
+	}
+	AddTestGDPObj(gdp)
+
+	hosts, deleteKeys := addAndVerifyTrafficWeightIngresses(t, testPrefix)
+
+	oldGdp := gdp.DeepCopy()
+	gdp.Spec.TrafficSplit[0].Weight = 10
+	gdp.ResourceVersion = "101"
+	UpdateTestGDPObj(oldGdp, gdp)
+
+	updateKeys := []string{GetIngressKey("UPDATE", "cluster1", "default", testPrefix+"def-ing1", hosts[0]),
+		GetIngressKey("UPDATE", "cluster1", "default", testPrefix+"def-ing2", hosts[1])}
+	VerifyAllKeys(t, updateKeys, false)
+	// no keys for cluster2
+	passed, errStr := waitAndVerify(t, []string{}, true)
+	if !passed {
+		t.Fatal(errStr)
+	}
+
+	deleteTrafficWeightIngresses(t, testPrefix, deleteKeys)
+	DeleteTestGDPObj(gdp)
+}
+
+// A GSLBHostRule must only re-publish the members of the GS for its fqdn.
+func TestGSLBHostRuleTrafficWeight(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	testPrefix := "twh-"
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	AddTestGDPObj(gdp)
+
+	hosts, deleteKeys := addAndVerifyTrafficWeightIngresses(t, testPrefix)
+
+	ingestionQ := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+	hr := getTestGSLBHostRule("test-hr", hosts[0], []gslbalphav2.ClusterWeight{{Cluster: "cluster2", Weight: 8}})
+	gslbingestion.AddGSLBHostRuleObj(hr, ingestionQ.Workqueue, 2)
+	// the status is written on the API server, the object from the informer cache isn't modified
+	g.Expect(hr.Status.Conditions).To(gomega.BeEmpty())
+
+	updateKeys := []string{GetIngressKey("UPDATE", "cluster1", "default", testPrefix+"def-ing1", hosts[0]),
+		GetIngressKey("UPDATE", "cluster2", "default", testPrefix+"def-ing1", hosts[0])}
+	VerifyAllKeys(t, updateKeys, false)
+	weight, src := gslbutils.ResolveTrafficWeight(0, hosts[0], "default", "cluster2")
+	g.Expect(weight).To(gomega.Equal(int32(8)))
+	g.Expect(src).To(gomega.Equal(gslbutils.WeightFromHostRule))
+	// an object level weight overrides the GSLBHostRule
+	weight, src = gslbutils.ResolveTrafficWeight(3, hosts[0], "default", "cluster2")
+	g.Expect(weight).To(gomega.Equal(int32(3)))
+	g.Expect(src).To(gomega.Equal(gslbutils.WeightFromObject))
+
+	// another GSLBHostRule for the same fqdn must be rejected
+	anotherHr := getTestGSLBHostRule("test-hr2", hosts[0], nil)
+	gslbingestion.AddGSLBHostRuleObj(anotherHr, ingestionQ.Workqueue, 2)
+	g.Expect(gslbingestion.GSLBHostRuleSanityChecks(anotherHr)).To(gomega.MatchError("GSLBHostRule " +
+		gslbutils.AVISystem + "/test-hr already exists for fqdn " + hosts[0]))
+
+	gslbingestion.DeleteGSLBHostRuleObj(hr, ingestionQ.Workqueue, 2)
+	VerifyAllKeys(t, updateKeys, false)
+
+	deleteTrafficWeightIngresses(t, testPrefix, deleteKeys)
+	DeleteTestGDPObj(gdp)
+}
+
+// A GDP object in a namespace other than avi-system only overrides the weights for its namespace.
+func TestNamespaceGDPTrafficWeight(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	testPrefix := "twn-"
+	buildAndAddTestGSLBObject(t)
+	gdp := getTestGDPObject(true, false)
+	gdp.Spec.TrafficSplit = []gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 5}}
+	AddTestGDPObj(gdp)
+
+	hosts, deleteKeys := addAndVerifyTrafficWeightIngresses(t, testPrefix)
+
+	nsGdp := getTestGDPObject(false, false)
+	nsGdp.ObjectMeta.Name = "ns-gdp"
+	nsGdp.ObjectMeta.Namespace = "default"
+	nsGdp.Spec.TrafficSplit = []gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 15}}
+	AddTestGDPObj(nsGdp)
+	g.Expect(getAcceptedMsg(nsGdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
+
+	// all the objects of the namespace are re-evaluated
+	updateKeys := []string{}
+	for _, cname := range []string{"cluster1", "cluster2"} {
+		updateKeys = append(updateKeys, GetIngressKey("UPDATE", cname, "default", testPrefix+"def-ing1", hosts[0]),
+			GetIngressKey("UPDATE", cname, "default", testPrefix+"def-ing2", hosts[1]))
+	}
+	VerifyAllKeys(t, updateKeys, false)
+	weight, src := gslbutils.ResolveTrafficWeight(0, hosts[0], "default", "cluster1")
+	g.Expect(weight).To(gomega.Equal(int32(15)))
+	g.Expect(src).To(gomega.Equal(gslbutils.WeightFromNamespace))
+	weight, src = gslbutils.ResolveTrafficWeight(0, hosts[0], "default", "cluster2")
+	g.Expect(weight).To(gomega.Equal(int32(1)))
+	g.Expect(src).To(gomega.Equal(gslbutils.WeightFromDefault))
+
+	DeleteTestGDPObj(nsGdp)
+	VerifyAllKeys(t, updateKeys, false)
+	weight, src = gslbutils.ResolveTrafficWeight(0, hosts[0], "default", "cluster1")
+	g.Expect(weight).To(gomega.Equal(int32(5)))
+	g.Expect(src).To(gomega.Equal(gslbutils.WeightFromGDP))
+
+	deleteTrafficWeightIngresses(t, testPrefix, deleteKeys)
+	DeleteTestGDPObj(gdp)
+}
+
+// Only one GDP object can override the weights of a namespace, another GDP object for the namespace
+// is rejected and doesn't affect the accepted one.
+func TestNamespaceGDPAlreadyExists(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	buildAndAddTestGSLBObject(t)
+	ns := "twn-dup"
+	host := "twn-dup." + TestDomain1
+
+	nsGdp := getTestGDPObject(false, false)
+	nsGdp.ObjectMeta.Name = "ns-gdp"
+	nsGdp.ObjectMeta.Namespace = ns
+	nsGdp.Spec.TrafficSplit = []gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 15}}
+	AddTestGDPObj(nsGdp)
+	g.Expect(getAcceptedMsg(nsGdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
+
+	verifyWeight := func(expected int32, expectedSrc gslbutils.TrafficWeightSource) {
+		weight, src := gslbutils.ResolveTrafficWeight(0, host, ns, "cluster1")
+		g.Expect(weight).To(gomega.Equal(expected))
+		g.Expect(src).To(gomega.Equal(expectedSrc))
+	}
+	verifyWeight(15, gslbutils.WeightFromNamespace)
+
+	anotherGdp := getTestGDPObject(false, false)
+	anotherGdp.ObjectMeta.Name = "ns-gdp2"
+	anotherGdp.ObjectMeta.Namespace = ns
+	anotherGdp.Spec.TrafficSplit = []gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 7}}
+	AddTestGDPObj(anotherGdp)
+	g.Expect(getAcceptedMsg(anotherGdp.Status.Conditions)).To(gomega.Equal("GDP object " + ns +
+		"/ns-gdp already exists for namespace " + ns))
+	verifyWeight(15, gslbutils.WeightFromNamespace)
+
+	// an invalid update or the deletion of the rejected GDP object must not remove the weights
+	invalidGdp := anotherGdp.DeepCopy()
+	invalidGdp.ObjectMeta.ResourceVersion = "200"
+	invalidGdp.Spec.TrafficSplit = []gslbalphav2.ClusterWeight{{Cluster: "cluster3", Weight: 7}}
+	UpdateTestGDPObj(anotherGdp, invalidGdp)
+	verifyWeight(15, gslbutils.WeightFromNamespace)
+	DeleteTestGDPObj(invalidGdp)
+	verifyWeight(15, gslbutils.WeightFromNamespace)
+
+	// once the accepted GDP object is deleted, another GDP object can be accepted for the namespace
+	DeleteTestGDPObj(nsGdp)
+	verifyWeight(1, gslbutils.WeightFromDefault)
+	AddTestGDPObj(anotherGdp)
+	g.Expect(getAcceptedMsg(anotherGdp.Status.Conditions)).To(gomega.Equal(gslbingestion.GDPSuccess))
+	verifyWeight(7, gslbutils.WeightFromNamespace)
+	DeleteTestGDPObj(anotherGdp)
+	verifyWeight(1, gslbutils.WeightFromDefault)
+}
@@ -0,0 +1,167 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package ingestion
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/webhook"
+	gslbalphav2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+)
+
+// sendAdmissionReview posts an admission review for obj to the webhook handler and returns the
+// response.
+func sendAdmissionReview(t *testing.T, kind, op string, obj metav1.Object) *webhook.AdmissionResponse {
+	raw, err := json.Marshal(obj)
+	if err != nil {
+		t.Fatalf("error in marshalling object: %v", err)
+	}
+	review := webhook.AdmissionReview{
+		TypeMeta: metav1.TypeMeta{APIVersion: "admission.k8s.io/v1", Kind: "AdmissionReview"},
+		Request: &webhook.AdmissionRequest{
+			UID:       "test-uid",
+			Kind:      metav1.GroupVersionKind{Group: "amko.vmware.com", Version: "v1alpha2", Kind: kind},
+			Name:      obj.GetName(),
+			Namespace: obj.GetNamespace(),
+			Operation: op,
+			Object:    runtime.RawExtension{Raw: raw},
+		},
+	}
+	body, _ := json.Marshal(review)
+	req := httptest.NewRequest(http.MethodPost, webhook.ValidatePath, bytes.NewReader(body))
+	rec := httptest.NewRecorder()
+	webhook.ServeValidate(rec, req)
+	if rec.Code != http.StatusOK {
+		t.Fatalf("unexpected webhook response code %d: %s", rec.Code, rec.Body.String())
+	}
+	var resp webhook.AdmissionReview
+	if err := json.Unmarshal(rec.Body.Bytes(), &resp); err != nil || resp.Response == nil {
+		t.Fatalf("malformed webhook response: %s", rec.Body.String())
+	}
+	if resp.Response.UID != "test-uid" {
+		t.Fatalf("unexpected uid in webhook response: %s", resp.Response.UID)
+	}
+	return resp.Response
+}
+
+func verifyAdmissionRejected(g *gomega.GomegaWithT, resp *webhook.AdmissionResponse, msg string) {
+	g.Expect(resp.Allowed).To(gomega.Equal(false))
+	g.Expect(resp.Result.Message).To(gomega.Equal(msg))
+}
+
+func TestWebhookGSLBConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbingestion.RegisterWebhookValidators()
+
+	gc := getTestGSLBObject()
+	g.Expect(sendAdmissionReview(t, gslbingestion.GSLBConfigKind, webhook.Create, gc).Allowed).To(gomega.Equal(true))
+
+	gc.Namespace = "default"
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GSLBConfigKind, webhook.Create, gc),
+		"invalid gslb config, namespace can only be avi-system")
+
+	gc = getTestGSLBObject()
+	gc.Spec.MemberClusters = append(gc.Spec.MemberClusters, gslbalphav2.MemberCluster{ClusterContext: "cluster1"})
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GSLBConfigKind, webhook.Create, gc),
+		"invalid gslb config, member cluster cluster1 is added more than once")
+
+	gc = getTestGSLBObject()
+	gc.Spec.LogLevel = "VERBOSE"
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GSLBConfigKind, webhook.Create, gc),
+		"invalid gslb config, log level VERBOSE unrecognized")
+
+	gc = getTestGSLBObject()
+	gc.Spec.StaleMemberPolicy = &gslbalphav2.StaleMemberPolicy{Action: "Drop"}
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GSLBConfigKind, webhook.Create, gc),
+		"invalid gslb config, stale member action Drop unrecognized")
+
+	gc = getTestGSLBObject()
+	gc.Spec.ClusterDiscovery = &gslbalphav2.ClusterDiscovery{Format: "Rancher"}
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GSLBConfigKind, webhook.Create, gc),
+		"invalid gslb config, cluster discovery format Rancher unrecognized")
+
+	gc = getTestGSLBObject()
+	gc.Spec.GSLBLeader.TLS = &gslbalphav2.ControllerTLS{Verification: "Skip"}
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GSLBConfigKind, webhook.Create, gc),
+		"invalid gslb config, TLS verification Skip unrecognized")
+}
+
+func TestWebhookGDP(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbingestion.RegisterWebhookValidators()
+	buildAndAddTestGSLBObject(t)
+
+	gdp := getTestGDPObject(true, false)
+	g.Expect(sendAdmissionReview(t, gslbingestion.GDPKind, webhook.Create, gdp).Allowed).To(gomega.Equal(true))
+
+	gdp.Spec.MatchClusters = []string{"cluster1", "cluster3"}
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GDPKind, webhook.Create, gdp),
+		"cluster context cluster3 not present in GSLBConfig")
+
+	gdp = getTestGDPObject(true, false)
+	gdp.Spec.TrafficSplit = []gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 25}}
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GDPKind, webhook.Update, gdp),
+		"traffic weight 25 must be between 1 and 20")
+
+	gdp = getTestGDPObject(true, false)
+	gdp.Spec.MatchRules.AppSelector.Label = map[string]string{"app key": "gslb"}
+	resp := sendAdmissionReview(t, gslbingestion.GDPKind, webhook.Create, gdp)
+	g.Expect(resp.Allowed).To(gomega.Equal(false))
+	g.Expect(resp.Result.Message).To(gomega.HavePrefix("label key app key is invalid"))
+
+	// only one GDP object is allowed in avi-system
+	gdp = getTestGDPObject(true, false)
+	AddTestGDPObj(gdp)
+	anotherGdp := getTestGDPObject(true, false)
+	anotherGdp.Name = "test-gdp-2"
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GDPKind, webhook.Create, anotherGdp),
+		"a GDP object already exists, can't add another")
+	g.Expect(sendAdmissionReview(t, gslbingestion.GDPKind, webhook.Update, gdp).Allowed).To(gomega.Equal(true))
+	DeleteTestGDPObj(gdp)
+}
+
+func TestWebhookGSLBHostRule(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gslbingestion.RegisterWebhookValidators()
+	buildAndAddTestGSLBObject(t)
+	fqdn := "webhook." + TestDomain1
+
+	hr := getTestGSLBHostRule("webhook-hr", fqdn, []gslbalphav2.ClusterWeight{{Cluster: "cluster3", Weight: 5}})
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GSLBHostRuleKind, webhook.Create, hr),
+		"cluster cluster3 in traffic policy not present in GSLBConfig")
+
+	hr = getTestGSLBHostRule("webhook-hr", fqdn, []gslbalphav2.ClusterWeight{{Cluster: "cluster1", Weight: 5}})
+	g.Expect(sendAdmissionReview(t, gslbingestion.GSLBHostRuleKind, webhook.Create, hr).Allowed).To(gomega.Equal(true))
+	ingestionQ := utils.SharedWorkQueue().GetQueueByName(utils.ObjectIngestionLayer)
+	gslbingestion.AddGSLBHostRuleObj(hr, ingestionQ.Workqueue, 2)
+
+	// another GSLBHostRule for the same fqdn must be rejected, the accepted one can be updated
+	anotherHr := getTestGSLBHostRule("webhook-hr2", fqdn, nil)
+	verifyAdmissionRejected(g, sendAdmissionReview(t, gslbingestion.GSLBHostRuleKind, webhook.Create, anotherHr),
+		"GSLBHostRule "+gslbutils.AVISystem+"/webhook-hr already exists for fqdn "+fqdn)
+	g.Expect(sendAdmissionReview(t, gslbingestion.GSLBHostRuleKind, webhook.Update, hr).Allowed).To(gomega.Equal(true))
+
+	gslbingestion.DeleteGSLBHostRuleObj(hr, ingestionQ.Workqueue, 2)
+}
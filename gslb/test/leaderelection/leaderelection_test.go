@@ -0,0 +1,145 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package leaderelection
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/onsi/gomega"
+	gslbingestion "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+
+	coordinationv1 "k8s.io/api/coordination/v1"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/client-go/kubernetes"
+	k8sfake "k8s.io/client-go/kubernetes/fake"
+	"k8s.io/client-go/tools/leaderelection"
+)
+
+const (
+	leaseNS          = "avi-system"
+	leaseName        = gslbingestion.LeaderElectionLeaseName
+	testLeaseSeconds = 2
+)
+
+type testElector struct {
+	leading int32
+	stopCh  chan struct{}
+	doneCh  chan struct{}
+}
+
+func (e *testElector) isLeader() bool {
+	return atomic.LoadInt32(&e.leading) == 1
+}
+
+// startTestElector runs the leader election with the config of AMKO, with shorter durations and
+// callbacks which only record the leadership of the replica.
+func startTestElector(t *testing.T, client kubernetes.Interface, identity string) *testElector {
+	e := &testElector{stopCh: make(chan struct{}), doneCh: make(chan struct{})}
+	config := gslbingestion.LeaderElectionConfig(client, identity)
+	config.LeaseDuration = testLeaseSeconds * time.Second
+	config.RenewDeadline = time.Second
+	config.RetryPeriod = 200 * time.Millisecond
+	config.Callbacks = leaderelection.LeaderCallbacks{
+		OnStartedLeading: func(ctx context.Context) { atomic.StoreInt32(&e.leading, 1) },
+		OnStoppedLeading: func() { atomic.StoreInt32(&e.leading, 0) },
+	}
+	le, err := leaderelection.NewLeaderElector(config)
+	if err != nil {
+		t.Fatalf("error in creating the leader elector for %s: %v", identity, err)
+	}
+	go func() {
+		gslbingestion.RunLeaderElection(le, e.stopCh)
+		close(e.doneCh)
+	}()
+	return e
+}
+
+func (e *testElector) stop() {
+	close(e.stopCh)
+	<-e.doneCh
+}
+
+func getLeaseHolder(client kubernetes.Interface) string {
+	lease, err := client.CoordinationV1().Leases(leaseNS).Get(leaseName, metav1.GetOptions{})
+	if err != nil || lease.Spec.HolderIdentity == nil {
+		return ""
+	}
+	return *lease.Spec.HolderIdentity
+}
+
+func TestLeaderElectionConfig(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	client := k8sfake.NewSimpleClientset()
+
+	config := gslbingestion.LeaderElectionConfig(client, "amko-0")
+	_, err := leaderelection.NewLeaderElector(config)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(config.Lock.Identity()).To(gomega.Equal("amko-0"))
+	g.Expect(config.Lock.Describe()).To(gomega.Equal(leaseNS + "/" + leaseName))
+	g.Expect(config.ReleaseOnCancel).To(gomega.BeTrue())
+}
+
+// TestLeaderElectionFailover verifies that only one of the two replicas leads, and that the standby
+// takes over once the leader releases the lease.
+func TestLeaderElectionFailover(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	client := k8sfake.NewSimpleClientset()
+
+	first := startTestElector(t, client, "amko-0")
+	g.Eventually(first.isLeader, 5*time.Second, 100*time.Millisecond).Should(gomega.BeTrue())
+	second := startTestElector(t, client, "amko-1")
+	defer second.stop()
+
+	// the standby must not acquire the lease while the leader keeps renewing it
+	g.Consistently(second.isLeader, 3*time.Second, 100*time.Millisecond).Should(gomega.BeFalse())
+	g.Expect(first.isLeader()).To(gomega.BeTrue())
+	g.Expect(getLeaseHolder(client)).To(gomega.Equal("amko-0"))
+
+	first.stop()
+	g.Expect(first.isLeader()).To(gomega.BeFalse())
+	g.Eventually(second.isLeader, 2*time.Second, 100*time.Millisecond).Should(gomega.BeTrue())
+	g.Expect(getLeaseHolder(client)).To(gomega.Equal("amko-1"))
+
+	lease, err := client.CoordinationV1().Leases(leaseNS).Get(leaseName, metav1.GetOptions{})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(*lease.Spec.LeaseTransitions).To(gomega.Equal(int32(1)))
+}
+
+// TestLeaderElectionExpiredLease verifies that a replica acquires the lease of a leader which
+// stopped renewing it, only after the lease duration.
+func TestLeaderElectionExpiredLease(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	holder := "amko-0"
+	leaseSeconds := int32(testLeaseSeconds)
+	renewTime := metav1.NewMicroTime(time.Now())
+	client := k8sfake.NewSimpleClientset(&coordinationv1.Lease{
+		ObjectMeta: metav1.ObjectMeta{Name: leaseName, Namespace: leaseNS},
+		Spec: coordinationv1.LeaseSpec{
+			HolderIdentity:       &holder,
+			LeaseDurationSeconds: &leaseSeconds,
+			RenewTime:            &renewTime,
+		},
+	})
+
+	start := time.Now()
+	e := startTestElector(t, client, "amko-1")
+	defer e.stop()
+	g.Eventually(e.isLeader, 5*time.Second, 100*time.Millisecond).Should(gomega.BeTrue())
+	g.Expect(time.Since(start)).To(gomega.BeNumerically(">=", testLeaseSeconds*time.Second))
+	g.Expect(getLeaseHolder(client)).To(gomega.Equal("amko-1"))
+}
@@ -16,6 +16,7 @@ package mockaviserver
 
 import (
 	"encoding/json"
+	"encoding/pem"
 	"fmt"
 	"io/ioutil"
 	"net/http"
@@ -67,6 +68,15 @@ func GetMockServerURL() string {
 	return strings.Split(AviMockAPIServer.URL, "https://")[1]
 }
 
+// MockServerName is the host name in the certificate of the mock server, along with its IP
+const MockServerName = "example.com"
+
+// GetMockServerCA returns the PEM encoded certificate of the mock server, which is self signed, so
+// it's the CA bundle to verify the mock server with.
+func GetMockServerCA() []byte {
+	return pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: AviMockAPIServer.Certificate().Raw})
+}
+
 func buildHealthMonitorRef(hmRefs []interface{}) []interface{} {
 	rHmRef := hmRefs[0].(string)
 	rHmSplit := strings.Split(rHmRef, "name=")
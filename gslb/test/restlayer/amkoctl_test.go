@@ -0,0 +1,265 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/amkoctl"
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/introspection"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+	gslbcs "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned"
+	gslbfake "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned/fake"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/api/models"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// startAMKOAPIServer starts an AMKO API server with the introspection and operations routes.
+func startAMKOAPIServer() *httptest.Server {
+	apiServer := api.NewServer("0", []models.ApiModel{&introspection.IntrospectionModel{}, &ingestion.OperationsModel{}})
+	return httptest.NewServer(apiServer.Handler)
+}
+
+func runAmkoctl(server string, args ...string) (string, error) {
+	var out bytes.Buffer
+	err := amkoctl.Run(append([]string{"--server", server}, args...), &out)
+	return out.String(), err
+}
+
+func graphQueueLen() int {
+	graphQ := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer)
+	return graphQ.Workqueue[0].Len()
+}
+
+// drainGraphQueue removes the keys from the graph queue, so that a key published again can be
+// verified.
+func drainGraphQueue() {
+	graphQ := utils.SharedWorkQueue().GetQueueByName(utils.GraphLayer).Workqueue[0]
+	for graphQ.Len() > 0 {
+		key, _ := graphQ.Get()
+		graphQ.Forget(key)
+		graphQ.Done(key)
+	}
+}
+
+func TestAmkoctlShowGS(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "amkoctl-show.avi.com"
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph([]string{"foo", "bar"}, []string{"10.10.10.71", "10.10.10.72"},
+		[]string{"ing1/" + host, "ing2/" + host}, host, v1alpha2.IngressObj)
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	defer nodes.SharedAviGSGraphLister().Delete(modelName)
+	// the first member has a different weight in the Avi controller, the second member is yet to be
+	// added to it, and a member removed from the graph is yet to be removed from it
+	cacheKey := avicache.TenantName{Tenant: utils.ADMIN_NS, Name: host}
+	avicache.GetAviCache().AviCacheAdd(cacheKey, &avicache.AviGSCache{
+		Name:   host,
+		Tenant: utils.ADMIN_NS,
+		Members: []avicache.GSMember{
+			{IPAddr: "10.10.10.71", Weight: 5, Enabled: true},
+			{IPAddr: "10.10.10.79", Weight: 10, Enabled: true},
+		},
+		CloudConfigCksum: gsGraph.GraphChecksum + 1,
+	})
+	defer avicache.GetAviCache().AviCacheDelete(cacheKey)
+	server := startAMKOAPIServer()
+	defer server.Close()
+
+	out, err := runAmkoctl(server.URL, "-o", "json", "show", host)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	var state amkoctl.GSState
+	g.Expect(json.Unmarshal([]byte(out), &state)).To(gomega.Succeed())
+	g.Expect(state.InSync).To(gomega.BeFalse())
+	g.Expect(state.Members).To(gomega.HaveLen(3))
+	g.Expect(state.Members[0].State).To(gomega.Equal(amkoctl.MemberOutOfSync))
+	g.Expect(state.Members[0].AviWeight).To(gomega.Equal(int32(5)))
+	g.Expect(state.Members[1].State).To(gomega.Equal(amkoctl.MemberMissing))
+	g.Expect(state.Members[2].IPAddr).To(gomega.Equal("10.10.10.79"))
+	g.Expect(state.Members[2].State).To(gomega.Equal(amkoctl.MemberStale))
+
+	out, err = runAmkoctl(server.URL, "show", host)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(out).To(gomega.ContainSubstring("(out of sync)"))
+	g.Expect(out).To(gomega.ContainSubstring(amkoctl.MemberStale))
+
+	out, err = runAmkoctl(server.URL, "list", "--fqdn", host)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	lines := strings.Split(strings.TrimSpace(out), "\n")
+	// a header, and a line for each member
+	g.Expect(lines).To(gomega.HaveLen(3))
+	g.Expect(lines[1]).To(gomega.ContainSubstring("10.10.10.71"))
+	g.Expect(lines[1]).To(gomega.ContainSubstring("enabled"))
+
+	_, err = runAmkoctl(server.URL, "show", "unknown.avi.com")
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+// annotateTestGSLBConfig requests an operation with the annotations of the GSLBConfig object, and
+// applies them like the GSLBConfig informer of AMKO.
+func annotateTestGSLBConfig(t *testing.T, gslbClient gslbcs.Interface, annotations map[string]*string) *v1alpha2.GSLBConfig {
+	gcs := gslbClient.AmkoV1alpha2().GSLBConfigs(gslbutils.AVISystem)
+	oldGc, err := gcs.Get("gc-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error in fetching the GSLBConfig object: %v", err)
+	}
+	if _, err := amkoctl.AnnotateGSLBConfig(gslbClient, gslbutils.AVISystem, annotations); err != nil {
+		t.Fatalf("error in annotating the GSLBConfig object: %v", err)
+	}
+	newGc, err := gcs.Get("gc-1", metav1.GetOptions{})
+	if err != nil {
+		t.Fatalf("error in fetching the GSLBConfig object: %v", err)
+	}
+	ingestion.ApplyReconcileAnnotations(oldGc, newGc)
+	return newGc
+}
+
+func TestAmkoctlPauseAndResync(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "amkoctl-resync.avi.com"
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph([]string{"foo"}, []string{"10.10.10.81"}, []string{"ing1/" + host}, host,
+		v1alpha2.IngressObj)
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	defer nodes.SharedAviGSGraphLister().Delete(modelName)
+	server := startAMKOAPIServer()
+	defer server.Close()
+	defer gslbutils.SetReconcilePaused(false)
+
+	// the operations are requested through the kubernetes API, and not the AMKO API server
+	_, err := runAmkoctl(server.URL, "pause")
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(gslbutils.IsReconcilePaused()).To(gomega.BeFalse())
+
+	gslbClient := gslbfake.NewSimpleClientset(&v1alpha2.GSLBConfig{
+		ObjectMeta: metav1.ObjectMeta{Name: "gc-1", Namespace: gslbutils.AVISystem},
+	})
+	paused := "true"
+	gc := annotateTestGSLBConfig(t, gslbClient, map[string]*string{gslbutils.ReconcilePausedAnnotation: &paused})
+	g.Expect(gc.Annotations[gslbutils.ReconcilePausedAnnotation]).To(gomega.Equal("true"))
+	g.Expect(gslbutils.IsReconcilePaused()).To(gomega.BeTrue())
+	out, err := runAmkoctl(server.URL, "-o", "json", "status")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	var st ingestion.ReconciliationStatus
+	g.Expect(json.Unmarshal([]byte(out), &st)).To(gomega.Succeed())
+	g.Expect(st.Paused).To(gomega.BeTrue())
+	// the rest layer skips the keys while paused
+	gsGraph.SetRetryCounter()
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+	_, found := avicache.GetAviCache().AviCacheGet(avicache.TenantName{Tenant: utils.ADMIN_NS, Name: host})
+	g.Expect(found).To(gomega.BeFalse())
+
+	// the paused state is applied from the GSLBConfig object on a restart
+	gslbutils.SetReconcilePaused(false)
+	ingestion.ApplyReconcileAnnotations(nil, gc)
+	g.Expect(gslbutils.IsReconcilePaused()).To(gomega.BeTrue())
+
+	// the keys skipped while paused are published again on resume
+	prevLen := graphQueueLen()
+	gc = annotateTestGSLBConfig(t, gslbClient, map[string]*string{gslbutils.ReconcilePausedAnnotation: nil})
+	g.Expect(gc.Annotations).NotTo(gomega.HaveKey(gslbutils.ReconcilePausedAnnotation))
+	g.Expect(gslbutils.IsReconcilePaused()).To(gomega.BeFalse())
+	g.Eventually(graphQueueLen, "5s").Should(gomega.BeNumerically(">", prevLen))
+
+	// a resync of the same key can be requested again with a new request time
+	for _, requestedAt := range []string{"2021-03-02T10:15:04Z", "2021-03-02T10:15:05Z"} {
+		drainGraphQueue()
+		requestedAt := requestedAt
+		annotateTestGSLBConfig(t, gslbClient, map[string]*string{
+			gslbutils.ResyncAnnotation:            &modelName,
+			gslbutils.ResyncRequestedAtAnnotation: &requestedAt,
+		})
+		g.Eventually(graphQueueLen, "5s").Should(gomega.BeNumerically(">", 0))
+	}
+}
+
+func TestAmkoctlValidate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	manifest := `apiVersion: amko.vmware.com/v1alpha2
+kind: GSLBConfig
+metadata:
+  name: gc-1
+  namespace: avi-system
+spec:
+  gslbLeader:
+    controllerIP: 10.10.10.1
+  memberClusters:
+  - clusterContext: cluster1
+  - clusterContext: cluster2
+---
+apiVersion: amko.vmware.com/v1alpha2
+kind: GlobalDeploymentPolicy
+metadata:
+  name: gdp-1
+spec:
+  matchRules:
+    appSelector:
+      label:
+        app: gslb
+  matchClusters:
+  - cluster1
+  trafficSplit:
+  - cluster: cluster1
+    weight: 5
+---
+apiVersion: amko.vmware.com/v1alpha2
+kind: GlobalDeploymentPolicy
+metadata:
+  name: gdp-2
+  namespace: ns1
+spec:
+  trafficSplit:
+  - cluster: cluster3
+    weight: 5
+`
+	f, err := ioutil.TempFile("", "amkoctl-*.yaml")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer os.Remove(f.Name())
+	_, err = f.WriteString(manifest)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	f.Close()
+
+	out, err := runAmkoctl("", "validate", "-f", f.Name())
+	g.Expect(err).To(gomega.HaveOccurred())
+	g.Expect(out).To(gomega.ContainSubstring("GSLBConfig avi-system/gc-1: valid"))
+	g.Expect(out).To(gomega.ContainSubstring("GlobalDeploymentPolicy avi-system/gdp-1: valid"))
+	g.Expect(out).To(gomega.ContainSubstring("GlobalDeploymentPolicy ns1/gdp-2: invalid, cluster cluster3 in traffic policy not present in GSLBConfig"))
+
+	results, err := amkoctl.ValidateManifests([]io.Reader{strings.NewReader(`kind: GSLBConfig
+metadata:
+  name: gc-2
+  namespace: default
+`)}, gslbutils.AVISystem, nil)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(results).To(gomega.HaveLen(1))
+	g.Expect(results[0].Err).To(gomega.MatchError("invalid gslb config, namespace can only be avi-system"))
+}
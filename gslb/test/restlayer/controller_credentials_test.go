@@ -0,0 +1,82 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/test/mockaviserver"
+)
+
+// newTestAuthController starts a controller which accepts the logins of the user with either the
+// password or the token.
+func newTestAuthController(username, password, token string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		if strings.HasSuffix(r.URL.EscapedPath(), "/login") {
+			cred := map[string]string{}
+			data, _ := ioutil.ReadAll(r.Body)
+			json.Unmarshal(data, &cred)
+			if cred["username"] != username ||
+				(cred["password"] != password || password == "") && (cred["token"] != token || token == "") {
+				w.WriteHeader(http.StatusUnauthorized)
+				w.Write([]byte(`{"error": "invalid credentials"}`))
+				return
+			}
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte(`{"success": "true"}`))
+	}))
+}
+
+func TestUpdateAviClientCredentials(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	ctrl := newTestAuthController("admin", "rotated-password", "test-token")
+	defer ctrl.Close()
+	existingCreds := gslbutils.GetAviConfig().AviControllerCredentials
+	defer func() {
+		gslbutils.SetAviControllerCredentials(existingCreds)
+		avicache.RetargetAviClients(mockaviserver.GetMockServerURL())
+	}()
+	g.Expect(avicache.RetargetAviClients(getTestSiteAddr(ctrl))).NotTo(gomega.Succeed())
+	gslbutils.SetAviControllerIP(getTestSiteAddr(ctrl))
+
+	// the old password is rejected, the password is rotated
+	creds := gslbutils.AviControllerCredentials{Username: "admin", Password: "rotated-password"}
+	g.Expect(avicache.UpdateAviClientCredentials(creds)).To(gomega.Succeed())
+	g.Expect(gslbutils.GetAviConfig().Password).To(gomega.Equal("rotated-password"))
+
+	// the token is used instead of the password
+	creds = gslbutils.AviControllerCredentials{Username: "admin", Token: "test-token"}
+	g.Expect(avicache.UpdateAviClientCredentials(creds)).To(gomega.Succeed())
+	g.Expect(gslbutils.GetAviConfig().Token).To(gomega.Equal("test-token"))
+
+	// the existing credentials are kept if the new ones are rejected
+	creds = gslbutils.AviControllerCredentials{Username: "admin", Password: "wrong-password"}
+	g.Expect(avicache.UpdateAviClientCredentials(creds)).NotTo(gomega.Succeed())
+	g.Expect(gslbutils.GetAviConfig().Token).To(gomega.Equal("test-token"))
+
+	creds = gslbutils.AviControllerCredentials{Username: "admin", ClientCert: []byte("test-cert"),
+		ClientKey: []byte("test-key")}
+	g.Expect(avicache.UpdateAviClientCredentials(creds)).NotTo(gomega.Succeed())
+}
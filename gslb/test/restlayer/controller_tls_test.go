@@ -0,0 +1,93 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"testing"
+
+	"github.com/onsi/gomega"
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/test/mockaviserver"
+)
+
+// verifyMockServerTLS verifies the certificate of the mock server with tlsCfg, and connects to it
+// with the avi clients.
+func verifyMockServerTLS(tlsCfg gslbutils.AviControllerTLS) error {
+	gslbutils.SetAviControllerTLS(tlsCfg)
+	if err := avicache.VerifyControllerTLS(mockaviserver.GetMockServerURL()); err != nil {
+		return err
+	}
+	return avicache.RetargetAviClients(mockaviserver.GetMockServerURL())
+}
+
+func TestControllerTLSVerified(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func() {
+		gslbutils.SetAviControllerTLS(gslbutils.AviControllerTLS{Verification: gslbutils.TLSVerificationNone})
+		avicache.RetargetAviClients(mockaviserver.GetMockServerURL())
+	}()
+	ca := mockaviserver.GetMockServerCA()
+
+	// the certificate of the mock server has its IP
+	g.Expect(verifyMockServerTLS(gslbutils.AviControllerTLS{
+		Verification: gslbutils.TLSVerificationFull,
+		CAData:       ca,
+	})).To(gomega.Succeed())
+
+	g.Expect(verifyMockServerTLS(gslbutils.AviControllerTLS{
+		Verification: gslbutils.TLSVerificationFull,
+		CAData:       ca,
+		ServerName:   mockaviserver.MockServerName,
+	})).To(gomega.Succeed())
+
+	// the host name isn't verified
+	g.Expect(verifyMockServerTLS(gslbutils.AviControllerTLS{
+		Verification: gslbutils.TLSVerificationCAOnly,
+		CAData:       ca,
+		ServerName:   "avi.other.com",
+	})).To(gomega.Succeed())
+
+	g.Expect(verifyMockServerTLS(gslbutils.AviControllerTLS{Verification: gslbutils.TLSVerificationNone})).To(gomega.Succeed())
+}
+
+func TestControllerTLSVerificationFailed(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer func() {
+		gslbutils.SetAviControllerTLS(gslbutils.AviControllerTLS{Verification: gslbutils.TLSVerificationNone})
+		avicache.RetargetAviClients(mockaviserver.GetMockServerURL())
+	}()
+	ca := mockaviserver.GetMockServerCA()
+
+	// the mock server isn't signed by a system CA
+	tlsCfg := gslbutils.AviControllerTLS{Verification: gslbutils.TLSVerificationFull}
+	g.Expect(verifyMockServerTLS(tlsCfg)).NotTo(gomega.Succeed())
+	g.Expect(avicache.RetargetAviClients(mockaviserver.GetMockServerURL())).NotTo(gomega.Succeed())
+
+	tlsCfg = gslbutils.AviControllerTLS{Verification: gslbutils.TLSVerificationCAOnly}
+	g.Expect(verifyMockServerTLS(tlsCfg)).NotTo(gomega.Succeed())
+
+	// the certificate isn't for the server name
+	tlsCfg = gslbutils.AviControllerTLS{
+		Verification: gslbutils.TLSVerificationFull,
+		CAData:       ca,
+		ServerName:   "avi.other.com",
+	}
+	g.Expect(verifyMockServerTLS(tlsCfg)).NotTo(gomega.Succeed())
+	g.Expect(avicache.RetargetAviClients(mockaviserver.GetMockServerURL())).NotTo(gomega.Succeed())
+
+	tlsCfg = gslbutils.AviControllerTLS{Verification: gslbutils.TLSVerificationFull, CAData: []byte("invalid")}
+	g.Expect(verifyMockServerTLS(tlsCfg)).NotTo(gomega.Succeed())
+}
@@ -0,0 +1,273 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/ingestion"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/test/mockaviserver"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	gcOrphanHost = "gc-orphan.avi.com"
+	gcUsedHost   = "gc-used.avi.com"
+	gcOrphanHm   = "amko--http--gc-orphan.avi.com--/"
+)
+
+func buildGCTestHm(name, uuid, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":         name,
+		"uuid":         uuid,
+		"type":         gslbutils.SystemGslbHealthMonitorHTTP,
+		"monitor_port": 80,
+		"description":  description,
+		"is_federated": true,
+	}
+}
+
+// serveGCTestObjs serves a GS for gcOrphanHost, which has no GS graph, a GS for gcUsedHost, which
+// has one, and health monitors of which only gcOrphanHm is orphaned. It returns the paths of the
+// DELETE requests.
+func serveGCTestObjs() *[]string {
+	orphanGraph := buildTestGSGraph([]string{"foo"}, []string{"10.10.10.121"}, []string{"ing1/" + gcOrphanHost},
+		gcOrphanHost, v1alpha2.IngressObj)
+	orphanGraph.Hm.PathNames = []string{}
+	usedGraph := buildTestGSGraph([]string{"foo"}, []string{"10.10.10.122"}, []string{"ing1/" + gcUsedHost},
+		gcUsedHost, v1alpha2.IngressObj)
+	usedGS := buildAviGSResp(&usedGraph, "gslbservice-gc-used-uuid", gslbutils.EncodeGSDescription(usedGraph.GetMemberObjRefs()))
+	// the GS refers to a health monitor which is no longer part of its graph
+	usedGS["health_monitor_refs"] = []interface{}{"https://10.10.100.10/api/healthmonitor/healthmonitor-gc-gs-uuid"}
+	gsList := []interface{}{
+		buildAviGSResp(&orphanGraph, "gslbservice-gc-orphan-uuid", gslbutils.EncodeGSDescription(orphanGraph.GetMemberObjRefs())),
+		usedGS,
+	}
+	hmList := []interface{}{
+		buildGCTestHm(gcOrphanHm, "healthmonitor-gc-orphan-uuid", gslbutils.AmkoHmDescription),
+		buildGCTestHm("amko--http--gc-used.avi.com--/", "healthmonitor-gc-graph-uuid", gslbutils.AmkoHmDescription),
+		buildGCTestHm("amko--http--gc-used.avi.com--/old", "healthmonitor-gc-gs-uuid", gslbutils.AmkoHmDescription),
+		buildGCTestHm("amko--http--gc-manual.avi.com--/", "healthmonitor-gc-manual-uuid", "created by the user"),
+		buildGCTestHm("gc-user-hm", "healthmonitor-gc-user-uuid", gslbutils.AmkoHmDescription),
+	}
+
+	var deletes []string
+	var lock sync.Mutex
+	mockaviserver.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		url := r.URL.EscapedPath()
+		var results []interface{}
+		switch {
+		case r.Method == "DELETE":
+			lock.Lock()
+			deletes = append(deletes, url)
+			lock.Unlock()
+			w.WriteHeader(http.StatusNoContent)
+			return
+		case r.Method == "GET" && strings.HasSuffix(url, "/api/gslbservice"):
+			results = gsList
+		case r.Method == "GET" && strings.HasSuffix(url, "/api/healthmonitor"):
+			results = hmList
+		default:
+			mockaviserver.DefaultServerMiddleware(w, r)
+			return
+		}
+		data, _ := json.Marshal(map[string]interface{}{"count": len(results), "results": results})
+		w.WriteHeader(http.StatusOK)
+		w.Write(data)
+	})
+	return &deletes
+}
+
+// setupGCTest saves a GS graph for gcUsedHost and applies the garbage collection policy, the
+// returned function restores the state.
+func setupGCTest(policy *v1alpha2.GarbageCollection) func() {
+	resetGCTracker()
+	gslbutils.SetGarbageCollectionPolicy(policy)
+	gslbutils.SetBootupSyncDone(true)
+	modelName := utils.ADMIN_NS + "/" + gcUsedHost
+	usedGraph := buildTestGSGraph([]string{"foo"}, []string{"10.10.10.122"}, []string{"ing1/" + gcUsedHost},
+		gcUsedHost, v1alpha2.IngressObj)
+	usedGraph.Hm.PathNames = []string{"amko--http--gc-used.avi.com--/"}
+	nodes.SharedAviGSGraphLister().Save(modelName, &usedGraph)
+	// the health monitors of a GS are found by their uuids in the hm cache
+	hmKey := avicache.TenantName{Tenant: utils.ADMIN_NS, Name: "amko--http--gc-used.avi.com--/old"}
+	avicache.GetAviHmCache().AviHmCacheAdd(hmKey, &avicache.AviHmObj{Name: hmKey.Name, Tenant: utils.ADMIN_NS,
+		UUID: "healthmonitor-gc-gs-uuid", Description: gslbutils.AmkoHmDescription})
+	return func() {
+		mockaviserver.ResetMiddleware()
+		nodes.SharedAviGSGraphLister().Delete(modelName)
+		avicache.GetAviHmCache().AviHmCacheDelete(hmKey)
+		resetGCTracker()
+		gslbutils.SetBootupSyncDone(false)
+	}
+}
+
+// resetGCTracker disables the garbage collection, which forgets the orphaned objects found so far.
+func resetGCTracker() {
+	gslbutils.SetGarbageCollectionPolicy(nil)
+	ingestion.CollectGarbage(time.Now())
+}
+
+func orphanActions(status *v1alpha2.GarbageCollectionStatus) map[string]string {
+	actions := make(map[string]string)
+	for _, obj := range status.Orphans {
+		actions[obj.Type+"/"+obj.Name] = obj.Action
+	}
+	return actions
+}
+
+func TestGarbageCollectionDisabled(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer setupGCTest(nil)()
+	deletes := serveGCTestObjs()
+
+	g.Expect(ingestion.CollectGarbage(time.Now())).To(gomega.BeNil())
+	g.Expect(*deletes).To(gomega.BeEmpty())
+}
+
+func TestGarbageCollectionGracePeriod(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer setupGCTest(&v1alpha2.GarbageCollection{GracePeriod: 60, MaxDeletions: 5})()
+	deletes := serveGCTestObjs()
+	graphQLen := graphQueueLen()
+	hmDeletions := counterValue(metrics.GCDeletions, gslbutils.GCTypeHM)
+	gsDeletions := counterValue(metrics.GCDeletions, gslbutils.GCTypeGS)
+
+	// the orphaned objects are only reported in the grace period
+	start := time.Now()
+	status := ingestion.CollectGarbage(start)
+	g.Expect(status).NotTo(gomega.BeNil())
+	g.Expect(orphanActions(status)).To(gomega.Equal(map[string]string{
+		gslbutils.GCTypeGS + "/" + gcOrphanHost: v1alpha2.OrphanPending,
+		gslbutils.GCTypeHM + "/" + gcOrphanHm:   v1alpha2.OrphanPending,
+	}))
+	g.Expect(status.Orphans[0].OrphanedSince.Unix()).To(gomega.Equal(start.Unix()))
+	g.Expect(*deletes).To(gomega.BeEmpty())
+	g.Expect(graphQueueLen()).To(gomega.Equal(graphQLen))
+
+	status = ingestion.CollectGarbage(start.Add(30 * time.Second))
+	g.Expect(orphanActions(status)).To(gomega.HaveKeyWithValue(gslbutils.GCTypeGS+"/"+gcOrphanHost, v1alpha2.OrphanPending))
+	g.Expect(status.Orphans[0].OrphanedSince.Unix()).To(gomega.Equal(start.Unix()))
+	g.Expect(*deletes).To(gomega.BeEmpty())
+
+	// the GS is deleted through the rest layer, and the health monitor right away
+	status = ingestion.CollectGarbage(start.Add(time.Minute))
+	g.Expect(orphanActions(status)).To(gomega.Equal(map[string]string{
+		gslbutils.GCTypeGS + "/" + gcOrphanHost: v1alpha2.OrphanDeleted,
+		gslbutils.GCTypeHM + "/" + gcOrphanHm:   v1alpha2.OrphanDeleted,
+	}))
+	g.Expect(*deletes).To(gomega.HaveLen(1))
+	g.Expect((*deletes)[0]).To(gomega.HaveSuffix("/api/healthmonitor/healthmonitor-gc-orphan-uuid"))
+	g.Eventually(graphQueueLen, 5*time.Second).Should(gomega.Equal(graphQLen + 1))
+	found, _ := nodes.SharedDeleteGSGraphLister().Get(utils.ADMIN_NS + "/" + gcOrphanHost)
+	g.Expect(found).To(gomega.BeTrue())
+	g.Expect(counterValue(metrics.GCDeletions, gslbutils.GCTypeHM)).To(gomega.Equal(hmDeletions + 1))
+	g.Expect(counterValue(metrics.GCDeletions, gslbutils.GCTypeGS)).To(gomega.Equal(gsDeletions + 1))
+
+	cacheKey := avicache.TenantName{Tenant: utils.ADMIN_NS, Name: gcOrphanHost}
+	avicache.GetAviCache().AviCacheDelete(cacheKey)
+	nodes.SharedDeleteGSGraphLister().Delete(utils.ADMIN_NS + "/" + gcOrphanHost)
+}
+
+func TestGarbageCollectionThreshold(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer setupGCTest(&v1alpha2.GarbageCollection{GracePeriod: 60, MaxDeletions: 1})()
+	deletes := serveGCTestObjs()
+	graphQLen := graphQueueLen()
+
+	start := time.Now()
+	ingestion.CollectGarbage(start)
+	status := ingestion.CollectGarbage(start.Add(time.Minute))
+
+	// none of the orphaned objects are deleted, as more than one of them are due
+	g.Expect(orphanActions(status)).To(gomega.Equal(map[string]string{
+		gslbutils.GCTypeGS + "/" + gcOrphanHost: v1alpha2.OrphanSkipped,
+		gslbutils.GCTypeHM + "/" + gcOrphanHm:   v1alpha2.OrphanSkipped,
+	}))
+	g.Expect(status.Message).To(gomega.ContainSubstring("more than the maximum of 1"))
+	g.Expect(*deletes).To(gomega.BeEmpty())
+	g.Expect(graphQueueLen()).To(gomega.Equal(graphQLen))
+}
+
+func TestGarbageCollectionDryRun(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer setupGCTest(&v1alpha2.GarbageCollection{GracePeriod: 60, DryRun: true})()
+	deletes := serveGCTestObjs()
+	graphQLen := graphQueueLen()
+
+	start := time.Now()
+	ingestion.CollectGarbage(start)
+	status := ingestion.CollectGarbage(start.Add(time.Minute))
+
+	g.Expect(orphanActions(status)).To(gomega.Equal(map[string]string{
+		gslbutils.GCTypeGS + "/" + gcOrphanHost: v1alpha2.OrphanSkipped,
+		gslbutils.GCTypeHM + "/" + gcOrphanHm:   v1alpha2.OrphanSkipped,
+	}))
+	g.Expect(status.Message).To(gomega.ContainSubstring("dry run"))
+	g.Expect(*deletes).To(gomega.BeEmpty())
+	g.Expect(graphQueueLen()).To(gomega.Equal(graphQLen))
+}
+
+func TestGarbageCollectionClustersNotReady(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	defer setupGCTest(&v1alpha2.GarbageCollection{GracePeriod: 60})()
+	deletes := serveGCTestObjs()
+	gslbutils.SetGSLBConfigObj(&v1alpha2.GSLBConfig{
+		ObjectMeta: metav1.ObjectMeta{Namespace: gslbutils.AVISystem, Name: "gc-config"},
+		Spec:       v1alpha2.GSLBConfigSpec{MemberClusters: []v1alpha2.MemberCluster{{ClusterContext: "cluster1"}}},
+	})
+	defer gslbutils.SetGSLBConfigObj(nil)
+	gslbutils.SetMemberClusterStatus("cluster1", v1alpha2.ClusterConnected, "connected")
+
+	start := time.Now()
+	status := ingestion.CollectGarbage(start)
+	g.Expect(status.Orphans).To(gomega.HaveLen(2))
+
+	// the GSes of an unreachable cluster aren't in the GS graphs, so the pass is skipped
+	gslbutils.SetMemberClusterStatus("cluster1", v1alpha2.ClusterDegraded, "cluster health check failed")
+	status = ingestion.CollectGarbage(start.Add(30 * time.Second))
+	g.Expect(status.Orphans).To(gomega.BeEmpty())
+	g.Expect(status.Message).To(gomega.ContainSubstring("cluster1"))
+	g.Expect(gslbutils.GetGSLBConfigStatus().GarbageCollection.Message).To(gomega.Equal(status.Message))
+
+	// the grace period starts afresh once the cluster is connected again
+	gslbutils.SetMemberClusterStatus("cluster1", v1alpha2.ClusterConnected, "connected")
+	status = ingestion.CollectGarbage(start.Add(time.Minute))
+	g.Expect(orphanActions(status)).To(gomega.Equal(map[string]string{
+		gslbutils.GCTypeGS + "/" + gcOrphanHost: v1alpha2.OrphanPending,
+		gslbutils.GCTypeHM + "/" + gcOrphanHm:   v1alpha2.OrphanPending,
+	}))
+	g.Expect(status.Orphans[0].OrphanedSince.Unix()).To(gomega.Equal(start.Add(time.Minute).Unix()))
+
+	// nothing is collected till the bootup sync is done
+	gslbutils.SetBootupSyncDone(false)
+	status = ingestion.CollectGarbage(start.Add(2 * time.Minute))
+	g.Expect(status.Orphans).To(gomega.BeEmpty())
+	g.Expect(status.Message).To(gomega.ContainSubstring("bootup sync"))
+	g.Expect(*deletes).To(gomega.BeEmpty())
+}
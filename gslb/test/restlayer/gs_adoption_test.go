@@ -0,0 +1,194 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/test/mockaviserver"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	avimodels "github.com/avinetworks/sdk/go/models"
+	"github.com/onsi/gomega"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// gsRequest is a GSLB service request received by the mock Avi server.
+type gsRequest struct {
+	method string
+	path   string
+	gs     avimodels.GslbService
+}
+
+// gsMatchesQuery returns true if gs is selected by the name and domain_names filters of a GSLB
+// service lookup, as done by the Avi controller.
+func gsMatchesQuery(gs map[string]interface{}, query url.Values) bool {
+	if name := query.Get("name"); name != "" && gs["name"] != name {
+		return false
+	}
+	if domain := query.Get("domain_names"); domain != "" {
+		var domains []string
+		data, _ := json.Marshal(gs["domain_names"])
+		json.Unmarshal(data, &domains)
+		return gslbutils.PresentInList(domain, domains)
+	}
+	return true
+}
+
+// serveExistingGS makes the mock Avi server list gs as the only GSLB service, and records the GSLB
+// service requests sent to it. The name and domain_names filters of a GET are applied to gs.
+func serveExistingGS(gs map[string]interface{}) *[]gsRequest {
+	var requests []gsRequest
+	var lock sync.Mutex
+	mockaviserver.AddMiddleware(func(w http.ResponseWriter, r *http.Request) {
+		url := r.URL.EscapedPath()
+		if !strings.Contains(url, "/api/gslbservice") {
+			mockaviserver.DefaultServerMiddleware(w, r)
+			return
+		}
+		if r.Method == "GET" {
+			results := []interface{}{}
+			if gsMatchesQuery(gs, r.URL.Query()) {
+				results = append(results, gs)
+			}
+			data, _ := json.Marshal(map[string]interface{}{"count": len(results), "results": results})
+			w.WriteHeader(http.StatusOK)
+			w.Write(data)
+			return
+		}
+		data, _ := ioutil.ReadAll(r.Body)
+		req := gsRequest{method: r.Method, path: url}
+		json.Unmarshal(data, &req.gs)
+		lock.Lock()
+		requests = append(requests, req)
+		lock.Unlock()
+		r.Body = ioutil.NopCloser(bytes.NewReader(data))
+		mockaviserver.DefaultServerMiddleware(w, r)
+	})
+	return &requests
+}
+
+func syncAdoptionTestGraph(host string, ips []string) {
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph([]string{"foo"}, ips, []string{"ing1/" + host}, host, v1alpha2.IngressObj)
+	gsGraph.SetRetryCounter()
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+}
+
+func TestAdoptExistingGS(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "adopt.avi.com"
+	gslbutils.SetGSAdoption(host, true)
+	defer gslbutils.SetGSAdoption(host, false)
+	defer nodes.SharedAviGSGraphLister().Delete(utils.ADMIN_NS + "/" + host)
+	requests := serveExistingGS(map[string]interface{}{
+		"name":         "manual-gs",
+		"uuid":         "gslbservice-manual-uuid",
+		"domain_names": []string{host},
+		"tenant_ref":   "https://10.10.100.10/api/tenant/admin",
+		"groups": []interface{}{map[string]interface{}{"name": "manual-pool", "members": []interface{}{
+			map[string]interface{}{"ip": map[string]interface{}{"addr": "10.10.10.101", "type": "V4"}, "ratio": 1}}}},
+	})
+	defer mockaviserver.ResetMiddleware()
+
+	syncAdoptionTestGraph(host, []string{"10.10.10.102"})
+
+	// the GS is updated in place, and not created
+	g.Expect(*requests).NotTo(gomega.BeEmpty())
+	for _, req := range *requests {
+		g.Expect(req.method).NotTo(gomega.Equal("POST"))
+	}
+	put := (*requests)[len(*requests)-1]
+	g.Expect(put.method).To(gomega.Equal("PUT"))
+	g.Expect(put.path).To(gomega.HaveSuffix("/api/gslbservice/gslbservice-manual-uuid"))
+	g.Expect(*put.gs.Name).To(gomega.Equal(host))
+	g.Expect(*put.gs.CreatedBy).To(gomega.Equal(gslbutils.AmkoUser))
+	desc, err := gslbutils.DecodeGSDescription(*put.gs.Description)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(desc.MemberObjList()).To(gomega.Equal([]string{v1alpha2.IngressObj + "/foo/" + DefaultNS + "/ing1/" + host}))
+	g.Expect(*put.gs.Groups[0].Members[0].IP.Addr).To(gomega.Equal("10.10.10.102"))
+
+	gsCache := getCachedGS(host)
+	g.Expect(gsCache).NotTo(gomega.BeNil())
+	g.Expect(gsCache.Uuid).To(gomega.Equal("gslbservice-manual-uuid"))
+	g.Expect(gsCache.Members).To(gomega.HaveLen(1))
+	g.Expect(gsCache.Members[0].IPAddr).To(gomega.Equal("10.10.10.102"))
+}
+
+func TestAdoptExistingGSRejected(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "adopt-rejected.avi.com"
+	gslbutils.SetGSAdoption(host, true)
+	defer gslbutils.SetGSAdoption(host, false)
+	defer nodes.SharedAviGSGraphLister().Delete(utils.ADMIN_NS + "/" + host)
+	// a GS with another domain name can't be adopted, as AMKO would remove that domain name
+	requests := serveExistingGS(map[string]interface{}{
+		"name":         host,
+		"uuid":         "gslbservice-rejected-uuid",
+		"domain_names": []string{host, "other.avi.com"},
+	})
+	defer mockaviserver.ResetMiddleware()
+
+	syncAdoptionTestGraph(host, []string{"10.10.10.103"})
+
+	g.Expect(*requests).NotTo(gomega.BeEmpty())
+	for _, req := range *requests {
+		g.Expect(req.method).To(gomega.Equal("POST"))
+	}
+	g.Expect(getCachedGS(host).Uuid).NotTo(gomega.Equal("gslbservice-rejected-uuid"))
+}
+
+func TestAdoptExistingGSDryRun(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "adopt-dryrun.avi.com"
+	gslbutils.SetGSAdoption(host, true)
+	defer gslbutils.SetGSAdoption(host, false)
+	defer nodes.SharedAviGSGraphLister().Delete(utils.ADMIN_NS + "/" + host)
+	requests := serveExistingGS(map[string]interface{}{
+		"name":         "manual-dryrun-gs",
+		"uuid":         "gslbservice-dryrun-uuid",
+		"domain_names": []string{host},
+		"tenant_ref":   "https://10.10.100.10/api/tenant/admin",
+	})
+	defer mockaviserver.ResetMiddleware()
+	gslbutils.SetDryRun(true)
+	defer gslbutils.SetDryRun(false)
+
+	syncAdoptionTestGraph(host, []string{"10.10.10.104"})
+
+	// the adoption and the update are only planned, the GS isn't added to the cache
+	g.Expect(*requests).To(gomega.BeEmpty())
+	g.Expect(getCachedGS(host)).To(gomega.BeNil())
+	var ops []rest.PlannedOperation
+	for _, plan := range rest.GetPlan(host) {
+		ops = append(ops, plan.Operations...)
+	}
+	adoptOp := findPlannedOp(ops, rest.PlannedAdoption, "GSLBService")
+	g.Expect(adoptOp).NotTo(gomega.BeNil())
+	g.Expect(adoptOp.Name).To(gomega.Equal("manual-dryrun-gs"))
+	g.Expect(adoptOp.Path).To(gomega.Equal("/api/gslbservice/gslbservice-dryrun-uuid"))
+	g.Expect(findPlannedOp(ops, "PUT", "GSLBService")).NotTo(gomega.BeNil())
+}
@@ -0,0 +1,254 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"encoding/json"
+	"strconv"
+	"strings"
+	"sync"
+	"testing"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/test/mockaviserver"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// buildAviGSResp returns a GSLB service, as sent by the Avi controller, for a graph built by
+// buildTestGSGraph, with the description set to description.
+func buildAviGSResp(gsGraph *nodes.AviGSObjectGraph, uuid, description string) map[string]interface{} {
+	members := []interface{}{}
+	for _, m := range gsGraph.MemberObjs {
+		members = append(members, map[string]interface{}{
+			"ip":      map[string]interface{}{"addr": m.IPAddr, "type": "V4"},
+			"ratio":   float64(m.Weight),
+			"enabled": true,
+		})
+	}
+	hmRefs := []interface{}{}
+	for _, hm := range gsGraph.Hm.PathNames {
+		hmRefs = append(hmRefs, "https://10.10.100.10/api/healthmonitor/healthmonitor-uuid#"+hm)
+	}
+	domainNames := []interface{}{}
+	for _, domain := range gsGraph.DomainNames {
+		domainNames = append(domainNames, domain)
+	}
+	return map[string]interface{}{
+		"name":                gsGraph.Name,
+		"uuid":                uuid,
+		"created_by":          gslbutils.AmkoUser,
+		"description":         description,
+		"domain_names":        domainNames,
+		"health_monitor_refs": hmRefs,
+		"groups":              []interface{}{map[string]interface{}{"name": gsGraph.Name, "members": members}},
+	}
+}
+
+func TestGSDescriptionEncodeDecode(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	// cluster context names can have "/" and "," in them, which the legacy format couldn't hold
+	members := []gslbutils.GSMemberObj{
+		{ObjType: v1alpha2.IngressObj, Cluster: "arn:aws:eks:us-west-2:123:cluster/c1", Namespace: DefaultNS,
+			Name: "ing1/foo.avi.com"},
+		{ObjType: v1alpha2.LBSvcObj, Cluster: "cluster,2", Namespace: DefaultNS, Name: "svc1"},
+	}
+	description := gslbutils.EncodeGSDescription(members)
+
+	var payload map[string]interface{}
+	g.Expect(json.Unmarshal([]byte(description), &payload)).To(gomega.Succeed())
+	g.Expect(payload["v"]).To(gomega.BeEquivalentTo(gslbutils.GSDescriptionVersion))
+	g.Expect(payload["o"]).To(gomega.Equal(gslbutils.AmkoUser))
+
+	desc, err := gslbutils.DecodeGSDescription(description)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(desc.Version).To(gomega.Equal(gslbutils.GSDescriptionVersion))
+	g.Expect(desc.Members).To(gomega.Equal(members))
+	g.Expect(desc.MemberObjList()).To(gomega.Equal([]string{
+		v1alpha2.IngressObj + "/arn:aws:eks:us-west-2:123:cluster/c1/" + DefaultNS + "/ing1/foo.avi.com",
+		v1alpha2.LBSvcObj + "/cluster,2/" + DefaultNS + "/svc1",
+	}))
+}
+
+func TestGSDescriptionDecodeLegacy(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	legacy := v1alpha2.IngressObj + "/cluster1/" + DefaultNS + "/ing1/foo.avi.com," +
+		v1alpha2.RouteObj + "/cluster2/" + DefaultNS + "/route1"
+
+	desc, err := gslbutils.DecodeGSDescription(legacy)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(desc.Version).To(gomega.Equal(0))
+	g.Expect(desc.Members).To(gomega.Equal([]gslbutils.GSMemberObj{
+		{ObjType: v1alpha2.IngressObj, Cluster: "cluster1", Namespace: DefaultNS, Name: "ing1/foo.avi.com"},
+		{ObjType: v1alpha2.RouteObj, Cluster: "cluster2", Namespace: DefaultNS, Name: "route1"},
+	}))
+	g.Expect(strings.Join(desc.MemberObjList(), ",")).To(gomega.Equal(legacy))
+
+	for _, malformed := range []string{
+		"",
+		v1alpha2.IngressObj + "/cluster1/" + DefaultNS + "/ing1",
+		"Pod/cluster1/" + DefaultNS + "/pod1",
+		`{"version": 1, "owner": "someone-else", "members": []}`,
+		`{"version": 99, "owner": "` + gslbutils.AmkoUser + `", "members": []}`,
+		`{"version": 1, "owner": "` + gslbutils.AmkoUser + `", "members": [{"type": "Pod"}]}`,
+		`{"version": 1,`,
+		`{"v": 2, "o": "someone-else", "m": {}}`,
+		`{"v": 99, "o": "` + gslbutils.AmkoUser + `", "m": {}}`,
+		`{"v": 2, "o": "` + gslbutils.AmkoUser + `", "m": {"cluster1": {"` + DefaultNS + `": {"Pod": ["pod1"]}}}}`,
+		`{"v": 2, "o": "` + gslbutils.AmkoUser + `", "m": {"cluster1": {"": {"` + v1alpha2.RouteObj + `": ["r"]}}}}`,
+		`{"v": 2, "o": "` + gslbutils.AmkoUser + `", "m": {"c": {"n": {"` + v1alpha2.RouteObj + `": ["r"]}}}, "n": 1}`,
+	} {
+		_, err := gslbutils.DecodeGSDescription(malformed)
+		g.Expect(err).To(gomega.HaveOccurred(), "description: %s", malformed)
+	}
+}
+
+func TestGSDescriptionDecodeV1(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "desc-v1.avi.com"
+	gsGraph := buildTestGSGraph([]string{"foo", "bar"}, []string{"10.10.10.114", "10.10.10.115"},
+		[]string{"route1", "route2"}, host, v1alpha2.RouteObj)
+
+	// the GS was written by an older AMKO, with the version 1 description
+	v1Description := `{"version": 1, "owner": "` + gslbutils.AmkoUser + `", "members": [` +
+		`{"type": "` + v1alpha2.RouteObj + `", "cluster": "foo", "namespace": "` + DefaultNS + `", "name": "route1"},` +
+		`{"type": "` + v1alpha2.RouteObj + `", "cluster": "bar", "namespace": "` + DefaultNS + `", "name": "route2"}]}`
+	desc, err := gslbutils.DecodeGSDescription(v1Description)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(desc.Version).To(gomega.Equal(1))
+	g.Expect(desc.MemberObjList()).To(gomega.ConsistOf(gsGraph.GetMemberObjList()))
+
+	// the checksum differs, so that the GS gets updated with the current version
+	cksum, _, memberObjs, _, err := avicache.GetDetailsFromAviGSLB(buildAviGSResp(&gsGraph, "gs-uuid", v1Description))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(memberObjs).To(gomega.ConsistOf(gsGraph.GetMemberObjList()))
+	g.Expect(cksum).NotTo(gomega.Equal(gsGraph.GraphChecksum))
+}
+
+func TestGSDescriptionManyMembers(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "desc-many-members.avi.com"
+	var clusters, ips, names []string
+	for i := 0; i < 200; i++ {
+		clusters = append(clusters, "cluster-"+strconv.Itoa(i%5))
+		ips = append(ips, "10.10.11."+strconv.Itoa(i))
+		names = append(names, "ing-"+strconv.Itoa(i)+"/"+host)
+	}
+
+	// a few members fit in the description
+	fewGraph := buildTestGSGraph(clusters[:10], ips[:10], names[:10], host, v1alpha2.IngressObj)
+	description := gslbutils.EncodeGSDescription(fewGraph.GetMemberObjRefs())
+	g.Expect(len(description)).To(gomega.BeNumerically("<=", gslbutils.GSDescriptionMaxLen))
+	desc, err := gslbutils.DecodeGSDescription(description)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(desc.Truncated).To(gomega.BeFalse())
+	g.Expect(desc.MemberObjList()).To(gomega.ConsistOf(fewGraph.GetMemberObjList()))
+
+	// too many members don't, only their count and checksum are written
+	gsGraph := buildTestGSGraph(clusters, ips, names, host, v1alpha2.IngressObj)
+	description = gslbutils.EncodeGSDescription(gsGraph.GetMemberObjRefs())
+	g.Expect(len(description)).To(gomega.BeNumerically("<=", gslbutils.GSDescriptionMaxLen))
+	desc, err = gslbutils.DecodeGSDescription(description)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(desc.Version).To(gomega.Equal(gslbutils.GSDescriptionVersion))
+	g.Expect(desc.Truncated).To(gomega.BeTrue())
+	g.Expect(desc.MemberCount).To(gomega.Equal(len(clusters)))
+	g.Expect(desc.Members).To(gomega.BeEmpty())
+
+	// the checksum of the GS still matches its graph, so that it isn't updated on every sync
+	cksum, _, _, _, err := avicache.GetDetailsFromAviGSLB(buildAviGSResp(&gsGraph, "gs-uuid", description))
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(cksum).To(gomega.Equal(gsGraph.GraphChecksum))
+
+	// and it changes with the member objects
+	names[0] = "ing-renamed/" + host
+	renamedGraph := buildTestGSGraph(clusters, ips, names, host, v1alpha2.IngressObj)
+	g.Expect(renamedGraph.GraphChecksum).NotTo(gomega.Equal(cksum))
+}
+
+func TestGSDescriptionChecksum(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "desc-cksum.avi.com"
+	gsGraph := buildTestGSGraph([]string{"foo", "bar"}, []string{"10.10.10.111", "10.10.10.112"},
+		[]string{"ing1/" + host, "ing2/" + host}, host, v1alpha2.IngressObj)
+
+	jsonResp := buildAviGSResp(&gsGraph, "gs-uuid", gslbutils.EncodeGSDescription(gsGraph.GetMemberObjRefs()))
+	cksum, _, memberObjs, _, err := avicache.GetDetailsFromAviGSLB(jsonResp)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(memberObjs).To(gomega.ConsistOf(gsGraph.GetMemberObjList()))
+	g.Expect(cksum).To(gomega.Equal(gsGraph.GraphChecksum))
+
+	// a legacy description has the same member objects, but a different checksum, so that the GS gets
+	// updated with the new description
+	legacyResp := buildAviGSResp(&gsGraph, "gs-uuid", strings.Join(gsGraph.GetMemberObjList(), ","))
+	legacyCksum, _, legacyObjs, _, err := avicache.GetDetailsFromAviGSLB(legacyResp)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(legacyObjs).To(gomega.ConsistOf(memberObjs))
+	g.Expect(legacyCksum).NotTo(gomega.Equal(gsGraph.GraphChecksum))
+
+	// the checksum is stable for the same GS, which is what the cache refresh compares
+	againCksum, _, _, _, _ := avicache.GetDetailsFromAviGSLB(legacyResp)
+	g.Expect(againCksum).To(gomega.Equal(legacyCksum))
+}
+
+func TestGSDescriptionMigration(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "desc-migrate.avi.com"
+	modelName := utils.ADMIN_NS + "/" + host
+	uuid := "gslbservice-legacy-uuid"
+	gsGraph := buildTestGSGraph([]string{"foo"}, []string{"10.10.10.113"}, []string{"ing1/" + host}, host,
+		v1alpha2.IngressObj)
+	gsGraph.SetRetryCounter()
+
+	// the GS was created by an older AMKO, with the legacy description
+	legacyResp := buildAviGSResp(&gsGraph, uuid, strings.Join(gsGraph.GetMemberObjList(), ","))
+	cksum, gsMembers, memberObjs, hms, err := avicache.GetDetailsFromAviGSLB(legacyResp)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	cacheKey := avicache.TenantName{Tenant: utils.ADMIN_NS, Name: host}
+	avicache.GetAviCache().AviCacheAdd(cacheKey, &avicache.AviGSCache{
+		Name:               host,
+		Tenant:             utils.ADMIN_NS,
+		Uuid:               uuid,
+		Members:            gsMembers,
+		K8sObjects:         memberObjs,
+		HealthMonitorNames: hms,
+		CloudConfigCksum:   cksum,
+	})
+	defer avicache.GetAviCache().AviCacheDelete(cacheKey)
+	defer nodes.SharedAviGSGraphLister().Delete(modelName)
+
+	requests := serveExistingGS(legacyResp)
+	defer mockaviserver.ResetMiddleware()
+
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+
+	// the GS is updated in place with the versioned description
+	g.Expect(*requests).NotTo(gomega.BeEmpty())
+	for _, put := range *requests {
+		g.Expect(put.method).To(gomega.Equal("PUT"))
+		g.Expect(put.path).To(gomega.HaveSuffix("/api/gslbservice/" + uuid))
+		desc, err := gslbutils.DecodeGSDescription(*put.gs.Description)
+		g.Expect(err).NotTo(gomega.HaveOccurred())
+		g.Expect(desc.Version).To(gomega.Equal(gslbutils.GSDescriptionVersion))
+		g.Expect(desc.MemberObjList()).To(gomega.Equal(gsGraph.GetMemberObjList()))
+	}
+	g.Expect(getCachedGS(host).K8sObjects).To(gomega.ConsistOf(gsGraph.GetMemberObjList()))
+}
@@ -0,0 +1,83 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/onsi/gomega"
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/test/mockaviserver"
+)
+
+// newTestGslbSite starts a controller for a GSLB site with the cluster uuid siteUUID, which reports
+// leaderUUID as the GSLB leader.
+func newTestGslbSite(siteUUID, leaderUUID string) *httptest.Server {
+	return httptest.NewTLSServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		url := r.URL.EscapedPath()
+		w.WriteHeader(http.StatusOK)
+		switch {
+		case strings.Contains(url, "login"):
+			w.Write([]byte(`{"success": "true"}`))
+		case strings.HasSuffix(url, "/api/initial-data"):
+			w.Write([]byte(`{"version": {"Version": "18.2.9"}}`))
+		case strings.HasSuffix(url, "/api/cluster"):
+			w.Write([]byte(fmt.Sprintf(`{"name": "cluster-%s", "uuid": "%s"}`, siteUUID, siteUUID)))
+		case strings.HasSuffix(url, "/api/gslb"):
+			w.Write([]byte(fmt.Sprintf(`{"count": 1, "results": [{"leader_cluster_uuid": "%s"}]}`, leaderUUID)))
+		default:
+			w.Write([]byte(`{"count": 0, "results": []}`))
+		}
+	}))
+}
+
+func getTestSiteAddr(site *httptest.Server) string {
+	return strings.TrimPrefix(site.URL, "https://")
+}
+
+func TestFindGslbLeaderEndpoint(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	follower := newTestGslbSite("site-1", "site-2")
+	defer follower.Close()
+	leader := newTestGslbSite("site-2", "site-2")
+	defer leader.Close()
+
+	endpoint, err := avicache.FindGslbLeaderEndpoint([]string{getTestSiteAddr(follower), getTestSiteAddr(leader)})
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(endpoint).To(gomega.Equal(getTestSiteAddr(leader)))
+
+	_, err = avicache.FindGslbLeaderEndpoint([]string{getTestSiteAddr(follower)})
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestRetargetAviClients(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	leader := newTestGslbSite("site-2", "site-2")
+	defer leader.Close()
+	// point the clients back to the mock server for the other tests
+	defer avicache.RetargetAviClients(mockaviserver.GetMockServerURL())
+
+	g.Expect(avicache.RetargetAviClients(getTestSiteAddr(leader))).To(gomega.Succeed())
+	g.Expect(gslbutils.GetAviConfig().IPAddr).To(gomega.Equal(getTestSiteAddr(leader)))
+	isLeader, err := avicache.IsAviSiteLeader()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(isLeader).To(gomega.BeTrue())
+}
@@ -0,0 +1,197 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	filter "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gdp_filter"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/introspection"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/k8sobjects"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+func getIntrospection(t *testing.T, handler http.HandlerFunc, url string, result interface{}) int {
+	rec := httptest.NewRecorder()
+	handler(rec, httptest.NewRequest("GET", url, nil))
+	if rec.Code == http.StatusOK && result != nil {
+		if err := json.Unmarshal(rec.Body.Bytes(), result); err != nil {
+			t.Fatalf("error in unmarshalling the response of %s: %v", url, err)
+		}
+	}
+	return rec.Code
+}
+
+func TestIntrospectGraphsAndCache(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "introspect.avi.com"
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph([]string{"foo", "bar"}, []string{"10.10.10.41", "10.10.10.42"},
+		[]string{"ing1/introspect.foo.com", "ing2/introspect.foo.com"}, host, v1alpha2.IngressObj)
+	gsGraph.SetRetryCounter()
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+
+	var graphs []introspection.GraphView
+	g.Expect(getIntrospection(t, introspection.GetGraphs, introspection.GraphsRoute+"?fqdn="+host,
+		&graphs)).To(gomega.Equal(http.StatusOK))
+	g.Expect(graphs).To(gomega.HaveLen(1))
+	g.Expect(graphs[0].Key).To(gomega.Equal(modelName))
+	g.Expect(graphs[0].Members).To(gomega.HaveLen(2))
+	g.Expect(graphs[0].Deleted).To(gomega.BeFalse())
+	// the counter is decremented on every sync of the REST layer
+	g.Expect(graphs[0].RetryCount).To(gomega.Equal(gslbutils.DefaultRetryCount - 1))
+
+	graphs = nil
+	getIntrospection(t, introspection.GetGraphs, introspection.GraphsRoute+"?fqdn="+host+"&cluster=bar&namespace="+
+		DefaultNS, &graphs)
+	g.Expect(graphs).To(gomega.HaveLen(1))
+	graphs = nil
+	getIntrospection(t, introspection.GetGraphs, introspection.GraphsRoute+"?fqdn="+host+"&cluster=baz", &graphs)
+	g.Expect(graphs).To(gomega.HaveLen(0))
+
+	var retries []introspection.RetryView
+	getIntrospection(t, introspection.GetRetries, introspection.RetriesRoute+"?fqdn="+host, &retries)
+	g.Expect(retries).To(gomega.Equal([]introspection.RetryView{{Key: modelName,
+		RetryCount: gslbutils.DefaultRetryCount - 1, MaxRetryCount: gslbutils.DefaultRetryCount}}))
+
+	var cache introspection.CacheView
+	g.Expect(getIntrospection(t, introspection.GetCache, introspection.CacheRoute+"?fqdn="+host,
+		&cache)).To(gomega.Equal(http.StatusOK))
+	g.Expect(cache.GSLBServices).To(gomega.HaveLen(1))
+	g.Expect(cache.GSLBServices[0].Name).To(gomega.Equal(host))
+	cache = introspection.CacheView{}
+	getIntrospection(t, introspection.GetCache, introspection.CacheRoute+"?fqdn="+host+"&cluster=baz", &cache)
+	g.Expect(cache.GSLBServices).To(gomega.HaveLen(0))
+	g.Expect(cache.HealthMonitors).To(gomega.HaveLen(0))
+}
+
+func TestIntrospectObjects(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	acceptedSvc := k8sobjects.SvcMeta{Cluster: "foo", Name: "svc1", Namespace: DefaultNS,
+		Hostname: "svc1.introspect.com", IPAddr: "10.10.10.51"}
+	rejectedSvc := k8sobjects.SvcMeta{Cluster: "bar", Name: "svc2", Namespace: "ns2",
+		Hostname: "svc2.introspect.com", IPAddr: "10.10.10.52"}
+	gslbutils.GetAcceptedLBSvcStore().AddOrUpdate(acceptedSvc, "foo", DefaultNS, "svc1")
+	gslbutils.GetRejectedLBSvcStore().AddOrUpdate(rejectedSvc, "bar", "ns2", "svc2")
+	defer gslbutils.GetAcceptedLBSvcStore().DeleteClusterNSObj("foo", DefaultNS, "svc1")
+	defer gslbutils.GetRejectedLBSvcStore().DeleteClusterNSObj("bar", "ns2", "svc2")
+
+	var objects []introspection.ObjectView
+	getIntrospection(t, introspection.GetObjects, introspection.ObjectsRoute+"?type="+gslbutils.SvcType, &objects)
+	g.Expect(objects).To(gomega.HaveLen(2))
+
+	objects = nil
+	getIntrospection(t, introspection.GetObjects, introspection.ObjectsRoute+"?state=rejected", &objects)
+	g.Expect(objects).To(gomega.HaveLen(1))
+	g.Expect(objects[0].Cluster).To(gomega.Equal("bar"))
+	g.Expect(objects[0].Namespace).To(gomega.Equal("ns2"))
+	g.Expect(objects[0].Name).To(gomega.Equal("svc2"))
+	g.Expect(objects[0].Hostname).To(gomega.Equal("svc2.introspect.com"))
+
+	objects = nil
+	getIntrospection(t, introspection.GetObjects, introspection.ObjectsRoute+"?fqdn=svc1.introspect.com&cluster=foo",
+		&objects)
+	g.Expect(objects).To(gomega.HaveLen(1))
+	g.Expect(objects[0].State).To(gomega.Equal(introspection.StateAccepted))
+
+	objects = nil
+	getIntrospection(t, introspection.GetObjects, introspection.ObjectsRoute+"?namespace=ns3", &objects)
+	g.Expect(objects).To(gomega.HaveLen(0))
+
+	g.Expect(getIntrospection(t, introspection.GetObjects, introspection.ObjectsRoute+"?type=Pod",
+		nil)).To(gomega.Equal(http.StatusBadRequest))
+	g.Expect(getIntrospection(t, introspection.GetObjects, introspection.ObjectsRoute+"?state=pending",
+		nil)).To(gomega.Equal(http.StatusBadRequest))
+}
+
+func TestIntrospectFilter(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gf := gslbutils.GetGlobalFilter()
+	gf.GlobalLock.Lock()
+	prevClusters := gf.ApplicableClusters
+	gf.ApplicableClusters = []string{"foo", "bar"}
+	gf.GlobalLock.Unlock()
+	defer func() {
+		gf.GlobalLock.Lock()
+		gf.ApplicableClusters = prevClusters
+		gf.GlobalLock.Unlock()
+	}()
+
+	var view introspection.FilterView
+	g.Expect(getIntrospection(t, introspection.GetFilter, introspection.FilterRoute,
+		&view)).To(gomega.Equal(http.StatusOK))
+	g.Expect(view.ApplicableClusters).To(gomega.Equal([]string{"foo", "bar"}))
+}
+
+func TestIntrospectExplain(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	gf := gslbutils.GetGlobalFilter()
+	gf.GlobalLock.Lock()
+	prevClusters, prevAppFilter := gf.ApplicableClusters, gf.AppFilter
+	gf.ApplicableClusters = []string{"foo"}
+	gf.AppFilter = &gslbutils.AppFilter{Label: gslbutils.Label{Key: "key", Value: "value"}}
+	gf.GlobalLock.Unlock()
+	defer func() {
+		gf.GlobalLock.Lock()
+		gf.ApplicableClusters, gf.AppFilter = prevClusters, prevAppFilter
+		gf.GlobalLock.Unlock()
+	}()
+
+	accepted := filter.RecordDecision(k8sobjects.SvcMeta{Cluster: "foo", Name: "svc1", Namespace: "explain",
+		Hostname: "svc1.explain.com", IPAddr: "10.10.10.61", Labels: map[string]string{"key": "value"}})
+	g.Expect(accepted.Accepted).To(gomega.BeTrue())
+	g.Expect(accepted.GSName).To(gomega.Equal("svc1.explain.com"))
+	filter.RecordDecision(k8sobjects.SvcMeta{Cluster: "foo", Name: "svc2", Namespace: "explain",
+		Hostname: "svc2.explain.com"})
+	filter.RecordDecision(k8sobjects.SvcMeta{Cluster: "bar", Name: "svc3", Namespace: "explain",
+		Hostname: "svc3.explain.com", IPAddr: "10.10.10.63", Labels: map[string]string{"key": "value"}})
+	defer filter.DeleteDecision(gslbutils.SvcType, "foo", "explain", "svc1")
+	defer filter.DeleteDecision(gslbutils.SvcType, "foo", "explain", "svc2")
+	defer filter.DeleteDecision(gslbutils.SvcType, "bar", "explain", "svc3")
+
+	var decisions []filter.Decision
+	g.Expect(getIntrospection(t, introspection.GetExplain, introspection.ExplainRoute+"?namespace=explain",
+		&decisions)).To(gomega.Equal(http.StatusOK))
+	g.Expect(decisions).To(gomega.HaveLen(3))
+
+	// the steps are evaluated until one of them fails
+	decisions = nil
+	getIntrospection(t, introspection.GetExplain, introspection.ExplainRoute+"?type="+gslbutils.SvcType+
+		"&cluster=foo&namespace=explain&name=svc2", &decisions)
+	g.Expect(decisions).To(gomega.HaveLen(1))
+	g.Expect(decisions[0].Accepted).To(gomega.BeFalse())
+	g.Expect(decisions[0].Steps[len(decisions[0].Steps)-1]).To(gomega.Equal(filter.DecisionStep{
+		Check: filter.CheckStatus, Passed: false, Message: "IP address or hostname not found in the status"}))
+
+	decisions = nil
+	getIntrospection(t, introspection.GetExplain, introspection.ExplainRoute+"?fqdn=svc3.explain.com", &decisions)
+	g.Expect(decisions).To(gomega.HaveLen(1))
+	g.Expect(decisions[0].Accepted).To(gomega.BeFalse())
+	g.Expect(decisions[0].Steps[len(decisions[0].Steps)-1].Check).To(gomega.Equal(filter.CheckCluster))
+
+	g.Expect(getIntrospection(t, introspection.GetExplain, introspection.ExplainRoute+"?type=pod",
+		nil)).To(gomega.Equal(http.StatusBadRequest))
+}
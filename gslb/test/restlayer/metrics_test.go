@@ -0,0 +1,94 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"net/http/httptest"
+	"sync"
+	"testing"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/metrics"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/onsi/gomega"
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+// counterValue returns the value of the counter of the label values.
+func counterValue(c *prometheus.CounterVec, labelValues ...string) float64 {
+	var m dto.Metric
+	c.WithLabelValues(labelValues...).Write(&m)
+	return m.GetCounter().GetValue()
+}
+
+// histogramCount returns the number of observations of the histogram of the label values.
+func histogramCount(h *prometheus.HistogramVec, labelValues ...string) uint64 {
+	var m dto.Metric
+	h.WithLabelValues(labelValues...).(prometheus.Histogram).Write(&m)
+	return m.GetHistogram().GetSampleCount()
+}
+
+func TestGaugeFunc(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	registry := prometheus.NewRegistry()
+	samples := []metrics.Sample{{LabelValues: []string{"q1"}, Value: 3}, {LabelValues: []string{"q2"}, Value: 0}}
+	registry.MustRegister(metrics.NewGaugeFunc("test_depth", "Depth.", func() []metrics.Sample {
+		return samples
+	}, "queue"))
+
+	families, err := registry.Gather()
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(families).To(gomega.HaveLen(1))
+	g.Expect(families[0].GetName()).To(gomega.Equal("test_depth"))
+	g.Expect(families[0].GetType()).To(gomega.Equal(dto.MetricType_GAUGE))
+	values := make(map[string]float64)
+	for _, m := range families[0].GetMetric() {
+		values[m.GetLabel()[0].GetValue()] = m.GetGauge().GetValue()
+	}
+	g.Expect(values).To(gomega.Equal(map[string]float64{"q1": 3, "q2": 0}))
+
+	// a sample with the wrong number of label values fails the scrape instead of being dropped
+	samples = append(samples, metrics.Sample{Value: 1})
+	_, err = registry.Gather()
+	g.Expect(err).To(gomega.HaveOccurred())
+}
+
+func TestRestCallMetrics(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "metrics.avi.com"
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph([]string{"foo"}, []string{"10.10.10.31"}, []string{"ing1/metrics.foo.com"}, host,
+		v1alpha2.IngressObj)
+
+	prevCount := counterValue(metrics.AviRestRequests, "POST", "GSLBService", "200")
+	prevObservations := histogramCount(metrics.AviRestRequestDuration, "POST", "GSLBService")
+	gsGraph.SetRetryCounter()
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+	g.Expect(counterValue(metrics.AviRestRequests, "POST", "GSLBService", "200")).To(gomega.Equal(prevCount + 1))
+	g.Expect(histogramCount(metrics.AviRestRequestDuration, "POST", "GSLBService")).To(gomega.Equal(prevObservations + 1))
+
+	rec := httptest.NewRecorder()
+	metrics.ServeMetrics(rec, httptest.NewRequest("GET", metrics.MetricsRoute, nil))
+	g.Expect(rec.Code).To(gomega.Equal(200))
+	g.Expect(rec.Body.String()).To(gomega.ContainSubstring(
+		`amko_avi_rest_requests_total{code="200",method="POST",object="GSLBService"}`))
+	g.Expect(rec.Body.String()).To(gomega.ContainSubstring(
+		`amko_avi_rest_request_duration_seconds_count{method="POST",object="GSLBService"}`))
+}
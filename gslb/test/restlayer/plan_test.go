@@ -0,0 +1,148 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"encoding/json"
+	"sync"
+	"testing"
+
+	avicache "github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/cache"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/nodes"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	"github.com/onsi/gomega"
+	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
+)
+
+func getPlannedOps(g *gomega.WithT, server, host string) []rest.PlannedOperation {
+	out, err := runAmkoctl(server, "-o", "json", "plan", "--fqdn", host)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	var plans []rest.GSPlan
+	g.Expect(json.Unmarshal([]byte(out), &plans)).To(gomega.Succeed())
+	if len(plans) == 0 {
+		return nil
+	}
+	g.Expect(plans).To(gomega.HaveLen(1))
+	g.Expect(plans[0].Key).To(gomega.Equal(utils.ADMIN_NS + "/" + host))
+	return plans[0].Operations
+}
+
+func findPlannedOp(ops []rest.PlannedOperation, method, model string) *rest.PlannedOperation {
+	for i := range ops {
+		if ops[i].Method == method && ops[i].Model == model {
+			return &ops[i]
+		}
+	}
+	return nil
+}
+
+func getCachedGS(host string) *avicache.AviGSCache {
+	gsObj, found := avicache.GetAviCache().AviCacheGet(avicache.TenantName{Tenant: utils.ADMIN_NS, Name: host})
+	if !found {
+		return nil
+	}
+	return gsObj.(*avicache.AviGSCache)
+}
+
+func TestDryRunPlansCreate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "dryrun-create.avi.com"
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph([]string{"foo", "bar"}, []string{"10.10.10.91", "10.10.10.92"},
+		[]string{"ing1/" + host, "ing2/" + host}, host, v1alpha2.IngressObj)
+	server := startAMKOAPIServer()
+	defer server.Close()
+	gslbutils.SetDryRun(true)
+	defer gslbutils.SetDryRun(false)
+
+	gsGraph.SetRetryCounter()
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	defer nodes.SharedAviGSGraphLister().Delete(modelName)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+	g.Expect(getCachedGS(host)).To(gomega.BeNil())
+
+	ops := getPlannedOps(g, server.URL, host)
+	hmOp := findPlannedOp(ops, "POST", "HealthMonitor")
+	g.Expect(hmOp).NotTo(gomega.BeNil())
+	g.Expect(hmOp.Diff).To(gomega.ContainElement(rest.FieldDiff{Field: "monitorPort", Planned: "443"}))
+	gsOp := findPlannedOp(ops, "POST", "GSLBService")
+	g.Expect(gsOp).NotTo(gomega.BeNil())
+	g.Expect(gsOp.Name).To(gomega.Equal(host))
+	g.Expect(gsOp.Diff).To(gomega.ContainElement(rest.FieldDiff{Field: "domainNames", Planned: host}))
+	g.Expect(gsOp.Diff).To(gomega.ContainElement(rest.FieldDiff{Field: "member 10.10.10.91",
+		Planned: "ratio=10, enabled=true"}))
+
+	out, err := runAmkoctl(server.URL, "plan", "--fqdn", host)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(out).To(gomega.ContainSubstring("POST GSLBService " + host))
+	out, err = runAmkoctl(server.URL, "status")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(out).To(gomega.MatchRegexp(`Dry run:\s+true`))
+}
+
+func TestDryRunPlansUpdateAndDelete(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "dryrun-update.avi.com"
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph([]string{"foo", "bar"}, []string{"10.10.10.93", "10.10.10.94"},
+		[]string{"ing1/" + host, "ing2/" + host}, host, v1alpha2.IngressObj)
+	gsGraph.SetRetryCounter()
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+	g.Expect(getCachedGS(host)).NotTo(gomega.BeNil())
+	server := startAMKOAPIServer()
+	defer server.Close()
+	gslbutils.SetDryRun(true)
+	defer gslbutils.SetDryRun(false)
+
+	// the weight of a member is changed, and a member is removed
+	updatedGraph := buildTestGSGraph([]string{"foo"}, []string{"10.10.10.93"}, []string{"ing1/" + host}, host,
+		v1alpha2.IngressObj)
+	updatedGraph.MemberObjs[0].Weight = 20
+	updatedGraph.GetChecksum()
+	updatedGraph.SetRetryCounter()
+	nodes.SharedAviGSGraphLister().Save(modelName, &updatedGraph)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+	// the Avi cache still has the GS as it was created
+	g.Expect(getCachedGS(host).Members).To(gomega.HaveLen(2))
+
+	ops := getPlannedOps(g, server.URL, host)
+	g.Expect(ops).To(gomega.HaveLen(1))
+	gsOp := findPlannedOp(ops, "PUT", "GSLBService")
+	g.Expect(gsOp).NotTo(gomega.BeNil())
+	g.Expect(gsOp.Diff).To(gomega.ContainElement(rest.FieldDiff{Field: "member 10.10.10.93 ratio",
+		Current: "10", Planned: "20"}))
+	g.Expect(gsOp.Diff).To(gomega.ContainElement(rest.FieldDiff{Field: "member 10.10.10.94",
+		Current: "ratio=10, enabled=true"}))
+
+	// the GS is deleted
+	nodes.SharedAviGSGraphLister().Delete(modelName)
+	nodes.SharedDeleteGSGraphLister().Save(modelName, &updatedGraph)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+	g.Expect(getCachedGS(host)).NotTo(gomega.BeNil())
+	ops = getPlannedOps(g, server.URL, host)
+	g.Expect(findPlannedOp(ops, "PUT", "GSLBService")).To(gomega.BeNil())
+	g.Expect(findPlannedOp(ops, "DELETE", "GSLBService")).NotTo(gomega.BeNil())
+	// the graph is retained to be deleted once the dry run mode is turned off
+	found, _ := nodes.SharedDeleteGSGraphLister().Get(modelName)
+	g.Expect(found).To(gomega.BeTrue())
+
+	gslbutils.SetDryRun(false)
+	rest.SyncFromNodesLayer(modelName, &sync.WaitGroup{})
+	g.Expect(getCachedGS(host)).To(gomega.BeNil())
+}
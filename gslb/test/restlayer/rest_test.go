@@ -24,7 +24,7 @@ import (
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/rest"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/test/mockaviserver"
 
-	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 
 	"github.com/onsi/gomega"
 	"github.com/vmware/load-balancer-and-ingress-services-for-kubernetes/pkg/utils"
@@ -53,7 +53,9 @@ func syncFuncForRetryTest(key string, wg *sync.WaitGroup) error {
 
 func setupQueue(testCh <-chan struct{}) {
 	slowRetryQParams := utils.WorkerQueue{NumWorkers: 1, WorkqueueName: gslbutils.SlowRetryQueue, SlowSyncTime: gslbutils.SlowSyncTime}
-	utils.SharedWorkQueue(slowRetryQParams)
+	// the graph layer queue isn't run, the keys published to the rest layer are verified in it
+	graphQueueParams := utils.WorkerQueue{NumWorkers: 1, WorkqueueName: utils.GraphLayer}
+	utils.SharedWorkQueue(slowRetryQParams, graphQueueParams)
 
 	slowRetryQ := utils.SharedWorkQueue().GetQueueByName(gslbutils.SlowRetryQueue)
 	slowRetryQ.SyncFunc = syncFuncForRetryTest
@@ -153,7 +155,7 @@ func TestCreateGS(t *testing.T) {
 	names := []string{"ing1/host1.foo.com", "ing2/host1.foo.com"}
 	modelName := utils.ADMIN_NS + "/" + host
 	// build a AviGSObjectGraph
-	gsGraph := buildTestGSGraph(clusterList, ipList, names, host, v1alpha1.IngressObj)
+	gsGraph := buildTestGSGraph(clusterList, ipList, names, host, v1alpha2.IngressObj)
 	saveSyncAndVerify(t, modelName, gsGraph, false)
 }
 
@@ -163,13 +165,13 @@ func TestUpdateGS(t *testing.T) {
 	ipList := []string{"10.10.10.21"}
 	names := []string{"ing1" + "/" + host}
 	modelName := utils.ADMIN_NS + "/" + host
-	gsGraph := buildTestGSGraph(clusterList, ipList, names, host, v1alpha1.IngressObj)
+	gsGraph := buildTestGSGraph(clusterList, ipList, names, host, v1alpha2.IngressObj)
 	saveSyncAndVerify(t, modelName, gsGraph, false)
 
 	// update the graph
 	newMember := nodes.AviGSK8sObj{
 		Cluster:   "bar",
-		ObjType:   v1alpha1.IngressObj,
+		ObjType:   v1alpha2.IngressObj,
 		Name:      "ing2" + "/" + host,
 		Namespace: DefaultNS,
 		IPAddr:    "10.10.10.22",
@@ -186,7 +188,7 @@ func TestDeleteGS(t *testing.T) {
 	names := []string{"ing1/" + host, "ing2/" + host}
 	modelName := utils.ADMIN_NS + "/" + host
 	// build a AviGSObjectGraph
-	gsGraph := buildTestGSGraph(clusterList, ipList, names, host, v1alpha1.IngressObj)
+	gsGraph := buildTestGSGraph(clusterList, ipList, names, host, v1alpha2.IngressObj)
 	saveSyncAndVerify(t, modelName, gsGraph, false)
 
 	gsGraph.SetRetryCounter()
@@ -198,8 +200,51 @@ func TestDeleteGS(t *testing.T) {
 	agl.Delete(modelName)
 	rest.SyncFromNodesLayer(gsGraph.Tenant+"/"+gsGraph.Name, &sync.WaitGroup{})
 
-	gsGraph.DeleteMember("foo", DefaultNS, names[0], v1alpha1.IngressObj)
-	gsGraph.DeleteMember("bar", DefaultNS, names[1], v1alpha1.IngressObj)
+	gsGraph.DeleteMember("foo", DefaultNS, names[0], v1alpha2.IngressObj)
+	gsGraph.DeleteMember("bar", DefaultNS, names[1], v1alpha2.IngressObj)
 
 	saveSyncAndVerify(t, modelName, gsGraph, true)
 }
+
+func TestDrainedGSMember(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	host := "host4.avi.com"
+	clusterList := []string{"foo", "bar"}
+	ipList := []string{"10.10.10.41", "10.10.10.42"}
+	names := []string{"ing1/" + host, "ing2/" + host}
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph(clusterList, ipList, names, host, v1alpha2.IngressObj)
+	gsGraph.MemberObjs[1].Drained = true
+	saveSyncAndVerify(t, modelName, gsGraph, false)
+
+	gsCache, _ := avicache.GetAviCache().AviCacheGet(avicache.TenantName{Tenant: gsGraph.Tenant, Name: gsGraph.Name})
+	for _, member := range gsCache.(*avicache.AviGSCache).Members {
+		g.Expect(member.Enabled).To(gomega.Equal(member.IPAddr != ipList[1]))
+	}
+	drainStatus := rest.GetClustersDrainStatus([]string{"bar"})
+	g.Expect(drainStatus).To(gomega.HaveLen(1))
+	g.Expect(drainStatus[0].Cluster).To(gomega.Equal("bar"))
+	g.Expect(drainStatus[0].Members).To(gomega.BeNumerically(">=", 1))
+	g.Expect(drainStatus[0].DisabledMembers).To(gomega.Equal(1))
+}
+
+// TestStandbyDoesntSyncGS verifies that a standby replica doesn't write the GS to the Avi controller,
+// and that the GS is created once the replica becomes the leader.
+func TestStandbyDoesntSyncGS(t *testing.T) {
+	host := "host-standby.avi.com"
+	clusterList := []string{"foo"}
+	ipList := []string{"10.10.10.51"}
+	names := []string{"ing1/" + host}
+	modelName := utils.ADMIN_NS + "/" + host
+	gsGraph := buildTestGSGraph(clusterList, ipList, names, host, v1alpha2.IngressObj)
+
+	gslbutils.SetAMKOLeader(false)
+	defer gslbutils.SetAMKOLeader(true)
+	gsGraph.SetRetryCounter()
+	nodes.SharedAviGSGraphLister().Save(modelName, &gsGraph)
+	rest.SyncFromNodesLayer(gsGraph.Tenant+"/"+gsGraph.Name, &sync.WaitGroup{})
+	verifyInAviCache(t, gsGraph, true)
+
+	gslbutils.SetAMKOLeader(true)
+	saveSyncAndVerify(t, modelName, gsGraph, false)
+}
@@ -0,0 +1,153 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package restlayer
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/amkoctl"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	"github.com/onsi/gomega"
+)
+
+const simulateManifest = `apiVersion: amko.vmware.com/v1alpha2
+kind: GSLBConfig
+metadata:
+  name: gc-1
+spec:
+  gslbLeader:
+    controllerIP: 10.10.10.1
+  memberClusters:
+  - clusterContext: sim-cluster1
+  - clusterContext: sim-cluster2
+---
+apiVersion: amko.vmware.com/v1alpha2
+kind: GlobalDeploymentPolicy
+metadata:
+  name: gdp-1
+spec:
+  matchRules:
+    appSelector:
+      label:
+        app: gslb
+  matchClusters:
+  - sim-cluster1
+  - sim-cluster2
+---
+apiVersion: amko.vmware.com/v1alpha2
+kind: GSLBHostRule
+metadata:
+  name: hr-1
+spec:
+  fqdn: sim-foo.avi.com
+  trafficSplit:
+  - cluster: sim-cluster1
+    weight: 3
+  - cluster: sim-cluster2
+    weight: 7
+`
+
+const simulateIngress = `apiVersion: networking.k8s.io/v1beta1
+kind: Ingress
+metadata:
+  name: %s
+  labels:
+    app: %s
+spec:
+  rules:
+  - host: %s
+    http:
+      paths:
+      - path: /
+        backend:
+          serviceName: svc
+          servicePort: 80
+status:
+  loadBalancer:
+    ingress:
+    - ip: %s
+      hostname: %s
+`
+
+func writeSimulateFile(t *testing.T, path, content string) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("error in creating the directory of %s: %v", path, err)
+	}
+	if err := ioutil.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("error in writing %s: %v", path, err)
+	}
+}
+
+func TestAmkoctlSimulate(t *testing.T) {
+	g := gomega.NewGomegaWithT(t)
+	// the simulator adds the GDP object to the global filter, each run starts with a new one
+	prevFilter := gslbutils.GetGlobalFilter()
+	defer func() {
+		gslbutils.Gfi = prevFilter
+	}()
+
+	dir, err := ioutil.TempDir("", "amkoctl-simulate")
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	defer os.RemoveAll(dir)
+	manifest := filepath.Join(dir, "gslb.yaml")
+	writeSimulateFile(t, manifest, simulateManifest)
+	objectsDir := filepath.Join(dir, "objects")
+	writeSimulateFile(t, filepath.Join(objectsDir, "sim-cluster1", "ingress.yaml"),
+		fmt.Sprintf(simulateIngress, "ing1", "gslb", "sim-foo.avi.com", "10.10.10.81", "sim-foo.avi.com")+"---\n"+
+			fmt.Sprintf(simulateIngress, "ing2", "other", "sim-bar.avi.com", "10.10.10.82", "sim-bar.avi.com"))
+	writeSimulateFile(t, filepath.Join(objectsDir, "sim-cluster2", "ingress.yaml"),
+		fmt.Sprintf(simulateIngress, "ing1", "gslb", "sim-foo.avi.com", "10.10.10.83", "sim-foo.avi.com"))
+
+	runSimulate := func(out *bytes.Buffer, args ...string) error {
+		gslbutils.Gfi = gslbutils.GetNewGlobalFilter()
+		return amkoctl.Run(append(args, "simulate", "-f", manifest, "--objects", objectsDir), out)
+	}
+
+	var out bytes.Buffer
+	err = runSimulate(&out, "-o", amkoctl.OutputJSON)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	var result []amkoctl.SimulatedGS
+	g.Expect(json.Unmarshal(out.Bytes(), &result)).To(gomega.Succeed())
+	// ing2 doesn't match the app selector of the GDP
+	g.Expect(result).To(gomega.HaveLen(1))
+	gs := result[0].GslbService
+	g.Expect(result[0].Name).To(gomega.Equal("sim-foo.avi.com"))
+	g.Expect(gs.DomainNames).To(gomega.Equal([]string{"sim-foo.avi.com"}))
+	g.Expect(gs.Groups).To(gomega.HaveLen(1))
+	ratios := make(map[string]int32)
+	for _, member := range gs.Groups[0].Members {
+		ratios[*member.IP.Addr] = *member.Ratio
+	}
+	g.Expect(ratios).To(gomega.Equal(map[string]int32{"10.10.10.81": 3, "10.10.10.83": 7}))
+
+	out.Reset()
+	err = runSimulate(&out)
+	g.Expect(err).NotTo(gomega.HaveOccurred())
+	g.Expect(out.String()).To(gomega.ContainSubstring("10.10.10.83"))
+
+	// the member clusters must be in the GSLBConfig object
+	writeSimulateFile(t, filepath.Join(objectsDir, "sim-cluster3", "ingress.yaml"),
+		fmt.Sprintf(simulateIngress, "ing1", "gslb", "sim-foo.avi.com", "10.10.10.84", "sim-foo.avi.com"))
+	err = runSimulate(&out)
+	g.Expect(err).To(gomega.MatchError(
+		"simulate: cluster sim-cluster3 is not a member cluster of the GSLBConfig object"))
+}
@@ -0,0 +1,283 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/runtime"
+	"k8s.io/apimachinery/pkg/types"
+)
+
+const (
+	// ConvertPath is the path on which the conversion requests for the AMKO CRDs are served
+	ConvertPath = "/convert"
+	// WebhookServiceName is the name of the Service of the webhook server
+	WebhookServiceName = "amko-webhook"
+	caFile             = "ca.crt"
+)
+
+// The apiextensions.k8s.io API types aren't vendored, these are the fields of the
+// ConversionReview objects used by AMKO.
+
+// ConversionReview is sent by the API server to convert the AMKO objects between the served
+// versions, it's returned with the response filled.
+type ConversionReview struct {
+	metav1.TypeMeta `json:",inline"`
+	Request         *ConversionRequest  `json:"request,omitempty"`
+	Response        *ConversionResponse `json:"response,omitempty"`
+}
+
+// ConversionRequest has the objects to be converted to DesiredAPIVersion.
+type ConversionRequest struct {
+	UID               types.UID              `json:"uid"`
+	DesiredAPIVersion string                 `json:"desiredAPIVersion"`
+	Objects           []runtime.RawExtension `json:"objects"`
+}
+
+// ConversionResponse has the converted objects, in the same order as the request.
+type ConversionResponse struct {
+	UID              types.UID              `json:"uid"`
+	ConvertedObjects []runtime.RawExtension `json:"convertedObjects"`
+	Result           metav1.Status          `json:"result"`
+}
+
+// AMKO CRDs which are served in both v1alpha1 and v1alpha2
+var conversionCRDs = []string{
+	"gslbconfigs.amko.vmware.com",
+	"globaldeploymentpolicies.amko.vmware.com",
+	"gslbhostrules.amko.vmware.com",
+	"gslbservicestatuses.amko.vmware.com",
+}
+
+func toV1alpha2(kind string, raw []byte) (interface{}, error) {
+	var err error
+	switch kind {
+	case "GSLBConfig":
+		var in v1alpha1.GSLBConfig
+		if err = json.Unmarshal(raw, &in); err == nil {
+			return v1alpha2.ConvertGSLBConfigFromV1alpha1(&in), nil
+		}
+	case "GlobalDeploymentPolicy":
+		var in v1alpha1.GlobalDeploymentPolicy
+		if err = json.Unmarshal(raw, &in); err == nil {
+			return v1alpha2.ConvertGDPFromV1alpha1(&in), nil
+		}
+	case "GSLBHostRule":
+		var in v1alpha1.GSLBHostRule
+		if err = json.Unmarshal(raw, &in); err == nil {
+			return v1alpha2.ConvertGSLBHostRuleFromV1alpha1(&in), nil
+		}
+	case "GSLBServiceStatus":
+		var in v1alpha1.GSLBServiceStatus
+		if err = json.Unmarshal(raw, &in); err == nil {
+			return v1alpha2.ConvertGSLBServiceStatusFromV1alpha1(&in), nil
+		}
+	default:
+		return nil, errors.New("unsupported kind " + kind)
+	}
+	return nil, errors.New("malformed " + kind + " object: " + err.Error())
+}
+
+func toV1alpha1(kind string, raw []byte) (interface{}, error) {
+	var err error
+	switch kind {
+	case "GSLBConfig":
+		var in v1alpha2.GSLBConfig
+		if err = json.Unmarshal(raw, &in); err == nil {
+			return v1alpha2.ConvertGSLBConfigToV1alpha1(&in), nil
+		}
+	case "GlobalDeploymentPolicy":
+		var in v1alpha2.GlobalDeploymentPolicy
+		if err = json.Unmarshal(raw, &in); err == nil {
+			return v1alpha2.ConvertGDPToV1alpha1(&in), nil
+		}
+	case "GSLBHostRule":
+		var in v1alpha2.GSLBHostRule
+		if err = json.Unmarshal(raw, &in); err == nil {
+			return v1alpha2.ConvertGSLBHostRuleToV1alpha1(&in), nil
+		}
+	case "GSLBServiceStatus":
+		var in v1alpha2.GSLBServiceStatus
+		if err = json.Unmarshal(raw, &in); err == nil {
+			return v1alpha2.ConvertGSLBServiceStatusToV1alpha1(&in), nil
+		}
+	default:
+		return nil, errors.New("unsupported kind " + kind)
+	}
+	return nil, errors.New("malformed " + kind + " object: " + err.Error())
+}
+
+// ConvertObject converts an AMKO object to desiredAPIVersion, the object is returned as is if it's
+// already in that version.
+func ConvertObject(raw []byte, desiredAPIVersion string) ([]byte, error) {
+	var typeMeta metav1.TypeMeta
+	if err := json.Unmarshal(raw, &typeMeta); err != nil {
+		return nil, errors.New("malformed object: " + err.Error())
+	}
+	if typeMeta.APIVersion == desiredAPIVersion {
+		return raw, nil
+	}
+
+	var obj interface{}
+	var err error
+	switch desiredAPIVersion {
+	case v1alpha2.SchemeGroupVersion.String():
+		if typeMeta.APIVersion != v1alpha1.SchemeGroupVersion.String() {
+			return nil, errors.New("can't convert from " + typeMeta.APIVersion + " to " + desiredAPIVersion)
+		}
+		obj, err = toV1alpha2(typeMeta.Kind, raw)
+	case v1alpha1.SchemeGroupVersion.String():
+		if typeMeta.APIVersion != v1alpha2.SchemeGroupVersion.String() {
+			return nil, errors.New("can't convert from " + typeMeta.APIVersion + " to " + desiredAPIVersion)
+		}
+		obj, err = toV1alpha1(typeMeta.Kind, raw)
+	default:
+		return nil, errors.New("unsupported api version " + desiredAPIVersion)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(obj)
+}
+
+// Convert returns the response for a conversion request. If any of the objects can't be
+// converted, the request fails and no object is returned.
+func Convert(req *ConversionRequest) *ConversionResponse {
+	resp := &ConversionResponse{
+		UID:    req.UID,
+		Result: metav1.Status{Status: metav1.StatusSuccess},
+	}
+	for _, obj := range req.Objects {
+		converted, err := ConvertObject(obj.Raw, req.DesiredAPIVersion)
+		if err != nil {
+			gslbutils.Errf("desiredAPIVersion: %s, msg: error in converting object: %s", req.DesiredAPIVersion, err)
+			resp.ConvertedObjects = nil
+			resp.Result = metav1.Status{Status: metav1.StatusFailure, Message: err.Error()}
+			return resp
+		}
+		resp.ConvertedObjects = append(resp.ConvertedObjects, runtime.RawExtension{Raw: converted})
+	}
+	return resp
+}
+
+// ServeConvert handles the ConversionReview requests sent by the API server.
+func ServeConvert(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "couldn't read the request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var review ConversionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "malformed conversion review", http.StatusBadRequest)
+		return
+	}
+	review.Response = Convert(review.Request)
+	review.Request = nil
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, "couldn't marshal the conversion review: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// ConversionPatch returns the merge patch which sets the conversion of an AMKO CRD to the webhook
+// server, served by the amko-webhook Service in namespace, with the CA certificate ca.
+func ConversionPatch(namespace string, ca []byte) []byte {
+	patch := map[string]interface{}{
+		"spec": map[string]interface{}{
+			"conversion": map[string]interface{}{
+				"strategy": "Webhook",
+				"webhook": map[string]interface{}{
+					"clientConfig": map[string]interface{}{
+						"service": map[string]interface{}{
+							"name":      WebhookServiceName,
+							"namespace": namespace,
+							"path":      ConvertPath,
+						},
+						"caBundle": base64.StdEncoding.EncodeToString(ca),
+					},
+					"conversionReviewVersions": []string{"v1", "v1beta1"},
+				},
+			},
+		},
+	}
+	patchBytes, _ := json.Marshal(patch)
+	return patchBytes
+}
+
+// StorageVersionPatch returns the json patch which makes v1alpha2 the storage version of an AMKO CRD.
+// The CRDs are installed with v1alpha1, the first version, as the storage version, since without a
+// conversion webhook, the objects stored in v1alpha1 can't be read as v1alpha2. The patch fails if
+// the versions of the CRD aren't in this order.
+func StorageVersionPatch() []byte {
+	patch := []map[string]interface{}{
+		{"op": "test", "path": "/spec/versions/0/name", "value": v1alpha1.SchemeGroupVersion.Version},
+		{"op": "test", "path": "/spec/versions/1/name", "value": v1alpha2.SchemeGroupVersion.Version},
+		{"op": "replace", "path": "/spec/versions/0/storage", "value": false},
+		{"op": "replace", "path": "/spec/versions/1/storage", "value": true},
+	}
+	patchBytes, _ := json.Marshal(patch)
+	return patchBytes
+}
+
+// patchConversionWebhook sets the conversion of the AMKO CRDs to the webhook server, and then makes
+// v1alpha2 their storage version. The CRDs are installed by helm, which can't template them, so they
+// have no conversion webhook, and it's set once the server starts, with the namespace of AMKO and the
+// CA of the webhook certificate.
+func patchConversionWebhook(certDir string) {
+	if gslbutils.GlobalKubeClient == nil {
+		return
+	}
+	ca, err := ioutil.ReadFile(filepath.Join(certDir, caFile))
+	if err != nil {
+		gslbutils.Warnf("certDir: %s, msg: webhook CA not found, the CRD conversion webhook won't be set", certDir)
+		return
+	}
+	namespace := os.Getenv("POD_NAMESPACE")
+	if namespace == "" {
+		namespace = gslbutils.AVISystem
+	}
+	patchBytes := ConversionPatch(namespace, ca)
+	for _, crd := range conversionCRDs {
+		err := gslbutils.GlobalKubeClient.Discovery().RESTClient().Patch(types.MergePatchType).
+			AbsPath("/apis/apiextensions.k8s.io/v1/customresourcedefinitions", crd).
+			Body(patchBytes).Do().Error()
+		if err != nil {
+			gslbutils.Warnf("crd: %s, msg: error in setting the conversion webhook: %s", crd, err)
+			continue
+		}
+		err = gslbutils.GlobalKubeClient.Discovery().RESTClient().Patch(types.JSONPatchType).
+			AbsPath("/apis/apiextensions.k8s.io/v1/customresourcedefinitions", crd).
+			Body(StorageVersionPatch()).Do().Error()
+		if err != nil {
+			gslbutils.Warnf("crd: %s, msg: error in setting v1alpha2 as the storage version: %s", crd, err)
+		}
+	}
+}
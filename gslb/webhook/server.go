@@ -0,0 +1,149 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package webhook
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/gslb/gslbutils"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+const (
+	// ValidatePath is the path on which the admission requests are served
+	ValidatePath = "/validate"
+	// DefaultPort is the port of the webhook server
+	DefaultPort = "9443"
+	// DefaultCertDir has the TLS certificate and key of the webhook server, set WEBHOOK_CERT_DIR
+	// to use a different directory
+	DefaultCertDir = "/etc/amko/webhook"
+	certFile       = "tls.crt"
+	keyFile        = "tls.key"
+)
+
+// ValidateFn validates the object of an admission request, a non nil error rejects the request
+// with the error as the reason.
+type ValidateFn func(req *AdmissionRequest) error
+
+type validators struct {
+	lock  sync.RWMutex
+	kinds map[string]ValidateFn
+}
+
+var kindValidators = validators{kinds: make(map[string]ValidateFn)}
+
+// RegisterValidator sets the validation function for the objects of kind.
+func RegisterValidator(kind string, fn ValidateFn) {
+	kindValidators.lock.Lock()
+	defer kindValidators.lock.Unlock()
+	kindValidators.kinds[kind] = fn
+}
+
+func getValidator(kind string) (ValidateFn, bool) {
+	kindValidators.lock.RLock()
+	defer kindValidators.lock.RUnlock()
+	fn, ok := kindValidators.kinds[kind]
+	return fn, ok
+}
+
+// Review returns the response for an admission request. Kinds without a validator are allowed.
+func Review(req *AdmissionRequest) *AdmissionResponse {
+	resp := &AdmissionResponse{UID: req.UID, Allowed: true}
+	fn, ok := getValidator(req.Kind.Kind)
+	if !ok {
+		return resp
+	}
+	if err := fn(req); err != nil {
+		gslbutils.Warnf("kind: %s, ns: %s, name: %s, operation: %s, msg: admission request rejected: %s",
+			req.Kind.Kind, req.Namespace, req.Name, req.Operation, err)
+		resp.Allowed = false
+		resp.Result = &metav1.Status{
+			Status:  metav1.StatusFailure,
+			Message: err.Error(),
+			Reason:  metav1.StatusReasonInvalid,
+			Code:    http.StatusUnprocessableEntity,
+		}
+	}
+	return resp
+}
+
+// ServeValidate handles the AdmissionReview requests sent by the API server.
+func ServeValidate(w http.ResponseWriter, r *http.Request) {
+	body, err := ioutil.ReadAll(r.Body)
+	if err != nil {
+		http.Error(w, "couldn't read the request body: "+err.Error(), http.StatusBadRequest)
+		return
+	}
+	var review AdmissionReview
+	if err := json.Unmarshal(body, &review); err != nil || review.Request == nil {
+		http.Error(w, "malformed admission review", http.StatusBadRequest)
+		return
+	}
+	review.Response = Review(review.Request)
+	review.Request = nil
+	resp, err := json.Marshal(review)
+	if err != nil {
+		http.Error(w, "couldn't marshal the admission review: "+err.Error(), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.Write(resp)
+}
+
+// StartServer runs the webhook server with the TLS certificate and key in the WEBHOOK_CERT_DIR
+// directory. It serves both the admission and the CRD conversion requests. The server isn't
+// started if they are missing, in which case the objects are only validated after they are
+// created.
+func StartServer() {
+	certDir := os.Getenv("WEBHOOK_CERT_DIR")
+	if certDir == "" {
+		certDir = DefaultCertDir
+	}
+	cert := filepath.Join(certDir, certFile)
+	key := filepath.Join(certDir, keyFile)
+	if _, err := os.Stat(cert); err != nil {
+		gslbutils.Warnf("certDir: %s, msg: webhook certificate not found, won't start the webhook server", certDir)
+		return
+	}
+	if _, err := os.Stat(key); err != nil {
+		gslbutils.Warnf("certDir: %s, msg: webhook key not found, won't start the webhook server", certDir)
+		return
+	}
+
+	patchConversionWebhook(certDir)
+
+	mux := http.NewServeMux()
+	mux.HandleFunc(ValidatePath, ServeValidate)
+	mux.HandleFunc(ConvertPath, ServeConvert)
+	server := &http.Server{
+		Addr:         ":" + DefaultPort,
+		Handler:      mux,
+		ReadTimeout:  10 * time.Second,
+		WriteTimeout: 10 * time.Second,
+	}
+	go func() {
+		gslbutils.Logf("port: %s, msg: starting the webhook server", DefaultPort)
+		if err := server.ListenAndServeTLS(cert, key); err != nil {
+			gslbutils.Errf("port: %s, msg: webhook server shutdown: %s", DefaultPort, err)
+		}
+	}()
+}
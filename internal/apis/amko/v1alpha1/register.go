@@ -54,6 +54,8 @@ func addKnownTypes(scheme *runtime.Scheme) error {
 		&GlobalDeploymentPolicyList{},
 		&GSLBHostRule{},
 		&GSLBHostRuleList{},
+		&GSLBServiceStatus{},
+		&GSLBServiceStatusList{},
 	)
 
 	scheme.AddKnownTypes(
@@ -48,6 +48,49 @@ type GSLBConfigSpec struct {
 	MemberClusters  []MemberCluster `json:"memberClusters,omitempty"`
 	RefreshInterval int             `json:"refreshInterval,omitempty"`
 	LogLevel        string          `json:"logLevel,omitempty"`
+	// StaleMemberPolicy decides what's done with the GS members of a disconnected member cluster,
+	// the members are kept as is if it isn't set
+	StaleMemberPolicy *StaleMemberPolicy `json:"staleMemberPolicy,omitempty"`
+	// ClusterDiscovery adds the member clusters from the kubeconfig secrets it selects, along with
+	// the ones in MemberClusters
+	ClusterDiscovery *ClusterDiscovery `json:"clusterDiscovery,omitempty"`
+	// GarbageCollection periodically deletes the GSLB services and health monitors created by AMKO,
+	// which no longer belong to a GS, it's disabled if not set
+	GarbageCollection *GarbageCollection `json:"garbageCollection,omitempty"`
+}
+
+// GarbageCollection deletes the GSLB services and health monitors created by AMKO on the Avi
+// controller, which don't belong to any GS built by AMKO, once they are orphaned for the grace
+// period.
+type GarbageCollection struct {
+	// GracePeriod is the time in seconds for which an object has to be orphaned before it's deleted,
+	// 1800 seconds if not set
+	GracePeriod int `json:"gracePeriod,omitempty"`
+	// MaxDeletions is the safety threshold, if more objects are due to be deleted in a pass, none
+	// of them are deleted. 10 if not set.
+	MaxDeletions int `json:"maxDeletions,omitempty"`
+	// DryRun only reports the orphaned objects in the status, they aren't deleted
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ClusterDiscovery selects the kubeconfig secrets on the AMKO cluster from which member clusters
+// are discovered.
+type ClusterDiscovery struct {
+	// Format of the secrets, ClusterAPI or ArgoCD
+	Format string `json:"format,omitempty"`
+	// Namespace of the secrets, all the namespaces if not set
+	Namespace string `json:"namespace,omitempty"`
+	// Label selects the secrets, along with the labels of the format
+	Label map[string]string `json:"label,omitempty"`
+}
+
+// StaleMemberPolicy is applied on the GS members of a member cluster, once AMKO is disconnected
+// from the cluster for the grace period.
+type StaleMemberPolicy struct {
+	// Action is one of Keep, Disable or Remove
+	Action string `json:"action,omitempty"`
+	// GracePeriod is the time in seconds after which the action is applied, 300 seconds if not set
+	GracePeriod int `json:"gracePeriod,omitempty"`
 }
 
 // GSLBLeader is the leader node in the GSLB cluster
@@ -55,11 +98,33 @@ type GSLBLeader struct {
 	Credentials       string `json:"credentials,omitempty"`
 	ControllerVersion string `json:"controllerVersion,omitempty"`
 	ControllerIP      string `json:"controllerIP,omitempty"`
+	// ControllerEndpoints are the controllers of the other GSLB sites, or their cluster VIPs, which
+	// AMKO re-targets to when the controller at ControllerIP is no longer the GSLB leader
+	ControllerEndpoints []string `json:"controllerEndpoints,omitempty"`
+	// TLS configures the verification of the certificate of the controller, it isn't verified if
+	// TLS isn't set
+	TLS *ControllerTLS `json:"tls,omitempty"`
+}
+
+// ControllerTLS configures the verification of the TLS certificate of the Avi controller.
+type ControllerTLS struct {
+	// CASecret is the name of the Secret in avi-system with the CA bundle in the ca.crt key, which
+	// the certificate is verified against. The system CAs are used if it isn't set.
+	CASecret string `json:"caSecret,omitempty"`
+	// ServerName overrides the host name which the certificate is verified for, the host of the
+	// controller IP if it isn't set
+	ServerName string `json:"serverName,omitempty"`
+	// Verification is one of Full, CAOnly or None, Full if not set. CAOnly verifies the certificate
+	// chain, but not the host name.
+	Verification string `json:"verification,omitempty"`
 }
 
 // MemberCluster defines a GSLB member cluster details
 type MemberCluster struct {
 	ClusterContext string `json:"clusterContext,omitempty"`
+	// Secret is the name of the Secret in avi-system with the credentials of the cluster, either a
+	// kubeconfig, or the API server address with a token or a client certificate
+	Secret string `json:"secret,omitempty"`
 }
 
 // GSLBConfigStatus represents the state and status message of the GSLB cluster
@@ -113,6 +178,15 @@ type GDPSpec struct {
 	MatchRules    MatchRules         `json:"matchRules,omitempty"`
 	MatchClusters []string           `json:"matchClusters,omitempty"`
 	TrafficSplit  []TrafficSplitElem `json:"trafficSplit,omitempty"`
+	// DrainClusters is a list of clusters in maintenance mode. The members from these clusters
+	// are kept in the GSLB Services, but are disabled, so no traffic is routed to them.
+	DrainClusters []string `json:"drainClusters,omitempty"`
+	// ScheduledTrafficSplit is a list of time bounded traffic splits. While a window is active, its
+	// weights override the TrafficSplit weights for the clusters present in that window.
+	ScheduledTrafficSplit []ScheduledTrafficSplit `json:"scheduledTrafficSplit,omitempty"`
+	// TrafficShift progressively moves the traffic from one cluster to another, a step is advanced
+	// only if the GSLB Service members of the target cluster stay healthy.
+	TrafficShift *TrafficShift `json:"trafficShift,omitempty"`
 }
 
 // MatchRules is the match criteria needed to select the kubernetes/openshift objects.
@@ -150,9 +224,54 @@ type TrafficSplitElem struct {
 	Weight  uint32 `json:"weight,omitempty"`
 }
 
+// ScheduledTrafficSplit is a traffic split which is applicable only between Start and End.
+type ScheduledTrafficSplit struct {
+	// Name of this window, it must be unique across all the windows of a GDP object
+	Name         string             `json:"name,omitempty"`
+	Start        metav1.Time        `json:"start,omitempty"`
+	End          metav1.Time        `json:"end,omitempty"`
+	TrafficSplit []TrafficSplitElem `json:"trafficSplit,omitempty"`
+}
+
+// TrafficShift moves the traffic from SourceCluster to TargetCluster in steps. Each step is the
+// percentage of traffic routed to TargetCluster, the rest is routed to SourceCluster. A step is
+// advanced once the members of TargetCluster have been healthy for SoakTime seconds, and the shift
+// is rolled back if any of them go down.
+type TrafficShift struct {
+	SourceCluster string `json:"sourceCluster,omitempty"`
+	TargetCluster string `json:"targetCluster,omitempty"`
+	Steps         []int  `json:"steps,omitempty"`
+	SoakTime      int    `json:"soakTime,omitempty"`
+}
+
+// TrafficShiftStatus gives the progress of a traffic shift.
+type TrafficShiftStatus struct {
+	// Step is the index of the current step in Steps
+	Step int `json:"step"`
+	// TargetPercent is the percentage of traffic currently routed to the target cluster
+	TargetPercent int `json:"targetPercent"`
+	// State is one of Progressing, Completed or RolledBack
+	State   string `json:"state,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
 // GDPStatus gives the current status of the policy object.
 type GDPStatus struct {
 	ErrorStatus string `json:"errorStatus,omitempty"`
+	// DrainStatus gives the drain progress of each cluster in DrainClusters.
+	DrainStatus []ClusterDrainStatus `json:"drainStatus,omitempty"`
+	// ActiveTrafficSplitWindow is the name of the scheduled traffic split window currently active.
+	ActiveTrafficSplitWindow string `json:"activeTrafficSplitWindow,omitempty"`
+	// TrafficShift gives the progress of the traffic shift.
+	TrafficShift *TrafficShiftStatus `json:"trafficShift,omitempty"`
+}
+
+// ClusterDrainStatus gives the drain progress of a cluster. Members is the number of GSLB Service
+// members from this cluster, out of which DisabledMembers are disabled on the Avi controller.
+type ClusterDrainStatus struct {
+	Cluster         string `json:"cluster,omitempty"`
+	Members         int    `json:"members"`
+	DisabledMembers int    `json:"disabledMembers"`
 }
 
 // +genclient
@@ -198,6 +317,9 @@ type GSLBHostRuleSpec struct {
 	HealthMonitorRefs []string `json:"hmRefs,omitempty"`
 	// TrafficSplit defines the weightage of traffic that can be routed to each cluster.
 	TrafficSplit []TrafficSplitElem `json:"trafficSplit,omitempty"`
+	// AdoptExistingGS if set to true, makes AMKO take over a GSLB Service for the fqdn which
+	// already exists on the Avi controller and wasn't created by AMKO, instead of creating a new one.
+	AdoptExistingGS bool `json:"adoptExistingGS,omitempty"`
 }
 
 // GSLBHostRuleStatus contains the current state of the GSLBHostRule resource. If the
@@ -207,6 +329,53 @@ type GSLBHostRuleStatus struct {
 	Status string `json:"status,omitempty"`
 }
 
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+
+// GSLBServiceStatus is a read-only object created by AMKO for each GSLB Service. It reports the
+// runtime health of the GSLB Service and its members as seen by the Avi controller.
+type GSLBServiceStatus struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +optional
+	Status GSLBServiceHealth `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GSLBServiceStatusList is a list of GSLBServiceStatus resources
+type GSLBServiceStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GSLBServiceStatus `json:"items"`
+}
+
+// GSLBServiceHealth is the runtime health of a GSLB Service.
+type GSLBServiceHealth struct {
+	// Fqdn is the domain name of the GSLB Service
+	Fqdn string `json:"fqdn,omitempty"`
+	// UUID of the GSLB Service on the Avi controller
+	UUID string `json:"uuid,omitempty"`
+	// State is the operational state of the GSLB Service: Up, Down, Disabled or Unknown
+	State   string                    `json:"state,omitempty"`
+	Members []GSLBServiceMemberHealth `json:"members,omitempty"`
+}
+
+// GSLBServiceMemberHealth is the runtime health of a GSLB Service member, along with the object
+// from which the member was derived.
+type GSLBServiceMemberHealth struct {
+	Cluster   string `json:"cluster,omitempty"`
+	ObjType   string `json:"objType,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	IPAddr    string `json:"ipAddr,omitempty"`
+	// State is the operational state of the member: Up, Down, Disabled or Unknown
+	State string `json:"state,omitempty"`
+}
+
 // SitePersistence
 type SitePersistence struct {
 	Enabled    bool   `json:"enabled,omitempty"`
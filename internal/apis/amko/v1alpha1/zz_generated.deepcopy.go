@@ -47,6 +47,61 @@ func (in *AppSelector) DeepCopy() *AppSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDiscovery) DeepCopyInto(out *ClusterDiscovery) {
+	*out = *in
+	if in.Label != nil {
+		in, out := &in.Label, &out.Label
+		*out = make(map[string]string, len(*in))
+		for key, val := range *in {
+			(*out)[key] = val
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDiscovery.
+func (in *ClusterDiscovery) DeepCopy() *ClusterDiscovery {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDiscovery)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ClusterDrainStatus) DeepCopyInto(out *ClusterDrainStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ClusterDrainStatus.
+func (in *ClusterDrainStatus) DeepCopy() *ClusterDrainStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(ClusterDrainStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ControllerTLS) DeepCopyInto(out *ControllerTLS) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ControllerTLS.
+func (in *ControllerTLS) DeepCopy() *ControllerTLS {
+	if in == nil {
+		return nil
+	}
+	out := new(ControllerTLS)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GDPSpec) DeepCopyInto(out *GDPSpec) {
 	*out = *in
@@ -61,6 +116,23 @@ func (in *GDPSpec) DeepCopyInto(out *GDPSpec) {
 		*out = make([]TrafficSplitElem, len(*in))
 		copy(*out, *in)
 	}
+	if in.DrainClusters != nil {
+		in, out := &in.DrainClusters, &out.DrainClusters
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.ScheduledTrafficSplit != nil {
+		in, out := &in.ScheduledTrafficSplit, &out.ScheduledTrafficSplit
+		*out = make([]ScheduledTrafficSplit, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	if in.TrafficShift != nil {
+		in, out := &in.TrafficShift, &out.TrafficShift
+		*out = new(TrafficShift)
+		(*in).DeepCopyInto(*out)
+	}
 	return
 }
 
@@ -77,6 +149,16 @@ func (in *GDPSpec) DeepCopy() *GDPSpec {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GDPStatus) DeepCopyInto(out *GDPStatus) {
 	*out = *in
+	if in.DrainStatus != nil {
+		in, out := &in.DrainStatus, &out.DrainStatus
+		*out = make([]ClusterDrainStatus, len(*in))
+		copy(*out, *in)
+	}
+	if in.TrafficShift != nil {
+		in, out := &in.TrafficShift, &out.TrafficShift
+		*out = new(TrafficShiftStatus)
+		**out = **in
+	}
 	return
 }
 
@@ -154,12 +236,27 @@ func (in *GSLBConfigList) DeepCopyObject() runtime.Object {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GSLBConfigSpec) DeepCopyInto(out *GSLBConfigSpec) {
 	*out = *in
-	out.GSLBLeader = in.GSLBLeader
+	in.GSLBLeader.DeepCopyInto(&out.GSLBLeader)
 	if in.MemberClusters != nil {
 		in, out := &in.MemberClusters, &out.MemberClusters
 		*out = make([]MemberCluster, len(*in))
 		copy(*out, *in)
 	}
+	if in.StaleMemberPolicy != nil {
+		in, out := &in.StaleMemberPolicy, &out.StaleMemberPolicy
+		*out = new(StaleMemberPolicy)
+		**out = **in
+	}
+	if in.ClusterDiscovery != nil {
+		in, out := &in.ClusterDiscovery, &out.ClusterDiscovery
+		*out = new(ClusterDiscovery)
+		(*in).DeepCopyInto(*out)
+	}
+	if in.GarbageCollection != nil {
+		in, out := &in.GarbageCollection, &out.GarbageCollection
+		*out = new(GarbageCollection)
+		**out = **in
+	}
 	return
 }
 
@@ -328,6 +425,16 @@ func (in *GSLBHostRuleStatus) DeepCopy() *GSLBHostRuleStatus {
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GSLBLeader) DeepCopyInto(out *GSLBLeader) {
 	*out = *in
+	if in.ControllerEndpoints != nil {
+		in, out := &in.ControllerEndpoints, &out.ControllerEndpoints
+		*out = make([]string, len(*in))
+		copy(*out, *in)
+	}
+	if in.TLS != nil {
+		in, out := &in.TLS, &out.TLS
+		*out = new(ControllerTLS)
+		**out = **in
+	}
 	return
 }
 
@@ -341,13 +448,126 @@ func (in *GSLBLeader) DeepCopy() *GSLBLeader {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GSLBServiceHealth) DeepCopyInto(out *GSLBServiceHealth) {
+	*out = *in
+	if in.Members != nil {
+		in, out := &in.Members, &out.Members
+		*out = make([]GSLBServiceMemberHealth, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GSLBServiceHealth.
+func (in *GSLBServiceHealth) DeepCopy() *GSLBServiceHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(GSLBServiceHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GSLBServiceMemberHealth) DeepCopyInto(out *GSLBServiceMemberHealth) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GSLBServiceMemberHealth.
+func (in *GSLBServiceMemberHealth) DeepCopy() *GSLBServiceMemberHealth {
+	if in == nil {
+		return nil
+	}
+	out := new(GSLBServiceMemberHealth)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GSLBServiceStatus) DeepCopyInto(out *GSLBServiceStatus) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
+	in.Status.DeepCopyInto(&out.Status)
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GSLBServiceStatus.
+func (in *GSLBServiceStatus) DeepCopy() *GSLBServiceStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(GSLBServiceStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GSLBServiceStatus) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GSLBServiceStatusList) DeepCopyInto(out *GSLBServiceStatusList) {
+	*out = *in
+	out.TypeMeta = in.TypeMeta
+	in.ListMeta.DeepCopyInto(&out.ListMeta)
+	if in.Items != nil {
+		in, out := &in.Items, &out.Items
+		*out = make([]GSLBServiceStatus, len(*in))
+		for i := range *in {
+			(*in)[i].DeepCopyInto(&(*out)[i])
+		}
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GSLBServiceStatusList.
+func (in *GSLBServiceStatusList) DeepCopy() *GSLBServiceStatusList {
+	if in == nil {
+		return nil
+	}
+	out := new(GSLBServiceStatusList)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyObject is an autogenerated deepcopy function, copying the receiver, creating a new runtime.Object.
+func (in *GSLBServiceStatusList) DeepCopyObject() runtime.Object {
+	if c := in.DeepCopy(); c != nil {
+		return c
+	}
+	return nil
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *GarbageCollection) DeepCopyInto(out *GarbageCollection) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new GarbageCollection.
+func (in *GarbageCollection) DeepCopy() *GarbageCollection {
+	if in == nil {
+		return nil
+	}
+	out := new(GarbageCollection)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *GlobalDeploymentPolicy) DeepCopyInto(out *GlobalDeploymentPolicy) {
 	*out = *in
 	out.TypeMeta = in.TypeMeta
 	in.ObjectMeta.DeepCopyInto(&out.ObjectMeta)
 	in.Spec.DeepCopyInto(&out.Spec)
-	out.Status = in.Status
+	in.Status.DeepCopyInto(&out.Status)
 	return
 }
 
@@ -459,6 +679,29 @@ func (in *NamespaceSelector) DeepCopy() *NamespaceSelector {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *ScheduledTrafficSplit) DeepCopyInto(out *ScheduledTrafficSplit) {
+	*out = *in
+	in.Start.DeepCopyInto(&out.Start)
+	in.End.DeepCopyInto(&out.End)
+	if in.TrafficSplit != nil {
+		in, out := &in.TrafficSplit, &out.TrafficSplit
+		*out = make([]TrafficSplitElem, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new ScheduledTrafficSplit.
+func (in *ScheduledTrafficSplit) DeepCopy() *ScheduledTrafficSplit {
+	if in == nil {
+		return nil
+	}
+	out := new(ScheduledTrafficSplit)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *SitePersistence) DeepCopyInto(out *SitePersistence) {
 	*out = *in
@@ -475,6 +718,59 @@ func (in *SitePersistence) DeepCopy() *SitePersistence {
 	return out
 }
 
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *StaleMemberPolicy) DeepCopyInto(out *StaleMemberPolicy) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new StaleMemberPolicy.
+func (in *StaleMemberPolicy) DeepCopy() *StaleMemberPolicy {
+	if in == nil {
+		return nil
+	}
+	out := new(StaleMemberPolicy)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficShift) DeepCopyInto(out *TrafficShift) {
+	*out = *in
+	if in.Steps != nil {
+		in, out := &in.Steps, &out.Steps
+		*out = make([]int, len(*in))
+		copy(*out, *in)
+	}
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficShift.
+func (in *TrafficShift) DeepCopy() *TrafficShift {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficShift)
+	in.DeepCopyInto(out)
+	return out
+}
+
+// DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
+func (in *TrafficShiftStatus) DeepCopyInto(out *TrafficShiftStatus) {
+	*out = *in
+	return
+}
+
+// DeepCopy is an autogenerated deepcopy function, copying the receiver, creating a new TrafficShiftStatus.
+func (in *TrafficShiftStatus) DeepCopy() *TrafficShiftStatus {
+	if in == nil {
+		return nil
+	}
+	out := new(TrafficShiftStatus)
+	in.DeepCopyInto(out)
+	return out
+}
+
 // DeepCopyInto is an autogenerated deepcopy function, copying the receiver, writing into out. in must be non-nil.
 func (in *TrafficSplitElem) DeepCopyInto(out *TrafficSplitElem) {
 	*out = *in
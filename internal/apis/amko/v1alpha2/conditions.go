@@ -0,0 +1,58 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// FindCondition returns the condition of type condType, nil if it isn't present.
+func FindCondition(conditions []Condition, condType string) *Condition {
+	for i := range conditions {
+		if conditions[i].Type == condType {
+			return &conditions[i]
+		}
+	}
+	return nil
+}
+
+// IsConditionTrue returns true if the condition of type condType is present and true.
+func IsConditionTrue(conditions []Condition, condType string) bool {
+	cond := FindCondition(conditions, condType)
+	return cond != nil && cond.Status == metav1.ConditionTrue
+}
+
+// SetCondition adds cond to conditions or updates the existing condition of the same type. The
+// LastTransitionTime is only changed if the status of the condition changes.
+func SetCondition(conditions *[]Condition, cond Condition) {
+	existing := FindCondition(*conditions, cond.Type)
+	if existing == nil {
+		if cond.LastTransitionTime.IsZero() {
+			cond.LastTransitionTime = metav1.Now()
+		}
+		*conditions = append(*conditions, cond)
+		return
+	}
+	if existing.Status != cond.Status {
+		existing.Status = cond.Status
+		existing.LastTransitionTime = metav1.Now()
+		if !cond.LastTransitionTime.IsZero() {
+			existing.LastTransitionTime = cond.LastTransitionTime
+		}
+	}
+	existing.Reason = cond.Reason
+	existing.Message = cond.Message
+	existing.ObservedGeneration = cond.ObservedGeneration
+}
@@ -0,0 +1,398 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package v1alpha2
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
+
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+)
+
+// StatusAnnotation keeps the status of a v1alpha2 object when it's converted to v1alpha1, so that
+// converting it back doesn't lose the fields which v1alpha1 can't represent.
+const StatusAnnotation = "amko.vmware.com/v1alpha2-status"
+
+// v1alpha1 status messages start with these prefixes
+const (
+	v1alpha1SuccessPrefix = "success"
+	v1alpha1ErrorPrefix   = "error"
+)
+
+func stashStatus(meta *metav1.ObjectMeta, status interface{}) {
+	data, err := json.Marshal(status)
+	if err != nil || string(data) == "{}" {
+		return
+	}
+	if meta.Annotations == nil {
+		meta.Annotations = make(map[string]string)
+	}
+	meta.Annotations[StatusAnnotation] = string(data)
+}
+
+func restoreStatus(meta *metav1.ObjectMeta, status interface{}) {
+	data, ok := meta.Annotations[StatusAnnotation]
+	if !ok {
+		return
+	}
+	delete(meta.Annotations, StatusAnnotation)
+	if len(meta.Annotations) == 0 {
+		meta.Annotations = nil
+	}
+	json.Unmarshal([]byte(data), status)
+}
+
+// setAcceptedCondition sets the Accepted condition derived from a v1alpha1 status, unless the
+// restored condition already has the same reason and message, in which case the v1alpha1 status
+// wasn't changed and the restored condition is kept as is.
+func setAcceptedCondition(conditions *[]Condition, cond Condition, matchReason bool) {
+	existing := FindCondition(*conditions, ConditionAccepted)
+	if existing != nil && existing.Message == cond.Message && (!matchReason || existing.Reason == cond.Reason) {
+		return
+	}
+	SetCondition(conditions, cond)
+}
+
+// acceptedConditionFromMsg builds the Accepted condition from a v1alpha1 status message, the
+// messages starting with "success" are accepted and the ones starting with "error" are rejected.
+func acceptedConditionFromMsg(msg string) Condition {
+	cond := Condition{Type: ConditionAccepted, Message: msg}
+	switch {
+	case strings.HasPrefix(msg, v1alpha1SuccessPrefix):
+		cond.Status = metav1.ConditionTrue
+		cond.Reason = ReasonAccepted
+	case strings.HasPrefix(msg, v1alpha1ErrorPrefix):
+		cond.Status = metav1.ConditionFalse
+		cond.Reason = ReasonRejected
+	default:
+		cond.Status = metav1.ConditionUnknown
+		cond.Reason = ReasonPending
+	}
+	return cond
+}
+
+func acceptedMsg(conditions []Condition) string {
+	if cond := FindCondition(conditions, ConditionAccepted); cond != nil {
+		return cond.Message
+	}
+	return ""
+}
+
+func clusterWeightsFromV1alpha1(in []v1alpha1.TrafficSplitElem) []ClusterWeight {
+	if in == nil {
+		return nil
+	}
+	out := make([]ClusterWeight, len(in))
+	for i := range in {
+		out[i] = ClusterWeight(in[i])
+	}
+	return out
+}
+
+func clusterWeightsToV1alpha1(in []ClusterWeight) []v1alpha1.TrafficSplitElem {
+	if in == nil {
+		return nil
+	}
+	out := make([]v1alpha1.TrafficSplitElem, len(in))
+	for i := range in {
+		out[i] = v1alpha1.TrafficSplitElem(in[i])
+	}
+	return out
+}
+
+func convertGSLBLeaderFromV1alpha1(in v1alpha1.GSLBLeader) GSLBLeader {
+	out := GSLBLeader{
+		Credentials:         in.Credentials,
+		ControllerVersion:   in.ControllerVersion,
+		ControllerIP:        in.ControllerIP,
+		ControllerEndpoints: append([]string(nil), in.ControllerEndpoints...),
+	}
+	if in.TLS != nil {
+		out.TLS = (*ControllerTLS)(in.TLS.DeepCopy())
+	}
+	return out
+}
+
+func convertGSLBLeaderToV1alpha1(in GSLBLeader) v1alpha1.GSLBLeader {
+	out := v1alpha1.GSLBLeader{
+		Credentials:         in.Credentials,
+		ControllerVersion:   in.ControllerVersion,
+		ControllerIP:        in.ControllerIP,
+		ControllerEndpoints: append([]string(nil), in.ControllerEndpoints...),
+	}
+	if in.TLS != nil {
+		out.TLS = (*v1alpha1.ControllerTLS)(in.TLS.DeepCopy())
+	}
+	return out
+}
+
+// ConvertGSLBConfigFromV1alpha1 converts a v1alpha1 GSLBConfig object to v1alpha2.
+func ConvertGSLBConfigFromV1alpha1(in *v1alpha1.GSLBConfig) *GSLBConfig {
+	out := &GSLBConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "GSLBConfig"},
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+	}
+	out.Spec.GSLBLeader = convertGSLBLeaderFromV1alpha1(in.Spec.GSLBLeader)
+	if in.Spec.MemberClusters != nil {
+		out.Spec.MemberClusters = make([]MemberCluster, len(in.Spec.MemberClusters))
+		for i := range in.Spec.MemberClusters {
+			out.Spec.MemberClusters[i] = MemberCluster(in.Spec.MemberClusters[i])
+		}
+	}
+	out.Spec.RefreshInterval = in.Spec.RefreshInterval
+	out.Spec.LogLevel = in.Spec.LogLevel
+	if in.Spec.StaleMemberPolicy != nil {
+		out.Spec.StaleMemberPolicy = &StaleMemberPolicy{
+			Action:      in.Spec.StaleMemberPolicy.Action,
+			GracePeriod: in.Spec.StaleMemberPolicy.GracePeriod,
+		}
+	}
+	if in.Spec.ClusterDiscovery != nil {
+		out.Spec.ClusterDiscovery = (*ClusterDiscovery)(in.Spec.ClusterDiscovery.DeepCopy())
+	}
+	if in.Spec.GarbageCollection != nil {
+		out.Spec.GarbageCollection = (*GarbageCollection)(in.Spec.GarbageCollection.DeepCopy())
+	}
+	restoreStatus(&out.ObjectMeta, &out.Status)
+	if in.Status.State != "" {
+		setAcceptedCondition(&out.Status.Conditions, acceptedConditionFromMsg(in.Status.State), false)
+	}
+	return out
+}
+
+// ConvertGSLBConfigToV1alpha1 converts a v1alpha2 GSLBConfig object to v1alpha1, the State is the
+// message of the Accepted condition.
+func ConvertGSLBConfigToV1alpha1(in *GSLBConfig) *v1alpha1.GSLBConfig {
+	out := &v1alpha1.GSLBConfig{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "GSLBConfig"},
+		ObjectMeta: *in.ObjectMeta.DeepCopy(),
+	}
+	out.Spec.GSLBLeader = convertGSLBLeaderToV1alpha1(in.Spec.GSLBLeader)
+	if in.Spec.MemberClusters != nil {
+		out.Spec.MemberClusters = make([]v1alpha1.MemberCluster, len(in.Spec.MemberClusters))
+		for i := range in.Spec.MemberClusters {
+			out.Spec.MemberClusters[i] = v1alpha1.MemberCluster(in.Spec.MemberClusters[i])
+		}
+	}
+	out.Spec.RefreshInterval = in.Spec.RefreshInterval
+	out.Spec.LogLevel = in.Spec.LogLevel
+	if in.Spec.StaleMemberPolicy != nil {
+		out.Spec.StaleMemberPolicy = &v1alpha1.StaleMemberPolicy{
+			Action:      in.Spec.StaleMemberPolicy.Action,
+			GracePeriod: in.Spec.StaleMemberPolicy.GracePeriod,
+		}
+	}
+	if in.Spec.ClusterDiscovery != nil {
+		out.Spec.ClusterDiscovery = (*v1alpha1.ClusterDiscovery)(in.Spec.ClusterDiscovery.DeepCopy())
+	}
+	if in.Spec.GarbageCollection != nil {
+		out.Spec.GarbageCollection = (*v1alpha1.GarbageCollection)(in.Spec.GarbageCollection.DeepCopy())
+	}
+	out.Status.State = acceptedMsg(in.Status.Conditions)
+	stashStatus(&out.ObjectMeta, in.Status)
+	return out
+}
+
+// ConvertGDPFromV1alpha1 converts a v1alpha1 GlobalDeploymentPolicy object to v1alpha2.
+func ConvertGDPFromV1alpha1(in *v1alpha1.GlobalDeploymentPolicy) *GlobalDeploymentPolicy {
+	in = in.DeepCopy()
+	out := &GlobalDeploymentPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "GlobalDeploymentPolicy"},
+		ObjectMeta: in.ObjectMeta,
+	}
+	out.Spec.MatchRules.AppSelector = AppSelector(in.Spec.MatchRules.AppSelector)
+	out.Spec.MatchRules.NamespaceSelector = NamespaceSelector(in.Spec.MatchRules.NamespaceSelector)
+	out.Spec.MatchClusters = in.Spec.MatchClusters
+	out.Spec.TrafficSplit = clusterWeightsFromV1alpha1(in.Spec.TrafficSplit)
+	out.Spec.DrainClusters = in.Spec.DrainClusters
+	if in.Spec.ScheduledTrafficSplit != nil {
+		out.Spec.ScheduledTrafficSplit = make([]ScheduledTrafficSplit, len(in.Spec.ScheduledTrafficSplit))
+		for i, w := range in.Spec.ScheduledTrafficSplit {
+			out.Spec.ScheduledTrafficSplit[i] = ScheduledTrafficSplit{
+				Name:         w.Name,
+				Start:        w.Start,
+				End:          w.End,
+				TrafficSplit: clusterWeightsFromV1alpha1(w.TrafficSplit),
+			}
+		}
+	}
+	if in.Spec.TrafficShift != nil {
+		ts := TrafficShift(*in.Spec.TrafficShift)
+		out.Spec.TrafficShift = &ts
+	}
+
+	restoreStatus(&out.ObjectMeta, &out.Status)
+	if in.Status.ErrorStatus != "" {
+		setAcceptedCondition(&out.Status.Conditions, acceptedConditionFromMsg(in.Status.ErrorStatus), false)
+	}
+	out.Status.DrainStatus = nil
+	out.Status.TrafficShift = nil
+	if in.Status.DrainStatus != nil {
+		out.Status.DrainStatus = make([]ClusterDrainStatus, len(in.Status.DrainStatus))
+		for i := range in.Status.DrainStatus {
+			out.Status.DrainStatus[i] = ClusterDrainStatus(in.Status.DrainStatus[i])
+		}
+	}
+	out.Status.ActiveTrafficSplitWindow = in.Status.ActiveTrafficSplitWindow
+	if in.Status.TrafficShift != nil {
+		tss := TrafficShiftStatus(*in.Status.TrafficShift)
+		out.Status.TrafficShift = &tss
+	}
+	return out
+}
+
+// ConvertGDPToV1alpha1 converts a v1alpha2 GlobalDeploymentPolicy object to v1alpha1, the
+// ErrorStatus is the message of the Accepted condition.
+func ConvertGDPToV1alpha1(in *GlobalDeploymentPolicy) *v1alpha1.GlobalDeploymentPolicy {
+	in = in.DeepCopy()
+	out := &v1alpha1.GlobalDeploymentPolicy{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "GlobalDeploymentPolicy"},
+		ObjectMeta: in.ObjectMeta,
+	}
+	out.Spec.MatchRules.AppSelector = v1alpha1.AppSelector(in.Spec.MatchRules.AppSelector)
+	out.Spec.MatchRules.NamespaceSelector = v1alpha1.NamespaceSelector(in.Spec.MatchRules.NamespaceSelector)
+	out.Spec.MatchClusters = in.Spec.MatchClusters
+	out.Spec.TrafficSplit = clusterWeightsToV1alpha1(in.Spec.TrafficSplit)
+	out.Spec.DrainClusters = in.Spec.DrainClusters
+	if in.Spec.ScheduledTrafficSplit != nil {
+		out.Spec.ScheduledTrafficSplit = make([]v1alpha1.ScheduledTrafficSplit, len(in.Spec.ScheduledTrafficSplit))
+		for i, w := range in.Spec.ScheduledTrafficSplit {
+			out.Spec.ScheduledTrafficSplit[i] = v1alpha1.ScheduledTrafficSplit{
+				Name:         w.Name,
+				Start:        w.Start,
+				End:          w.End,
+				TrafficSplit: clusterWeightsToV1alpha1(w.TrafficSplit),
+			}
+		}
+	}
+	if in.Spec.TrafficShift != nil {
+		ts := v1alpha1.TrafficShift(*in.Spec.TrafficShift)
+		out.Spec.TrafficShift = &ts
+	}
+
+	out.Status.ErrorStatus = acceptedMsg(in.Status.Conditions)
+	stashStatus(&out.ObjectMeta, in.Status)
+	if in.Status.DrainStatus != nil {
+		out.Status.DrainStatus = make([]v1alpha1.ClusterDrainStatus, len(in.Status.DrainStatus))
+		for i := range in.Status.DrainStatus {
+			out.Status.DrainStatus[i] = v1alpha1.ClusterDrainStatus(in.Status.DrainStatus[i])
+		}
+	}
+	out.Status.ActiveTrafficSplitWindow = in.Status.ActiveTrafficSplitWindow
+	if in.Status.TrafficShift != nil {
+		tss := v1alpha1.TrafficShiftStatus(*in.Status.TrafficShift)
+		out.Status.TrafficShift = &tss
+	}
+	return out
+}
+
+// ConvertGSLBHostRuleFromV1alpha1 converts a v1alpha1 GSLBHostRule object to v1alpha2, the
+// Status of the v1alpha1 object becomes the reason of the Accepted condition.
+func ConvertGSLBHostRuleFromV1alpha1(in *v1alpha1.GSLBHostRule) *GSLBHostRule {
+	in = in.DeepCopy()
+	out := &GSLBHostRule{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "GSLBHostRule"},
+		ObjectMeta: in.ObjectMeta,
+	}
+	out.Spec.Fqdn = in.Spec.Fqdn
+	out.Spec.TTL = in.Spec.TTL
+	if in.Spec.SitePersistenceEnabled {
+		out.Spec.SitePersistence = &SitePersistence{Enabled: true}
+	}
+	out.Spec.HealthMonitorRefs = in.Spec.HealthMonitorRefs
+	out.Spec.TrafficSplit = clusterWeightsFromV1alpha1(in.Spec.TrafficSplit)
+	out.Spec.AdoptExistingGS = in.Spec.AdoptExistingGS
+
+	restoreStatus(&out.ObjectMeta, &out.Status)
+	if in.Status.Status != "" {
+		cond := Condition{
+			Type:    ConditionAccepted,
+			Status:  metav1.ConditionUnknown,
+			Reason:  in.Status.Status,
+			Message: in.Status.Error,
+		}
+		switch in.Status.Status {
+		case ReasonAccepted:
+			cond.Status = metav1.ConditionTrue
+		case ReasonRejected:
+			cond.Status = metav1.ConditionFalse
+		}
+		setAcceptedCondition(&out.Status.Conditions, cond, true)
+	}
+	return out
+}
+
+// ConvertGSLBHostRuleToV1alpha1 converts a v1alpha2 GSLBHostRule object to v1alpha1.
+func ConvertGSLBHostRuleToV1alpha1(in *GSLBHostRule) *v1alpha1.GSLBHostRule {
+	in = in.DeepCopy()
+	out := &v1alpha1.GSLBHostRule{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "GSLBHostRule"},
+		ObjectMeta: in.ObjectMeta,
+	}
+	out.Spec.Fqdn = in.Spec.Fqdn
+	out.Spec.TTL = in.Spec.TTL
+	out.Spec.SitePersistenceEnabled = in.Spec.SitePersistence != nil && in.Spec.SitePersistence.Enabled
+	out.Spec.HealthMonitorRefs = in.Spec.HealthMonitorRefs
+	out.Spec.TrafficSplit = clusterWeightsToV1alpha1(in.Spec.TrafficSplit)
+	out.Spec.AdoptExistingGS = in.Spec.AdoptExistingGS
+
+	if cond := FindCondition(in.Status.Conditions, ConditionAccepted); cond != nil {
+		out.Status.Status = cond.Reason
+		out.Status.Error = cond.Message
+	}
+	stashStatus(&out.ObjectMeta, in.Status)
+	return out
+}
+
+// ConvertGSLBServiceStatusFromV1alpha1 converts a v1alpha1 GSLBServiceStatus object to v1alpha2,
+// both the versions have the same schema.
+func ConvertGSLBServiceStatusFromV1alpha1(in *v1alpha1.GSLBServiceStatus) *GSLBServiceStatus {
+	in = in.DeepCopy()
+	out := &GSLBServiceStatus{
+		TypeMeta:   metav1.TypeMeta{APIVersion: SchemeGroupVersion.String(), Kind: "GSLBServiceStatus"},
+		ObjectMeta: in.ObjectMeta,
+	}
+	out.Status.Fqdn = in.Status.Fqdn
+	out.Status.UUID = in.Status.UUID
+	out.Status.State = in.Status.State
+	if in.Status.Members != nil {
+		out.Status.Members = make([]GSLBServiceMemberHealth, len(in.Status.Members))
+		for i := range in.Status.Members {
+			out.Status.Members[i] = GSLBServiceMemberHealth(in.Status.Members[i])
+		}
+	}
+	return out
+}
+
+// ConvertGSLBServiceStatusToV1alpha1 converts a v1alpha2 GSLBServiceStatus object to v1alpha1.
+func ConvertGSLBServiceStatusToV1alpha1(in *GSLBServiceStatus) *v1alpha1.GSLBServiceStatus {
+	in = in.DeepCopy()
+	out := &v1alpha1.GSLBServiceStatus{
+		TypeMeta:   metav1.TypeMeta{APIVersion: v1alpha1.SchemeGroupVersion.String(), Kind: "GSLBServiceStatus"},
+		ObjectMeta: in.ObjectMeta,
+	}
+	out.Status.Fqdn = in.Status.Fqdn
+	out.Status.UUID = in.Status.UUID
+	out.Status.State = in.Status.State
+	if in.Status.Members != nil {
+		out.Status.Members = make([]v1alpha1.GSLBServiceMemberHealth, len(in.Status.Members))
+		for i := range in.Status.Members {
+			out.Status.Members[i] = v1alpha1.GSLBServiceMemberHealth(in.Status.Members[i])
+		}
+	}
+	return out
+}
@@ -0,0 +1,513 @@
+/*
+ * Copyright 2019-2020 VMware, Inc.
+ * All Rights Reserved.
+* Licensed under the Apache License, Version 2.0 (the "License");
+* you may not use this file except in compliance with the License.
+* You may obtain a copy of the License at
+*   http://www.apache.org/licenses/LICENSE-2.0
+* Unless required by applicable law or agreed to in writing, software
+* distributed under the License is distributed on an "AS IS" BASIS,
+* WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+* See the License for the specific language governing permissions and
+* limitations under the License.
+*/
+
+package v1alpha2
+
+import metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+
+// GSLBConfig is the top-level type
+type GSLBConfig struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// spec for GSLB Config
+	Spec GSLBConfigSpec `json:"spec,omitempty"`
+	// +optional
+	Status GSLBConfigStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GSLBConfigList is a list of GSLBConfig resources
+type GSLBConfigList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GSLBConfig `json:"items"`
+}
+
+// GSLBConfigSpec is the GSLB configuration
+type GSLBConfigSpec struct {
+	GSLBLeader      GSLBLeader      `json:"gslbLeader,omitempty"`
+	MemberClusters  []MemberCluster `json:"memberClusters,omitempty"`
+	RefreshInterval int             `json:"refreshInterval,omitempty"`
+	LogLevel        string          `json:"logLevel,omitempty"`
+	// StaleMemberPolicy decides what's done with the GS members of a disconnected member cluster,
+	// the members are kept as is if it isn't set
+	StaleMemberPolicy *StaleMemberPolicy `json:"staleMemberPolicy,omitempty"`
+	// ClusterDiscovery adds the member clusters from the kubeconfig secrets it selects, along with
+	// the ones in MemberClusters
+	ClusterDiscovery *ClusterDiscovery `json:"clusterDiscovery,omitempty"`
+	// GarbageCollection periodically deletes the GSLB services and health monitors created by AMKO,
+	// which no longer belong to a GS, it's disabled if not set
+	GarbageCollection *GarbageCollection `json:"garbageCollection,omitempty"`
+}
+
+// GarbageCollection deletes the GSLB services and health monitors created by AMKO on the Avi
+// controller, which don't belong to any GS built by AMKO, once they are orphaned for the grace
+// period.
+type GarbageCollection struct {
+	// GracePeriod is the time in seconds for which an object has to be orphaned before it's deleted,
+	// 1800 seconds if not set
+	GracePeriod int `json:"gracePeriod,omitempty"`
+	// MaxDeletions is the safety threshold, if more objects are due to be deleted in a pass, none
+	// of them are deleted. 10 if not set.
+	MaxDeletions int `json:"maxDeletions,omitempty"`
+	// DryRun only reports the orphaned objects in the status, they aren't deleted
+	DryRun bool `json:"dryRun,omitempty"`
+}
+
+// ClusterDiscovery selects the kubeconfig secrets on the AMKO cluster from which member clusters
+// are discovered.
+type ClusterDiscovery struct {
+	// Format of the secrets, ClusterAPI or ArgoCD
+	Format string `json:"format,omitempty"`
+	// Namespace of the secrets, all the namespaces if not set
+	Namespace string `json:"namespace,omitempty"`
+	// Label selects the secrets, along with the labels of the format
+	Label map[string]string `json:"label,omitempty"`
+}
+
+// StaleMemberPolicy is applied on the GS members of a member cluster, once AMKO is disconnected
+// from the cluster for the grace period.
+type StaleMemberPolicy struct {
+	// Action is one of Keep, Disable or Remove
+	Action string `json:"action,omitempty"`
+	// GracePeriod is the time in seconds after which the action is applied, 300 seconds if not set
+	GracePeriod int `json:"gracePeriod,omitempty"`
+}
+
+// GSLBLeader is the leader node in the GSLB cluster
+type GSLBLeader struct {
+	Credentials       string `json:"credentials,omitempty"`
+	ControllerVersion string `json:"controllerVersion,omitempty"`
+	ControllerIP      string `json:"controllerIP,omitempty"`
+	// ControllerEndpoints are the controllers of the other GSLB sites, or their cluster VIPs, which
+	// AMKO re-targets to when the controller at ControllerIP is no longer the GSLB leader
+	ControllerEndpoints []string `json:"controllerEndpoints,omitempty"`
+	// TLS configures the verification of the certificate of the controller, it isn't verified if
+	// TLS isn't set
+	TLS *ControllerTLS `json:"tls,omitempty"`
+}
+
+// ControllerTLS configures the verification of the TLS certificate of the Avi controller.
+type ControllerTLS struct {
+	// CASecret is the name of the Secret in avi-system with the CA bundle in the ca.crt key, which
+	// the certificate is verified against. The system CAs are used if it isn't set.
+	CASecret string `json:"caSecret,omitempty"`
+	// ServerName overrides the host name which the certificate is verified for, the host of the
+	// controller IP if it isn't set
+	ServerName string `json:"serverName,omitempty"`
+	// Verification is one of Full, CAOnly or None, Full if not set. CAOnly verifies the certificate
+	// chain, but not the host name.
+	Verification string `json:"verification,omitempty"`
+}
+
+// MemberCluster defines a GSLB member cluster details
+type MemberCluster struct {
+	ClusterContext string `json:"clusterContext,omitempty"`
+	// Secret is the name of the Secret in avi-system with the credentials of the cluster, either a
+	// kubeconfig, or the API server address with a token or a client certificate
+	Secret string `json:"secret,omitempty"`
+}
+
+// GSLBConfigStatus represents the state of the GSLB configuration as a list of conditions, along
+// with the state of each member cluster.
+type GSLBConfigStatus struct {
+	// ObservedGeneration is the generation of the object last processed by AMKO
+	ObservedGeneration int64                 `json:"observedGeneration,omitempty"`
+	Conditions         []Condition           `json:"conditions,omitempty"`
+	MemberClusters     []MemberClusterStatus `json:"memberClusters,omitempty"`
+	// GarbageCollection is the report of the last garbage collection pass
+	GarbageCollection *GarbageCollectionStatus `json:"garbageCollection,omitempty"`
+}
+
+// GarbageCollectionStatus reports the orphaned objects found by a garbage collection pass, and
+// what was done with them.
+type GarbageCollectionStatus struct {
+	// LastRun is the time of the pass
+	LastRun metav1.Time `json:"lastRun,omitempty"`
+	// Message says why the objects due to be deleted weren't deleted, if so
+	Message string           `json:"message,omitempty"`
+	Orphans []OrphanedObject `json:"orphans,omitempty"`
+}
+
+// OrphanedObject is a GSLB service or a health monitor created by AMKO, which doesn't belong to any
+// GS built by AMKO.
+type OrphanedObject struct {
+	// Type is GSLBService or HealthMonitor
+	Type string `json:"type"`
+	Name string `json:"name"`
+	UUID string `json:"uuid"`
+	// OrphanedSince is the time at which the object was first found orphaned
+	OrphanedSince metav1.Time `json:"orphanedSince"`
+	// Action is Pending, Deleted, Skipped or Failed
+	Action string `json:"action"`
+}
+
+// Actions on the orphaned objects in the garbage collection report
+const (
+	// OrphanPending is the action on an object which is orphaned for less than the grace period
+	OrphanPending = "Pending"
+	// OrphanDeleted is the action on an object which was deleted
+	OrphanDeleted = "Deleted"
+	// OrphanSkipped is the action on an object which is due to be deleted, but wasn't deleted in the
+	// dry run mode, or as the safety threshold was exceeded
+	OrphanSkipped = "Skipped"
+	// OrphanFailed is the action on an object which couldn't be deleted, it's retried in the next pass
+	OrphanFailed = "Failed"
+)
+
+// MemberClusterStatus gives the state of a member cluster, its Ready condition is true once AMKO
+// is connected to the cluster.
+type MemberClusterStatus struct {
+	ClusterContext string `json:"clusterContext"`
+	// State is the connectivity of AMKO to the cluster: Connected, Degraded or Disconnected
+	State string `json:"state,omitempty"`
+	// StaleMembers is Disabled or Removed once the stale member policy is applied on the GS members
+	// of the disconnected cluster
+	StaleMembers string      `json:"staleMembers,omitempty"`
+	Conditions   []Condition `json:"conditions,omitempty"`
+}
+
+// Connectivity states of a member cluster
+const (
+	// ClusterConnected is the state of a cluster whose API server is reachable, its informers are
+	// running
+	ClusterConnected = "Connected"
+	// ClusterDegraded is the state of a cluster whose last health checks failed, its informers are
+	// kept running till the cluster is disconnected
+	ClusterDegraded = "Degraded"
+	// ClusterDisconnected is the state of a cluster which is unreachable, its informers are stopped
+	// till it's reachable again
+	ClusterDisconnected = "Disconnected"
+)
+
+// Values of the StaleMembers field of a member cluster
+const (
+	StaleMembersDisabled = "Disabled"
+	StaleMembersRemoved  = "Removed"
+)
+
+// how the Global services are going to be named
+const (
+	GSNameType = "HOSTNAME"
+)
+
+// +genclient
+// +genclient:noStatus
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+
+// GlobalDeploymentPolicy is the top-level type: Global Deployment Policy
+// encloses all the rules, actions and configuration required for deploying
+// applications.
+type GlobalDeploymentPolicy struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// spec for GSLB Config
+	Spec GDPSpec `json:"spec,omitempty"`
+	// +optional
+	Status GDPStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GlobalDeploymentPolicyList is a list of GDP resources
+type GlobalDeploymentPolicyList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GlobalDeploymentPolicy `json:"items"`
+}
+
+// GDPSpec encloses all the properties of a GDP object.
+type GDPSpec struct {
+	MatchRules    MatchRules      `json:"matchRules,omitempty"`
+	MatchClusters []string        `json:"matchClusters,omitempty"`
+	TrafficSplit  []ClusterWeight `json:"trafficSplit,omitempty"`
+	// DrainClusters is a list of clusters in maintenance mode. The members from these clusters
+	// are kept in the GSLB Services, but are disabled, so no traffic is routed to them.
+	DrainClusters []string `json:"drainClusters,omitempty"`
+	// ScheduledTrafficSplit is a list of time bounded traffic splits. While a window is active, its
+	// weights override the TrafficSplit weights for the clusters present in that window.
+	ScheduledTrafficSplit []ScheduledTrafficSplit `json:"scheduledTrafficSplit,omitempty"`
+	// TrafficShift progressively moves the traffic from one cluster to another, a step is advanced
+	// only if the GSLB Service members of the target cluster stay healthy.
+	TrafficShift *TrafficShift `json:"trafficShift,omitempty"`
+}
+
+// MatchRules is the match criteria needed to select the kubernetes/openshift objects.
+type MatchRules struct {
+	AppSelector       AppSelector       `json:"appSelector,omitempty"`
+	NamespaceSelector NamespaceSelector `json:"namespaceSelector,omitempty"`
+}
+
+// AppSelector selects the applications based on their labels
+type AppSelector struct {
+	Label map[string]string `json:"label,omitempty"`
+}
+
+// NamespaceSelector selects the applications based on their labels
+type NamespaceSelector struct {
+	Label map[string]string `json:"label,omitempty"`
+}
+
+// Objects on which rules will be applied
+const (
+	// RouteObj only applies to openshift Routes
+	RouteObj = "ROUTE"
+	// IngressObj applies to K8S Ingresses
+	IngressObj = "INGRESS"
+	// LBSvc applies to service type LoadBalancer
+	LBSvcObj = "LBSVC"
+	// NSObj applies to namespaces
+	NSObj = "Namespace"
+)
+
+// ClusterWeight determines how much traffic to be routed to a cluster. It's used by the traffic
+// splits of the GDP and GSLBHostRule objects.
+type ClusterWeight struct {
+	// Cluster is the cluster context
+	Cluster string `json:"cluster,omitempty"`
+	Weight  uint32 `json:"weight,omitempty"`
+}
+
+// ScheduledTrafficSplit is a traffic split which is applicable only between Start and End.
+type ScheduledTrafficSplit struct {
+	// Name of this window, it must be unique across all the windows of a GDP object
+	Name         string          `json:"name,omitempty"`
+	Start        metav1.Time     `json:"start,omitempty"`
+	End          metav1.Time     `json:"end,omitempty"`
+	TrafficSplit []ClusterWeight `json:"trafficSplit,omitempty"`
+}
+
+// TrafficShift moves the traffic from SourceCluster to TargetCluster in steps. Each step is the
+// percentage of traffic routed to TargetCluster, the rest is routed to SourceCluster. A step is
+// advanced once the members of TargetCluster have been healthy for SoakTime seconds, and the shift
+// is rolled back if any of them go down.
+type TrafficShift struct {
+	SourceCluster string `json:"sourceCluster,omitempty"`
+	TargetCluster string `json:"targetCluster,omitempty"`
+	Steps         []int  `json:"steps,omitempty"`
+	SoakTime      int    `json:"soakTime,omitempty"`
+}
+
+// TrafficShiftStatus gives the progress of a traffic shift.
+type TrafficShiftStatus struct {
+	// Step is the index of the current step in Steps
+	Step int `json:"step"`
+	// TargetPercent is the percentage of traffic currently routed to the target cluster
+	TargetPercent int `json:"targetPercent"`
+	// State is one of Progressing, Completed or RolledBack
+	State   string `json:"state,omitempty"`
+	Message string `json:"message,omitempty"`
+}
+
+// GDPStatus gives the current status of the policy object.
+type GDPStatus struct {
+	// ObservedGeneration is the generation of the object last processed by AMKO
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+	// DrainStatus gives the drain progress of each cluster in DrainClusters.
+	DrainStatus []ClusterDrainStatus `json:"drainStatus,omitempty"`
+	// ActiveTrafficSplitWindow is the name of the scheduled traffic split window currently active.
+	ActiveTrafficSplitWindow string `json:"activeTrafficSplitWindow,omitempty"`
+	// TrafficShift gives the progress of the traffic shift.
+	TrafficShift *TrafficShiftStatus `json:"trafficShift,omitempty"`
+}
+
+// ClusterDrainStatus gives the drain progress of a cluster. Members is the number of GSLB Service
+// members from this cluster, out of which DisabledMembers are disabled on the Avi controller.
+type ClusterDrainStatus struct {
+	Cluster         string `json:"cluster,omitempty"`
+	Members         int    `json:"members"`
+	DisabledMembers int    `json:"disabledMembers"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+
+// GSLBHostRule is the top-level type which allows a user to override certain
+// fields of a GSLB Service.
+type GSLBHostRule struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// spec for GSLB Config
+	Spec GSLBHostRuleSpec `json:"spec,omitempty"`
+	// +optional
+	Status GSLBHostRuleStatus `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GSLBHostRuleList is a list of GSLBHostRule resources
+type GSLBHostRuleList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GSLBHostRule `json:"items"`
+}
+
+// GSLBHostRuleSpec defines all the properties of a GSLB Service that can be overriden
+// by a user.
+type GSLBHostRuleSpec struct {
+	// Fqdn is the fqdn of the GSLB Service for which the below properties can be
+	// changed.
+	Fqdn string `json:"fqdn,omitempty"`
+	// TTL is Time To Live in seconds. This tells a DNS resolver how long to hold this DNS
+	// record.
+	TTL int `json:"ttl,omitempty"`
+	// SitePersistence if enabled, enables stickiness to the same site where the connection
+	// from the client was initiated to.
+	// +optional
+	SitePersistence *SitePersistence `json:"sitePersistence,omitempty"`
+	// HealthMonitoreRefs is a list of custom health monitors which will monitor the
+	// GSLB Service's pool members.
+	HealthMonitorRefs []string `json:"healthMonitorRefs,omitempty"`
+	// TrafficSplit defines the weightage of traffic that can be routed to each cluster.
+	TrafficSplit []ClusterWeight `json:"trafficSplit,omitempty"`
+	// AdoptExistingGS if set to true, makes AMKO take over a GSLB Service for the fqdn which
+	// already exists on the Avi controller and wasn't created by AMKO, instead of creating a new one.
+	AdoptExistingGS bool `json:"adoptExistingGS,omitempty"`
+}
+
+// SitePersistence defines the site stickiness of a GSLB Service
+type SitePersistence struct {
+	Enabled bool `json:"enabled"`
+	// ProfileRef is a custom site persistence profile, applied on the GSLB Service
+	ProfileRef string `json:"profileRef,omitempty"`
+}
+
+// GSLBHostRuleStatus contains the current state of the GSLBHostRule resource as a list of
+// conditions.
+type GSLBHostRuleStatus struct {
+	// ObservedGeneration is the generation of the object last processed by AMKO
+	ObservedGeneration int64       `json:"observedGeneration,omitempty"`
+	Conditions         []Condition `json:"conditions,omitempty"`
+}
+
+// +genclient
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+// +k8s:openapi-gen=true
+
+// GSLBServiceStatus is a read-only object created by AMKO for each GSLB Service. It reports the
+// runtime health of the GSLB Service and its members as seen by the Avi controller.
+type GSLBServiceStatus struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ObjectMeta `json:"metadata,omitempty"`
+	// +optional
+	Status GSLBServiceHealth `json:"status,omitempty"`
+}
+
+// +k8s:deepcopy-gen:interfaces=k8s.io/apimachinery/pkg/runtime.Object
+
+// GSLBServiceStatusList is a list of GSLBServiceStatus resources
+type GSLBServiceStatusList struct {
+	metav1.TypeMeta `json:",inline"`
+	// +optional
+	metav1.ListMeta `json:"metadata,omitempty"`
+	Items           []GSLBServiceStatus `json:"items"`
+}
+
+// GSLBServiceHealth is the runtime health of a GSLB Service.
+type GSLBServiceHealth struct {
+	// Fqdn is the domain name of the GSLB Service
+	Fqdn string `json:"fqdn,omitempty"`
+	// UUID of the GSLB Service on the Avi controller
+	UUID string `json:"uuid,omitempty"`
+	// State is the operational state of the GSLB Service: Up, Down, Disabled or Unknown
+	State   string                    `json:"state,omitempty"`
+	Members []GSLBServiceMemberHealth `json:"members,omitempty"`
+}
+
+// GSLBServiceMemberHealth is the runtime health of a GSLB Service member, along with the object
+// from which the member was derived.
+type GSLBServiceMemberHealth struct {
+	Cluster   string `json:"cluster,omitempty"`
+	ObjType   string `json:"objType,omitempty"`
+	Namespace string `json:"namespace,omitempty"`
+	Name      string `json:"name,omitempty"`
+	IPAddr    string `json:"ipAddr,omitempty"`
+	// State is the operational state of the member: Up, Down, Disabled or Unknown
+	State string `json:"state,omitempty"`
+}
+
+// Condition types
+const (
+	// ConditionAccepted is true once the object is validated and applied by AMKO
+	ConditionAccepted = "Accepted"
+	// ConditionClustersReady is true once AMKO is connected to all the member clusters
+	ConditionClustersReady = "ClustersReady"
+	// ConditionControllerReachable is true if the Avi controller is reachable and is the GSLB leader
+	ConditionControllerReachable = "ControllerReachable"
+	// ConditionSynced is true once the objects from the member clusters are synced to the Avi
+	// controller
+	ConditionSynced = "Synced"
+	// ConditionReady is the condition of a member cluster, true once AMKO is connected to it
+	ConditionReady = "Ready"
+	// ConditionTLSVerified is true if the certificate of the Avi controller is verified, it's false
+	// if the verification is disabled
+	ConditionTLSVerified = "TLSVerified"
+)
+
+// Condition reasons
+const (
+	ReasonAccepted         = "Accepted"
+	ReasonRejected         = "Rejected"
+	ReasonPending          = "Pending"
+	ReasonInvalidConfig    = "InvalidConfig"
+	ReasonAlreadyExists    = "AlreadyExists"
+	ReasonSecretNotFound   = "SecretNotFound"
+	ReasonConnected        = "Connected"
+	ReasonConnectionFailed = "ConnectionFailed"
+	ReasonNotLeader        = "NotLeader"
+	ReasonKubeConfigError  = "KubeConfigError"
+	ReasonSyncing          = "Syncing"
+	ReasonSyncComplete     = "SyncComplete"
+	ReasonRestartRequired  = "RestartRequired"
+	ReasonDegraded         = "Degraded"
+	ReasonDisconnected     = "Disconnected"
+	// reasons of the TLSVerified condition
+	ReasonTLSVerified             = "Verified"
+	ReasonTLSVerificationFailed   = "VerificationFailed"
+	ReasonTLSVerificationDisabled = "VerificationDisabled"
+)
+
+// Condition is the state of one aspect of an AMKO object, in the format of the conditions of the
+// kubernetes objects.
+type Condition struct {
+	// Type of the condition
+	Type string `json:"type"`
+	// Status is one of True, False or Unknown
+	Status metav1.ConditionStatus `json:"status"`
+	// ObservedGeneration is the generation of the object when this condition was set
+	// +optional
+	ObservedGeneration int64 `json:"observedGeneration,omitempty"`
+	// LastTransitionTime is the last time when the status of the condition changed
+	LastTransitionTime metav1.Time `json:"lastTransitionTime,omitempty"`
+	// Reason is a CamelCase reason for the last transition
+	Reason string `json:"reason,omitempty"`
+	// Message is a human readable description of the condition
+	Message string `json:"message,omitempty"`
+}
@@ -22,6 +22,7 @@ import (
 	"fmt"
 
 	amkov1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned/typed/amko/v1alpha1"
+	amkov1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned/typed/amko/v1alpha2"
 
 	discovery "k8s.io/client-go/discovery"
 	rest "k8s.io/client-go/rest"
@@ -31,6 +32,7 @@ import (
 type Interface interface {
 	Discovery() discovery.DiscoveryInterface
 	AmkoV1alpha1() amkov1alpha1.AmkoV1alpha1Interface
+	AmkoV1alpha2() amkov1alpha2.AmkoV1alpha2Interface
 }
 
 // Clientset contains the clients for groups. Each group has exactly one
@@ -38,6 +40,7 @@ type Interface interface {
 type Clientset struct {
 	*discovery.DiscoveryClient
 	amkoV1alpha1 *amkov1alpha1.AmkoV1alpha1Client
+	amkoV1alpha2 *amkov1alpha2.AmkoV1alpha2Client
 }
 
 // AmkoV1alpha1 retrieves the AmkoV1alpha1Client
@@ -45,6 +48,11 @@ func (c *Clientset) AmkoV1alpha1() amkov1alpha1.AmkoV1alpha1Interface {
 	return c.amkoV1alpha1
 }
 
+// AmkoV1alpha2 retrieves the AmkoV1alpha2Client
+func (c *Clientset) AmkoV1alpha2() amkov1alpha2.AmkoV1alpha2Interface {
+	return c.amkoV1alpha2
+}
+
 // Discovery retrieves the DiscoveryClient
 func (c *Clientset) Discovery() discovery.DiscoveryInterface {
 	if c == nil {
@@ -70,6 +78,10 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 	if err != nil {
 		return nil, err
 	}
+	cs.amkoV1alpha2, err = amkov1alpha2.NewForConfig(&configShallowCopy)
+	if err != nil {
+		return nil, err
+	}
 
 	cs.DiscoveryClient, err = discovery.NewDiscoveryClientForConfig(&configShallowCopy)
 	if err != nil {
@@ -83,6 +95,7 @@ func NewForConfig(c *rest.Config) (*Clientset, error) {
 func NewForConfigOrDie(c *rest.Config) *Clientset {
 	var cs Clientset
 	cs.amkoV1alpha1 = amkov1alpha1.NewForConfigOrDie(c)
+	cs.amkoV1alpha2 = amkov1alpha2.NewForConfigOrDie(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClientForConfigOrDie(c)
 	return &cs
@@ -92,6 +105,7 @@ func NewForConfigOrDie(c *rest.Config) *Clientset {
 func New(c rest.Interface) *Clientset {
 	var cs Clientset
 	cs.amkoV1alpha1 = amkov1alpha1.New(c)
+	cs.amkoV1alpha2 = amkov1alpha2.New(c)
 
 	cs.DiscoveryClient = discovery.NewDiscoveryClient(c)
 	return &cs
@@ -21,13 +21,14 @@ package v1alpha1
 import (
 	v1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
 	"github.com/vmware/global-load-balancing-services-for-kubernetes/internal/client/clientset/versioned/scheme"
-
 	rest "k8s.io/client-go/rest"
 )
 
 type AmkoV1alpha1Interface interface {
 	RESTClient() rest.Interface
 	GSLBConfigsGetter
+	GSLBHostRulesGetter
+	GSLBServiceStatusesGetter
 	GlobalDeploymentPoliciesGetter
 }
 
@@ -40,6 +41,14 @@ func (c *AmkoV1alpha1Client) GSLBConfigs(namespace string) GSLBConfigInterface {
 	return newGSLBConfigs(c, namespace)
 }
 
+func (c *AmkoV1alpha1Client) GSLBHostRules(namespace string) GSLBHostRuleInterface {
+	return newGSLBHostRules(c, namespace)
+}
+
+func (c *AmkoV1alpha1Client) GSLBServiceStatuses(namespace string) GSLBServiceStatusInterface {
+	return newGSLBServiceStatuses(c, namespace)
+}
+
 func (c *AmkoV1alpha1Client) GlobalDeploymentPolicies(namespace string) GlobalDeploymentPolicyInterface {
 	return newGlobalDeploymentPolicies(c, namespace)
 }
@@ -20,4 +20,8 @@ package v1alpha1
 
 type GSLBConfigExpansion interface{}
 
+type GSLBHostRuleExpansion interface{}
+
+type GSLBServiceStatusExpansion interface{}
+
 type GlobalDeploymentPolicyExpansion interface{}
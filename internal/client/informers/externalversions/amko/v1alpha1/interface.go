@@ -26,6 +26,10 @@ import (
 type Interface interface {
 	// GSLBConfigs returns a GSLBConfigInformer.
 	GSLBConfigs() GSLBConfigInformer
+	// GSLBHostRules returns a GSLBHostRuleInformer.
+	GSLBHostRules() GSLBHostRuleInformer
+	// GSLBServiceStatuses returns a GSLBServiceStatusInformer.
+	GSLBServiceStatuses() GSLBServiceStatusInformer
 	// GlobalDeploymentPolicies returns a GlobalDeploymentPolicyInformer.
 	GlobalDeploymentPolicies() GlobalDeploymentPolicyInformer
 }
@@ -46,6 +50,16 @@ func (v *version) GSLBConfigs() GSLBConfigInformer {
 	return &gSLBConfigInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
 }
 
+// GSLBHostRules returns a GSLBHostRuleInformer.
+func (v *version) GSLBHostRules() GSLBHostRuleInformer {
+	return &gSLBHostRuleInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
+// GSLBServiceStatuses returns a GSLBServiceStatusInformer.
+func (v *version) GSLBServiceStatuses() GSLBServiceStatusInformer {
+	return &gSLBServiceStatusInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
+}
+
 // GlobalDeploymentPolicies returns a GlobalDeploymentPolicyInformer.
 func (v *version) GlobalDeploymentPolicies() GlobalDeploymentPolicyInformer {
 	return &globalDeploymentPolicyInformer{factory: v.factory, namespace: v.namespace, tweakListOptions: v.tweakListOptions}
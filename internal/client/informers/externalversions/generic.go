@@ -22,7 +22,7 @@ import (
 	"fmt"
 
 	v1alpha1 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha1"
-
+	v1alpha2 "github.com/vmware/global-load-balancing-services-for-kubernetes/internal/apis/amko/v1alpha2"
 	schema "k8s.io/apimachinery/pkg/runtime/schema"
 	cache "k8s.io/client-go/tools/cache"
 )
@@ -56,9 +56,23 @@ func (f *sharedInformerFactory) ForResource(resource schema.GroupVersionResource
 	// Group=amko.vmware.com, Version=v1alpha1
 	case v1alpha1.SchemeGroupVersion.WithResource("gslbconfigs"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Amko().V1alpha1().GSLBConfigs().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("gslbhostrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Amko().V1alpha1().GSLBHostRules().Informer()}, nil
+	case v1alpha1.SchemeGroupVersion.WithResource("gslbservicestatuses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Amko().V1alpha1().GSLBServiceStatuses().Informer()}, nil
 	case v1alpha1.SchemeGroupVersion.WithResource("globaldeploymentpolicies"):
 		return &genericInformer{resource: resource.GroupResource(), informer: f.Amko().V1alpha1().GlobalDeploymentPolicies().Informer()}, nil
 
+	// Group=amko.vmware.com, Version=v1alpha2
+	case v1alpha2.SchemeGroupVersion.WithResource("gslbconfigs"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Amko().V1alpha2().GSLBConfigs().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("gslbhostrules"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Amko().V1alpha2().GSLBHostRules().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("gslbservicestatuses"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Amko().V1alpha2().GSLBServiceStatuses().Informer()}, nil
+	case v1alpha2.SchemeGroupVersion.WithResource("globaldeploymentpolicies"):
+		return &genericInformer{resource: resource.GroupResource(), informer: f.Amko().V1alpha2().GlobalDeploymentPolicies().Informer()}, nil
+
 	}
 
 	return nil, fmt.Errorf("no informer found for %v", resource)
@@ -26,6 +26,22 @@ type GSLBConfigListerExpansion interface{}
 // GSLBConfigNamespaceLister.
 type GSLBConfigNamespaceListerExpansion interface{}
 
+// GSLBHostRuleListerExpansion allows custom methods to be added to
+// GSLBHostRuleLister.
+type GSLBHostRuleListerExpansion interface{}
+
+// GSLBHostRuleNamespaceListerExpansion allows custom methods to be added to
+// GSLBHostRuleNamespaceLister.
+type GSLBHostRuleNamespaceListerExpansion interface{}
+
+// GSLBServiceStatusListerExpansion allows custom methods to be added to
+// GSLBServiceStatusLister.
+type GSLBServiceStatusListerExpansion interface{}
+
+// GSLBServiceStatusNamespaceListerExpansion allows custom methods to be added to
+// GSLBServiceStatusNamespaceLister.
+type GSLBServiceStatusNamespaceListerExpansion interface{}
+
 // GlobalDeploymentPolicyListerExpansion allows custom methods to be added to
 // GlobalDeploymentPolicyLister.
 type GlobalDeploymentPolicyListerExpansion interface{}